@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"customclaude/pkg/mcp"
+	"customclaude/pkg/mcpapproval"
+)
+
+// approvalServerCommand runs this binary as the MCP permission server
+const approvalServerCommand = "mcp-approval"
+
+// runApprovalServer serves claude's permission prompts over stdio, passing
+// them to the TUI whose socket is in the environment. Stdout belongs to
+// the MCP protocol, so nothing else may be printed.
+func runApprovalServer() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := &mcpapproval.Server{Socket: os.Getenv(mcpapproval.SocketEnv), Log: os.Stderr}
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// addApprovalServer adds this binary as the permission server claude's
+// --permission-prompt-tool calls, unless an MCP config file defines its own
+func addApprovalServer(manager *mcp.Manager, socket string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the executable: %w", err)
+	}
+	definition := mcpapproval.ServerDefinition(executable, []string{approvalServerCommand}, socket)
+	return manager.SetBuiltin(mcpapproval.ServerName, definition)
+}
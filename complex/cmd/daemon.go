@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"complex/internal/claude"
+	"complex/internal/config"
+	"complex/internal/notify"
+	"complex/internal/schedule"
+	"complex/internal/sound"
+)
+
+// runDaemon runs the configured schedules without the TUI until ctx is
+// cancelled. Every run is a new conversation saved to the session store and
+// tagged "scheduled", so it can be reviewed with /resume.
+func runDaemon(ctx context.Context, cfg config.Config, newSession func() *claude.SessionManager) error {
+	notifier, err := notify.NewDispatcher(cfg.Notify, map[string]notify.Notifier{
+		"sound": notify.SoundNotifier{Player: sound.NewPlayer(cfg.Sound)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+
+	run := func(ctx context.Context, job schedule.Job) error {
+		sm := newSession()
+		defer sm.ReleaseSessionLock()
+		if job.ReadOnly {
+			sm.SetReadOnly(true)
+		}
+
+		// Runs are skipped, not overridden, once a budget is spent
+		if alert, exceeded := sm.BudgetExceeded(); exceeded {
+			fmt.Printf("%s  %s: skipped, %s\n", time.Now().Format(time.DateTime), job.Name, alert)
+			notifier.Notify(notify.Notification{
+				Event: notify.EventBudget,
+				Title: fmt.Sprintf("Scheduled run %q skipped", job.Name),
+				Body:  alert.String(),
+			})
+			return errors.New(alert.String())
+		}
+
+		fmt.Printf("%s  %s: running\n", time.Now().Format(time.DateTime), job.Name)
+		turn, err := sm.RunTurn(ctx, job.Prompt, false, job.Model)
+
+		// Without a session store there is nothing to tag
+		for _, tag := range []string{"scheduled", "schedule:" + job.Name} {
+			sm.TagConversations([]string{sm.ConversationID()}, tag, true)
+		}
+
+		if err != nil {
+			fmt.Printf("%s  %s: failed: %v\n", time.Now().Format(time.DateTime), job.Name, err)
+			notifier.Notify(notify.Notification{
+				Event: notify.EventError,
+				Title: fmt.Sprintf("Scheduled run %q failed", job.Name),
+				Body:  err.Error(),
+			})
+			return err
+		}
+
+		fmt.Printf("%s  %s: done, $%.4f\n", time.Now().Format(time.DateTime), job.Name, turn.CostUSD)
+		notifier.Notify(notify.Notification{
+			Event: notify.EventTurnComplete,
+			Title: fmt.Sprintf("Scheduled run %q finished", job.Name),
+			Body:  truncate(turn.Result, 200),
+		})
+		return nil
+	}
+
+	scheduler, err := schedule.New(cfg.Schedule, run)
+	if err != nil {
+		return err
+	}
+	if cfg.Update.Check {
+		go watchUpdates(ctx, newUpdateChecker(cfg), notifier)
+	}
+	for name, next := range scheduler.NextRuns(time.Now()) {
+		fmt.Printf("Scheduled %s, next run %s\n", name, next.Format(time.DateTime))
+	}
+	if err := scheduler.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// truncate shortens text to at most n characters for notifications, never
+// splitting one
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-3]) + "..."
+}
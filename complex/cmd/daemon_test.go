@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly ten", 11, "exactly ten"},
+		{"a longer result text", 10, "a longe..."},
+		{"héllo wörld, ça va", 10, "héllo w..."},
+		{"日本語のテキストです", 6, "日本語..."},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.text, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+		}
+	}
+}
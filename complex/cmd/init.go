@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"complex/internal/claude"
+	"complex/internal/config"
+	"customclaude/pkg/claudecli"
+	"customclaude/pkg/platform"
+)
+
+// isInteractive reports whether stdin is a terminal, so setup can ask
+// questions
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runSetup walks through writing config.toml: it checks the claude CLI,
+// writes a starter MCP config, and asks for the default model and theme. On
+// the first run it can be declined, leaving the defaults in place.
+func runSetup(ctx context.Context, in io.Reader, out io.Writer, firstRun bool) error {
+	reader := bufio.NewReader(in)
+	ask := func(question, fallback string) (string, error) {
+		fmt.Fprintf(out, "%s [%s]: ", question, fallback)
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			return fallback, nil
+		}
+		return line, nil
+	}
+	choose := func(question string, options []string, fallback string) (string, error) {
+		fmt.Fprintln(out, question)
+		for i, option := range options {
+			fmt.Fprintf(out, "  %d) %s\n", i+1, option)
+		}
+		answer, err := ask("Choice (number or name)", fallback)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		return answer, nil
+	}
+	confirm := func(question string, fallback bool) (bool, error) {
+		hint := "y/N"
+		if fallback {
+			hint = "Y/n"
+		}
+		answer, err := ask(question, hint)
+		if err != nil || answer == hint {
+			return fallback, err
+		}
+		return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	if firstRun {
+		fmt.Fprintf(out, "No configuration found at %s.\n", path)
+		setup, err := confirm("Run setup now? (run `init` later otherwise)", true)
+		if err != nil || !setup {
+			fmt.Fprintln(out, "Starting with the default settings.")
+			return err
+		}
+	} else if exists, _ := config.Exists(); exists {
+		overwrite, err := confirm(fmt.Sprintf("%s exists. Overwrite it?", path), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			fmt.Fprintln(out, "Setup cancelled; the configuration is unchanged.")
+			return nil
+		}
+	}
+
+	// The claude CLI does all the work, so check it first
+	fmt.Fprintln(out)
+	if binary, err := claudecli.FindBinary(); err != nil {
+		fmt.Fprintln(out, "✗ claude CLI: not found in PATH. Install it with `npm install -g @anthropic-ai/claude-code`.")
+		fmt.Fprintf(out, "  Without it, set kind = %q under [backend] to chat through the API with $%s (no tools).\n", claude.BackendAPI, claude.DefaultAPIKeyEnv)
+		proceed, err := confirm("Continue anyway?", false)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			return fmt.Errorf("claude CLI not found in PATH")
+		}
+	} else {
+		fmt.Fprintf(out, "✓ claude CLI: %s (%s)\n", binary, claudeVersion(ctx, binary))
+	}
+	fmt.Fprintln(out)
+
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return err
+	}
+	defaults := config.Default()
+	model, err := choose("Default model:", config.Models, config.Models[0])
+	if err != nil {
+		return err
+	}
+	theme, err := choose("Theme:", config.Themes, defaults.Theme)
+	if err != nil {
+		return err
+	}
+	mcpConfig, err := ask("MCP config file for extra servers (created empty when missing)",
+		filepath.Join(dir, "config.json"))
+	if err != nil {
+		return err
+	}
+	if mcpConfig, err = filepath.Abs(mcpConfig); err != nil {
+		return fmt.Errorf("failed to resolve MCP config path: %w", err)
+	}
+	labels := make([]string, 0, len(claude.AllowlistPresets)+1)
+	for _, preset := range claude.AllowlistPresets {
+		labels = append(labels, preset.Name+" - "+preset.Description)
+	}
+	labels = append(labels, "none - claude's own permission settings")
+	answer, err := choose("Tool allowlist (a project can pick another with /allowlist):", labels, "full-dev")
+	if err != nil {
+		return err
+	}
+	allowlist, _, _ := strings.Cut(answer, " - ")
+	if allowlist == "none" {
+		allowlist = ""
+	} else if _, ok := claude.FindAllowlist(allowlist); !ok {
+		return fmt.Errorf("unknown allowlist %q", allowlist)
+	}
+
+	written, err := config.WriteStarter(config.Starter{Model: model, Theme: theme, MCPConfig: mcpConfig, Allowlist: allowlist})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nWrote %s\n", written)
+	return nil
+}
+
+// claudeVersion returns the output of claude --version
+func claudeVersion(ctx context.Context, binary string) string {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, binary, "--version").Output()
+	if err != nil {
+		return "version unknown: " + err.Error()
+	}
+	return strings.TrimSpace(string(out))
+}
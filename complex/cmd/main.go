@@ -2,18 +2,44 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
 
 	"complex/internal/app"
 	"complex/internal/claude"
+	"complex/internal/config"
+	"complex/internal/logging"
+	"complex/internal/update"
+	"customclaude/pkg/mcp"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"customclaude/pkg/mcpapproval"
+	"customclaude/pkg/platform"
 )
 
 func main() {
+	readOnly := flag.Bool("read-only", false, "disallow write tools and run Claude in plan mode")
+	a11y := flag.Bool("a11y", false, "screen-reader friendly linear output without panels or alternate screen")
+	diffContext := flag.Bool("diff-context", false, "prepend the git diff since the last turn to each prompt")
+	logLevel := flag.String("log-level", "", "log level: debug, info, warn or error (default from config, info)")
+	daemon := flag.Bool("daemon", false, "run the [[schedule]] prompts from the config without the TUI")
+	cwd := flag.String("cwd", "", "directory to run claude in (default the current directory)")
+	replay := flag.String("replay", "", "answer every prompt by replaying this recorded stream-json file instead of running claude")
+	replayDelay := flag.Duration("replay-delay", 40*time.Millisecond, "pause before each replayed line")
+	flag.Parse()
+
+	// Claude starts this binary again as the MCP permission server
+	if flag.Arg(0) == approvalServerCommand {
+		runApprovalServer()
+		return
+	}
+
 	// Set up signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -26,28 +52,217 @@ func main() {
 		cancel()
 	}()
 
-	// Create session manager
-	sessionManager := claude.NewSessionManager()
+	// "init" runs setup alone; a first interactive run offers it too
+	if flag.Arg(0) == "init" {
+		if err := runSetup(ctx, os.Stdin, os.Stdout, false); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if exists, err := config.Exists(); err == nil && !exists && !*daemon && flag.NArg() == 0 && isInteractive() {
+		if err := runSetup(ctx, os.Stdin, os.Stdout, true); err != nil {
+			fmt.Printf("Warning: setup failed: %v\n", err)
+		}
+	}
+
+	// Load user configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if *diffContext {
+		cfg.DiffContext.Enabled = true
+	}
+	if *logLevel != "" {
+		cfg.LogLevel = *logLevel
+	}
+
+	// Log to a file; the terminal belongs to the TUI
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	logPath := cfg.LogFile
+	if logPath == "" {
+		if logPath, err = logging.DefaultPath(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+	closeLog, err := logging.Setup(logPath, level)
+	if err != nil {
+		fmt.Printf("Warning: logging to file disabled: %v\n", err)
+	}
+	defer closeLog()
+	logging.For("main").Info("starting", "log_level", level.String(), "read_only", *readOnly, "daemon", *daemon)
+
+	// "version", "update" and "usage" print and exit
+	switch flag.Arg(0) {
+	case "version":
+		fmt.Println(update.Current())
+		return
+	case "usage":
+		if err := runUsageReport(flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "update":
+		if err := runUpdateCheck(ctx, newUpdateChecker(cfg), os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Persist conversations so they survive restarts, in local JSON files
+	// when the configured backend cannot be opened
+	store, err := claude.NewSessionStore(cfg.Storage)
+	if err != nil && cfg.Storage.Backend != "" && cfg.Storage.Backend != claude.StoreBackendJSON {
+		fmt.Printf("Warning: %s session storage unavailable, using local JSON files: %v\n", cfg.Storage.Backend, err)
+		store, err = claude.NewSessionStore(claude.StoreConfig{})
+	}
+	if err != nil {
+		fmt.Printf("Warning: session persistence disabled: %v\n", err)
+	}
+
+	// Merge the configured MCP config files; without [mcp] files the legacy
+	// mcp_config file is used alone
+	mcpConfigPath := cfg.MCPConfig
+	if len(cfg.MCP.Files) == 0 {
+		// A missing legacy file leaves the bundled permission server alone
+		if _, err := os.Stat(cfg.MCPConfig); err == nil {
+			cfg.MCP.Files = []string{cfg.MCPConfig}
+		}
+	}
+	if cfg.Approval.Socket == "" {
+		cfg.Approval.Socket = mcpapproval.DefaultSocket()
+	}
+	var mcpManager *mcp.Manager
+	var spendLedger, usageLedger string
+	if dir, err := platform.ConfigDir(); err == nil {
+		spendLedger = filepath.Join(dir, "spend.json")
+		usageLedger = filepath.Join(dir, usageLedgerFile)
+		mcpManager, err = mcp.NewManager(cfg.MCP, filepath.Join(dir, "mcp-merged.json"))
+		if err != nil {
+			fmt.Printf("Warning: MCP server management disabled: %v\n", err)
+		} else {
+			mcpConfigPath = mcpManager.MergedPath()
+			if err := addApprovalServer(mcpManager, cfg.Approval.Socket); err != nil {
+				fmt.Printf("Warning: bundled permission server disabled: %v\n", err)
+			}
+		}
+	}
+
+	// The CLI, or the Messages API for machines without it
+	runner, err := claude.NewRunner(cfg.Backend)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Check --cwd once; every session starts there
+	if *cwd != "" {
+		if *cwd, err = claude.ResolveDir(*cwd); err != nil {
+			fmt.Printf("Error: --cwd: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	for name := range cfg.Env {
+		if !claude.ValidEnvName(name) {
+			fmt.Printf("Warning: ignoring invalid environment variable name %q in [env]\n", name)
+			delete(cfg.Env, name)
+		}
+	}
+
+	// newSession creates a configured session manager; each tab gets its own
+	newSession := func() *claude.SessionManager {
+		sessionManager := claude.NewSessionManager()
+		sessionManager.Model = cfg.Model
+		sessionManager.MCPConfigPath = mcpConfigPath
+		sessionManager.PermissionTool = cfg.PermissionTool
+		sessionManager.SetReadOnly(*readOnly)
+		sessionManager.SetAllowlist(cfg.Allowlist)
+		if *cwd != "" {
+			sessionManager.SetDir(*cwd)
+		}
+		for name, value := range cfg.Env {
+			sessionManager.SetEnv(name, value)
+		}
+		// A permission server from an MCP config file finds the TUI this way
+		sessionManager.SetEnv(mcpapproval.SocketEnv, cfg.Approval.Socket)
+		sessionManager.SetStreamMirror(cfg.StreamMirror)
+		if *replay != "" {
+			sessionManager.SetReplay(*replay, *replayDelay)
+		} else {
+			sessionManager.SetRunner(runner)
+		}
+
+		// Retry on a fallback model when the primary one is overloaded
+		sessionManager.SetFallbackModel(cfg.FallbackModel)
+
+		// Retry failed invocations with exponential backoff
+		sessionManager.SetRetryPolicy(cfg.Retries)
+		sessionManager.SetTurnTimeout(cfg.TurnTimeout)
+		sessionManager.SetMaxLineBytes(cfg.MaxLineBytes)
+		sessionManager.SetContextWindow(cfg.ContextWindow)
+
+		// Keep Claude aware of edits made between turns
+		sessionManager.SetDiffContext(cfg.DiffContext)
+
+		// Warn about, and optionally stop, spending past the budget
+		sessionManager.SetBudget(cfg.Budget, spendLedger)
+		sessionManager.SetUsageLedger(usageLedger)
+
+		if store != nil {
+			sessionManager.SetStore(store)
+		}
+
+		// Last, so the settings above are the ones a profile replaces
+		sessionManager.SetProfiles(cfg.Profiles)
+		return sessionManager
+	}
+
+	if *daemon {
+		if err := runDaemon(ctx, cfg, newSession); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	sessionManager := newSession()
 
 	// Create application
-	tuiApp, err := app.NewApplication(ctx, sessionManager)
+	tuiApp, err := app.NewApplication(ctx, sessionManager, cfg)
 	if err != nil {
 		fmt.Printf("Error creating application: %v\n", err)
 		os.Exit(1)
 	}
+	tuiApp.SetSessionFactory(newSession)
+	if mcpManager != nil {
+		tuiApp.SetMCPManager(mcpManager)
+	}
+	tuiApp.SetUpdateChecker(newUpdateChecker(cfg))
 
 	// Create bubbletea program
-	program := tea.NewProgram(
-		tuiApp,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+	options := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if *a11y {
+		tuiApp.SetLinearMode(true)
+		options = nil
+	}
+	program := tea.NewProgram(tuiApp, options...)
 
 	// Set the program in the application for shutdown handling
 	tuiApp.SetProgram(program)
 
 	// Start the program
-	if _, err := program.Run(); err != nil {
+	_, err = program.Run()
+	tuiApp.Shutdown()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
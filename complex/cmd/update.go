@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"complex/internal/config"
+	"complex/internal/notify"
+	"complex/internal/update"
+	"customclaude/pkg/platform"
+)
+
+// newUpdateChecker creates the release checker, caching checks in the
+// config directory
+func newUpdateChecker(cfg config.Config) *update.Checker {
+	cachePath := ""
+	if dir, err := platform.ConfigDir(); err == nil {
+		cachePath = filepath.Join(dir, "update.json")
+	}
+	return update.NewChecker(cfg.Update, cachePath)
+}
+
+// runUpdateCheck runs "update": it asks for the latest releases whether or
+// not the check is enabled, and prints the notes of the newer ones and the
+// upgrade command
+func runUpdateCheck(ctx context.Context, checker *update.Checker, out io.Writer) error {
+	result, err := checker.Refresh(ctx)
+	if err != nil {
+		return err
+	}
+	latest, ok := result.Latest()
+	if !ok {
+		fmt.Fprintf(out, "Running %s; no releases are published yet.\n", result.Current)
+		return nil
+	}
+
+	newer := result.Newer()
+	switch {
+	case result.Current == "dev":
+		fmt.Fprintf(out, "Running a development build; the latest release is %s.\n", latest.Tag)
+	case len(newer) == 0:
+		fmt.Fprintf(out, "%s is the latest release.\n", result.Current)
+		return nil
+	default:
+		fmt.Fprintf(out, "%s is available (running %s).\n", latest.Tag, result.Current)
+	}
+
+	if len(newer) == 0 {
+		newer = []update.Release{latest}
+	}
+	for _, release := range newer {
+		fmt.Fprintf(out, "\n== %s (%s)\n\n%s\n", release.Tag, release.Published.Format(time.DateOnly), release.Notes)
+	}
+	fmt.Fprintf(out, "\nUpgrade with:\n  %s\n", checker.Config().UpgradeCommand(latest.Tag))
+	return nil
+}
+
+// watchUpdates checks for releases every interval while the daemon runs,
+// announcing each newer release once
+func watchUpdates(ctx context.Context, checker *update.Checker, notifier *notify.Dispatcher) {
+	interval := checker.Config().Interval
+	if interval <= 0 {
+		interval = update.DefaultConfig().Interval
+	}
+	announced := ""
+	for {
+		result, err := checker.Check(ctx)
+		if err != nil {
+			fmt.Printf("%s  update check failed: %v\n", time.Now().Format(time.DateTime), err)
+		} else if latest, _ := result.Latest(); result.Available() && latest.Tag != announced {
+			announced = latest.Tag
+			command := checker.Config().UpgradeCommand(latest.Tag)
+			fmt.Printf("%s  %s is available (running %s); upgrade with: %s\n",
+				time.Now().Format(time.DateTime), latest.Tag, result.Current, command)
+			notifier.Notify(notify.Notification{
+				Event: notify.EventUpdate,
+				Title: fmt.Sprintf("cc-custom %s is available", latest.Tag),
+				Body:  truncate(latest.Notes, 200),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"complex/internal/claude"
+	"customclaude/pkg/platform"
+)
+
+// usageLedgerFile is the ledger in the config directory every result's
+// usage is appended to
+const usageLedgerFile = "usage.jsonl"
+
+// runUsageReport runs "usage": it prints the spending recorded in the usage
+// ledger per day, model and project
+func runUsageReport(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("usage", flag.ContinueOnError)
+	flags.SetOutput(out)
+	days := flags.Int("days", 7, "number of days to report, today included")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return err
+	}
+	entries, err := claude.ReadUsageLedger(filepath.Join(dir, usageLedgerFile))
+	if err != nil {
+		return err
+	}
+	report := claude.SummarizeUsage(entries, *days, time.Now())
+	if report.Total.Results == 0 {
+		fmt.Fprintf(out, "No usage recorded since %s.\n", report.Since)
+		return nil
+	}
+
+	fmt.Fprintf(out, "Usage since %s: $%.2f over %d results\n", report.Since, report.Total.Cost, report.Total.Results)
+	printUsageTotals(out, "Day", report.ByDay)
+	printUsageTotals(out, "Model", report.ByModel)
+	printUsageTotals(out, "Project", report.ByProject)
+	return nil
+}
+
+// printUsageTotals prints one table of the usage report
+func printUsageTotals(out io.Writer, heading string, totals []claude.UsageTotal) {
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Cost\tResults\tInput\tCache read\tOutput\t\t%s\n", heading)
+	for _, total := range totals {
+		usage := total.Usage
+		fmt.Fprintf(w, "$%.2f\t%d\t%d\t%d\t%d\t\t%s\n", total.Cost, total.Results,
+			usage.InputTokens+usage.CacheCreationInputTokens, usage.CacheReadInputTokens, usage.OutputTokens, total.Key)
+	}
+	w.Flush()
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// handleAllowlistCommand runs "/allowlist [<preset>|default]": without an
+// argument it shows the presets, otherwise it picks the project's preset
+func (a *Application) handleAllowlistCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		a.showAllowlists()
+		return a, nil
+	}
+
+	name := args[0]
+	if name == "default" {
+		name = ""
+	}
+	path, err := a.sessionManager.SetProjectAllowlist(name)
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "allowlist"}
+		}
+	}
+	message := fmt.Sprintf("Project allowlist set to %s in %s; it applies from the next prompt", name, path)
+	if name == "" {
+		message = "Project allowlist removed; the configured one applies from the next prompt"
+	}
+	return a, func() tea.Msg {
+		return StatusMsg{Status: "allowlist", Message: message}
+	}
+}
+
+// showAllowlists adds the presets, with the active one marked, to the
+// conversation
+func (a *Application) showAllowlists() {
+	active, ok := a.sessionManager.Allowlist()
+	lines := []string{"Tool allowlists (/allowlist <name> picks one for this project, /allowlist default drops it):", ""}
+	for _, preset := range claude.AllowlistPresets {
+		marker := "  "
+		if ok && preset.Name == active.Name {
+			marker = "▶ "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s - %s", marker, preset.Name, preset.Description))
+		lines = append(lines, fmt.Sprintf("    mode %s; allows %s", preset.PermissionMode, strings.Join(preset.Allowed, ", ")))
+		if len(preset.Disallowed) > 0 {
+			lines = append(lines, fmt.Sprintf("    denies %s", strings.Join(preset.Disallowed, ", ")))
+		}
+	}
+	if !ok {
+		lines = append(lines, "", "No allowlist is active; claude's own permission settings apply.")
+	}
+	if a.sessionManager.ReadOnly {
+		lines = append(lines, "", "Read-only mode is on: plan mode and no write tools, whatever the allowlist.")
+	}
+
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("allowlist_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   strings.Join(lines, "\n"),
+		Timestamp: time.Now(),
+	})
+	a.scrollToBottomSafe()
+}
@@ -2,15 +2,29 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"complex/internal/approval"
 	"complex/internal/claude"
+	"complex/internal/config"
+	"complex/internal/guard"
+	"complex/internal/logging"
+	"complex/internal/notify"
+	"complex/internal/retry"
+	"complex/internal/sound"
 	"complex/internal/ui/components"
+	"complex/internal/update"
+	"customclaude/pkg/mcp"
 )
 
 // ApplicationState represents the current state of the application
@@ -20,6 +34,14 @@ const (
 	StateMain ApplicationState = iota
 	StateSettings
 	StateHelp
+	StateResume
+	StateContext
+	StateMCP
+	StateSessions
+	StateRawStream
+	StateChangelog
+	StatePromptPreview
+	StateStats
 )
 
 // InputMode represents the vim-like input mode
@@ -28,6 +50,8 @@ type InputMode int
 const (
 	InputModeNormal InputMode = iota
 	InputModeInsert
+	InputModeVisual
+	InputModeVisualLine
 )
 
 // Application represents the main TUI application
@@ -49,6 +73,8 @@ type Application struct {
 	messages       []claude.ConversationMessage
 	errors         []ErrorMsg
 	toolActivity   []ToolActivityMsg
+	lastTurn       *claude.TurnResult
+	turnUsage      []turnUsage // recent turns, for the sidebar sparklines
 
 	// Input handling
 	inputBuffer   string
@@ -56,19 +82,176 @@ type Application struct {
 	inputMode     InputMode
 	cursorPos     int
 	commandBuffer string // For multi-key commands like "cw"
+	vim           vimState
+	compose       composeView
 
 	// Status
 	statusMessage string
 	isLoading     bool
+	cancelCommand context.CancelFunc
+	// The request in flight, shown in the status bar
+	run           runStatus
+	statusTicking bool
 
 	// Styles
 	styles *Styles
 
 	// Markdown renderer
 	markdownRenderer *components.MarkdownRenderer
+	highlighter      *components.Highlighter
+	// wideRenderer renders messages re-rendered at the full panel width
+	wideRenderer *components.MarkdownRenderer
+	// fitRenderers render assistant messages narrower than markdownRenderer
+	// wraps, when timestamps or the selection bar take columns, by width
+	fitRenderers map[int]*components.MarkdownRenderer
+
+	// Sound, desktop, webhook and command notifications, routed by event
+	notifier *notify.Dispatcher
+	// lastInput is when a key or the mouse was last used, a hint that
+	// someone is watching
+	lastInput time.Time
+
+	// User configuration
+	config config.Config
+	keymap *Keymap
+
+	// Risky shell command detection
+	guard *guard.Detector
+
+	// Permission prompts
+	approvalBroker *approval.Broker
+	approvals      []approval.Request
+
+	// Conversation viewport over the rendered message lines, and whether
+	// it follows the live tail (F)
+	viewport viewport.Model
+	follow   components.FollowMode
+	// viewportBuild is the layout build loaded into the viewport, 0 when
+	// it holds highlighted lines
+	viewportBuild int
+
+	// Formatted messages by ID, the conversation laid out as lines, and
+	// the terminal size waiting for resizing to pause
+	renderCache      map[string]renderedMessage
+	convLayout       conversationLayout
+	layoutGeneration int
+	pendingResize    tea.WindowSizeMsg
+	resizeSeq        int
+
+	// Panel placement for mouse events and the text selected by dragging
+	mouseLayout mouseLayout
+	selection   textSelection
+
+	// Conversation picker for /resume
+	resume resumePicker
+
+	// MCP server management (/mcp)
+	mcp      *mcp.Manager
+	mcpPanel mcpPanel
+
+	// Config editor (Ctrl+S, /config)
+	configEditor configEditor
+
+	// Release checks and the changelog view (/changelog)
+	updates   *update.Checker
+	changelog changelogView
+
+	// Usage dashboard over the stored conversations (Ctrl+U, /stats)
+	stats statsView
+
+	// The prompt being typed, expanded as it will be sent (Ctrl+P)
+	preview promptPreview
+
+	// Session selected in the session chain view (Ctrl+L)
+	sessionsSelected int
+
+	// Assistant messages still receiving partial text, by message ID
+	streaming map[string]bool
+
+	// Footnote jump ("f" followed by a number)
+	footnoteJump   bool
+	footnoteDigits string
+
+	// "/" search over the conversation panel
+	search conversationSearch
+
+	// Tool message selected with Tab and the ones expanded to full detail,
+	// by tool_use ID
+	selectedTool  string
+	expandedTools map[string]bool
+	// renderModes overrides how single messages are rendered, by message ID
+	renderModes map[string]renderMode
+
+	// Message under the cursor moved with [ and ], -1 when none, and "y"
+	// waiting for a following "c"
+	selectedMessage int
+	yankPrefix      bool
+
+	// Retried and cancelled attempts are folded unless this is set
+	showSuperseded bool
+
+	// How message times are shown (:set timestamps)
+	timestamps timestampMode
+
+	// Model the next prompt runs on instead of the session's, set by /ask
+	nextModel string
+
+	log *slog.Logger
+
+	// Follow-up prompts offered after recognizable failures
+	retry            *retry.Matcher
+	retrySuggestions []retry.Suggestion
+	retryIndex       int
+
+	// One-key follow-ups offered after an assistant turn
+	quickReplies []string
+
+	// Modal dialogs waiting for an answer, the active one first
+	dialogs []*dialog
+
+	// Transient notifications, oldest first
+	toasts       []toast
+	nextToastID  int
+	toastTicking bool
 
-	// Scrolling state
-	scrollPosition int
+	// Projected cost of the prompt being composed
+	forecast promptForecast
+
+	// Context window usage shown in the side panel
+	contextMeter contextMeter
+
+	// UI state snapshots taken after each turn, for debug bundles
+	uiSnapshots []uiSnapshot
+
+	// Stream lines read from claude and how the raw stream view shows them
+	rawLines []claude.RawLine
+	rawView  rawStreamView
+
+	// Lines claude wrote to stderr, the warnings not seen yet, and the pane
+	// showing them
+	stderrLines  []claude.StderrLine
+	stderrUnread int
+	stderrPane   stderrPane
+
+	// Side panel size, collapsed with Ctrl+\ and resized with
+	// Ctrl+Left/Right, and the pending save of it
+	sidebarWidth   int
+	sidebarHidden  bool
+	sidebarSaveSeq int
+
+	// Independent conversations; the active one is mirrored in the fields above
+	tabs       []*tab
+	activeTab  int
+	nextTabID  int
+	newSession func() *claude.SessionManager
+	tabPrefix  bool // "g" pressed, waiting for t/T
+
+	// Screen-reader friendly linear output (--a11y)
+	linear    bool
+	linearOut linearOutput
+
+	// Render panics already logged, by panel and panic value
+	renderPanics map[string]bool
 }
 
 // Styles contains all the styling for the application
@@ -84,6 +267,28 @@ type Styles struct {
 	Tool       lipgloss.Style
 	Status     lipgloss.Style
 	Highlight  lipgloss.Style
+
+	// Search matches in the conversation panel
+	Match        lipgloss.Style
+	CurrentMatch lipgloss.Style
+
+	// Text selected with the mouse
+	Selection lipgloss.Style
+
+	// Diffs of file-editing tools
+	DiffAdd    lipgloss.Style
+	DiffRemove lipgloss.Style
+	DiffHunk   lipgloss.Style
+
+	// Modal dialog box and toast notifications
+	Dialog lipgloss.Style
+	Toast  lipgloss.Style
+
+	// Box shown in place of a panel that failed to render
+	ErrorBox lipgloss.Style
+
+	// "New messages" indicator below the conversation
+	Pill lipgloss.Style
 }
 
 // NewStyles creates default styles for the application
@@ -129,6 +334,37 @@ func NewStyles() *Styles {
 		Highlight: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("205")).
 			Bold(true),
+		Match: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("220")),
+		CurrentMatch: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(lipgloss.Color("208")).
+			Bold(true),
+		Selection: lipgloss.NewStyle().
+			Reverse(true),
+		DiffAdd: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("42")),
+		DiffRemove: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203")),
+		DiffHunk: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39")),
+		Dialog: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(1, 2),
+		Toast: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(0, 1),
+		ErrorBox: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("196")).
+			Padding(0, 1),
+		Pill: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("230")).
+			Background(lipgloss.Color("62")).
+			Padding(0, 1),
 	}
 }
 
@@ -136,31 +372,66 @@ func NewStyles() *Styles {
 func NewApplication(
 	ctx context.Context,
 	sessionManager *claude.SessionManager,
+	cfg config.Config,
 ) (*Application, error) {
-	eventBus := NewEventBus(ctx)
-	eventProcessor := NewEventProcessor(ctx, eventBus)
-
 	// Create markdown renderer with default width
-	markdownRenderer, err := components.NewMarkdownRenderer(80)
+	wrapWidth := 80
+	if cfg.WordWrap > 0 {
+		wrapWidth = cfg.WordWrap
+	}
+	theme := cfg.Theme
+	if theme == "" {
+		theme = "dark"
+	}
+	markdownRenderer, err := components.NewMarkdownRendererWithStyle(wrapWidth, theme)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create markdown renderer: %w", err)
 	}
 
+	detector, err := guard.NewDetector(cfg.Guard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create command guard: %w", err)
+	}
+
+	retryMatcher, err := retry.NewMatcher(cfg.Retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retry templates: %w", err)
+	}
+
+	notifier, err := notify.NewDispatcher(cfg.Notify, map[string]notify.Notifier{
+		"sound": notify.SoundNotifier{Player: sound.NewPlayer(cfg.Sound)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notifications: %w", err)
+	}
+
+	timestamps, err := parseTimestampMode(cfg.Timestamps)
+	if err != nil {
+		return nil, err
+	}
+
 	app := &Application{
 		ctx:              ctx,
-		sessionManager:   sessionManager,
-		eventBus:         eventBus,
-		eventProcessor:   eventProcessor,
 		state:            StateMain,
-		messages:         make([]claude.ConversationMessage, 0),
-		errors:           make([]ErrorMsg, 0),
-		toolActivity:     make([]ToolActivityMsg, 0),
 		styles:           NewStyles(),
 		markdownRenderer: markdownRenderer,
+		highlighter:      components.NewHighlighter(theme),
+		notifier:         notifier,
+		lastInput:        time.Now(),
+		config:           cfg,
+		showSuperseded:   !cfg.HideSuperseded,
+		timestamps:       timestamps,
+		sidebarWidth:     cfg.SidebarWidth,
+		sidebarHidden:    cfg.SidebarHidden,
+		keymap:           NewKeymap(cfg.EffectiveKeybindings()),
+		guard:            detector,
+		retry:            retryMatcher,
+		log:              logging.For("app"),
 	}
 
-	// Register event bus as event handler for session manager
-	sessionManager.AddEventHandler(eventBus)
+	// The initial conversation becomes the first tab
+	app.newTab(sessionManager)
+	app.loadTab(0)
 
 	return app, nil
 }
@@ -168,14 +439,26 @@ func NewApplication(
 // SetProgram sets the bubbletea program reference
 func (a *Application) SetProgram(program *tea.Program) {
 	a.program = program
-	a.eventBus.SetProgram(program)
-	a.eventProcessor.ProcessEvents(program)
+	for _, t := range a.tabs {
+		t.eventBus.SetProgram(program)
+		t.eventProcessor.ProcessEvents(program)
+	}
+	a.startApprovalBroker()
 }
 
 // Init initializes the application (bubbletea interface)
 func (a *Application) Init() tea.Cmd {
+	if a.linear {
+		return tea.Batch(
+			tea.Println("CustomClaude TUI started in linear mode. Press Enter to type a message, Ctrl+H for help."),
+			checkOrphans,
+			a.startupUpdateCheck(),
+		)
+	}
 	return tea.Batch(
 		tea.EnterAltScreen,
+		checkOrphans,
+		a.startupUpdateCheck(),
 		func() tea.Msg {
 			return StatusMsg{
 				Status:  "init",
@@ -187,43 +470,92 @@ func (a *Application) Init() tea.Cmd {
 
 // Update handles messages (bubbletea interface)
 func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(type) {
+	case toastTickMsg:
+		a.expireToasts()
+	case statusTickMsg:
+		a.advanceStatus()
+	}
+	scrolled, tab := a.viewport.YOffset, a.activeTab
+	model, cmd := a.update(msg)
+	if a.linear {
+		cmd = tea.Batch(cmd, a.flushLinear())
+	}
+	a.trackRun()
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+		if a.viewport.YOffset != scrolled && a.activeTab == tab {
+			a.noteScrolled()
+		}
+	}
+	return model, tea.Batch(cmd, a.scheduleToastExpiry(), a.scheduleStatusTick())
+}
+
+// update applies a message to the active tab
+func (a *Application) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.WindowSizeMsg:
-		a.width = msg.Width
-		a.height = msg.Height
-		// Update markdown renderer width using layout manager constraints
-		if a.markdownRenderer != nil {
-			lm := components.NewLayoutManager(a.width, a.height)
-			constraints := lm.GetConversationConstraints()
-			contentWidth := constraints.ConversationWidth - 4 // account for message prefix/padding
-			if contentWidth > 20 {
-				a.markdownRenderer.UpdateWidth(contentWidth)
-			}
-		}
-		return a, nil
+		return a.handleResize(msg)
+
+	case resizeSettledMsg:
+		return a.handleResizeSettled(msg)
+
+	case sidebarSaveMsg:
+		return a.handleSidebarSave(msg)
 
 	case tea.KeyMsg:
+		a.lastInput = time.Now()
 		return a.handleKeyPress(msg)
 
+	case tea.MouseMsg:
+		a.lastInput = time.Now()
+		return a.handleMouse(msg)
+
+	case TabMsg:
+		return a.handleTabMsg(msg)
+
 	case SessionStateMsg:
 		a.currentSession = msg.SessionInfo
 		a.sessionStats = msg.Stats
+		if msg.Stats.CumulativeTurns == 0 {
+			// A new conversation starts its sparklines afresh
+			a.turnUsage = nil
+		}
 		return a, nil
 
 	case MessageStreamMsg:
+		a.noteStreamedTokens(msg.Message)
+		if count := len(a.messages); a.applyStreamedMessage(msg) {
+			a.followNewContent(len(a.messages) > count)
+			return a, nil
+		}
 		a.messages = append(a.messages, msg.Message)
+		if warning, ok := a.checkRiskyCommand(msg.Message); ok {
+			a.messages = append(a.messages, warning)
+		}
 		// Keep only last 500 messages to prevent memory issues
 		if len(a.messages) > 500 {
 			a.messages = a.messages[len(a.messages)-500:]
 			// Recalculate scroll position after truncation
 			a.clampScrollPosition()
 		}
-		// Auto-scroll to bottom for new messages
-		a.scrollToBottomSafe()
+		// Follow new messages only when already at the bottom
+		a.followNewContent(true)
 		return a, nil
 
 	case ToolActivityMsg:
+		a.attachToolResult(msg)
+		// Completion updates the entry of the tool_use it belongs to
+		for i := range a.toolActivity {
+			if msg.ToolUseID != "" && a.toolActivity[i].ToolUseID == msg.ToolUseID {
+				if msg.Activity == "" {
+					msg.Activity = a.toolActivity[i].Activity
+				}
+				a.toolActivity[i] = msg
+				return a, nil
+			}
+		}
 		a.toolActivity = append(a.toolActivity, msg)
 		// Keep only last 10 tool activities
 		if len(a.toolActivity) > 10 {
@@ -231,7 +563,93 @@ func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case TurnCompleteMsg:
+		turn := msg.Turn
+		a.lastTurn = &turn
+		a.recordTurnUsage(turn)
+		if msg.Turn.IsError {
+			a.notifier.Notify(notify.Notification{
+				Event: notify.EventError,
+				Title: "Claude turn failed",
+				Body:  truncateString(turn.Result, 200),
+			})
+		} else {
+			a.notifier.Notify(notify.Notification{
+				Event: notify.EventTurnComplete,
+				Title: "Claude turn complete",
+				Body: fmt.Sprintf("%d turn(s) in %s, $%s",
+					turn.NumTurns, formatLatency(time.Duration(turn.DurationMs)*time.Millisecond), formatCost(turn.CostUSD)),
+			})
+		}
+		a.offerRetry(turn)
+		if !turn.IsError {
+			a.offerQuickReplies()
+		}
+		a.noteUISnapshot("turn_complete")
+		return a, nil
+
+	case CommandFinishedMsg:
+		a.isLoading = false
+		if a.cancelCommand != nil {
+			a.cancelCommand()
+			a.cancelCommand = nil
+		}
+		a.notifyLongTurn(msg)
+		a.noteUISnapshot("command_finished")
+		return a, nil
+
+	case CompactedMsg:
+		return a.handleCompacted(msg)
+
+	case RetryMsg:
+		a.messages = append(a.messages, claude.ConversationMessage{
+			ID:   fmt.Sprintf("retrying_%d", msg.Timestamp.UnixNano()),
+			Type: "system",
+			Content: fmt.Sprintf("Claude failed (%s); retrying %d/%d in %s…",
+				truncateString(msg.Retry.Err, 80), msg.Retry.Attempt, msg.Retry.MaxAttempts, msg.Retry.Delay),
+			Timestamp: msg.Timestamp,
+		})
+		a.statusMessage = fmt.Sprintf("[retry] Retrying %d/%d…", msg.Retry.Attempt, msg.Retry.MaxAttempts)
+		a.followNewContent(true)
+		return a, nil
+
+	case BudgetAlertMsg:
+		return a.handleBudgetAlert(msg)
+
+	case RawLineMsg:
+		a.noteRawLine(msg.Line)
+		return a, nil
+
+	case StderrMsg:
+		a.noteStderr(msg.Line)
+		return a, nil
+
+	case CommandCancelledMsg:
+		// Partial assistant text stays, explained by the interruption note
+		for id := range a.streaming {
+			delete(a.streaming, id)
+		}
+		interruption := msg.Interruption
+		a.messages = append(a.messages, claude.ConversationMessage{
+			ID:           fmt.Sprintf("interrupted_%d", msg.Timestamp.UnixNano()),
+			Type:         "system",
+			Content:      fmt.Sprintf("%s (prompt: %s)", interruption.Summary(), truncateString(interruption.Prompt, 60)),
+			Timestamp:    msg.Timestamp,
+			IsError:      true,
+			Interruption: &interruption,
+		})
+		a.followNewContent(true)
+		return a, nil
+
 	case ErrorMsg:
+		a.log.Error("error", "context", msg.Context, "err", msg.Error)
+		if msg.Context == "command_execution" {
+			a.notifier.Notify(notify.Notification{
+				Event: notify.EventError,
+				Title: "Claude command failed",
+				Body:  msg.Error.Error(),
+			})
+		}
 		a.errors = append(a.errors, msg)
 		// Keep only last 5 errors
 		if len(a.errors) > 5 {
@@ -243,9 +661,33 @@ func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.statusMessage = fmt.Sprintf("[%s] %s", msg.Status, msg.Message)
 		return a, nil
 
+	case MCPHealthMsg:
+		return a.handleMCPHealth(msg)
+
+	case UpdateCheckedMsg:
+		return a.handleUpdateChecked(msg)
+
+	case PromptPreviewedMsg:
+		return a.handlePromptPreviewed(msg)
+
+	case PromptEditedMsg:
+		return a.handlePromptEdited(msg)
+
+	case OrphansFoundMsg:
+		return a.handleOrphansFound(msg)
+
+	case SystemPromptEditedMsg:
+		return a.handleSystemPromptEdited(msg)
+
 	case PromptInputMsg:
 		return a.handlePromptInput(msg)
 
+	case ApprovalRequestMsg:
+		return a.handleApprovalRequest(msg)
+
+	case ApprovalAutoDecidedMsg:
+		return a.handleApprovalAutoDecided(msg)
+
 	case EventMsg:
 		// Handle raw events if needed
 		return a, nil
@@ -257,8 +699,96 @@ func (a *Application) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeyPress handles keyboard input
 func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// A paste arrives as one message; fields of one line take it joined
+	if msg.Paste && (len(a.dialogs) > 0 || a.state != StateMain || a.search.editing || a.footnoteJump) {
+		msg = singleLinePaste(msg)
+	}
+
+	if len(a.dialogs) > 0 {
+		return a.handleDialogKeyPress(msg)
+	}
+
+	if a.state == StateResume {
+		return a.handleResumeKeyPress(msg)
+	}
+
+	if a.state == StateMCP {
+		return a.handleMCPKeyPress(msg)
+	}
+
+	if a.state == StateSettings {
+		return a.handleConfigKeyPress(msg)
+	}
+
+	if a.state == StateSessions {
+		return a.handleSessionsKeyPress(msg)
+	}
+
+	if a.state == StateRawStream {
+		return a.handleRawStreamKeyPress(msg)
+	}
+
+	if a.state == StateChangelog {
+		return a.handleChangelogKeyPress(msg)
+	}
+
+	if a.state == StatePromptPreview {
+		return a.handlePromptPreviewKeyPress(msg)
+	}
+
+	if a.state == StateStats {
+		return a.handleStatsKeyPress(msg)
+	}
+
+	if a.footnoteJump {
+		return a.handleFootnoteKeyPress(msg)
+	}
+
+	if a.search.editing {
+		return a.handleSearchKeyPress(msg)
+	}
+
+	// Resolve configured keybindings outside of insert mode, where keys are text
+	key := msg.String()
+	if !(a.inputActive && a.inputMode == InputModeInsert) {
+		key = a.keymap.Resolve(key, a.inputActive)
+	}
+
+	// Interrupt a running command
+	if a.isLoading && (key == "ctrl+x" || key == "esc") {
+		if a.cancelCommand != nil {
+			a.cancelCommand()
+			a.statusMessage = "[command] Cancelling..."
+		}
+		return a, nil
+	}
+
+	// Write the prompt in $EDITOR
+	if key == "ctrl+g" {
+		return a.editPromptExternally()
+	}
+
+	// Preview the prompt being typed as it will be sent
+	if a.inputActive && msg.String() == "ctrl+p" {
+		return a.openPromptPreview()
+	}
+
+	// Track edits of the input line for undo and "."
+	if a.inputActive && !a.vim.replaying {
+		a.beginVimKey(msg)
+		defer a.endVimKey()
+	}
+
+	// Pasted text goes into the input verbatim
+	if msg.Paste {
+		return a.pasteInput(msg)
+	}
+
 	// Handle insert mode character input first (highest priority)
 	if a.inputActive && a.inputMode == InputModeInsert {
+		if handled, cmd := a.handleComposeKey(msg); handled {
+			return a, cmd
+		}
 		switch msg.String() {
 		case "esc":
 			a.inputMode = InputModeNormal
@@ -274,22 +804,7 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return a, nil
 		case "enter":
-			if strings.TrimSpace(a.inputBuffer) != "" {
-				prompt := strings.TrimSpace(a.inputBuffer)
-				a.inputBuffer = ""
-				a.inputActive = false
-				a.inputMode = InputModeNormal
-				a.cursorPos = 0
-				a.isLoading = true
-
-				return a, func() tea.Msg {
-					return PromptInputMsg{
-						Prompt: prompt,
-						Resume: a.sessionManager.CurrentSessionID != "",
-					}
-				}
-			}
-			return a, nil
+			return a, a.submitInput()
 		case "left":
 			if a.cursorPos > 0 {
 				a.cursorPos--
@@ -309,20 +824,37 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	// Handle normal mode and non-input mode keys
-	switch msg.String() {
-	case "ctrl+c":
-		return a, tea.Quit
+	if a.inputActive {
+		if handled, model, cmd := a.handleVimKey(key); handled {
+			return model, cmd
+		}
+	}
 
-	case "q":
-		if !a.inputActive {
-			return a, tea.Quit
+	if a.yankPrefix {
+		a.yankPrefix = false
+		if key == "c" && !a.inputActive {
+			a.yankCodeBlocks()
+			return a, nil
 		}
+	}
+
+	if handled, model, cmd := a.handleTabKey(key); handled {
+		return model, cmd
+	}
+
+	if a.quickReplyKey(key) {
 		return a, nil
+	}
+
+	// Handle normal mode and non-input mode keys
+	switch key {
+	case "ctrl+c":
+		return a, tea.Quit
 
 	case "ctrl+n":
+		sessionManager := a.sessionManager
 		return a, func() tea.Msg {
-			a.sessionManager.StartNewConversation()
+			sessionManager.StartNewConversation()
 			return StatusMsg{
 				Status:  "session",
 				Message: "Started new conversation",
@@ -333,15 +865,75 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		a.state = StateHelp
 		return a, nil
 
-	case "ctrl+s":
-		a.state = StateSettings
+	case "ctrl+y":
+		a.copySummary()
+		return a, nil
+
+	case "ctrl+e":
+		a.promptExportPath()
 		return a, nil
 
+	case "r":
+		if !a.inputActive {
+			a.prefillRetry()
+		}
+		return a, nil
+
+	case "ctrl+o":
+		return a.openContextPanel()
+
+	case "ctrl+l":
+		return a.openSessionsView()
+
+	case "ctrl+d":
+		return a.openRawStreamView()
+
+	case "ctrl+b":
+		return a.toggleStderrPane()
+
+	case "ctrl+u":
+		return a.openStatsView(statsLedgerDays)
+
+	case "ctrl+k":
+		if a.isLoading {
+			a.statusMessage = "[templates] Wait for the running command to finish"
+			return a, nil
+		}
+		return a.handleTemplateCommand(nil)
+
+	case "ctrl+s":
+		return a.openConfigEditor()
+
 	case "ctrl+m":
 		a.state = StateMain
 		return a, nil
 
+	case "ctrl+\\":
+		return a.toggleSidebar()
+
+	case "ctrl+left":
+		return a.resizeSidebar(sidebarStep)
+
+	case "ctrl+right":
+		return a.resizeSidebar(-sidebarStep)
+
+	case "tab":
+		if !a.inputActive {
+			a.selectTool(1)
+		}
+		return a, nil
+
+	case "shift+tab":
+		if !a.inputActive {
+			a.selectTool(-1)
+		}
+		return a, nil
+
 	case "enter":
+		if !a.inputActive && a.selectedTool != "" {
+			a.toggleSelectedTool()
+			return a, nil
+		}
 		if !a.inputActive {
 			a.inputActive = true
 			a.inputMode = InputModeNormal
@@ -354,6 +946,17 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.inputActive = false
 			a.inputMode = InputModeNormal
 			a.cursorPos = 0
+		} else if !a.selection.empty() {
+			a.selection = textSelection{}
+		} else if a.messageSelected() {
+			a.selectedMessage = -1
+			a.statusMessage = ""
+		} else if a.selectedTool != "" {
+			a.selectedTool = ""
+			a.statusMessage = ""
+		} else if a.search.query != "" {
+			a.clearSearch()
+			a.statusMessage = ""
 		} else {
 			a.state = StateMain
 		}
@@ -380,13 +983,14 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "A":
 		if a.inputActive && a.inputMode == InputModeNormal {
 			a.inputMode = InputModeInsert
-			a.cursorPos = len(a.inputBuffer)
+			_, a.cursorPos = lineBounds(a.inputBuffer, a.cursorPos)
 			a.commandBuffer = ""
 		}
 		return a, nil
 
 	case "x":
 		if a.inputActive && a.inputMode == InputModeNormal && a.cursorPos < len(a.inputBuffer) {
+			a.yank(a.inputBuffer[a.cursorPos:a.cursorPos+1], false)
 			a.inputBuffer = a.inputBuffer[:a.cursorPos] + a.inputBuffer[a.cursorPos+1:]
 			if a.cursorPos >= len(a.inputBuffer) && len(a.inputBuffer) > 0 {
 				a.cursorPos = len(a.inputBuffer) - 1
@@ -396,8 +1000,13 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "d":
 		if a.inputActive && a.inputMode == InputModeNormal {
-			if a.commandBuffer == "d" {
+			if a.commandBuffer == "d" && strings.Contains(a.inputBuffer, "\n") {
+				// dd - delete the cursor's line of a multi-line input
+				a.yank(a.deleteLine(), true)
+				a.commandBuffer = ""
+			} else if a.commandBuffer == "d" {
 				// dd - delete entire line
+				a.yank(a.inputBuffer, true)
 				a.inputBuffer = ""
 				a.cursorPos = 0
 				a.commandBuffer = ""
@@ -410,9 +1019,11 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "c":
 		if a.inputActive && a.inputMode == InputModeNormal {
 			if a.commandBuffer == "c" {
-				// cc - change entire line
-				a.inputBuffer = ""
-				a.cursorPos = 0
+				// cc - change the cursor's line
+				start, end := lineBounds(a.inputBuffer, a.cursorPos)
+				a.yank(a.inputBuffer[start:end], true)
+				a.inputBuffer = a.inputBuffer[:start] + a.inputBuffer[end:]
+				a.cursorPos = start
 				a.inputMode = InputModeInsert
 				a.commandBuffer = ""
 			} else {
@@ -425,11 +1036,11 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if a.inputActive && a.inputMode == InputModeNormal {
 			if a.commandBuffer == "d" {
 				// dw - delete word
-				a.deleteWord()
+				a.yank(a.deleteWord(), false)
 				a.commandBuffer = ""
 			} else if a.commandBuffer == "c" {
 				// cw - change word
-				a.deleteWord()
+				a.yank(a.deleteWord(), false)
 				a.inputMode = InputModeInsert
 				a.commandBuffer = ""
 			} else {
@@ -447,17 +1058,14 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "0":
 		if a.inputActive && a.inputMode == InputModeNormal {
-			a.cursorPos = 0
+			a.cursorPos, _ = lineBounds(a.inputBuffer, a.cursorPos)
 		}
 		return a, nil
 
 	case "$":
 		if a.inputActive && a.inputMode == InputModeNormal {
-			if len(a.inputBuffer) > 0 {
-				a.cursorPos = len(a.inputBuffer) - 1
-			} else {
-				a.cursorPos = 0
-			}
+			start, end := lineBounds(a.inputBuffer, a.cursorPos)
+			a.cursorPos = max(start, end-1)
 		}
 		return a, nil
 
@@ -473,30 +1081,84 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
-	case "up":
+	case "z":
 		if !a.inputActive {
-			a.scrollUp()
+			a.toggleSuperseded()
 		}
 		return a, nil
 
-	case "k":
+	case "[":
 		if !a.inputActive {
-			a.scrollUp()
+			a.selectMessage(-1)
 		}
-		// In normal mode, 'k' doesn't do anything for input (could add up navigation later)
 		return a, nil
 
-	case "down":
+	case "]":
 		if !a.inputActive {
-			a.scrollDown()
+			a.selectMessage(1)
+		}
+		return a, nil
+
+	case "y":
+		if !a.inputActive {
+			a.yankMessage()
+			a.yankPrefix = true
+		}
+		return a, nil
+
+	case "v":
+		if !a.inputActive {
+			return a.openRerenderDialog()
+		}
+		return a, nil
+
+	case "B":
+		if !a.inputActive {
+			return a.forkFromMessage()
 		}
 		return a, nil
 
-	case "j":
+	case "F":
+		if !a.inputActive {
+			a.toggleFollow()
+		}
+		return a, nil
+
+	case "f":
+		if !a.inputActive {
+			a.footnoteJump = true
+			a.footnoteDigits = ""
+		}
+		return a, nil
+
+	case "/":
+		if !a.inputActive {
+			a.startSearch()
+		}
+		return a, nil
+
+	case "n":
+		if !a.inputActive {
+			a.jumpToMatch(1)
+		}
+		return a, nil
+
+	case "N":
+		if !a.inputActive {
+			a.jumpToMatch(-1)
+		}
+		return a, nil
+
+	case "up":
+		if !a.inputActive {
+			a.scrollUp()
+		}
+		return a, nil
+
+	case "down":
 		if !a.inputActive {
 			a.scrollDown()
 		}
-		// In normal mode, 'j' doesn't do anything for input (could add down navigation later)
 		return a, nil
 
 	case "pgup":
@@ -519,7 +1181,7 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "end":
 		if !a.inputActive {
-			a.scrollToBottom()
+			a.jumpToNewMessages()
 		}
 		return a, nil
 
@@ -528,8 +1190,48 @@ func (a *Application) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// restorePrompt returns a prompt that was held back to the input line for
+// editing
+func (a *Application) restorePrompt(prompt string) {
+	a.isLoading = false
+	a.inputBuffer = prompt
+	a.inputActive = true
+	a.inputMode = InputModeNormal
+	a.cursorPos = 0
+}
+
 // handlePromptInput processes user prompt input
 func (a *Application) handlePromptInput(msg PromptInputMsg) (tea.Model, tea.Cmd) {
+	if strings.HasPrefix(msg.Prompt, "/") {
+		return a.handleSlashCommand(msg.Prompt)
+	}
+	if fields := strings.Fields(msg.Prompt); len(fields) > 0 && fields[0] == ":set" {
+		return a.handleSetCommand(fields[1:])
+	}
+
+	if msg.Resume && !msg.StaleChecked && a.isStale() {
+		return a.offerStaleChoice(msg)
+	}
+
+	if !msg.BudgetConfirmed && a.sessionManager.Budget.HardLimit {
+		if alert, exceeded := a.sessionManager.BudgetExceeded(); exceeded {
+			return a.offerBudgetOverride(msg, alert)
+		}
+	}
+
+	if msg.Turns == nil && a.needsSplit(msg.Prompt) {
+		return a.offerSplit(msg)
+	}
+
+	if !msg.ContextChecked && a.nearContextLimit(msg.Prompt) {
+		return a.offerContextChoice(msg)
+	}
+
+	if msg.Model == "" {
+		msg.Model, a.nextModel = a.nextModel, ""
+	}
+	a.quickReplies = nil
+
 	// Add user message to conversation immediately
 	userMsg := claude.ConversationMessage{
 		ID:        fmt.Sprintf("user_%d", time.Now().UnixNano()),
@@ -537,23 +1239,36 @@ func (a *Application) handlePromptInput(msg PromptInputMsg) (tea.Model, tea.Cmd)
 		Content:   msg.Prompt,
 		Timestamp: time.Now(),
 		IsError:   false,
+		Model:     msg.Model,
 	}
 	a.messages = append(a.messages, userMsg)
 
 	// Auto-scroll to bottom to show new user message
-	a.scrollToBottomSafe()
+	a.jumpToNewMessages()
 
+	cmdCtx, cancel := context.WithCancel(a.ctx)
+	a.cancelCommand = cancel
+
+	sessionManager := a.sessionManager
+	tabID := a.tabs[a.activeTab].id
 	return a, tea.Cmd(func() tea.Msg {
 		go func() {
-			if err := a.sessionManager.ExecuteCommand(a.ctx, msg.Prompt, msg.Resume); err != nil {
-				a.program.Send(ErrorMsg{
+			started := time.Now()
+			var err error
+			if len(msg.Turns) > 0 {
+				err = sessionManager.ExecuteBatch(cmdCtx, msg.Turns, msg.Resume, msg.Model)
+			} else {
+				err = sessionManager.ExecuteCommandWithModel(cmdCtx, msg.Prompt, msg.Resume, msg.Model)
+			}
+			if err != nil && !errors.Is(err, claude.ErrCommandCancelled) {
+				a.program.Send(TabMsg{TabID: tabID, Msg: ErrorMsg{
 					Error:   err,
 					Context: "command_execution",
-				})
+				}})
 			}
+			a.program.Send(TabMsg{TabID: tabID, Msg: CommandFinishedMsg{Err: err, Prompt: msg.Prompt, Started: started}})
 		}()
 
-		a.isLoading = false
 		return StatusMsg{
 			Status:  "command",
 			Message: fmt.Sprintf("Executing: %s", msg.Prompt),
@@ -563,13 +1278,46 @@ func (a *Application) handlePromptInput(msg PromptInputMsg) (tea.Model, tea.Cmd)
 
 // View renders the application (bubbletea interface)
 func (a *Application) View() string {
+	if a.linear {
+		return a.renderView()
+	}
+	return a.overlayToasts(a.renderView())
+}
+
+// renderView renders the dialog or screen for the current state. Each
+// screen renders inside an error boundary, and the main view has one more
+// around each panel and message so the others stay usable.
+func (a *Application) renderView() string {
+	if len(a.dialogs) > 0 {
+		return a.renderBoundary("dialog", a.width, a.renderDialog)
+	}
+
 	switch a.state {
 	case StateHelp:
-		return a.renderHelpView()
+		return a.renderBoundary("help", a.width, a.renderHelpView)
 	case StateSettings:
-		return a.renderSettingsView()
+		return a.renderBoundary("settings", a.width, a.renderSettingsView)
+	case StateResume:
+		return a.renderBoundary("conversation picker", a.width, a.renderResumeView)
+	case StateContext:
+		return a.renderBoundary("context view", a.width, a.renderContextView)
+	case StateMCP:
+		return a.renderBoundary("MCP panel", a.width, a.renderMCPView)
+	case StateSessions:
+		return a.renderBoundary("session chain", a.width, a.renderSessionsView)
+	case StateRawStream:
+		return a.renderBoundary("raw stream view", a.width, a.renderRawStreamView)
+	case StateChangelog:
+		return a.renderBoundary("changelog", a.width, a.renderChangelogView)
+	case StatePromptPreview:
+		return a.renderBoundary("prompt preview", a.width, a.renderPromptPreviewView)
+	case StateStats:
+		return a.renderBoundary("stats view", a.width, a.renderStatsView)
 	default:
-		return a.renderMainView()
+		if a.linear {
+			return a.renderBoundary("linear view", a.width, a.renderLinearView)
+		}
+		return a.renderBoundary("main view", a.width, a.renderMainView)
 	}
 }
 
@@ -580,37 +1328,73 @@ func (a *Application) renderMainView() string {
 	}
 
 	// Header
-	header := a.styles.Header.
-		Width(a.width - 2).
-		Render("CustomClaude TUI - Claude CLI Interface")
-
-	// Footer with shortcuts
-	footer := a.styles.Footer.
+	title := "CustomClaude TUI - Claude CLI Interface"
+	headerStyle := a.styles.Header
+	if a.sessionManager.ReadOnly {
+		title += " [READ-ONLY: plan mode, no write tools]"
+		headerStyle = headerStyle.Background(lipgloss.Color("52"))
+	}
+	if replay := a.sessionManager.Replay(); replay != "" {
+		title += " [REPLAY: " + filepath.Base(replay) + "]"
+	}
+	if profile := a.sessionManager.ActiveProfile(); profile != nil {
+		title += " [PROFILE: " + profile.Name + "]"
+	}
+	title += " | " + shortenHome(a.sessionManager.ProjectRoot())
+	if tabBar := a.renderBoundary("tab bar", 0, a.renderTabBar); tabBar != "" {
+		title += " | " + tabBar
+	}
+	header := headerStyle.
 		Width(a.width - 2).
-		Render("Ctrl+C/Q: Quit | Ctrl+N: New | Ctrl+H: Help | Enter: Input | Esc: Cancel")
+		Render(title)
 
 	// Layout calculations via LayoutManager
-	lm := components.NewLayoutManager(a.width, a.height)
+	lm := a.layout()
 	dims := lm.CalculatePanelDimensions()
 
 	// Conversation panel: pass inner content height (panel height minus padding/border)
-	conversationContent := a.renderConversationPanel(
-		dims.ConversationWidth-4,
-		max(1, dims.ConversationHeight-4),
-	)
+	conversationContent := a.renderBoundary("conversation panel", dims.ConversationWidth-4, func() string {
+		return a.renderConversationPanel(
+			dims.ConversationWidth-4,
+			max(1, dims.ConversationHeight-4),
+		)
+	})
 	conversationPanel := a.styles.MainPanel.
 		Width(dims.ConversationWidth).
 		Height(dims.ConversationHeight).
 		Render(conversationContent)
 
-	// Side panel with session info (pass inner height like conversation)
-	sideContent := a.renderSidePanel(max(1, dims.SidebarHeight-4))
-	sidePanel := a.styles.SidePanel.
-		Height(dims.SidebarHeight).
-		Render(sideContent)
+	// Footer with shortcuts, rendered after the conversation so the search
+	// match count is current
+	shortcuts := "Ctrl+C/Q: Quit | Ctrl+N: New | Ctrl+H: Help | Enter: Input | Esc: Cancel"
+	if status := a.searchStatus(); status != "" {
+		shortcuts = status + " | Esc: Clear search"
+	}
+	if badge := a.stderrBadge(); badge != "" {
+		shortcuts = badge + " | " + shortcuts
+	}
+	shortcuts = a.followIndicator() + " | " + shortcuts
+	footer := a.styles.Footer.
+		Width(a.width - 2).
+		Render(shortcuts)
+
+	// Side panel with session info (pass inner height like conversation),
+	// unless collapsed
+	sidePanel := ""
+	if width := a.sidePanelWidth(); width > 0 {
+		sideContent := a.renderBoundary("side panel", width-4, func() string {
+			return a.renderSidePanel(max(1, dims.SidebarHeight-4))
+		})
+		sidePanel = a.styles.SidePanel.
+			Width(width).
+			Height(dims.SidebarHeight).
+			Render(sideContent)
+	}
 
-		// Input panel
-	inputContent := a.renderInputPanel(a.width - 4)
+	// Input panel
+	inputContent := a.renderBoundary("input panel", a.width-4, func() string {
+		return a.renderInputPanel(a.width - 4)
+	})
 	inputPanel := a.styles.InputPanel.
 		Width(a.width - 2).
 		Render(inputContent)
@@ -622,14 +1406,34 @@ func (a *Application) renderMainView() string {
 		sidePanel,
 	)
 
+	// Collapsible stderr pane between the panels and the input
+	sections := []string{header, mainContent}
+	if a.stderrPane.open {
+		stderrContent := a.renderBoundary("stderr pane", a.width-4, func() string {
+			return a.renderStderrPane(a.width - 4)
+		})
+		sections = append(sections, a.styles.MainPanel.Width(a.width-2).Render(stderrContent))
+	}
+
 	// Combine all sections
-	return lipgloss.JoinVertical(
+	view := lipgloss.JoinVertical(
 		lipgloss.Left,
-		header,
-		mainContent,
-		inputPanel,
-		footer,
+		append(sections, inputPanel, footer)...,
 	)
+
+	// The renderer drops the top lines of a view taller than the terminal,
+	// which shifts everything up. Rows below are inside the conversation
+	// panel's margin, border and padding.
+	shift := max(0, lipgloss.Height(view)-a.height)
+	a.mouseLayout = mouseLayout{
+		convTop:   lipgloss.Height(header) + 2 - shift,
+		convLeft:  3,
+		convRows:  max(1, dims.ConversationHeight-4) - 2,
+		convWidth: dims.ConversationWidth - 4,
+		sideLeft:  lipgloss.Width(conversationPanel),
+		inputTop:  lipgloss.Height(header) + lipgloss.Height(mainContent) + a.stderrPaneHeight() - shift,
+	}
+	return view
 }
 
 // Optional future: hook for layout validation. Currently a no-op to avoid changing behavior.
@@ -638,152 +1442,209 @@ func (a *Application) renderMainView() string {
 //     _ = lm // Placeholder for validation via lm.ValidatePanelHeights
 // }
 
-// renderConversationPanel renders the main conversation area with scrolling
+// renderConversationPanel renders the main conversation area with scrolling.
+// The last two rows are kept for the "new messages" pill.
 func (a *Application) renderConversationPanel(width, height int) string {
 	if len(a.messages) == 0 {
 		return a.styles.Status.Render("No messages yet. Press Enter to start a conversation.")
 	}
-
-	// First, render ALL messages into lines
-	var allLines []string
-
-	for i, msg := range a.messages {
-		var formattedMsg string
-		switch msg.Type {
-		case "assistant":
-			// Use markdown renderer for assistant messages
-			if a.markdownRenderer != nil {
-				if rendered, err := a.markdownRenderer.Render(msg.Content); err == nil {
-					// Clean up the rendered output
-					rendered = strings.TrimSpace(rendered)
-					lines := strings.Split(rendered, "\n")
-
-					// Add emoji prefix to first line only
-					if len(lines) > 0 {
-						lines[0] = "🤖 " + lines[0]
-						for j := 1; j < len(lines); j++ {
-							lines[j] = "   " + lines[j] // Indent continuation
-						}
-					}
-					formattedMsg = strings.Join(lines, "\n")
-				} else {
-					wrappedContent := wordWrap(msg.Content, width-4)
-					formattedMsg = a.styles.Message.Render("🤖 " + wrappedContent)
-				}
-			} else {
-				wrappedContent := wordWrap(msg.Content, width-4)
-				formattedMsg = a.styles.Message.Render("🤖 " + wrappedContent)
-			}
-		case "tool_use":
-			wrappedContent := wordWrap(msg.Content, width-4)
-			formattedMsg = a.styles.Tool.Render("🔧 " + wrappedContent)
-		case "user":
-			wrappedContent := wordWrap(msg.Content, width-4)
-			formattedMsg = a.styles.Highlight.Render("👤 " + wrappedContent)
-		default:
-			wrappedContent := wordWrap(msg.Content, width-4)
-			formattedMsg = a.styles.Message.Render("ℹ️  " + wrappedContent)
-		}
-
-		// Split formatted message into individual lines
-		msgLines := strings.Split(formattedMsg, "\n")
-		allLines = append(allLines, msgLines...)
-
-		// Add spacing between messages (except after last message)
-		if i < len(a.messages)-1 {
-			allLines = append(allLines, "")
-		}
-	}
-
-	// Calculate total lines
-	totalLines := len(allLines)
-
-	// Ensure minimum height
 	if height < 3 {
 		return a.styles.Status.Render("Window too small")
 	}
 
-	// Always reserve space for scroll indicator to maintain consistent viewport
-	scrollIndicatorLines := 2
-	contentViewportHeight := height - scrollIndicatorLines
-
-	// Show scroll indicator when needed, but viewport height stays consistent
-	needsScrollIndicator := totalLines > contentViewportHeight
+	if a.search.query == "" && a.selection.empty() {
+		a.syncViewport()
+	} else {
+		// Highlighted lines are loaded for this frame only
+		lines, _ := a.conversationLines(width)
+		if a.search.query != "" {
+			lines = a.highlightSearch(lines)
+		}
+		lines = a.highlightSelection(lines)
+		a.viewport.SetContent(strings.Join(lines, "\n"))
+		a.viewportBuild = 0
+	}
+	a.viewport.Width, a.viewport.Height = width, height-2
+	a.viewport.SetYOffset(a.viewport.YOffset)
 
-	// Ensure scroll position is valid
-	if a.scrollPosition < 0 {
-		a.scrollPosition = 0
+	pill := ""
+	if a.follow.Unseen() > 0 && a.viewport.TotalLineCount() > a.viewport.Height {
+		pill = a.renderNewMessagesPill(width)
 	}
+	return a.viewport.View() + "\n" + pill + "\n"
+}
 
-	// Calculate max scroll position
-	maxScroll := 0
-	if totalLines > contentViewportHeight {
-		maxScroll = totalLines - contentViewportHeight
+// conversationLines lays every message out into display lines for a panel
+// of the given inner width, returning the lines and the index of the first
+// line of each message. The layout is kept until something shown changes,
+// and only appended messages are laid out when the conversation grows.
+func (a *Application) conversationLines(width int) ([]string, []int) {
+	l := &a.convLayout
+	view := a.currentLayoutView(width)
+	switch {
+	case l.build > 0 && l.view == view && l.count == len(a.messages) && l.laidOutThrough(a):
+		// Unchanged
+	case l.build > 0 && l.view == view && l.count > 0 && l.count < len(a.messages) && l.laidOutThrough(a):
+		folds := a.hiddenAttempts()
+		a.layoutMessages(a.restartIndex(folds), folds)
+	default:
+		l.view = view
+		a.layoutMessages(0, a.hiddenAttempts())
 	}
+	return l.lines, l.offsets
+}
 
-	if a.scrollPosition > maxScroll {
-		a.scrollPosition = maxScroll
+// layoutMessages lays the messages out from index start on, keeping the
+// lines of the ones before it
+func (a *Application) layoutMessages(start int, folds map[int]foldedAttempts) {
+	l := &a.convLayout
+	width := l.view.width
+	notes := a.footnotes()
+	rendered := a.renderCache
+	if start == 0 || rendered == nil {
+		rendered = make(map[string]renderedMessage, len(a.messages))
 	}
+	foldAt, foldEnd := 0, 0
+	now := time.Now()
 
-	// Get the lines to display based on scroll position
-	var displayLines []string
-	if totalLines <= contentViewportHeight {
-		// All content fits, show everything
-		displayLines = allLines
+	if start == 0 {
+		l.lines, l.offsets, l.starts = nil, nil, nil
 	} else {
-		// Apply scrolling - take exactly contentViewportHeight lines
-		startLine := a.scrollPosition
-		endLine := startLine + contentViewportHeight
-		if endLine > totalLines {
-			endLine = totalLines
-		}
-		displayLines = allLines[startLine:endLine]
+		// Clipped so appending never writes over lines already handed out
+		l.lines = slices.Clip(l.lines[:l.starts[start]])
+		l.offsets = l.offsets[:start]
+		l.starts = l.starts[:start]
 	}
 
-	// Build final content
-	var finalContent []string
-
-	// Add the content lines
-	finalContent = append(finalContent, displayLines...)
-
-	// Add scroll indicator if needed
-	if needsScrollIndicator {
-		// Calculate actual displayed range
-		// displayStart := a.scrollPosition + 1
-		// displayEnd := a.scrollPosition + len(displayLines)
-
-		// scrollInfo := fmt.Sprintf("[Lines %d-%d of %d] ", displayStart, displayEnd, totalLines)
+	for i := start; i < len(a.messages); i++ {
+		msg := a.messages[i]
+		l.starts = append(l.starts, len(l.lines))
+		l.offsets = append(l.offsets, len(l.lines))
+		if i < foldEnd {
+			// Folded away; points at the fold's line
+			l.offsets[i] = foldAt
+			continue
+		}
+		if fold, ok := folds[i]; ok {
+			foldAt, foldEnd = len(l.lines), fold.end
+			l.lines = append(l.lines, a.styles.Status.Render(fold.label()))
+			if fold.end < len(a.messages) {
+				l.lines = append(l.lines, "")
+			}
+			continue
+		}
+		if a.timestamps != timestampsOff {
+			var prev claude.ConversationMessage
+			if i > 0 {
+				prev = a.messages[i-1]
+			}
+			if separator := a.dateSeparator(prev, msg, i == 0, width); separator != "" {
+				l.lines = append(l.lines, separator, "")
+				l.offsets[i] = len(l.lines)
+			}
+		}
+		content := a.displayContent(i, notes)
+		if label := a.modelLabel(msg); label != "" {
+			content = label + " " + content
+		}
 
-		// if a.scrollPosition == 0 {
-		// 	scrollInfo += "↓ scroll down"
-		// } else if a.scrollPosition >= maxScroll {
-		// 	scrollInfo += "↑ scroll up"
-		// } else {
-		// 	scrollInfo += "↑↓ scroll"
-		// }
+		// The selected message gives up a column to its cursor bar, and
+		// each message to its time when they are shown
+		selected := i == a.selectedMessage
+		msgWidth := width
+		if selected {
+			msgWidth--
+		}
+		if a.timestamps != timestampsOff {
+			msgWidth -= timestampWidth
+		}
 
-		// Pad content to exact height before adding scroll indicator
-		for len(finalContent) < contentViewportHeight {
-			finalContent = append(finalContent, "")
+		// Copied, as the cached lines are shared
+		msgLines := slices.Clone(a.renderMessageCached(msg, content, msgWidth, rendered))
+		if a.timestamps != timestampsOff {
+			msgLines[0] = a.timestampLabel(msg.Timestamp, now) + msgLines[0]
+			for j := 1; j < len(msgLines); j++ {
+				msgLines[j] = strings.Repeat(" ", timestampWidth) + msgLines[j]
+			}
 		}
+		if selected {
+			bar := a.styles.Highlight.Render("▌")
+			for j := range msgLines {
+				msgLines[j] = bar + msgLines[j]
+			}
+		}
+		l.lines = append(l.lines, msgLines...)
 
-		// Add separator and scroll indicator
-		finalContent = append(finalContent, "")
-		// if len(finalContent) < height {
-		// 	finalContent = append(finalContent, a.styles.Status.Render(scrollInfo))
-		// }
+		// Add spacing between messages (except after last message)
+		if i < len(a.messages)-1 {
+			l.lines = append(l.lines, "")
+		}
 	}
 
-	for len(finalContent) < height {
-		finalContent = append(finalContent, "")
+	a.renderCache = rendered
+	l.build++
+	l.count = len(a.messages)
+	l.folds = folds
+	l.lastID, l.lastLen, l.lastDay = "", 0, ""
+	if l.count > 0 {
+		last := a.messages[l.count-1]
+		l.lastID, l.lastLen, l.lastDay = last.ID, len(last.Content), messageDay(last)
 	}
-	// Safety cap: never exceed allotted height
-	if len(finalContent) > height {
-		finalContent = finalContent[:height]
-	}
-	content := strings.Join(finalContent, "\n")
+}
 
-	return content
+// formatMessage renders a message for the conversation panel
+func (a *Application) formatMessage(msg claude.ConversationMessage, content string, msgWidth int) string {
+	var formattedMsg string
+	switch msg.Type {
+	case "assistant":
+		// Use markdown renderer for assistant messages, narrowed to the
+		// columns left after the prefix
+		if renderer := a.assistantMarkdown(msgWidth - 4); renderer != nil {
+			if rendered, err := renderer.Render(content); err == nil {
+				// Clean up the rendered output
+				rendered = strings.TrimSpace(rendered)
+				lines := strings.Split(rendered, "\n")
+
+				// Add emoji prefix to first line only
+				if len(lines) > 0 {
+					lines[0] = "🤖 " + lines[0]
+					for j := 1; j < len(lines); j++ {
+						lines[j] = "   " + lines[j] // Indent continuation
+					}
+				}
+				formattedMsg = strings.Join(lines, "\n")
+			} else {
+				wrappedContent := a.wrapContent(content, msgWidth-4)
+				formattedMsg = a.styles.Message.Render("🤖 " + wrappedContent)
+			}
+		} else {
+			wrappedContent := a.wrapContent(content, msgWidth-4)
+			formattedMsg = a.styles.Message.Render("🤖 " + wrappedContent)
+		}
+	case "tool_use":
+		wrappedContent := wrapMessage(content, msgWidth-4)
+		if msg.ToolUseID != "" && msg.ToolUseID == a.selectedTool {
+			formattedMsg = a.styles.Highlight.Render("▶  " + wrappedContent)
+		} else {
+			formattedMsg = a.styles.Tool.Render("🔧 " + wrappedContent)
+		}
+		if msg.Diff != "" {
+			formattedMsg += "\n" + a.renderDiff(msg, msgWidth-4)
+		}
+		if a.expandedTools[msg.ToolUseID] {
+			formattedMsg += "\n" + a.toolDetail(msg, msgWidth-4)
+		}
+	case "warning":
+		wrappedContent := a.wrapContent(content, msgWidth-4)
+		formattedMsg = a.styles.Error.Render("⚠️  " + wrappedContent)
+	case "user":
+		wrappedContent := a.wrapContent(content, msgWidth-4)
+		formattedMsg = a.styles.Highlight.Render("👤 " + wrappedContent)
+	default:
+		wrappedContent := a.wrapContent(content, msgWidth-4)
+		formattedMsg = a.styles.Message.Render("ℹ️  " + wrappedContent)
+	}
+	return formattedMsg
 }
 
 // renderSidePanel renders the side panel with session info
@@ -810,6 +1671,15 @@ func (a *Application) renderSidePanel(height int) string {
 			fmt.Sprintf("Turns: %d", a.currentSession.TurnCount),
 			fmt.Sprintf("Cost: $%.4f", a.currentSession.TotalCost),
 		)
+		if a.sessionStats.Interruptions > 0 {
+			content = append(content, a.styles.Error.Render(
+				fmt.Sprintf("Interrupted turns: %d", a.sessionStats.Interruptions)))
+		}
+		content = append(content, a.budgetLines()...)
+		content = append(content, a.contextMeterLine())
+		if badge := a.stalenessBadge(); badge != "" {
+			content = append(content, badge)
+		}
 	} else {
 		if managerSessionID != "" {
 			content = append(content, "Manager has session, UI doesn't")
@@ -821,12 +1691,23 @@ func (a *Application) renderSidePanel(height int) string {
 	content = append(content, "")
 
 	// Token usage
-	if a.sessionStats.CumulativeUsage.InputTokens > 0 {
-		content = append(content, a.styles.Highlight.Render("Token Usage"))
+	if usage := a.usageLines(); len(usage) > 0 {
+		content = append(content, usage...)
+		content = append(content, "")
+	}
+
+	// Latency breakdown for the last turn
+	if a.lastTurn != nil && a.lastTurn.Latency.WallTime > 0 {
+		latency := a.lastTurn.Latency
+		content = append(content, a.styles.Highlight.Render("Last Turn"))
+		if a.lastTurn.Model != "" {
+			content = append(content, fmt.Sprintf("On: %s", truncateString(a.lastTurn.Model, 25)))
+		}
 		content = append(content,
-			fmt.Sprintf("Input: %d", a.sessionStats.CumulativeUsage.InputTokens),
-			fmt.Sprintf("Output: %d", a.sessionStats.CumulativeUsage.OutputTokens),
-			fmt.Sprintf("Cache: %d", a.sessionStats.CumulativeUsage.CacheReadInputTokens),
+			fmt.Sprintf("First token: %s", formatLatency(latency.TimeToFirstToken)),
+			fmt.Sprintf("Model: %s", formatLatency(latency.ModelTime)),
+			fmt.Sprintf("Tools: %s", formatLatency(latency.ToolTime)),
+			fmt.Sprintf("Total: %s", formatLatency(latency.WallTime)),
 		)
 		content = append(content, "")
 	}
@@ -847,9 +1728,18 @@ func (a *Application) renderSidePanel(height int) string {
 	if len(a.toolActivity) > 0 {
 		content = append(content, a.styles.Tool.Render("Tool Activity"))
 		for _, activity := range a.toolActivity[max(0, len(a.toolActivity)-3):] {
+			marker := "•"
+			switch activity.Status {
+			case "running":
+				marker = "⟳"
+			case "completed":
+				marker = "✓"
+			case "failed":
+				marker = "✗"
+			}
 			content = append(
 				content,
-				a.styles.Tool.Render("• "+truncateString(activity.Activity, 25)),
+				a.styles.Tool.Render(marker+" "+truncateString(activity.Activity, 25)),
 			)
 		}
 	}
@@ -871,30 +1761,23 @@ func (a *Application) renderSidePanel(height int) string {
 // renderInputPanel renders the input area
 func (a *Application) renderInputPanel(width int) string {
 	if a.isLoading {
-		return a.styles.Status.Render("⏳ Processing...")
+		return a.renderStatusBar(width)
 	}
 
-	if a.inputActive {
-		var modeIndicator string
-		var cursor string
-
-		switch a.inputMode {
-		case InputModeNormal:
-			modeIndicator = "[NORMAL]"
-			cursor = "█" // Block cursor for normal mode
-		case InputModeInsert:
-			modeIndicator = "[INSERT]"
-			cursor = "│" // Line cursor for insert mode
-		}
+	if a.composing() {
+		return a.renderComposeInput(width, a.inputCursor())
+	}
 
-		// Show command buffer if in multi-key command
-		if a.commandBuffer != "" {
-			modeIndicator = fmt.Sprintf("[NORMAL:%s]", a.commandBuffer)
-		}
+	if a.inputActive {
+		// Mode, with the pending command or register
+		modeIndicator := a.vimModeLabel()
+		cursor := a.inputCursor()
 
 		// Build input line with cursor at correct position
 		var inputLine string
-		if len(a.inputBuffer) == 0 {
+		if a.inputMode == InputModeVisual || a.inputMode == InputModeVisualLine {
+			inputLine = a.renderVisualInput()
+		} else if len(a.inputBuffer) == 0 {
 			inputLine = cursor
 		} else if a.cursorPos >= len(a.inputBuffer) {
 			inputLine = a.inputBuffer + cursor
@@ -902,14 +1785,31 @@ func (a *Application) renderInputPanel(width int) string {
 			inputLine = a.inputBuffer[:a.cursorPos] + cursor + a.inputBuffer[a.cursorPos:]
 		}
 
-		prompt := fmt.Sprintf("%s > %s", modeIndicator, inputLine)
-		return a.styles.Highlight.Render(prompt)
+		prompt := a.styles.Highlight.Render(fmt.Sprintf("%s > %s", modeIndicator, inputLine))
+		if a.nextModel != "" {
+			prompt = a.styles.Status.Render("["+a.nextModel+"]") + " " + prompt
+		}
+		if label := a.forecastLabel(); label != "" {
+			prompt += "  " + a.styles.Status.Render(label)
+		}
+		return prompt
+	}
+
+	if a.footnoteJump {
+		return a.styles.Highlight.Render(fmt.Sprintf("Jump to footnote: %s█", a.footnoteDigits))
+	}
+
+	if a.search.editing {
+		return a.styles.Highlight.Render(fmt.Sprintf("/%s█", a.search.input))
 	}
 
 	instruction := "Press Enter to start typing your message..."
 	if a.statusMessage != "" {
 		instruction = a.statusMessage
 	}
+	if len(a.quickReplies) > 0 {
+		return a.styles.Highlight.Render(a.quickRepliesLabel()) + "  " + a.styles.Status.Render(instruction)
+	}
 
 	return a.styles.Status.Render(instruction)
 }
@@ -919,20 +1819,38 @@ func (a *Application) renderHelpView() string {
 	content := []string{
 		a.styles.Header.Render("CustomClaude TUI - Help"),
 		"",
-		a.styles.Highlight.Render("Keyboard Shortcuts:"),
-		"  Enter     - Start typing a message",
-		"  Ctrl+C/Q  - Quit application",
-		"  Ctrl+N    - Start new conversation",
-		"  Ctrl+H    - Show this help",
-		"  Ctrl+S    - Settings (future)",
-		"  Ctrl+M    - Return to main view",
-		"  Esc       - Cancel input or return to main",
+		a.styles.Highlight.Render("Commands:"),
+		"  /resume   - Browse saved conversations (resume, mark, tag, archive, export, delete)",
+		"  /context  - Show approximate context window composition",
+		"  /takeover - Take over a session locked by another instance",
+		"  /mcp      - Manage MCP servers (list, check, enable <name>, disable <name>)",
+		"  /system   - Show the system prompt (edit, set <text>, clear, reset)",
+		"  /allowlist [name|default] - Show the tool allowlist presets or pick one for this project",
+		"  /profile [name|none|auto] - List the profiles or switch this tab to one",
+		"  /stderr [all|warn|error|clear] - Toggle the stderr pane or filter it by severity",
+		"  /fork     - Pick an earlier reply and fork a new conversation from it",
+		"  /ask [model] [prompt] - Run one prompt on another model (no model: pick one)",
+		"  /log [n]  - Show the last n log entries (default 20)",
+		"  /review [focus] - Review uncommitted changes and list findings as JSON",
+		"  /findings [path] - Show the last review's findings, or export them (.sarif, .sarif.json or .xml for JUnit)",
+		"  /mirror [path | off] - Copy assistant text to a file or named pipe as it streams",
+		"  /compact  - Summarize the conversation and continue in a new one seeded with the summary",
+		"  /stats    - Show usage statistics across saved conversations",
+		"  /usage [days] - Show spending per day, model and project over the last days (7)",
+		"  /changelog - Check for a newer release and show its notes and the upgrade command",
+		"  /config - Edit config.toml in a settings tree (also Ctrl+S)",
+		"  /cd [path] - Show or change the directory claude runs in; changing it starts a new conversation",
+		"  /env [NAME=value | unset NAME] - Show or change the environment of claude's tools in this tab",
+		"  /t [name] - Fill in and send a prompt template from the templates config directory",
+		"  /doctor [kill] - Check the setup and orphaned claude processes (kill: stop them)",
+		"  /debug-bundle [turn] - Zip stream lines, events and UI state for a bug report",
 		"",
 		a.styles.Highlight.Render("Vim-like Input Mode:"),
 		"  Normal Mode:",
 		"    i       - Insert mode at cursor",
 		"    a       - Insert mode after cursor",
 		"    A       - Insert mode at end of line",
+		"    o / O   - Open a line below / above (multi-line compose)",
 		"    x       - Delete character under cursor",
 		"    dd      - Delete entire line",
 		"    cw      - Change word (delete and insert)",
@@ -942,15 +1860,25 @@ func (a *Application) renderHelpView() string {
 		"    0       - Move to beginning of line",
 		"    $       - Move to end of line",
 		"    ←/→     - Move cursor left/right",
+		"    yy / yw - Yank line / word",
+		"    p / P   - Put after / before the cursor",
+		"    \"x      - Use register x (a-z, \" or + for the clipboard) for the next yank, delete or put",
+		"    u       - Undo",
+		"    Ctrl+R  - Redo",
+		"    .       - Repeat the last change",
+		"    v / V   - Visual mode, by character / by line",
+		"  Visual Mode:",
+		"    h/l w b 0 $ - Extend the selection; o - Switch ends",
+		"    y d c p - Yank, delete, change or put over the selection; Esc - Back to normal mode",
 		"  Insert Mode:",
 		"    Esc     - Return to normal mode",
-		"    Enter   - Send message (if not empty)",
+		"    Enter   - Send message (if not empty); a new line while composing",
+		"    Ctrl+J / Alt+Enter - New line, starting multi-line compose",
+		"  Compose (multi-line input):",
+		"    ↑/↓ or j/k - Move between lines",
+		"    Ctrl+S, or Enter in normal mode - Send the message",
 		"    Backspace - Delete previous character",
-		"",
-		a.styles.Highlight.Render("Scrolling:"),
-		"  ↑/↓ or j/k  - Scroll up/down one line (when not in input)",
-		"  PgUp/PgDn   - Scroll page up/down",
-		"  Home/End    - Jump to top/bottom",
+		"    Ctrl+P  - Preview the prompt as sent (diff context, @files, template, tokens); Enter sends, Esc edits, x discards",
 		"",
 		a.styles.Highlight.Render("Features:"),
 		"  • Real-time streaming from Claude",
@@ -964,18 +1892,15 @@ func (a *Application) renderHelpView() string {
 		"Press Ctrl+M or Esc to return to main view",
 	}
 
-	return a.styles.App.Render(strings.Join(content, "\n"))
-}
-
-// renderSettingsView renders the settings screen (placeholder)
-func (a *Application) renderSettingsView() string {
-	content := []string{
-		a.styles.Header.Render("CustomClaude TUI - Settings"),
-		"",
-		"Settings panel coming soon...",
-		"",
-		"Press Ctrl+M or Esc to return to main view",
+	// Key bindings come first, listed from the registry as currently bound
+	var bindings []string
+	for _, context := range []string{contextGlobal, contextConversation} {
+		bindings = append(bindings, a.styles.Highlight.Render(context+":"))
+		bindings = append(bindings, a.keymap.helpLines(context)...)
+		bindings = append(bindings, "")
 	}
+	bindings = append(bindings, "  Keys are rebound in [keybindings] of config.toml, e.g. help = \"f1\"", "")
+	content = slices.Insert(content, 2, bindings...)
 
 	return a.styles.App.Render(strings.Join(content, "\n"))
 }
@@ -988,186 +1913,27 @@ func max(a, b int) int {
 	return b
 }
 
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+func formatLatency(d time.Duration) string {
+	if d <= 0 {
+		return "-"
 	}
-	return s[:maxLen-3] + "..."
-}
-
-func wordWrap(text string, width int) string {
-	if len(text) <= width {
-		return text
-	}
-
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return text
-	}
-
-	var result []string
-	var currentLine strings.Builder
-
-	for _, word := range words {
-		if currentLine.Len()+len(word)+1 > width {
-			if currentLine.Len() > 0 {
-				result = append(result, currentLine.String())
-				currentLine.Reset()
-			}
-		}
-
-		if currentLine.Len() > 0 {
-			currentLine.WriteString(" ")
-		}
-		currentLine.WriteString(word)
-	}
-
-	if currentLine.Len() > 0 {
-		result = append(result, currentLine.String())
-	}
-
-	return strings.Join(result, "\n")
-}
-
-// Helper methods for safe scrolling
-func (a *Application) calculateMaxScrollPosition() int {
-	// Use LayoutManager to match rendered widths/heights
-	lm := components.NewLayoutManager(a.width, a.height)
-	dims := lm.CalculatePanelDimensions()
-	constraints := lm.GetConversationConstraints()
-
-	// Match wrapping used in renderConversationPanel for non-markdown content
-	wrapBaseWidth := dims.ConversationWidth - 4
-	if wrapBaseWidth < 1 {
-		wrapBaseWidth = 1
-	}
-	wrapWidth := wrapBaseWidth - 4
-	if wrapWidth < 1 {
-		wrapWidth = 1
-	}
-
-	// Calculate total lines from all messages using same logic as renderConversationPanel
-	var allLines []string
-	for i, msg := range a.messages {
-		var formattedMsg string
-		switch msg.Type {
-		case "assistant":
-			if a.markdownRenderer != nil {
-				if rendered, err := a.markdownRenderer.Render(msg.Content); err == nil {
-					rendered = strings.TrimSpace(rendered)
-					lines := strings.Split(rendered, "\n")
-					if len(lines) > 0 {
-						lines[0] = "🤖 " + lines[0]
-						for j := 1; j < len(lines); j++ {
-							lines[j] = "   " + lines[j]
-						}
-					}
-					formattedMsg = strings.Join(lines, "\n")
-				} else {
-					wrapped := wordWrap(msg.Content, wrapWidth)
-					formattedMsg = "🤖 " + wrapped
-				}
-			} else {
-				wrapped := wordWrap(msg.Content, wrapWidth)
-				formattedMsg = "🤖 " + wrapped
-			}
-		case "tool_use":
-			wrapped := wordWrap(msg.Content, wrapWidth)
-			formattedMsg = "🔧 " + wrapped
-		case "user":
-			wrapped := wordWrap(msg.Content, wrapWidth)
-			formattedMsg = "👤 " + wrapped
-		default:
-			wrapped := wordWrap(msg.Content, wrapWidth)
-			formattedMsg = "ℹ️  " + wrapped
-		}
-		msgLines := strings.Split(formattedMsg, "\n")
-		allLines = append(allLines, msgLines...)
-		if i < len(a.messages)-1 {
-			allLines = append(allLines, "")
-		}
-	}
-
-	totalLines := len(allLines)
-
-	viewportHeight := constraints.ViewportHeight
-	if viewportHeight < 1 {
-		viewportHeight = 1
-	}
-
-	maxScroll := totalLines - viewportHeight
-	if maxScroll < 0 {
-		maxScroll = 0
-	}
-	return maxScroll
-}
-
-func (a *Application) clampScrollPosition() {
-	if a.scrollPosition < 0 {
-		a.scrollPosition = 0
-	}
-	maxScroll := a.calculateMaxScrollPosition()
-	if a.scrollPosition > maxScroll {
-		a.scrollPosition = maxScroll
-	}
-}
-
-func (a *Application) scrollToBottomSafe() {
-	a.scrollPosition = a.calculateMaxScrollPosition()
-}
-
-// Scrolling methods
-func (a *Application) scrollUp() {
-	if a.scrollPosition > 0 {
-		a.scrollPosition--
-	}
-}
-
-func (a *Application) scrollDown() {
-	maxScroll := a.calculateMaxScrollPosition()
-	if a.scrollPosition < maxScroll {
-		a.scrollPosition++
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
 	}
+	return d.Round(100 * time.Millisecond).String()
 }
 
-func (a *Application) scrollPageUp() {
-	lm := components.NewLayoutManager(a.width, a.height)
-	dims := lm.GetConversationConstraints()
-
-	// Calculate viewport height the same way as renderConversationPanel
-	height := max(1, dims.ConversationHeight-4)
-	scrollIndicatorLines := 2
-	viewport := height - scrollIndicatorLines
-
-	if viewport < 1 {
-		viewport = 1
-	}
-	a.scrollPosition -= viewport
-	a.clampScrollPosition()
-}
-
-func (a *Application) scrollPageDown() {
-	lm := components.NewLayoutManager(a.width, a.height)
-	dims := lm.GetConversationConstraints()
-
-	// Calculate viewport height the same way as renderConversationPanel
-	height := max(1, dims.ConversationHeight-4)
-	scrollIndicatorLines := 2
-	viewport := height - scrollIndicatorLines
-
-	if viewport < 1 {
-		viewport = 1
-	}
-	a.scrollPosition += viewport
-	a.clampScrollPosition()
+func truncateString(s string, maxLen int) string {
+	return components.Truncate(s, maxLen)
 }
 
-func (a *Application) scrollToTop() {
-	a.scrollPosition = 0
+func wordWrap(text string, width int) string {
+	return components.WrapText(text, width)
 }
 
-func (a *Application) scrollToBottom() {
-	a.scrollToBottomSafe()
+// wrapMessage wraps message text to width, right-aligning RTL content
+func wrapMessage(text string, width int) string {
+	return components.AlignText(wordWrap(text, width), width, components.DetectScript(text))
 }
 
 func min(a, b int) int {
@@ -1186,7 +1952,7 @@ func (a *Application) insertChar(char string) {
 		a.cursorPos = len(a.inputBuffer)
 	} else {
 		a.inputBuffer = a.inputBuffer[:a.cursorPos] + char + a.inputBuffer[a.cursorPos:]
-		a.cursorPos++
+		a.cursorPos += len(char)
 	}
 }
 
@@ -1231,10 +1997,10 @@ func (a *Application) moveWordBackward() {
 	}
 }
 
-// deleteWord deletes the word at cursor position
-func (a *Application) deleteWord() {
+// deleteWord deletes the word at cursor position, returning it
+func (a *Application) deleteWord() string {
 	if a.cursorPos >= len(a.inputBuffer) {
-		return
+		return ""
 	}
 
 	startPos := a.cursorPos
@@ -1250,6 +2016,7 @@ func (a *Application) deleteWord() {
 	}
 
 	// Delete the word
+	deleted := a.inputBuffer[startPos:a.cursorPos]
 	a.inputBuffer = a.inputBuffer[:startPos] + a.inputBuffer[a.cursorPos:]
 	a.cursorPos = startPos
 
@@ -1257,4 +2024,5 @@ func (a *Application) deleteWord() {
 	if a.cursorPos >= len(a.inputBuffer) && len(a.inputBuffer) > 0 {
 		a.cursorPos = len(a.inputBuffer) - 1
 	}
+	return deleted
 }
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/approval"
+	"complex/internal/notify"
+	"customclaude/pkg/platform"
+)
+
+// maxApprovalInputLines bounds how much of the tool input the modal shows
+const maxApprovalInputLines = 20
+
+// ApprovalRequestMsg is sent when the permission server asks for a decision
+type ApprovalRequestMsg struct {
+	Request approval.Request
+}
+
+// ApprovalAutoDecidedMsg is sent when a request was decided without asking,
+// by the permission policy or an always allow choice
+type ApprovalAutoDecidedMsg struct {
+	Request  approval.Request
+	Decision approval.Decision
+	Reason   string
+}
+
+// startApprovalBroker starts listening for permission requests
+func (a *Application) startApprovalBroker() {
+	a.approvalBroker = approval.NewBroker(func(req approval.Request) {
+		if a.program != nil {
+			a.program.Send(ApprovalRequestMsg{Request: req})
+		}
+	})
+	a.approvalBroker.SetGuard(a.guard)
+	a.approvalBroker.OnAutoDecision(func(req approval.Request, decision approval.Decision, reason string) {
+		if a.program != nil {
+			a.program.Send(ApprovalAutoDecidedMsg{Request: req, Decision: decision, Reason: reason})
+		}
+	})
+	if path := a.approvalPolicyPath(); path != "" {
+		a.approvalBroker.SetPolicyFile(path)
+		if _, _, err := a.approvalBroker.Policy(); err != nil {
+			go a.program.Send(ErrorMsg{Error: err, Context: "approval policy"})
+		}
+	}
+	if err := a.approvalBroker.Start(a.ctx, a.config.Approval.Listen, a.config.Approval.Socket); err != nil {
+		go a.program.Send(ErrorMsg{Error: err, Context: "approval"})
+	}
+}
+
+// approvalPolicyPath returns the configured policy file, or policy.toml in
+// the config directory
+func (a *Application) approvalPolicyPath() string {
+	if a.config.Approval.Policy != "" {
+		return a.config.Approval.Policy
+	}
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, approval.DefaultPolicyFile)
+}
+
+// handleApprovalAutoDecided reports a request decided without asking
+func (a *Application) handleApprovalAutoDecided(msg ApprovalAutoDecidedMsg) (tea.Model, tea.Cmd) {
+	if msg.Decision.Behavior == approval.BehaviorDeny {
+		a.notify(toastWarning, fmt.Sprintf("%s denied automatically (%s)", msg.Request.ToolName, msg.Reason))
+	} else {
+		a.notify(toastInfo, fmt.Sprintf("%s allowed automatically (%s)", msg.Request.ToolName, msg.Reason))
+	}
+	return a, nil
+}
+
+// handleApprovalRequest queues a permission request; the dialog shows one
+// request at a time
+func (a *Application) handleApprovalRequest(msg ApprovalRequestMsg) (tea.Model, tea.Cmd) {
+	a.approvals = append(a.approvals, msg.Request)
+	a.notifier.Notify(notify.Notification{
+		Event: notify.EventApproval,
+		Title: "Claude needs approval",
+		Body:  fmt.Sprintf("%s is waiting for permission", msg.Request.ToolName),
+	})
+	if len(a.approvals) == 1 {
+		a.openApprovalDialog()
+	}
+	return a, nil
+}
+
+// openApprovalDialog asks about the request at the head of the queue.
+// Risky commands require typing "yes" instead of a single keypress.
+func (a *Application) openApprovalDialog() {
+	req := a.approvals[0]
+	risks := a.approvalBroker.Risks(req)
+
+	body := []string{a.styles.Highlight.Render(fmt.Sprintf("Claude wants to use: %s", req.ToolName)), ""}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, req.Input, "", "  "); err != nil {
+		pretty.Reset()
+		pretty.Write(req.Input)
+	}
+	inputLines := strings.Split(pretty.String(), "\n")
+	if len(inputLines) > maxApprovalInputLines {
+		hidden := len(inputLines) - maxApprovalInputLines
+		inputLines = append(inputLines[:maxApprovalInputLines], fmt.Sprintf("... %d more lines", hidden))
+	}
+	for _, line := range inputLines {
+		body = append(body, "  "+line)
+	}
+	if len(a.approvals) > 1 {
+		body = append(body, "", a.styles.Status.Render(fmt.Sprintf("%d more requests queued", len(a.approvals)-1)))
+	}
+
+	if len(risks) > 0 {
+		body = append(body, "",
+			a.styles.Error.Render("WARNING: potentially destructive command"),
+			a.styles.Error.Render("Matched: "+strings.Join(risks, ", ")))
+		d := newInputDialog("Permission Request", body, "Type \"yes\" to allow: ", "",
+			func(result dialogResult) (tea.Model, tea.Cmd) {
+				if result.cancelled {
+					a.resolveApproval(approval.Deny("User denied the risky command"))
+				} else {
+					a.resolveApproval(approval.Allow(req.Input))
+				}
+				return a, nil
+			})
+		d.danger = true
+		d.accept = func(text string) bool { return strings.TrimSpace(text) == "yes" }
+		d.hint = "Esc: Deny"
+		a.openDialog(d)
+		return
+	}
+
+	a.openDialog(newChoiceDialog("Permission Request", body,
+		[]dialogOption{{"y", "Allow"}, {"n", "Deny"}, {"a", "Always allow this tool"}},
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			switch result.choice {
+			case "y":
+				a.resolveApproval(approval.Allow(req.Input))
+			case "a":
+				a.approvalBroker.AlwaysAllow(req.ToolName)
+				a.resolveApproval(approval.Allow(req.Input))
+			default:
+				a.resolveApproval(approval.Deny("User denied the request"))
+			}
+			return a, nil
+		}))
+}
+
+// resolveApproval answers the request at the head of the queue and asks
+// about the next one
+func (a *Application) resolveApproval(decision approval.Decision) {
+	req := a.approvals[0]
+	a.approvals = a.approvals[1:]
+
+	if err := a.approvalBroker.Resolve(req.ID, decision); err != nil {
+		a.errors = append(a.errors, ErrorMsg{Error: err, Context: "approval"})
+	}
+	level := toastSuccess
+	if decision.Behavior == approval.BehaviorDeny {
+		level = toastWarning
+	}
+	a.notify(level, fmt.Sprintf("%s: %s", req.ToolName, decision.Behavior))
+	if len(a.approvals) > 0 {
+		a.openApprovalDialog()
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// askModels are the model aliases offered by the /ask picker
+var askModels = []string{"opus", "sonnet", "haiku"}
+
+// handleAskCommand runs "/ask [<model> [prompt]]". With a prompt it is sent
+// on the model; without one the model is used for the next prompt only, and
+// with no model a picker asks for it.
+func (a *Application) handleAskCommand(args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		a.openAskPicker()
+		return a, nil
+	}
+
+	model, prompt, _ := strings.Cut(args, " ")
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		a.nextModel = model
+		a.statusMessage = fmt.Sprintf("[ask] Next prompt runs on %s", model)
+		return a, nil
+	}
+
+	a.isLoading = true
+	return a.handlePromptInput(PromptInputMsg{
+		Prompt: prompt,
+		Resume: a.sessionManager.CurrentSessionID != "",
+		Model:  model,
+	})
+}
+
+// openAskPicker asks which model the next prompt runs on
+func (a *Application) openAskPicker() {
+	options := make([]dialogOption, 0, len(askModels)+1)
+	for i, model := range askModels {
+		options = append(options, dialogOption{strconv.Itoa(i + 1), model})
+	}
+	options = append(options, dialogOption{"d", "Session default"})
+
+	current := a.sessionManager.Model
+	if current == "" {
+		current = "claude's default"
+	}
+	a.openDialog(newChoiceDialog(
+		"Model for next prompt",
+		[]string{fmt.Sprintf("The session stays on %s; only the next prompt is affected.", current)},
+		options,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.cancelled {
+				return a, nil
+			}
+			if result.choice == "d" {
+				a.nextModel = ""
+				a.statusMessage = "[ask] Next prompt runs on the session model"
+				return a, nil
+			}
+			index, _ := strconv.Atoi(result.choice)
+			a.nextModel = askModels[index-1]
+			a.statusMessage = fmt.Sprintf("[ask] Next prompt runs on %s", a.nextModel)
+			return a, nil
+		},
+	))
+}
+
+// modelLabel tags user prompts sent with /ask and replies from a model other
+// than the session's
+func (a *Application) modelLabel(msg claude.ConversationMessage) string {
+	if msg.Model == "" {
+		return ""
+	}
+	switch msg.Type {
+	case "user":
+		return "[" + msg.Model + "]"
+	case "assistant":
+		if msg.Model != a.sessionManager.Model {
+			return "[" + msg.Model + "]"
+		}
+	}
+	return ""
+}
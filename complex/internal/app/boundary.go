@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// renderBoundary calls render, turning a panic into an inline error box in
+// place of its output so one bad panel or message leaves the rest of the UI
+// working. Each distinct panic is logged once with its stack, as the view is
+// rendered on every update.
+func (a *Application) renderBoundary(name string, width int, render func() string) (out string) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		key := fmt.Sprintf("%s: %v", name, r)
+		if !a.renderPanics[key] {
+			if a.renderPanics == nil {
+				a.renderPanics = make(map[string]bool)
+			}
+			a.renderPanics[key] = true
+			a.log.Error("render panicked", "panel", name, "panic", r, "stack", string(debug.Stack()))
+		}
+		out = a.renderErrorBox(name, r, width)
+	}()
+	return render()
+}
+
+// renderErrorBox renders the box shown in place of a panel that panicked
+func (a *Application) renderErrorBox(name string, r interface{}, width int) string {
+	lines := []string{
+		a.styles.Error.Render(fmt.Sprintf("⚠ Failed to render %s", name)),
+		wrapMessage(fmt.Sprint(r), max(10, width-4)),
+		a.styles.Status.Render("Details are in the log."),
+	}
+	style := a.styles.ErrorBox
+	if width > 4 {
+		style = style.Width(width - 2)
+	}
+	return style.Render(strings.Join(lines, "\n"))
+}
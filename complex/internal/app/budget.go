@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+	"complex/internal/notify"
+)
+
+// handleBudgetAlert warns with a toast when spending crosses a budget
+// threshold, and notes reaching a limit in the conversation
+func (a *Application) handleBudgetAlert(msg BudgetAlertMsg) (tea.Model, tea.Cmd) {
+	a.notifier.Notify(notify.Notification{
+		Event: notify.EventBudget,
+		Title: "Claude budget alert",
+		Body:  msg.Alert.String(),
+		Time:  msg.Timestamp,
+	})
+	if !msg.Alert.Exceeded() {
+		a.notify(toastWarning, msg.Alert.String())
+	} else {
+		a.notify(toastError, msg.Alert.String())
+		content := msg.Alert.String()
+		if a.sessionManager.Budget.HardLimit {
+			content += "; new prompts need confirmation"
+		}
+		a.messages = append(a.messages, claude.ConversationMessage{
+			ID:        fmt.Sprintf("budget_%d", msg.Timestamp.UnixNano()),
+			Type:      "warning",
+			Content:   content,
+			Timestamp: msg.Timestamp,
+		})
+		a.followNewContent(true)
+	}
+	return a, nil
+}
+
+// budgetLines renders spending against the configured limits for the side
+// panel
+func (a *Application) budgetLines() []string {
+	budget := a.sessionManager.Budget
+	var lines []string
+	line := func(label string, spent, limit float64) {
+		if limit <= 0 {
+			return
+		}
+		text := fmt.Sprintf("%s: $%.2f / $%.2f", label, spent, limit)
+		if spent >= limit {
+			lines = append(lines, a.styles.Error.Render(text))
+		} else {
+			lines = append(lines, a.styles.Status.Render(text))
+		}
+	}
+	line("Budget", a.sessionStats.CumulativeCost, budget.Conversation)
+	line("Today", a.sessionStats.DailyCost, budget.Daily)
+	return lines
+}
+
+// offerBudgetOverride holds a prompt that would spend past a hard budget
+// limit and asks whether to send it anyway
+func (a *Application) offerBudgetOverride(msg PromptInputMsg, alert claude.BudgetAlert) (tea.Model, tea.Cmd) {
+	d := newConfirmDialog(
+		"Budget limit reached",
+		[]string{fmt.Sprintf("The %s.", alert), "Send this prompt anyway?"},
+		false,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.choice == "y" {
+				msg.BudgetConfirmed = true
+				return a.handlePromptInput(msg)
+			}
+			a.restorePrompt(msg.Prompt)
+			a.statusMessage = "[budget] Prompt not sent"
+			return a, nil
+		},
+	)
+	d.danger = true
+	a.openDialog(d)
+	return a, nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// handleCdCommand runs "/cd [path]": without a path it shows the directory
+// claude runs in, otherwise it moves this tab there and starts a new
+// conversation, as claude sessions cannot be resumed across projects
+func (a *Application) handleCdCommand(path string) (tea.Model, tea.Cmd) {
+	if path == "" {
+		a.statusMessage = fmt.Sprintf("[cd] %s (project %s)", a.sessionManager.Dir(), shortenHome(a.sessionManager.ProjectRoot()))
+		return a, nil
+	}
+	if a.isLoading {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "cd", Message: "Wait for the current prompt to finish before changing directory"}
+		}
+	}
+
+	previous := a.sessionManager.Dir()
+	if err := a.sessionManager.SetDir(a.resolvePath(path)); err != nil {
+		return a, func() tea.Msg { return ErrorMsg{Error: err, Context: "cd"} }
+	}
+	dir := a.sessionManager.Dir()
+	if dir == previous {
+		a.statusMessage = fmt.Sprintf("[cd] Already in %s", shortenHome(dir))
+		return a, nil
+	}
+
+	content := fmt.Sprintf("Changed directory to %s. Started a new conversation there.", dir)
+	if profile := a.sessionManager.ActiveProfile(); profile != nil && profile.Source != "" {
+		content += fmt.Sprintf(" Profile %s is active (%s).", profile.Name, profile.Profile.Summary())
+	}
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("cd_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   content,
+		Timestamp: time.Now(),
+	})
+	a.scrollToBottomSafe()
+	a.statusMessage = fmt.Sprintf("[cd] %s", shortenHome(dir))
+	return a, nil
+}
+
+// resolvePath expands a leading ~ and makes a relative path relative to the
+// directory claude runs in
+func (a *Application) resolvePath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(a.sessionManager.Dir(), path)
+	}
+	return path
+}
+
+// shortenHome abbreviates the home directory at the start of path to ~
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return filepath.Join("~", rest)
+	}
+	return path
+}
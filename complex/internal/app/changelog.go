@@ -0,0 +1,188 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/update"
+)
+
+// changelogView holds the last update check and the changelog scroll
+type changelogView struct {
+	result   update.Result
+	checked  bool
+	checking bool
+	err      error
+	scroll   int
+}
+
+// UpdateCheckedMsg carries the result of an update check; manual checks
+// come from /changelog, which shows the result
+type UpdateCheckedMsg struct {
+	Result update.Result
+	Err    error
+	Manual bool
+}
+
+// SetUpdateChecker enables /changelog, and the check at startup when
+// [update] check is on
+func (a *Application) SetUpdateChecker(checker *update.Checker) {
+	a.updates = checker
+}
+
+// checkForUpdates looks for releases in the background; a manual check
+// skips the cache
+func (a *Application) checkForUpdates(manual bool) tea.Cmd {
+	if a.updates == nil || a.changelog.checking {
+		return nil
+	}
+	a.changelog.checking = true
+	checker := a.updates
+	ctx := a.ctx
+	return func() tea.Msg {
+		check := checker.Check
+		if manual {
+			check = checker.Refresh
+		}
+		result, err := check(ctx)
+		return UpdateCheckedMsg{Result: result, Err: err, Manual: manual}
+	}
+}
+
+// startupUpdateCheck checks for releases at startup if enabled
+func (a *Application) startupUpdateCheck() tea.Cmd {
+	if a.updates == nil || !a.updates.Config().Check {
+		return nil
+	}
+	return a.checkForUpdates(false)
+}
+
+// handleUpdateChecked stores a check, announcing a newer release found by
+// the startup check
+func (a *Application) handleUpdateChecked(msg UpdateCheckedMsg) (tea.Model, tea.Cmd) {
+	a.changelog.checking = false
+	a.changelog.err = msg.Err
+	if msg.Err != nil {
+		a.log.Warn("update check failed", "err", msg.Err)
+		if msg.Manual {
+			a.statusMessage = fmt.Sprintf("[update] Check failed: %v", msg.Err)
+		}
+		return a, nil
+	}
+	a.changelog.result = msg.Result
+	a.changelog.checked = true
+
+	if latest, ok := msg.Result.Latest(); ok && msg.Result.Available() && !msg.Manual {
+		a.notify(toastInfo, fmt.Sprintf("%s is available. /changelog shows what changed and how to upgrade.", latest.Tag))
+	}
+	return a, nil
+}
+
+// handleChangelogCommand runs "/changelog": it checks for releases now and
+// shows their notes with the upgrade command
+func (a *Application) handleChangelogCommand() (tea.Model, tea.Cmd) {
+	if a.updates == nil {
+		a.statusMessage = "[update] Update checks are not available"
+		return a, nil
+	}
+	a.state = StateChangelog
+	a.changelog.scroll = 0
+	return a, a.checkForUpdates(true)
+}
+
+// changelogRows is the number of lines the changelog view can show
+func (a *Application) changelogRows() int {
+	return max(5, a.height-12)
+}
+
+// handleChangelogKeyPress handles scrolling in the changelog view
+func (a *Application) handleChangelogKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		a.changelog.scroll--
+	case "down", "j":
+		a.changelog.scroll++
+	case "pgup":
+		a.changelog.scroll -= a.changelogRows()
+	case "pgdown", " ":
+		a.changelog.scroll += a.changelogRows()
+	case "home", "g":
+		a.changelog.scroll = 0
+	case "r":
+		return a, a.checkForUpdates(true)
+	case "esc", "q", "ctrl+m":
+		a.state = StateMain
+	case "ctrl+c":
+		return a, tea.Quit
+	}
+	a.changelog.scroll = max(0, a.changelog.scroll)
+	return a, nil
+}
+
+// renderChangelogView renders the notes of the releases newer than the
+// running build, or of the latest one, and the upgrade command
+func (a *Application) renderChangelogView() string {
+	view := &a.changelog
+	result := view.result
+	content := []string{
+		a.styles.Header.Render(fmt.Sprintf("CustomClaude TUI - Changelog (running %s)", update.Current())),
+		"",
+	}
+
+	latest, ok := result.Latest()
+	releases := result.Newer()
+	switch {
+	case view.checking && !view.checked:
+		content = append(content, a.styles.Status.Render("Checking for releases..."))
+	case view.err != nil && !view.checked:
+		content = append(content, a.styles.Error.Render(fmt.Sprintf("Update check failed: %v", view.err)))
+	case !ok:
+		content = append(content, a.styles.Status.Render("No releases are published yet."))
+	case len(releases) > 0:
+		content = append(content,
+			a.styles.Highlight.Render(fmt.Sprintf("%s is available (%d newer releases).", latest.Tag, len(releases))),
+			"Upgrade with: "+a.updates.Config().UpgradeCommand(latest.Tag),
+		)
+	case result.Current == "dev":
+		releases = []update.Release{latest}
+		content = append(content,
+			a.styles.Status.Render(fmt.Sprintf("Development build; the latest release is %s.", latest.Tag)),
+			"Upgrade with: "+a.updates.Config().UpgradeCommand(latest.Tag),
+		)
+	default:
+		releases = []update.Release{latest}
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("%s is the latest release.", result.Current)))
+	}
+
+	var body []string
+	for _, release := range releases {
+		notes := fmt.Sprintf("## %s (%s)\n\n%s", release.Tag, release.Published.Format(time.DateOnly), release.Notes)
+		rendered := notes
+		if a.markdownRenderer != nil {
+			if out, err := a.markdownRenderer.Render(notes); err == nil {
+				rendered = strings.TrimSpace(out)
+			}
+		}
+		body = append(body, strings.Split(rendered, "\n")...)
+		body = append(body, "")
+	}
+
+	rows := a.changelogRows()
+	view.scroll = min(view.scroll, max(0, len(body)-rows))
+	end := min(len(body), view.scroll+rows)
+	content = append(content, "")
+	content = append(content, body[view.scroll:end]...)
+	if end < len(body) {
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("  ... %d more lines", len(body)-end)))
+	}
+
+	footer := "↑/↓ or j/k: Scroll | PgUp/PgDn: Page | r: Check again | Esc: Back"
+	if view.checked {
+		footer += " | Checked " + result.Checked.Local().Format("2006-01-02 15:04")
+	}
+	content = append(content, "", footer)
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
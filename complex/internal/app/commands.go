@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleSlashCommand runs an in-app command typed into the prompt input
+func (a *Application) handleSlashCommand(input string) (tea.Model, tea.Cmd) {
+	a.isLoading = false
+
+	fields := strings.Fields(input)
+	command := fields[0]
+	a.log.Debug("slash command", "command", command)
+
+	switch command {
+	case "/resume":
+		return a.openResumePicker()
+
+	case "/context":
+		return a.openContextPanel()
+
+	case "/mcp":
+		return a.handleMCPCommand(fields[1:])
+
+	case "/debug-bundle":
+		return a.handleDebugBundleCommand(fields[1:])
+
+	case "/fork":
+		return a.openBranchPicker()
+
+	case "/ask":
+		return a.handleAskCommand(strings.TrimSpace(strings.TrimPrefix(input, command)))
+
+	case "/doctor":
+		return a.handleDoctorCommand(fields[1:])
+
+	case "/t":
+		return a.handleTemplateCommand(fields[1:])
+
+	case "/review":
+		return a.handleReviewCommand(strings.TrimSpace(strings.TrimPrefix(input, command)))
+
+	case "/findings":
+		return a.handleFindingsCommand(strings.TrimSpace(strings.TrimPrefix(input, command)))
+
+	case "/mirror":
+		return a.handleMirrorCommand(strings.TrimSpace(strings.TrimPrefix(input, command)))
+
+	case "/compact":
+		return a.handleCompactCommand()
+
+	case "/stats":
+		return a.openStatsView(statsLedgerDays)
+
+	case "/usage":
+		return a.handleUsageCommand(fields[1:])
+
+	case "/changelog":
+		return a.handleChangelogCommand()
+
+	case "/config":
+		return a.handleConfigCommand()
+
+	case "/cd":
+		return a.handleCdCommand(strings.TrimSpace(strings.TrimPrefix(input, command)))
+
+	case "/env":
+		return a.handleEnvCommand(strings.TrimSpace(strings.TrimPrefix(input, command)))
+
+	case "/log":
+		return a.handleLogCommand(fields[1:])
+
+	case "/system":
+		return a.handleSystemCommand(strings.TrimSpace(strings.TrimPrefix(input, command)))
+
+	case "/allowlist":
+		return a.handleAllowlistCommand(fields[1:])
+
+	case "/stderr":
+		return a.handleStderrCommand(fields[1:])
+
+	case "/profile":
+		return a.handleProfileCommand(fields[1:])
+
+	case "/takeover":
+		if err := a.sessionManager.TakeOverSession(); err != nil {
+			return a, func() tea.Msg {
+				return ErrorMsg{Error: err, Context: "takeover"}
+			}
+		}
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "session", Message: "Took over session lock"}
+		}
+
+	default:
+		return a, func() tea.Msg {
+			return StatusMsg{
+				Status:  "command",
+				Message: fmt.Sprintf("Unknown command: %s", command),
+			}
+		}
+	}
+}
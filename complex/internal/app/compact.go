@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// CompactedMsg reports the end of /compact
+type CompactedMsg struct {
+	Compaction claude.Compaction
+	Err        error
+}
+
+// handleCompactCommand runs "/compact": claude summarizes the conversation
+// and a new conversation continues from the summary, so later prompts do
+// not pay for the whole history
+func (a *Application) handleCompactCommand() (tea.Model, tea.Cmd) {
+	if a.isLoading {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "compact", Message: "Wait for the current prompt to finish before compacting"}
+		}
+	}
+	if a.sessionManager.CurrentSessionID == "" {
+		a.statusMessage = "[compact] Nothing to compact yet"
+		return a, nil
+	}
+
+	a.isLoading = true
+	a.statusMessage = "[compact] Summarizing the conversation…"
+	cmdCtx, cancel := context.WithCancel(a.ctx)
+	a.cancelCommand = cancel
+
+	sessionManager := a.sessionManager
+	tabID := a.tabs[a.activeTab].id
+	return a, func() tea.Msg {
+		go func() {
+			started := time.Now()
+			compaction, err := sessionManager.Compact(cmdCtx)
+			a.program.Send(TabMsg{TabID: tabID, Msg: CompactedMsg{Compaction: compaction, Err: err}})
+			a.program.Send(TabMsg{TabID: tabID, Msg: CommandFinishedMsg{Err: err, Prompt: "/compact", Started: started}})
+		}()
+		return nil
+	}
+}
+
+// handleCompacted notes the new conversation, or why compaction failed
+func (a *Application) handleCompacted(msg CompactedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		a.errors = append(a.errors, ErrorMsg{Error: msg.Err, Context: "compact", Timestamp: time.Now()})
+		a.statusMessage = "[compact] Failed: " + msg.Err.Error()
+		return a, nil
+	}
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:   fmt.Sprintf("compact_%d", time.Now().UnixNano()),
+		Type: "system",
+		Content: fmt.Sprintf("Compacted into a new conversation seeded with the summary above (%d characters). "+
+			"The full conversation stays saved as %s and can be reopened with /resume.",
+			len(msg.Compaction.Summary), msg.Compaction.From),
+		Timestamp: time.Now(),
+	})
+	a.scrollToBottomSafe()
+	a.statusMessage = "[compact] Continuing in a compacted conversation"
+	return a, nil
+}
@@ -0,0 +1,268 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/ui/components"
+)
+
+// pasteWindow is how soon after a burst of typed text an Enter is taken as
+// part of a paste rather than a submit
+const pasteWindow = 50 * time.Millisecond
+
+// composeView is the multi-line input: while it is on, Enter adds a line
+// and the prompt is sent with Ctrl+S
+type composeView struct {
+	active  bool
+	top     int       // first row of the input shown
+	pasteAt time.Time // when the last burst of text arrived
+}
+
+// composing reports whether the input is being written over several lines
+func (a *Application) composing() bool {
+	return a.inputActive && a.inputBuffer != "" && (a.compose.active || strings.Contains(a.inputBuffer, "\n"))
+}
+
+// handleComposeKey handles the insert mode keys that add lines, move
+// between them and send a multi-line prompt
+func (a *Application) handleComposeKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+j", "alt+enter", "shift+enter":
+		a.insertNewline()
+	case "enter":
+		// Enter right after pasted text is a line break of the paste
+		if !a.composing() && time.Since(a.compose.pasteAt) > pasteWindow {
+			return false, nil
+		}
+		a.insertNewline()
+	case "ctrl+s":
+		if !a.composing() {
+			return false, nil
+		}
+		return true, a.submitInput()
+	case "up", "down":
+		if !a.composing() {
+			return false, nil
+		}
+		a.moveLine(msg.String() == "down")
+	default:
+		if msg.Type == tea.KeyRunes && len(msg.Runes) > 1 {
+			// Without bracketed paste, pasted text arrives in bursts of runes
+			a.insertChar(strings.ReplaceAll(string(msg.Runes), "\r", "\n"))
+			a.compose.pasteAt = time.Now()
+			return true, nil
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleComposeNormalKey handles the normal mode keys that open lines and
+// send a multi-line prompt
+func (a *Application) handleComposeNormalKey(key string) (bool, tea.Cmd) {
+	if a.commandBuffer != "" {
+		return false, nil
+	}
+	switch key {
+	case "o", "O":
+		a.openLine(key == "O")
+	case "j", "down", "k", "up":
+		if !a.composing() {
+			return false, nil
+		}
+		a.moveLine(key == "j" || key == "down")
+	case "enter", "ctrl+s":
+		if !a.composing() {
+			return false, nil
+		}
+		return true, a.submitInput()
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// insertNewline breaks the line at the cursor, starting compose mode
+func (a *Application) insertNewline() {
+	a.compose.active = true
+	a.insertChar("\n")
+}
+
+// openLine opens a line below the cursor's, or above it for O, and starts
+// inserting there
+func (a *Application) openLine(above bool) {
+	start, end := lineBounds(a.inputBuffer, a.cursorPos)
+	if above {
+		a.inputBuffer = a.inputBuffer[:start] + "\n" + a.inputBuffer[start:]
+		a.cursorPos = start
+	} else {
+		a.inputBuffer = a.inputBuffer[:end] + "\n" + a.inputBuffer[end:]
+		a.cursorPos = end + 1
+	}
+	a.compose.active = true
+	a.inputMode = InputModeInsert
+}
+
+// moveLine moves the cursor to the same column of the next or previous line
+func (a *Application) moveLine(down bool) {
+	start, end := lineBounds(a.inputBuffer, a.cursorPos)
+	column := a.cursorPos - start
+	if down {
+		if end >= len(a.inputBuffer) {
+			return
+		}
+		start, end = lineBounds(a.inputBuffer, end+1)
+	} else {
+		if start == 0 {
+			return
+		}
+		start, end = lineBounds(a.inputBuffer, start-1)
+	}
+	a.cursorPos = min(start+column, end)
+	if a.inputMode == InputModeNormal && a.cursorPos == end && end > start {
+		a.cursorPos--
+	}
+}
+
+// deleteLine deletes the cursor's line of a multi-line input with its line
+// break, returning it
+func (a *Application) deleteLine() string {
+	start, end := lineBounds(a.inputBuffer, a.cursorPos)
+	line := a.inputBuffer[start:end]
+	if end < len(a.inputBuffer) {
+		end++
+	} else if start > 0 {
+		start--
+	}
+	a.inputBuffer = a.inputBuffer[:start] + a.inputBuffer[end:]
+	a.cursorPos, _ = lineBounds(a.inputBuffer, start)
+	return line
+}
+
+// submitInput sends the input as a prompt
+func (a *Application) submitInput() tea.Cmd {
+	prompt := strings.TrimSpace(a.inputBuffer)
+	if prompt == "" {
+		return nil
+	}
+	a.inputBuffer = ""
+	a.inputActive = false
+	a.inputMode = InputModeNormal
+	a.cursorPos = 0
+	a.compose = composeView{}
+	a.isLoading = true
+	resume := a.sessionManager.CurrentSessionID != ""
+
+	return func() tea.Msg {
+		return PromptInputMsg{
+			Prompt: prompt,
+			Resume: resume,
+		}
+	}
+}
+
+// composeRows is the most rows of input shown while composing
+func (a *Application) composeRows() int {
+	return max(3, min(12, a.height/3))
+}
+
+// inputPanelExtraRows is how many rows the input panel takes beyond its
+// usual one; the conversation panel gives them up
+func (a *Application) inputPanelExtraRows() int {
+	if !a.composing() || a.isLoading {
+		return 0
+	}
+	rows, _ := a.composeLines(a.inputContentWidth(), a.inputCursor())
+	return min(len(rows), a.composeRows())
+}
+
+// inputContentWidth is the inner width of the input panel
+func (a *Application) inputContentWidth() int {
+	return max(1, a.width-4)
+}
+
+// layout returns the panel layout for the terminal, leaving room for a
+// multi-line input and sizing the side panel as set
+func (a *Application) layout() *components.LayoutManager {
+	lm := components.NewLayoutManager(a.width, a.height-a.inputPanelExtraRows()-a.stderrPaneHeight())
+	lm.SetSidebarWidth(a.sidePanelWidth())
+	return lm
+}
+
+// composeLines wraps the input, with the cursor or selection drawn in,
+// into rows of the panel width, returning the row holding the cursor
+func (a *Application) composeLines(width int, cursor string) ([]string, int) {
+	visual := a.inputMode == InputModeVisual || a.inputMode == InputModeVisualLine
+	selStart, selEnd := 0, 0
+	if visual {
+		selStart, selEnd = a.visualBounds()
+	}
+
+	var rows []string
+	cursorRow := 0
+	for start := 0; start <= len(a.inputBuffer); {
+		_, end := lineBounds(a.inputBuffer, start)
+		line := a.inputBuffer[start:end]
+		hasCursor := a.cursorPos >= start && a.cursorPos <= end
+		switch {
+		case visual:
+			s, e := max(start, selStart), min(end, selEnd)
+			if s < e {
+				line = a.inputBuffer[start:s] + a.styles.Selection.Render(a.inputBuffer[s:e]) + a.inputBuffer[e:end]
+			}
+		case hasCursor:
+			line = a.inputBuffer[start:a.cursorPos] + cursor + a.inputBuffer[a.cursorPos:end]
+		}
+
+		wrapped := strings.Split(ansi.Hardwrap(line, width, true), "\n")
+		if hasCursor {
+			col := ansi.StringWidth(a.inputBuffer[start:a.cursorPos])
+			cursorRow = len(rows) + min(len(wrapped)-1, col/width)
+		}
+		rows = append(rows, wrapped...)
+		start = end + 1
+	}
+	return rows, cursorRow
+}
+
+// inputCursor is the cursor drawn in the input for its mode
+func (a *Application) inputCursor() string {
+	if a.inputMode == InputModeInsert {
+		return "│" // Line cursor for insert mode
+	}
+	return "█" // Block cursor for normal mode
+}
+
+// renderComposeInput renders the rows of a multi-line input around the
+// cursor, with a status line
+func (a *Application) renderComposeInput(width int, cursor string) string {
+	rows, cursorRow := a.composeLines(width, cursor)
+	visible := min(len(rows), a.composeRows())
+	view := &a.compose
+	if cursorRow < view.top {
+		view.top = cursorRow
+	} else if cursorRow >= view.top+visible {
+		view.top = cursorRow - visible + 1
+	}
+	view.top = max(0, min(view.top, len(rows)-visible))
+
+	lines := make([]string, 0, visible+1)
+	for _, row := range rows[view.top : view.top+visible] {
+		lines = append(lines, a.styles.Highlight.Render(row))
+	}
+
+	status := fmt.Sprintf("%s Compose: line %d/%d", a.vimModeLabel(),
+		strings.Count(a.inputBuffer[:min(a.cursorPos, len(a.inputBuffer))], "\n")+1,
+		strings.Count(a.inputBuffer, "\n")+1)
+	if hidden := len(rows) - visible; hidden > 0 {
+		status += fmt.Sprintf(" (%d above, %d below)", view.top, len(rows)-visible-view.top)
+	}
+	status += " | Ctrl+S: Send | Enter/Ctrl+J: New line | Esc: Normal mode"
+	lines = append(lines, a.styles.Status.Render(ansi.Truncate(status, width, "…")))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/config"
+)
+
+// configEditor holds the state of the config editor (Ctrl+S or /config)
+type configEditor struct {
+	doc       *config.Document
+	selected  int
+	collapsed map[string]bool // section paths
+	editing   bool
+	input     string
+	status    string
+	problems  []string // validation errors of the edited settings
+	dirty     bool
+	// all shows the whole settings tree rather than the settings form
+	all bool
+	// saved is the file's settings as last read or saved, restored to the
+	// running application when edits are discarded
+	saved config.Config
+}
+
+// openConfigEditor loads config.toml and switches to the editor
+func (a *Application) openConfigEditor() (tea.Model, tea.Cmd) {
+	doc, err := config.OpenDocument()
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "config"}
+		}
+	}
+	collapsed := a.configEditor.collapsed
+	if collapsed == nil {
+		collapsed = make(map[string]bool)
+	}
+	a.configEditor = configEditor{doc: doc, collapsed: collapsed, all: a.configEditor.all, saved: doc.Config}
+	a.configEditor.validate()
+	if fields := a.configEditor.visibleFields(); a.configEditor.selected >= len(fields) {
+		a.configEditor.selected = max(0, len(fields)-1)
+	}
+	a.state = StateSettings
+	return a, nil
+}
+
+// visibleFields returns the fields of the settings form, or of the whole
+// tree those not hidden in a collapsed section
+func (e *configEditor) visibleFields() []config.Field {
+	if !e.all {
+		return e.formFields()
+	}
+	var fields []config.Field
+	for _, field := range e.doc.Fields() {
+		hidden := false
+		for section := range e.collapsed {
+			if e.collapsed[section] && strings.HasPrefix(field.Path, section+".") {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// validate refreshes the validation errors shown under the tree
+func (e *configEditor) validate() {
+	e.problems = nil
+	if err := config.Validate(e.doc.Config); err != nil {
+		e.problems = strings.Split(err.Error(), "\n")
+	}
+}
+
+// set applies a value to the field at path, reporting parse errors inline
+func (e *configEditor) set(path, value string) bool {
+	if err := e.doc.Set(path, value); err != nil {
+		e.status = err.Error()
+		return false
+	}
+	e.dirty = true
+	e.status = ""
+	e.validate()
+	return true
+}
+
+// handleConfigCommand runs "/config", opening the config editor
+func (a *Application) handleConfigCommand() (tea.Model, tea.Cmd) {
+	return a.openConfigEditor()
+}
+
+// handleConfigKeyPress handles navigation and editing inside the config
+// editor
+func (a *Application) handleConfigKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := &a.configEditor
+	if e.doc == nil {
+		a.state = StateMain
+		return a, nil
+	}
+	fields := e.visibleFields()
+	if e.editing {
+		return a.handleConfigInput(msg, fields[e.selected])
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if e.selected > 0 {
+			e.selected--
+		}
+	case "down", "j":
+		if e.selected < len(fields)-1 {
+			e.selected++
+		}
+	case "home", "g":
+		e.selected = 0
+	case "end", "G":
+		e.selected = max(0, len(fields)-1)
+	case " ", "enter":
+		if e.selected >= len(fields) {
+			break
+		}
+		field := fields[e.selected]
+		switch {
+		case field.Section:
+			e.collapsed[field.Path] = !e.collapsed[field.Path]
+		case field.Kind == config.KindBool:
+			a.setConfigField(field.Path, fmt.Sprint(field.Value != "true"))
+		case field.Editable():
+			e.editing = true
+			e.input = field.Value
+			e.status = ""
+		default:
+			e.status = fmt.Sprintf("%s can only be edited in %s", field.Path, shortenHome(e.doc.Path))
+		}
+	case "a":
+		e.all = !e.all
+		e.selected = 0
+	case "s":
+		if err := e.doc.Save(); err != nil {
+			e.status = fmt.Sprintf("Not saved: %v", err)
+			return a, nil
+		}
+		e.dirty = false
+		e.saved = e.doc.Config
+		e.status = fmt.Sprintf("Saved %s (previous version in config.toml.bak)", shortenHome(e.doc.Path))
+		a.notify(toastSuccess, "Settings saved; those in the form apply at once, most others on restart")
+	case "r":
+		return a.confirmConfigDiscard("Reload config.toml", a.openConfigEditor)
+	case "esc", "q", "ctrl+m":
+		return a.confirmConfigDiscard("Leave the config editor", func() (tea.Model, tea.Cmd) {
+			a.state = StateMain
+			return a, nil
+		})
+	case "ctrl+c":
+		return a, tea.Quit
+	}
+	return a, nil
+}
+
+// handleConfigInput handles keys while a value is being edited
+func (a *Application) handleConfigInput(msg tea.KeyMsg, field config.Field) (tea.Model, tea.Cmd) {
+	e := &a.configEditor
+	switch msg.Type {
+	case tea.KeyEnter:
+		if a.setConfigField(field.Path, e.input) {
+			e.editing = false
+		}
+	case tea.KeyEsc:
+		e.editing = false
+		e.status = ""
+	case tea.KeyCtrlC:
+		return a, tea.Quit
+	case tea.KeyBackspace:
+		if runes := []rune(e.input); len(runes) > 0 {
+			e.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeyCtrlU:
+		e.input = ""
+	case tea.KeyRunes, tea.KeySpace:
+		e.input += string(msg.Runes)
+	}
+	return a, nil
+}
+
+// confirmConfigDiscard runs then, asking first when there are unsaved edits
+func (a *Application) confirmConfigDiscard(title string, then func() (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	if !a.configEditor.dirty {
+		return then()
+	}
+	a.openDialog(newConfirmDialog(title, []string{"Discard the unsaved changes?"}, false,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.choice != "y" {
+				return a, nil
+			}
+			a.applyLiveSettings(a.configEditor.doc.Config, a.configEditor.saved)
+			return then()
+		}))
+	return a, nil
+}
+
+// configPageSize is the number of fields shown at once
+func (a *Application) configPageSize() int {
+	return max(5, a.height-12-len(a.configEditor.problems))
+}
+
+// renderSettingsView renders the config editor: the settings form with a
+// preview of its effect, or the whole settings tree, with changed values
+// marked, the value being edited and validation errors
+func (a *Application) renderSettingsView() string {
+	e := &a.configEditor
+	if e.doc == nil {
+		return a.styles.App.Render(a.styles.Header.Render("CustomClaude TUI - Settings"))
+	}
+
+	title := "CustomClaude TUI - Settings (" + shortenHome(e.doc.Path)
+	if e.dirty {
+		title += ", unsaved"
+	}
+	content := []string{a.styles.Header.Render(title + ")"), ""}
+
+	fields := e.visibleFields()
+	page := a.configPageSize()
+	first := max(0, min(e.selected-page/2, len(fields)-page))
+	last := min(len(fields), first+page)
+	if first > 0 {
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("  ... %d more", first)))
+	}
+
+	for i := first; i < last; i++ {
+		field := fields[i]
+		indent := strings.Repeat("  ", field.Depth)
+		var line string
+		switch {
+		case field.Section && e.collapsed[field.Path]:
+			line = indent + "▸ [" + field.Path + "]"
+		case field.Section:
+			line = indent + "▾ [" + field.Path + "]"
+		case i == e.selected && e.editing:
+			line = fmt.Sprintf("%s%-24s = %s█", indent, field.Key, e.input)
+		default:
+			mark := " "
+			if field.Changed {
+				mark = "*"
+			}
+			line = fmt.Sprintf("%s%-24s = %s %s", indent, field.Key, truncateString(field.Value, 60), mark)
+		}
+
+		switch {
+		case i == e.selected:
+			content = append(content, a.styles.Highlight.Render("> "+line))
+		case field.Section:
+			content = append(content, "  "+a.styles.Header.Render(line))
+		case !field.Editable():
+			content = append(content, a.styles.Status.Render("  "+line))
+		default:
+			content = append(content, "  "+line)
+		}
+	}
+	if last < len(fields) {
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("  ... %d more", len(fields)-last)))
+	}
+
+	if !e.all {
+		content = append(content, "")
+		content = append(content, a.renderSettingsPreview()...)
+	}
+
+	content = append(content, "")
+	if e.selected < len(fields) && !fields[e.selected].Section {
+		field := fields[e.selected]
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("%s (%s)", field.Path, field.Kind)))
+	}
+	for _, problem := range e.problems {
+		content = append(content, a.styles.Error.Render("✗ "+problem))
+	}
+	if e.status != "" {
+		content = append(content, a.styles.Status.Render(e.status))
+	}
+
+	if e.editing {
+		content = append(content, "Enter: Apply | Esc: Cancel | Ctrl+U: Clear | Lists and pairs are comma-separated")
+	} else {
+		other := "All settings"
+		if e.all {
+			other = "Settings form"
+		}
+		content = append(content,
+			"↑/↓ or j/k: Select | Enter: Edit/toggle/fold | a: "+other+" | s: Save | r: Reload | Esc: Back",
+			a.styles.Footer.Render("* differs from the default. Saving drops comments from config.toml; the form applies at once, most other settings on restart."),
+		)
+	}
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
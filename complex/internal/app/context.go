@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// contextBarWidth is the width of each bar in the context panel
+const contextBarWidth = 30
+
+// openContextPanel switches to the context composition panel
+func (a *Application) openContextPanel() (tea.Model, tea.Cmd) {
+	a.state = StateContext
+	return a, nil
+}
+
+// contextBar renders a proportional bar for part of the context window
+func contextBar(tokens, window int) string {
+	return meterBar(tokens, window, contextBarWidth)
+}
+
+// meterBar renders a bar width cells wide, filled in proportion to
+// tokens out of window
+func meterBar(tokens, window, width int) string {
+	filled := 0
+	if window > 0 {
+		filled = min(width, tokens*width/window)
+	}
+	if tokens > 0 && filled == 0 {
+		filled = 1
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// renderContextView renders the approximate context window composition
+func (a *Application) renderContextView() string {
+	comp := a.sessionManager.ContextComposition()
+	total := comp.Total()
+
+	content := []string{
+		a.styles.Header.Render("CustomClaude TUI - Context Composition"),
+		"",
+	}
+
+	rows := []struct {
+		label  string
+		tokens int
+	}{
+		{"System prompt & tools", comp.SystemPrompt},
+		{"CLAUDE.md", comp.Memory},
+		{"Conversation turns", comp.Conversation},
+		{"Tool results", comp.ToolResults},
+	}
+	for _, row := range rows {
+		percent := 0.0
+		if total > 0 {
+			percent = float64(row.tokens) * 100 / float64(total)
+		}
+		content = append(content, fmt.Sprintf("  %-22s %s %7d tokens  %5.1f%%",
+			row.label, contextBar(row.tokens, comp.Window), row.tokens, percent))
+	}
+
+	content = append(content,
+		"",
+		a.styles.Highlight.Render(fmt.Sprintf("  ~%d of %d tokens (%.0f%% of the context window)",
+			total, comp.Window, float64(total)*100/float64(comp.Window))),
+	)
+
+	if len(comp.MemoryFiles) > 0 {
+		content = append(content, "", "CLAUDE.md files:")
+		for _, path := range comp.MemoryFiles {
+			content = append(content, "  "+path)
+		}
+	}
+
+	basis := "Estimates use ~4 characters per token; the system prompt is a baseline guess until a turn reports its prompt size."
+	if comp.Measured {
+		basis = "Estimates use ~4 characters per token; the system prompt is what remains of the prompt size reported by the last turn."
+	}
+	content = append(content,
+		"",
+		a.styles.Footer.Render(basis),
+		a.styles.Footer.Render("Start a new conversation (Ctrl+N) to clear turns and tool results."),
+		"",
+		"Press Ctrl+M or Esc to return to main view",
+	)
+
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
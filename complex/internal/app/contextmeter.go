@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// contextMeterWidth is the width of the side panel's context bar
+const contextMeterWidth = 10
+
+// contextMeter caches the context window usage, which reads the memory and
+// system prompt files, until the conversation moves on
+type contextMeter struct {
+	conversation string
+	turns        int
+	model        string
+	tokens       int
+	window       int
+}
+
+// contextUsage returns the estimated tokens in the context window and the
+// size of the window
+func (a *Application) contextUsage() (int, int) {
+	m := &a.contextMeter
+	conversation := a.sessionManager.ConversationID()
+	if m.window == 0 || m.conversation != conversation ||
+		m.turns != a.sessionStats.CumulativeTurns || m.model != a.sessionManager.Model {
+		comp := a.sessionManager.ContextComposition()
+		*m = contextMeter{
+			conversation: conversation,
+			turns:        a.sessionStats.CumulativeTurns,
+			model:        a.sessionManager.Model,
+			tokens:       comp.Total(),
+			window:       comp.Window,
+		}
+	}
+	return m.tokens, m.window
+}
+
+// contextMeterLine renders the context window usage for the side panel,
+// in red once the conversation nears the limit
+func (a *Application) contextMeterLine() string {
+	tokens, window := a.contextUsage()
+	line := fmt.Sprintf("Context: %s %.0f%% of %s",
+		meterBar(tokens, window, contextMeterWidth), float64(tokens)*100/float64(window), formatTokens(window))
+	if float64(tokens) >= claude.ContextWarnFraction*float64(window) {
+		return a.styles.Error.Render(line)
+	}
+	return a.styles.Status.Render(line)
+}
+
+// nearContextLimit reports whether sending prompt would fill the context
+// window past the warning level
+func (a *Application) nearContextLimit(prompt string) bool {
+	tokens, window := a.contextUsage()
+	tokens += claude.EstimatePromptTokens(prompt)
+	return float64(tokens) >= claude.ContextWarnFraction*float64(window)
+}
+
+// offerContextChoice holds a prompt that would take the conversation close
+// to its context window, where claude starts forgetting or fails, and asks
+// how to send it
+func (a *Application) offerContextChoice(msg PromptInputMsg) (tea.Model, tea.Cmd) {
+	msg.ContextChecked = true
+	tokens, window := a.contextUsage()
+	promptTokens := claude.EstimatePromptTokens(msg.Prompt)
+	options := []dialogOption{{"s", "Send anyway"}}
+	if msg.Resume {
+		options = append(options, dialogOption{"c", "Compact first"})
+	}
+	options = append(options, dialogOption{"n", "New conversation"}, dialogOption{"e", "Edit"})
+
+	a.openDialog(newChoiceDialog(
+		"Context window nearly full",
+		[]string{fmt.Sprintf("The conversation holds ~%s tokens and this prompt ~%s, %.0f%% of the %s-token window.",
+			formatTokens(tokens), formatTokens(promptTokens),
+			float64(tokens+promptTokens)*100/float64(window), formatTokens(window))},
+		options,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			switch result.choice {
+			case "s":
+				return a.handlePromptInput(msg)
+			case "c":
+				msg.Turns = []string{claude.CompactCommand, msg.Prompt}
+				return a.handlePromptInput(msg)
+			case "n":
+				a.sessionManager.StartNewConversation()
+				msg.Resume = false
+				return a.handlePromptInput(msg)
+			}
+			a.restorePrompt(msg.Prompt)
+			return a, nil
+		},
+	))
+	return a, nil
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// uiSnapshotLimit bounds the UI state snapshots kept for debug bundles
+const uiSnapshotLimit = 50
+
+// uiSnapshot is the state of the UI at a point in time, for debug bundles
+type uiSnapshot struct {
+	Taken          time.Time           `json:"taken"`
+	Reason         string              `json:"reason"`
+	Tab            int                 `json:"tab"`
+	State          int                 `json:"state"`
+	Width          int                 `json:"width"`
+	Height         int                 `json:"height"`
+	ScrollPosition int                 `json:"scroll_position"`
+	InputActive    bool                `json:"input_active"`
+	InputMode      int                 `json:"input_mode"`
+	InputBuffer    string              `json:"input_buffer"`
+	IsLoading      bool                `json:"is_loading"`
+	StatusMessage  string              `json:"status_message"`
+	Messages       int                 `json:"messages"`
+	Streaming      []string            `json:"streaming,omitempty"`
+	Errors         []string            `json:"errors,omitempty"`
+	SearchQuery    string              `json:"search_query,omitempty"`
+	SelectedTool   string              `json:"selected_tool,omitempty"`
+	Stats          claude.SessionStats `json:"stats"`
+}
+
+// snapshotUI captures the state of the active tab
+func (a *Application) snapshotUI(reason string) uiSnapshot {
+	snapshot := uiSnapshot{
+		Taken:          time.Now(),
+		Reason:         reason,
+		Tab:            a.tabs[a.activeTab].id,
+		State:          int(a.state),
+		Width:          a.width,
+		Height:         a.height,
+		ScrollPosition: a.viewport.YOffset,
+		InputActive:    a.inputActive,
+		InputMode:      int(a.inputMode),
+		InputBuffer:    a.inputBuffer,
+		IsLoading:      a.isLoading,
+		StatusMessage:  a.statusMessage,
+		Messages:       len(a.messages),
+		SearchQuery:    a.search.query,
+		SelectedTool:   a.selectedTool,
+		Stats:          a.sessionStats,
+	}
+	for id := range a.streaming {
+		snapshot.Streaming = append(snapshot.Streaming, id)
+	}
+	for _, err := range a.errors {
+		snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("[%s] %v", err.Context, err.Error))
+	}
+	return snapshot
+}
+
+// noteUISnapshot keeps a snapshot of the UI for later debug bundles
+func (a *Application) noteUISnapshot(reason string) {
+	a.uiSnapshots = append(a.uiSnapshots, a.snapshotUI(reason))
+	if len(a.uiSnapshots) > uiSnapshotLimit {
+		a.uiSnapshots = a.uiSnapshots[len(a.uiSnapshots)-uiSnapshotLimit:]
+	}
+}
+
+// handleDebugBundleCommand runs "/debug-bundle [turn]": it zips the recorded
+// turns of the active conversation, or one of them, with UI snapshots
+func (a *Application) handleDebugBundleCommand(args []string) (tea.Model, tea.Cmd) {
+	turn := 0
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return a, func() tea.Msg {
+				return StatusMsg{Status: "debug", Message: fmt.Sprintf("Usage: /debug-bundle [turn], recorded turns: %v", a.sessionManager.DebugTurns())}
+			}
+		}
+		turn = n
+	}
+
+	tabID := a.tabs[a.activeTab].id
+	var snapshots []uiSnapshot
+	for _, snapshot := range a.uiSnapshots {
+		if snapshot.Tab == tabID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	extra := make(map[string][]byte)
+	for name, value := range map[string]interface{}{
+		"ui/snapshots.json": snapshots,
+		"ui/current.json":   a.snapshotUI("export"),
+	} {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return a, func() tea.Msg {
+				return ErrorMsg{Error: fmt.Errorf("failed to encode UI snapshot: %w", err), Context: "debug"}
+			}
+		}
+		extra[name] = data
+	}
+
+	path, err := a.sessionManager.WriteDebugBundle(a.config.DebugBundlePath, turn, extra)
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "debug"}
+		}
+	}
+	a.notify(toastSuccess, fmt.Sprintf("Debug bundle written to %s (contains prompts and tool output)", path))
+	return a, nil
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxDialogWidth is the widest a dialog box grows on large terminals
+const maxDialogWidth = 80
+
+// dialogOption is one answer of a choice dialog, picked with its key or by
+// moving to it and pressing Enter
+type dialogOption struct {
+	key   string
+	label string
+}
+
+// dialogResult is how a dialog was answered: the key of the chosen option,
+// the entered text, or neither when it was cancelled with Esc
+type dialogResult struct {
+	choice    string
+	input     string
+	cancelled bool
+}
+
+// dialog is a modal that takes the keyboard until it is answered. Dialogs
+// with options ask for a choice; dialogs without ask for a line of text.
+type dialog struct {
+	title  string
+	body   []string
+	danger bool
+
+	options  []dialogOption
+	selected int
+
+	prompt string
+	input  string
+	// allow filters typed characters and accept gates Enter; either may be nil
+	allow  func(r rune) bool
+	accept func(text string) bool
+	hint   string
+
+	onResult func(result dialogResult) (tea.Model, tea.Cmd)
+}
+
+// newConfirmDialog asks a yes/no question. Enter picks the default answer.
+func newConfirmDialog(title string, body []string, defaultYes bool, onResult func(dialogResult) (tea.Model, tea.Cmd)) *dialog {
+	d := &dialog{
+		title:    title,
+		body:     body,
+		options:  []dialogOption{{"y", "Yes"}, {"n", "No"}},
+		onResult: onResult,
+	}
+	if !defaultYes {
+		d.selected = 1
+	}
+	return d
+}
+
+// newChoiceDialog asks to choose one of several options
+func newChoiceDialog(title string, body []string, options []dialogOption, onResult func(dialogResult) (tea.Model, tea.Cmd)) *dialog {
+	return &dialog{
+		title:    title,
+		body:     body,
+		options:  options,
+		onResult: onResult,
+	}
+}
+
+// newInputDialog asks for a line of text, starting from initial
+func newInputDialog(title string, body []string, prompt, initial string, onResult func(dialogResult) (tea.Model, tea.Cmd)) *dialog {
+	return &dialog{
+		title:    title,
+		body:     body,
+		prompt:   prompt,
+		input:    initial,
+		onResult: onResult,
+	}
+}
+
+// openDialog queues a dialog; dialogs are answered in the order opened
+func (a *Application) openDialog(d *dialog) {
+	a.dialogs = append(a.dialogs, d)
+}
+
+// closeDialog removes the active dialog and hands its result to the owner
+func (a *Application) closeDialog(result dialogResult) (tea.Model, tea.Cmd) {
+	d := a.dialogs[0]
+	a.dialogs = a.dialogs[1:]
+	return d.onResult(result)
+}
+
+// handleDialogKeyPress handles keys while a dialog is showing
+func (a *Application) handleDialogKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := a.dialogs[0]
+	key := msg.String()
+
+	switch key {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc":
+		return a.closeDialog(dialogResult{cancelled: true})
+	}
+
+	if len(d.options) == 0 {
+		switch msg.Type {
+		case tea.KeyEnter:
+			if d.accept != nil && !d.accept(d.input) {
+				return a, nil
+			}
+			return a.closeDialog(dialogResult{input: d.input})
+		case tea.KeyBackspace:
+			if runes := []rune(d.input); len(runes) > 0 {
+				d.input = string(runes[:len(runes)-1])
+			}
+		case tea.KeyCtrlU:
+			d.input = ""
+		case tea.KeyRunes, tea.KeySpace:
+			for _, r := range msg.Runes {
+				if d.allow == nil || d.allow(r) {
+					d.input += string(r)
+				}
+			}
+		}
+		return a, nil
+	}
+
+	for _, option := range d.options {
+		if strings.EqualFold(key, option.key) {
+			return a.closeDialog(dialogResult{choice: option.key})
+		}
+	}
+
+	switch key {
+	case "left", "up", "shift+tab", "h", "k":
+		d.selected = (d.selected + len(d.options) - 1) % len(d.options)
+		return a, nil
+	case "right", "down", "tab", "l", "j":
+		d.selected = (d.selected + 1) % len(d.options)
+		return a, nil
+	case "enter":
+		return a.closeDialog(dialogResult{choice: d.options[d.selected].key})
+	}
+	return a, nil
+}
+
+// renderDialog renders the active dialog centered on the screen, or as plain
+// lines in linear mode
+func (a *Application) renderDialog() string {
+	d := a.dialogs[0]
+
+	title := a.styles.Highlight.Render(d.title)
+	if d.danger {
+		title = a.styles.Error.Render("⚠️  " + d.title)
+	}
+	content := []string{title, ""}
+	content = append(content, d.body...)
+	if len(d.body) > 0 {
+		content = append(content, "")
+	}
+
+	help := "Enter: Submit | Esc: Cancel"
+	if len(d.options) == 0 {
+		content = append(content, fmt.Sprintf("%s%s█", d.prompt, d.input))
+		if d.hint != "" {
+			content = append(content, a.styles.Status.Render(d.hint))
+		}
+	} else {
+		labels := make([]string, len(d.options))
+		for i, option := range d.options {
+			label := fmt.Sprintf("[%s] %s", option.key, option.label)
+			if i == d.selected {
+				label = a.styles.Selection.Render(label)
+			}
+			labels[i] = label
+		}
+		content = append(content, strings.Join(labels, "  "))
+		help = "Key or ←/→ + Enter: Choose | Esc: Cancel"
+	}
+	if len(a.dialogs) > 1 {
+		help += fmt.Sprintf(" | %d more waiting", len(a.dialogs)-1)
+	}
+	content = append(content, "", a.styles.Status.Render(help))
+
+	if a.linear {
+		return strings.Join(content, "\n")
+	}
+	style := a.styles.Dialog.Width(min(maxDialogWidth, max(a.width-4, 20)))
+	if d.danger {
+		style = style.BorderForeground(lipgloss.Color("196"))
+	}
+	box := style.Render(strings.Join(content, "\n"))
+	return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, box)
+}
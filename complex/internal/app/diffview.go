@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"complex/internal/claude"
+	"complex/internal/diff"
+)
+
+// maxDiffPreviewLines caps the diff shown under a collapsed tool message
+const maxDiffPreviewLines = 30
+
+// diffStat is the added/removed line counts of a tool message's diff
+func diffStat(msg claude.ConversationMessage) string {
+	if msg.Diff == "" {
+		return ""
+	}
+	added, removed := diff.Stats(msg.Diff)
+	return fmt.Sprintf("+%d -%d", added, removed)
+}
+
+// renderDiff colors the diff of a file-editing tool message. Collapsed
+// messages show the first lines; expanding shows all of it.
+func (a *Application) renderDiff(msg claude.ConversationMessage, width int) string {
+	lines := strings.Split(strings.TrimRight(msg.Diff, "\n"), "\n")
+	hidden := 0
+	if !a.expandedTools[msg.ToolUseID] && len(lines) > maxDiffPreviewLines {
+		hidden = len(lines) - maxDiffPreviewLines
+		lines = lines[:maxDiffPreviewLines]
+	}
+
+	rendered := make([]string, 0, len(lines)+1)
+	for _, line := range lines {
+		line = truncateString(line, max(10, width-3))
+		style := a.styles.Status
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			style = a.styles.Highlight
+		case strings.HasPrefix(line, "@@"):
+			style = a.styles.DiffHunk
+		case strings.HasPrefix(line, "+"):
+			style = a.styles.DiffAdd
+		case strings.HasPrefix(line, "-"):
+			style = a.styles.DiffRemove
+		}
+		rendered = append(rendered, "   "+style.Render(line))
+	}
+	if hidden > 0 {
+		rendered = append(rendered, "   "+a.styles.Status.Render(fmt.Sprintf("... %d more lines (Tab to select, Enter to expand)", hidden)))
+	}
+	return strings.Join(rendered, "\n")
+}
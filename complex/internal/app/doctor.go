@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+	"complex/internal/config"
+	"customclaude/pkg/claudecli"
+	"customclaude/pkg/procs"
+)
+
+// OrphansFoundMsg reports claude processes left running by crashed runs
+type OrphansFoundMsg struct {
+	Orphans []procs.Orphan
+	Err     error
+}
+
+// checkOrphans looks for orphaned claude processes at startup
+func checkOrphans() tea.Msg {
+	orphans, err := procs.FindOrphans()
+	return OrphansFoundMsg{Orphans: orphans, Err: err}
+}
+
+// handleOrphansFound kills orphaned processes when configured to, and
+// otherwise warns that they are still spending
+func (a *Application) handleOrphansFound(msg OrphansFoundMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		a.log.Warn("failed to check for orphaned processes", "err", msg.Err)
+		return a, nil
+	}
+	if len(msg.Orphans) == 0 {
+		return a, nil
+	}
+	if a.config.KillOrphans {
+		a.killOrphans(msg.Orphans)
+		return a, nil
+	}
+	a.log.Warn("orphaned claude processes found", "count", len(msg.Orphans))
+	a.notify(toastWarning, fmt.Sprintf("%d claude process(es) left by a crashed run are still running; see /doctor",
+		len(msg.Orphans)))
+	return a, nil
+}
+
+// killOrphans terminates orphaned processes and reports the outcome
+func (a *Application) killOrphans(orphans []procs.Orphan) {
+	killed := 0
+	for _, orphan := range orphans {
+		if err := procs.Kill(orphan); err != nil {
+			a.errors = append(a.errors, ErrorMsg{Error: err, Context: "doctor", Timestamp: time.Now()})
+			continue
+		}
+		a.log.Info("killed orphaned claude process", "pid", orphan.PID, "session_id", orphan.SessionID)
+		killed++
+	}
+	if killed > 0 {
+		a.notify(toastSuccess, fmt.Sprintf("Killed %d orphaned claude process(es)", killed))
+	}
+}
+
+// handleDoctorCommand runs "/doctor [kill]", reporting on the setup and on
+// orphaned claude processes, or killing the orphans
+func (a *Application) handleDoctorCommand(args []string) (tea.Model, tea.Cmd) {
+	orphans, orphanErr := procs.FindOrphans()
+
+	if len(args) > 0 {
+		if args[0] != "kill" {
+			return a, func() tea.Msg {
+				return StatusMsg{Status: "doctor", Message: "Usage: /doctor [kill]"}
+			}
+		}
+		if len(orphans) == 0 {
+			a.statusMessage = "[doctor] No orphaned claude processes"
+			return a, nil
+		}
+		a.killOrphans(orphans)
+		return a, nil
+	}
+
+	report := []string{"Doctor report:", ""}
+	if a.config.Backend.Kind == claude.BackendAPI {
+		report = append(report, "• Backend: Messages API (no tools or MCP servers)")
+	} else if path, err := claudecli.FindBinary(); err != nil {
+		report = append(report, "✗ claude CLI: not found in PATH")
+	} else {
+		report = append(report, "✓ claude CLI: "+path)
+	}
+	if path, err := config.Path(); err == nil {
+		report = append(report, "• Config: "+path)
+	}
+	if a.approvalBroker != nil {
+		switch policy, path, err := a.approvalBroker.Policy(); {
+		case err != nil:
+			report = append(report, "✗ Permission policy: "+err.Error())
+		case policy.Len() == 0:
+			report = append(report, fmt.Sprintf("• Permission policy: no rules (%s)", shortenHome(path)))
+		default:
+			report = append(report, fmt.Sprintf("✓ Permission policy: %d rules (%s)", policy.Len(), shortenHome(path)))
+		}
+	}
+
+	switch {
+	case orphanErr != nil:
+		report = append(report, "✗ Orphaned processes: "+orphanErr.Error())
+	case len(orphans) == 0:
+		report = append(report, "✓ Orphaned processes: none")
+	default:
+		report = append(report, fmt.Sprintf("✗ Orphaned processes: %d still running (/doctor kill stops them)", len(orphans)))
+		for _, orphan := range orphans {
+			session := orphan.SessionID
+			if session == "" {
+				session = "new session"
+			}
+			report = append(report, fmt.Sprintf("    PID %d, %s, started %s (%s ago)",
+				orphan.PID, session, orphan.Started.Local().Format("2006-01-02 15:04"),
+				time.Since(orphan.Started).Round(time.Minute)))
+		}
+	}
+
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("doctor_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   strings.Join(report, "\n"),
+		Timestamp: time.Now(),
+	})
+	a.scrollToBottomSafe()
+	return a, nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"customclaude/pkg/platform"
+)
+
+// PromptEditedMsg reports that the prompt editor (Ctrl+G) exited
+type PromptEditedMsg struct {
+	Path string
+	Err  error
+}
+
+// editorCommand runs $VISUAL or $EDITOR, or the platform default, on path
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = platform.DefaultEditor()
+	}
+	// The editor may carry flags, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	return exec.Command(parts[0], append(parts[1:], path)...)
+}
+
+// restoreTerminal turns mouse reporting back on after a program ran in the
+// foreground; bubbletea restores the alt screen but leaves the mouse off
+func (a *Application) restoreTerminal() tea.Cmd {
+	if a.linear {
+		return nil
+	}
+	return tea.EnableMouseCellMotion
+}
+
+// editPromptExternally opens the input in $EDITOR on a temporary file,
+// suspending the TUI; the saved text is sent when the editor exits
+func (a *Application) editPromptExternally() (tea.Model, tea.Cmd) {
+	if a.isLoading {
+		a.statusMessage = "[editor] Wait for the running command to finish"
+		return a, nil
+	}
+
+	file, err := os.CreateTemp("", "cc-custom-prompt-*.md")
+	if err == nil {
+		_, err = file.WriteString(a.inputBuffer)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: fmt.Errorf("failed to create prompt file: %w", err), Context: "editor"}
+		}
+	}
+
+	path := file.Name()
+	return a, tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return PromptEditedMsg{Path: path, Err: err}
+	})
+}
+
+// handlePromptEdited sends the saved prompt. An empty file leaves the input
+// as it was.
+func (a *Application) handlePromptEdited(msg PromptEditedMsg) (tea.Model, tea.Cmd) {
+	data, readErr := os.ReadFile(msg.Path)
+	os.Remove(msg.Path)
+	err := msg.Err
+	if err != nil {
+		err = fmt.Errorf("failed to run editor: %w", err)
+	} else if readErr != nil {
+		err = fmt.Errorf("failed to read prompt file: %w", readErr)
+	}
+	if err != nil {
+		return a, tea.Batch(a.restoreTerminal(), func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "editor"}
+		})
+	}
+
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		a.statusMessage = "[editor] Empty prompt; nothing sent"
+		return a, a.restoreTerminal()
+	}
+	a.inputBuffer = prompt
+	a.cursorPos = 0
+	return a, tea.Batch(a.restoreTerminal(), a.submitInput())
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// handleEnvCommand runs "/env [NAME=value | unset NAME]": it lists or changes
+// the variables added to the environment of this tab's claude process
+func (a *Application) handleEnvCommand(args string) (tea.Model, tea.Cmd) {
+	usage := func() tea.Msg {
+		return StatusMsg{Status: "env", Message: "Usage: /env [NAME=value | unset NAME]"}
+	}
+
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 0:
+		content := "No session environment variables. Set one with /env NAME=value, or in the [env] table of config.toml."
+		if env := a.sessionManager.Env(); len(env) > 0 {
+			content = fmt.Sprintf("Environment of claude and its tools in this tab:\n\n%s", strings.Join(env, "\n"))
+		}
+		a.messages = append(a.messages, claude.ConversationMessage{
+			ID:        fmt.Sprintf("env_%d", time.Now().UnixNano()),
+			Type:      "system",
+			Content:   content,
+			Timestamp: time.Now(),
+		})
+		a.scrollToBottomSafe()
+		return a, nil
+
+	case fields[0] == "unset" && len(fields) == 2:
+		if err := a.sessionManager.SetEnv(fields[1], ""); err != nil {
+			return a, func() tea.Msg { return ErrorMsg{Error: err, Context: "env"} }
+		}
+		a.statusMessage = fmt.Sprintf("[env] Unset %s for the next prompts", fields[1])
+		return a, nil
+
+	case strings.Contains(fields[0], "="):
+		// The value is the rest of the line, spaces included
+		name, value, _ := strings.Cut(args, "=")
+		if value == "" {
+			return a, usage
+		}
+		if err := a.sessionManager.SetEnv(name, value); err != nil {
+			return a, func() tea.Msg { return ErrorMsg{Error: err, Context: "env"} }
+		}
+		a.statusMessage = fmt.Sprintf("[env] Set %s for the next prompts", name)
+		return a, nil
+	}
+	return a, usage
+}
@@ -2,30 +2,34 @@ package app
 
 import (
 	"context"
+	"log/slog"
 	"sync"
 	"time"
 
 	"complex/internal/claude"
+	"complex/internal/logging"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // EventBus manages event distribution throughout the application
 type EventBus struct {
-	subscribers map[claude.EventType][]chan claude.Event
+	subscribers map[claude.EventType][]subscription
 	mutex       sync.RWMutex
 	ctx         context.Context
 	cancel      context.CancelFunc
 	program     *tea.Program
+	log         *slog.Logger
 }
 
 // NewEventBus creates a new event bus
 func NewEventBus(ctx context.Context) *EventBus {
 	busCtx, cancel := context.WithCancel(ctx)
 	return &EventBus{
-		subscribers: make(map[claude.EventType][]chan claude.Event),
+		subscribers: make(map[claude.EventType][]subscription),
 		ctx:         busCtx,
 		cancel:      cancel,
+		log:         logging.For("eventbus"),
 	}
 }
 
@@ -34,35 +38,59 @@ func (eb *EventBus) SetProgram(program *tea.Program) {
 	eb.program = program
 }
 
-// Subscribe subscribes to specific event types
+// subscription is a subscriber's channel. Events for a lossless one wait
+// for room in its buffer; the others are dropped when it is full.
+type subscription struct {
+	ch       chan claude.Event
+	lossless bool
+}
+
+// Subscribe subscribes to specific event types. Events are dropped while the
+// buffer is full, so it suits events superseded by the next one.
 func (eb *EventBus) Subscribe(eventType claude.EventType, bufferSize int) <-chan claude.Event {
+	return eb.subscribe(eventType, bufferSize, false)
+}
+
+// SubscribeLossless subscribes to events that must all be delivered: while
+// the buffer is full, the sender waits
+func (eb *EventBus) SubscribeLossless(eventType claude.EventType, bufferSize int) <-chan claude.Event {
+	return eb.subscribe(eventType, bufferSize, true)
+}
+
+func (eb *EventBus) subscribe(eventType claude.EventType, bufferSize int, lossless bool) <-chan claude.Event {
 	eb.mutex.Lock()
 	defer eb.mutex.Unlock()
 
 	eventCh := make(chan claude.Event, bufferSize)
-	eb.subscribers[eventType] = append(eb.subscribers[eventType], eventCh)
+	eb.subscribers[eventType] = append(eb.subscribers[eventType], subscription{ch: eventCh, lossless: lossless})
 
 	return eventCh
 }
 
 // HandleEvent implements claude.EventHandler interface
 func (eb *EventBus) HandleEvent(event claude.Event) {
+	// The read lock is held while sending, so Shutdown cannot close a
+	// channel a lossless send is waiting on; it cancels the context first
 	eb.mutex.RLock()
-	subscribers, exists := eb.subscribers[event.Type]
-	eb.mutex.RUnlock()
-
-	if !exists {
-		return
-	}
+	defer eb.mutex.RUnlock()
 
 	// Send event to all subscribers of this type
-	for _, subscriber := range subscribers {
+	for _, subscriber := range eb.subscribers[event.Type] {
+		if subscriber.lossless {
+			select {
+			case subscriber.ch <- event:
+			case <-eb.ctx.Done():
+				return
+			}
+			continue
+		}
 		select {
-		case subscriber <- event:
+		case subscriber.ch <- event:
 		case <-eb.ctx.Done():
 			return
 		default:
 			// Non-blocking send - drop event if channel is full
+			eb.log.Warn("dropped event, subscriber buffer full", "type", event.Type)
 		}
 	}
 
@@ -74,6 +102,7 @@ func (eb *EventBus) HandleEvent(event claude.Event) {
 
 // Shutdown gracefully shuts down the event bus
 func (eb *EventBus) Shutdown() {
+	eb.log.Debug("shutting down")
 	eb.cancel()
 
 	eb.mutex.Lock()
@@ -81,12 +110,12 @@ func (eb *EventBus) Shutdown() {
 
 	// Close all subscriber channels
 	for _, subscribers := range eb.subscribers {
-		for _, ch := range subscribers {
-			close(ch)
+		for _, subscriber := range subscribers {
+			close(subscriber.ch)
 		}
 	}
 
-	eb.subscribers = make(map[claude.EventType][]chan claude.Event)
+	eb.subscribers = make(map[claude.EventType][]subscription)
 }
 
 // EventMsg wraps claude.Event for bubbletea
@@ -108,8 +137,10 @@ type MessageStreamMsg struct {
 
 // ToolActivityMsg represents tool execution activity
 type ToolActivityMsg struct {
-	Activity string
-	Status   string
+	Activity  string
+	Status    string
+	ToolUseID string
+	Output    string
 }
 
 // ErrorMsg represents error events
@@ -134,6 +165,16 @@ type StatusMsg struct {
 type PromptInputMsg struct {
 	Prompt string
 	Resume bool
+	// Turns, when set, are sent in order instead of Prompt
+	Turns []string
+	// StaleChecked is set once the stale session prompt was answered
+	StaleChecked bool
+	// BudgetConfirmed is set once sending past the budget was confirmed
+	BudgetConfirmed bool
+	// ContextChecked is set once a prompt near the context limit was confirmed
+	ContextChecked bool
+	// Model runs just this prompt on another model when set
+	Model string
 }
 
 // ResizeMsg represents terminal resize events
@@ -154,6 +195,43 @@ type CommandMsg struct {
 	Args    []string
 }
 
+// TurnCompleteMsg represents the end of a Claude turn
+type TurnCompleteMsg struct {
+	Turn claude.TurnResult
+}
+
+// CommandCancelledMsg represents a command that ended without a result:
+// cancelled by the user, timed out or abandoned after failed retries
+type CommandCancelledMsg struct {
+	Interruption claude.TurnInterruption
+	Timestamp    time.Time
+}
+
+// CommandFinishedMsg is sent when a claude invocation returns, successfully
+// or not
+type CommandFinishedMsg struct {
+	Err     error
+	Prompt  string
+	Started time.Time
+}
+
+// RetryMsg is sent when a failed claude invocation is about to be retried
+type RetryMsg struct {
+	Retry     claude.RetryInfo
+	Timestamp time.Time
+}
+
+// BudgetAlertMsg is sent when spending crosses a budget warning threshold
+type BudgetAlertMsg struct {
+	Alert     claude.BudgetAlert
+	Timestamp time.Time
+}
+
+// RawLineMsg carries a stream line as read from the claude process
+type RawLineMsg struct {
+	Line claude.RawLine
+}
+
 // QuitMsg represents quit application request
 type QuitMsg struct{}
 
@@ -161,6 +239,7 @@ type QuitMsg struct{}
 type EventProcessor struct {
 	eventBus *EventBus
 	ctx      context.Context
+	tabID    int
 }
 
 // NewEventProcessor creates a new event processor
@@ -176,17 +255,33 @@ func (ep *EventProcessor) ProcessEvents(program *tea.Program) {
 	// Subscribe to all event types
 	sessionEvents := ep.eventBus.Subscribe(claude.EventSessionInit, 10)
 	sessionUpdates := ep.eventBus.Subscribe(claude.EventSessionUpdate, 10)
-	messageEvents := ep.eventBus.Subscribe(claude.EventMessageReceived, 50)
+	// Completed messages are never dropped; streamed deltas, each holding
+	// the whole text so far, may be when the UI falls behind
+	messageEvents := ep.eventBus.SubscribeLossless(claude.EventMessageReceived, 200)
+	deltaEvents := ep.eventBus.Subscribe(claude.EventMessageDelta, 200)
 	toolEvents := ep.eventBus.Subscribe(claude.EventToolActivity, 20)
 	errorEvents := ep.eventBus.Subscribe(claude.EventError, 20)
 	statsEvents := ep.eventBus.Subscribe(claude.EventStatsUpdate, 10)
+	turnEvents := ep.eventBus.Subscribe(claude.EventTurnComplete, 10)
+	cancelEvents := ep.eventBus.Subscribe(claude.EventCommandCancelled, 10)
+	retryEvents := ep.eventBus.Subscribe(claude.EventRetry, 10)
+	budgetEvents := ep.eventBus.Subscribe(claude.EventBudgetAlert, 10)
+	rawEvents := ep.eventBus.Subscribe(claude.EventRawLine, 500)
+	stderrEvents := ep.eventBus.Subscribe(claude.EventStderr, 100)
 
 	go ep.processEventStream(sessionEvents, program, ep.handleSessionEvent)
 	go ep.processEventStream(sessionUpdates, program, ep.handleSessionUpdate)
 	go ep.processEventStream(messageEvents, program, ep.handleMessageEvent)
+	go ep.processEventStream(deltaEvents, program, ep.handleMessageEvent)
 	go ep.processEventStream(toolEvents, program, ep.handleToolEvent)
 	go ep.processEventStream(errorEvents, program, ep.handleErrorEvent)
 	go ep.processEventStream(statsEvents, program, ep.handleStatsEvent)
+	go ep.processEventStream(turnEvents, program, ep.handleTurnEvent)
+	go ep.processEventStream(cancelEvents, program, ep.handleCancelEvent)
+	go ep.processEventStream(retryEvents, program, ep.handleRetryEvent)
+	go ep.processEventStream(budgetEvents, program, ep.handleBudgetEvent)
+	go ep.processEventStream(rawEvents, program, ep.handleRawLineEvent)
+	go ep.processEventStream(stderrEvents, program, ep.handleStderrEvent)
 }
 
 // processEventStream processes a stream of events
@@ -198,7 +293,7 @@ func (ep *EventProcessor) processEventStream(eventCh <-chan claude.Event, progra
 				return
 			}
 			if msg := handler(event); msg != nil {
-				program.Send(msg)
+				program.Send(TabMsg{TabID: ep.tabID, Msg: msg})
 			}
 		case <-ep.ctx.Done():
 			return
@@ -249,6 +344,11 @@ func (ep *EventProcessor) handleMessageEvent(event claude.Event) tea.Msg {
 			Message:   data,
 			IsPartial: false,
 		}
+	case claude.PartialMessage:
+		return MessageStreamMsg{
+			Message:   data.Message,
+			IsPartial: true,
+		}
 	case claude.Message:
 		return StatusMsg{
 			Status:  "raw_message",
@@ -259,9 +359,17 @@ func (ep *EventProcessor) handleMessageEvent(event claude.Event) tea.Msg {
 }
 
 func (ep *EventProcessor) handleToolEvent(event claude.Event) tea.Msg {
-	if activity, ok := event.Data.(string); ok {
+	switch data := event.Data.(type) {
+	case claude.ToolActivity:
+		return ToolActivityMsg{
+			Activity:  data.ToolName,
+			Status:    data.Status,
+			ToolUseID: data.ToolUseID,
+			Output:    data.Output,
+		}
+	case string:
 		return ToolActivityMsg{
-			Activity: activity,
+			Activity: data,
 			Status:   "active",
 		}
 	}
@@ -287,3 +395,56 @@ func (ep *EventProcessor) handleStatsEvent(event claude.Event) tea.Msg {
 	}
 	return nil
 }
+
+func (ep *EventProcessor) handleTurnEvent(event claude.Event) tea.Msg {
+	if turn, ok := event.Data.(claude.TurnResult); ok {
+		return TurnCompleteMsg{
+			Turn: turn,
+		}
+	}
+	return nil
+}
+
+func (ep *EventProcessor) handleCancelEvent(event claude.Event) tea.Msg {
+	if interruption, ok := event.Data.(claude.TurnInterruption); ok {
+		return CommandCancelledMsg{
+			Interruption: interruption,
+			Timestamp:    event.Timestamp,
+		}
+	}
+	return nil
+}
+
+func (ep *EventProcessor) handleRetryEvent(event claude.Event) tea.Msg {
+	if retry, ok := event.Data.(claude.RetryInfo); ok {
+		return RetryMsg{
+			Retry:     retry,
+			Timestamp: event.Timestamp,
+		}
+	}
+	return nil
+}
+
+func (ep *EventProcessor) handleBudgetEvent(event claude.Event) tea.Msg {
+	if alert, ok := event.Data.(claude.BudgetAlert); ok {
+		return BudgetAlertMsg{
+			Alert:     alert,
+			Timestamp: event.Timestamp,
+		}
+	}
+	return nil
+}
+
+func (ep *EventProcessor) handleRawLineEvent(event claude.Event) tea.Msg {
+	if line, ok := event.Data.(claude.RawLine); ok {
+		return RawLineMsg{Line: line}
+	}
+	return nil
+}
+
+func (ep *EventProcessor) handleStderrEvent(event claude.Event) tea.Msg {
+	if line, ok := event.Data.(claude.StderrLine); ok {
+		return StderrMsg{Line: line}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"complex/internal/claude"
+)
+
+func TestEventBusKeepsCompletedMessagesUnderDeltaFlood(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := NewEventBus(ctx)
+	// Tiny buffers, read only after the flood, as when the UI falls behind
+	deltas := bus.Subscribe(claude.EventMessageDelta, 4)
+	messages := bus.SubscribeLossless(claude.EventMessageReceived, 1)
+
+	const id = "msg_1_0"
+	final := strings.Repeat("x", 2000)
+	completed := []claude.ConversationMessage{
+		{ID: id, Type: "assistant", Content: final},
+		{ID: "toolu_1", Type: "tool_use", Content: "Bash"},
+		{ID: "result_1", Type: "system", Content: "done"},
+	}
+
+	// Handlers run in their own goroutines, as SessionManager.emitEvent
+	// starts them
+	var wg sync.WaitGroup
+	for i := 1; i <= len(final); i++ {
+		wg.Add(1)
+		go func(text string) {
+			defer wg.Done()
+			bus.HandleEvent(claude.Event{Type: claude.EventMessageDelta, Data: claude.PartialMessage{
+				Message: claude.ConversationMessage{ID: id, Type: "assistant", Content: text},
+			}})
+		}(final[:i])
+	}
+	wg.Wait()
+	for _, msg := range completed {
+		wg.Add(1)
+		go func(msg claude.ConversationMessage) {
+			defer wg.Done()
+			bus.HandleEvent(claude.Event{Type: claude.EventMessageReceived, Data: msg})
+		}(msg)
+	}
+
+	ep := &EventProcessor{eventBus: bus, ctx: ctx}
+	a := &Application{streaming: make(map[string]bool)}
+	for len(deltas) > 0 {
+		a.applyStreamedMessage(ep.handleMessageEvent(<-deltas).(MessageStreamMsg))
+	}
+	for range completed {
+		select {
+		case event := <-messages:
+			if msg := ep.handleMessageEvent(event).(MessageStreamMsg); !a.applyStreamedMessage(msg) {
+				a.messages = append(a.messages, msg.Message)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("completed message lost")
+		}
+	}
+	wg.Wait()
+
+	if len(a.messages) != len(completed) {
+		t.Fatalf("%d messages, want %d", len(a.messages), len(completed))
+	}
+	if idx := a.findMessage(id, "assistant"); idx < 0 || a.messages[idx].Content != final {
+		t.Errorf("assistant text not completed: %d", idx)
+	}
+	if len(a.streaming) != 0 {
+		t.Errorf("partials left streaming: %v", a.streaming)
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptExportPath asks where to export the transcript, starting from the
+// configured export path
+func (a *Application) promptExportPath() {
+	d := newInputDialog(
+		"Export transcript",
+		[]string{"Paths ending in .jsonl export JSON lines, anything else Markdown."},
+		"Path: ", a.config.ExportPath,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.cancelled {
+				return a, nil
+			}
+			path, err := a.sessionManager.ExportTranscript(strings.TrimSpace(result.input))
+			if err != nil {
+				a.errors = append(a.errors, ErrorMsg{Error: err, Context: "export", Timestamp: time.Now()})
+				return a, nil
+			}
+			a.notify(toastSuccess, fmt.Sprintf("Transcript written to %s", path))
+			return a, nil
+		},
+	)
+	d.accept = func(text string) bool { return strings.TrimSpace(text) != "" }
+	d.hint = "Placeholders: {date} {time} {session_id} {conversation_id} | Ctrl+U: Clear"
+	a.openDialog(d)
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// followNewContent keeps the conversation on its live tail after it grew,
+// unless following is paused or auto_scroll is off. Then the reading
+// position stays and new messages are counted for the pill.
+func (a *Application) followNewContent(newMessage bool) {
+	if !a.config.AutoScroll {
+		a.follow.PauseFollow()
+	}
+	if a.follow.ContentArrived(newMessage) {
+		a.scrollToBottomSafe()
+	}
+}
+
+// jumpToNewMessages scrolls to the bottom and follows the tail again
+func (a *Application) jumpToNewMessages() {
+	a.scrollToBottomSafe()
+	a.follow.FollowTail()
+}
+
+// toggleFollow switches between following the tail and a frozen viewport (F)
+func (a *Application) toggleFollow() {
+	if a.follow.ToggleFollow() {
+		a.scrollToBottomSafe()
+		a.statusMessage = "[follow] Following new output"
+	} else {
+		a.statusMessage = "[follow] Paused; F or End to follow again"
+	}
+}
+
+// noteScrolled pauses following when the conversation was scrolled away
+// from the tail, and resumes it when scrolled back
+func (a *Application) noteScrolled() {
+	if a.atTail() {
+		a.follow.FollowTail()
+	} else {
+		a.follow.PauseFollow()
+	}
+}
+
+// followIndicator renders the follow mode for the footer
+func (a *Application) followIndicator() string {
+	if a.follow.Following() {
+		return a.follow.FollowLabel()
+	}
+	return a.styles.Highlight.Render(a.follow.FollowLabel())
+}
+
+// renderNewMessagesPill renders the "N new messages ↓" line, right-aligned
+// below the conversation
+func (a *Application) renderNewMessagesPill(width int) string {
+	label := "1 new message ↓"
+	if unseen := a.follow.Unseen(); unseen > 1 {
+		label = fmt.Sprintf("%d new messages ↓", unseen)
+	}
+	pill := a.styles.Pill.Render(label)
+	return lipgloss.PlaceHorizontal(width, lipgloss.Right, pill)
+}
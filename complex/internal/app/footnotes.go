@@ -0,0 +1,174 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// superscriptDigits are used for footnote markers in assistant text
+var superscriptDigits = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+// footnoteRef links a file referenced by a tool to its footnote number
+type footnoteRef struct {
+	path   string
+	number int
+}
+
+// footnoteIndex numbers tool entries and records, for each assistant
+// message, the files touched by tools earlier in the same turn
+type footnoteIndex struct {
+	numbers []int                 // footnote number per message, 0 if none
+	refs    map[int][]footnoteRef // assistant message index -> references
+	paths   map[int]string        // tool message index -> referenced path
+}
+
+// footnotes builds the footnote index for the current conversation
+func (a *Application) footnotes() footnoteIndex {
+	notes := footnoteIndex{
+		numbers: make([]int, len(a.messages)),
+		refs:    make(map[int][]footnoteRef),
+		paths:   make(map[int]string),
+	}
+
+	var turnRefs []footnoteRef
+	next := 1
+	for i, msg := range a.messages {
+		switch msg.Type {
+		case "user":
+			turnRefs = nil
+		case "tool_use":
+			notes.numbers[i] = next
+			if path := toolPath(msg.ToolInput); path != "" {
+				notes.paths[i] = path
+				turnRefs = append(turnRefs, footnoteRef{path: path, number: next})
+			}
+			next++
+		case "assistant":
+			if len(turnRefs) > 0 {
+				notes.refs[i] = append([]footnoteRef(nil), turnRefs...)
+			}
+		}
+	}
+	return notes
+}
+
+// toolPath extracts the file a tool operated on from its input
+func toolPath(input json.RawMessage) string {
+	if len(input) == 0 {
+		return ""
+	}
+	var fields struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+		Path         string `json:"path"`
+	}
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return ""
+	}
+	switch {
+	case fields.FilePath != "":
+		return fields.FilePath
+	case fields.NotebookPath != "":
+		return fields.NotebookPath
+	default:
+		return fields.Path
+	}
+}
+
+// displayContent returns the message text as shown in the conversation
+// panel: tool entries get their footnote number, assistant text gets
+// superscript markers after the first mention of each referenced file
+func (a *Application) displayContent(i int, notes footnoteIndex) string {
+	msg := a.messages[i]
+
+	switch msg.Type {
+	case "tool_use":
+		content := toolSummary(msg)
+		if path, ok := notes.paths[i]; ok && !strings.Contains(content, path) {
+			content = fmt.Sprintf("%s (%s)", content, path)
+		}
+		if n := notes.numbers[i]; n > 0 {
+			content = fmt.Sprintf("[%d] %s", n, content)
+		}
+		return content
+	case "assistant":
+		return annotateReferences(msg.Content, notes.refs[i])
+	default:
+		return msg.Content
+	}
+}
+
+// annotateReferences inserts a superscript marker after the first mention of
+// each referenced path (or its base name) in text
+func annotateReferences(text string, refs []footnoteRef) string {
+	marked := make(map[string]bool)
+	for _, ref := range refs {
+		for _, candidate := range []string{ref.path, filepath.Base(ref.path)} {
+			if len(candidate) < 3 || marked[candidate] {
+				continue
+			}
+			idx := strings.Index(text, candidate)
+			if idx < 0 {
+				continue
+			}
+			end := idx + len(candidate)
+			text = text[:end] + superscript(ref.number) + text[end:]
+			marked[ref.path] = true
+			marked[filepath.Base(ref.path)] = true
+			break
+		}
+	}
+	return text
+}
+
+// superscript renders n with superscript digits
+func superscript(n int) string {
+	var b strings.Builder
+	for _, r := range strconv.Itoa(n) {
+		b.WriteRune(superscriptDigits[r-'0'])
+	}
+	return b.String()
+}
+
+// handleFootnoteKeyPress collects the footnote number typed after "f" and
+// jumps to the corresponding tool entry
+func (a *Application) handleFootnoteKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "esc":
+		a.footnoteJump = false
+	case "backspace":
+		if len(a.footnoteDigits) > 0 {
+			a.footnoteDigits = a.footnoteDigits[:len(a.footnoteDigits)-1]
+		}
+	case "enter":
+		a.footnoteJump = false
+		if n, err := strconv.Atoi(a.footnoteDigits); err == nil {
+			a.jumpToFootnote(n)
+		}
+	default:
+		if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+			a.footnoteDigits += key
+		}
+	}
+	return a, nil
+}
+
+// jumpToFootnote scrolls the conversation so the numbered tool entry is at
+// the top of the viewport
+func (a *Application) jumpToFootnote(n int) {
+	notes := a.footnotes()
+	for i, number := range notes.numbers {
+		if number != n {
+			continue
+		}
+		a.scrollToMessage(i)
+		a.statusMessage = fmt.Sprintf("[footnote] Jumped to tool entry %d", n)
+		return
+	}
+	a.statusMessage = fmt.Sprintf("[footnote] No tool entry %d", n)
+}
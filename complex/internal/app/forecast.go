@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"complex/internal/claude"
+)
+
+// promptForecast caches the cost forecast of the prompt being composed, so
+// it is only recomputed when the prompt or the conversation changes
+type promptForecast struct {
+	prompt   string
+	turns    int
+	forecast claude.CostForecast
+}
+
+// forecastLabel renders the projected cost range of the input buffer
+func (a *Application) forecastLabel() string {
+	prompt := strings.TrimSpace(a.inputBuffer)
+	if prompt == "" || strings.HasPrefix(prompt, "/") {
+		return ""
+	}
+
+	if a.forecast.prompt != prompt || a.forecast.turns != a.sessionStats.CumulativeTurns {
+		a.forecast = promptForecast{
+			prompt:   prompt,
+			turns:    a.sessionStats.CumulativeTurns,
+			forecast: a.sessionManager.ForecastCost(prompt),
+		}
+	}
+
+	forecast := a.forecast.forecast
+	label := fmt.Sprintf("~$%s–$%s, %s tokens (%s in)",
+		formatCost(forecast.Low), formatCost(forecast.High),
+		formatTokens(forecast.PromptTokens), formatTokens(forecast.PromptTokens+forecast.ContextTokens))
+	if n := len(forecast.AttachedFiles); n == 1 {
+		label += ", 1 file"
+	} else if n > 1 {
+		label += fmt.Sprintf(", %d files", n)
+	}
+	return label
+}
+
+// formatCost renders a dollar amount with precision suited to its size
+func formatCost(cost float64) string {
+	if cost < 0.01 {
+		return fmt.Sprintf("%.4f", cost)
+	}
+	return fmt.Sprintf("%.2f", cost)
+}
+
+// formatTokens renders a token count in thousands above 1000
+func formatTokens(tokens int) string {
+	if tokens < 1000 {
+		return fmt.Sprintf("%d", tokens)
+	}
+	return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+}
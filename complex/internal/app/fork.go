@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// maxBranchPoints is how many branch points the picker offers, keyed 1-9
+const maxBranchPoints = 9
+
+// branchPoint is the last message of one session in the chain; forking from
+// it resumes that session
+type branchPoint struct {
+	chainIndex int
+	message    claude.ConversationMessage
+}
+
+// branchPoints returns the last assistant message of each session of the
+// chain, oldest first
+func (a *Application) branchPoints() []branchPoint {
+	latest := make(map[int]claude.ConversationMessage)
+	for _, msg := range a.messages {
+		if msg.Type != "assistant" {
+			continue
+		}
+		if index := a.sessionManager.ChainIndex(msg.SessionID); index >= 0 {
+			latest[index] = msg
+		}
+	}
+
+	var points []branchPoint
+	for index := range a.sessionManager.GetSessionChain() {
+		if msg, ok := latest[index]; ok {
+			points = append(points, branchPoint{chainIndex: index, message: msg})
+		}
+	}
+	return points
+}
+
+// openBranchPicker lists recent branch points to fork the conversation from
+func (a *Application) openBranchPicker() (tea.Model, tea.Cmd) {
+	points := a.branchPoints()
+	if len(points) == 0 {
+		a.statusMessage = "[fork] No finished turns to fork from yet"
+		return a, nil
+	}
+	if len(points) > maxBranchPoints {
+		points = points[len(points)-maxBranchPoints:]
+	}
+
+	options := make([]dialogOption, 0, len(points))
+	for i, point := range points {
+		label := fmt.Sprintf("%s  %s", point.message.Timestamp.Local().Format("15:04"), truncateString(point.message.Content, 50))
+		options = append(options, dialogOption{strconv.Itoa(i + 1), label})
+	}
+	d := newChoiceDialog("Fork from a reply",
+		[]string{"Start a new conversation that continues after the chosen reply. The current one stays in /resume."},
+		options,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.cancelled {
+				return a, nil
+			}
+			choice, err := strconv.Atoi(result.choice)
+			if err != nil || choice < 1 || choice > len(points) {
+				return a, nil
+			}
+			return a.forkFromSession(points[choice-1].chainIndex)
+		})
+	a.openDialog(d)
+	return a, nil
+}
+
+// forkFromMessage forks from the turn that produced the selected message
+func (a *Application) forkFromMessage() (tea.Model, tea.Cmd) {
+	if !a.messageSelected() {
+		a.statusMessage = "[fork] Select a message with [ or ] first (/fork lists branch points)"
+		return a, nil
+	}
+	msg := a.messages[a.selectedMessage]
+	if msg.Type != "assistant" && msg.Type != "tool_use" {
+		a.statusMessage = "[fork] Select a reply from Claude to fork from"
+		return a, nil
+	}
+	if msg.SessionID == "" {
+		a.statusMessage = "[fork] This message has no recorded session; fork by session with Ctrl+L"
+		return a, nil
+	}
+	index := a.sessionManager.ChainIndex(msg.SessionID)
+	if index < 0 {
+		a.statusMessage = "[fork] The turn of this message did not finish, so it cannot be resumed"
+		return a, nil
+	}
+
+	d := newConfirmDialog("Fork from this reply?",
+		[]string{
+			truncateString(msg.Content, 120),
+			"",
+			fmt.Sprintf("A new conversation resumes session %s. Later replies of that turn are kept; the current conversation stays in /resume.", truncateString(msg.SessionID, 36)),
+		},
+		true,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.choice != "y" {
+				return a, nil
+			}
+			a.selectedMessage = -1
+			return a.forkFromSession(index)
+		})
+	a.openDialog(d)
+	return a, nil
+}
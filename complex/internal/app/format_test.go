@@ -0,0 +1,37 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+	"complex/internal/ui/components"
+)
+
+func TestFormatAssistantMessageFitsWidth(t *testing.T) {
+	const panel = 80
+	renderer, err := components.NewMarkdownRendererWithStyle(panel-4, "notty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Application{markdownRenderer: renderer}
+	msg := claude.ConversationMessage{
+		ID:      "msg_1",
+		Type:    "assistant",
+		Content: strings.Repeat("The reply wraps across several lines of the panel. ", 8) + "\n\n- a list item that is also long enough to wrap at the panel width",
+	}
+
+	// Full width, then the columns left by timestamps and the selection bar
+	for _, width := range []int{panel, panel - timestampWidth, panel - timestampWidth - 1} {
+		for i, line := range strings.Split(a.formatMessage(msg, msg.Content, width), "\n") {
+			if w := ansi.StringWidth(line); w > width {
+				t.Errorf("width %d: line %d is %d columns: %q", width, i, w, line)
+			}
+		}
+	}
+	if len(a.fitRenderers) != 2 {
+		t.Errorf("%d narrower renderers kept, want 2", len(a.fitRenderers))
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"complex/internal/claude"
+)
+
+// bashCommand extracts the shell command from a Bash tool_use message
+func bashCommand(msg claude.ConversationMessage) (string, bool) {
+	if msg.Type != "tool_use" || msg.ToolName != "Bash" || len(msg.ToolInput) == 0 {
+		return "", false
+	}
+	var input struct {
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal(msg.ToolInput, &input); err != nil || input.Command == "" {
+		return "", false
+	}
+	return input.Command, true
+}
+
+// checkRiskyCommand returns a warning message when a Bash tool_use matches
+// one of the guard rules
+func (a *Application) checkRiskyCommand(msg claude.ConversationMessage) (claude.ConversationMessage, bool) {
+	command, ok := bashCommand(msg)
+	if !ok {
+		return claude.ConversationMessage{}, false
+	}
+	matches := a.guard.Check(command)
+	if len(matches) == 0 {
+		return claude.ConversationMessage{}, false
+	}
+
+	return claude.ConversationMessage{
+		ID:   fmt.Sprintf("warning_%s", msg.ToolUseID),
+		Type: "warning",
+		Content: fmt.Sprintf("Risky command (%s): %s",
+			strings.Join(matches, ", "), command),
+		Timestamp: msg.Timestamp,
+		IsError:   true,
+		ToolUseID: msg.ToolUseID,
+	}, true
+}
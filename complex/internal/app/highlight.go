@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+	"complex/internal/ui/components"
+)
+
+// fileTools are the tools whose output is the content of their file_path
+var fileTools = map[string]bool{"Read": true}
+
+// lineGutter matches the line number claude's Read tool puts before each line
+var lineGutter = regexp.MustCompile(`^(\s*\d+)(→|\t)`)
+
+// hasFence reports whether text may contain a fenced code block
+func hasFence(text string) bool {
+	return strings.Contains(text, "```") || strings.Contains(text, "~~~")
+}
+
+// wrapContent wraps text that is not rendered as Markdown. Fenced code blocks
+// are highlighted and their long lines broken without reflowing, so the
+// indentation survives; the prose around them wraps as before.
+func (a *Application) wrapContent(text string, width int) string {
+	if !hasFence(text) {
+		return wrapMessage(text, width)
+	}
+	var parts []string
+	for _, segment := range components.SplitFences(text) {
+		if !segment.Code {
+			parts = append(parts, wrapMessage(segment.Text, width))
+			continue
+		}
+		lines := []string{a.styles.Status.Render("```" + segment.Language)}
+		for _, line := range a.highlighter.Highlight(segment.Text, segment.Language) {
+			lines = append(lines, ansi.Hardwrap(line, max(1, width), true))
+		}
+		parts = append(parts, strings.Join(append(lines, a.styles.Status.Render("```")), "\n"))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// highlightToolOutput colors a tool's output. The output of tools that read a
+// file is highlighted by the file's type, leaving the line numbers out of the
+// lexer; other output has its fenced code blocks highlighted. code marks the
+// lines that must not be reflowed.
+func (a *Application) highlightToolOutput(msg claude.ConversationMessage, output []string) (lines []string, code []bool) {
+	if path := toolFilePath(msg); path != "" {
+		gutters := make([]string, len(output))
+		source := make([]string, len(output))
+		for i, line := range output {
+			if m := lineGutter.FindStringIndex(line); m != nil {
+				gutters[i], source[i] = line[:m[1]], line[m[1]:]
+			} else {
+				source[i] = line
+			}
+		}
+		highlighted := a.highlighter.Highlight(strings.Join(source, "\n"), path)
+		for i, line := range highlighted {
+			lines = append(lines, a.styles.Status.Render(gutters[i])+line)
+			code = append(code, true)
+		}
+		return lines, code
+	}
+
+	if !hasFence(strings.Join(output, "\n")) {
+		return output, make([]bool, len(output))
+	}
+	for _, segment := range components.SplitFences(strings.Join(output, "\n")) {
+		if !segment.Code {
+			for _, line := range strings.Split(segment.Text, "\n") {
+				lines, code = append(lines, line), append(code, false)
+			}
+			continue
+		}
+		lines, code = append(lines, "```"+segment.Language), append(code, false)
+		for _, line := range a.highlighter.Highlight(segment.Text, segment.Language) {
+			lines, code = append(lines, line), append(code, true)
+		}
+		lines, code = append(lines, "```"), append(code, false)
+	}
+	return lines, code
+}
+
+// toolFilePath returns the file a file-reading tool call read, or ""
+func toolFilePath(msg claude.ConversationMessage) string {
+	if !fileTools[msg.ToolName] || msg.IsError {
+		return ""
+	}
+	var input struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := json.Unmarshal(msg.ToolInput, &input); err != nil {
+		return ""
+	}
+	return input.FilePath
+}
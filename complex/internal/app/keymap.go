@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Contexts of key bindings, which are also the help sections listing them
+const (
+	contextGlobal       = "Keyboard Shortcuts"
+	contextConversation = "Conversation"
+)
+
+// keyBinding is an action of the registry: its name in [keybindings], the
+// keys bound to it by default, where it works and its help line
+type keyBinding struct {
+	action  string
+	keys    []string
+	context string
+	help    string
+	// label replaces the keys in the help; bindings without an action are
+	// listed in the help only, for keys that cannot be rebound
+	label string
+}
+
+// keyBindings is the registry of key bindings, in help order. The first key
+// of an action is the one the application dispatches on; its other keys,
+// and keys configured for it, resolve to that one.
+var keyBindings = []keyBinding{
+	{label: "Enter", context: contextGlobal, help: "Start typing a message"},
+	{action: "quit", keys: []string{"ctrl+c", "q"}, context: contextGlobal, help: "Quit application"},
+	{action: "new_conversation", keys: []string{"ctrl+n"}, context: contextGlobal, help: "Start new conversation"},
+	{action: "new_tab", keys: []string{"ctrl+t"}, context: contextGlobal, help: "Open a new conversation tab"},
+	{action: "close_tab", keys: []string{"ctrl+w"}, context: contextGlobal, help: "Close the conversation tab"},
+	{label: "Alt+1..9", context: contextGlobal, help: "Switch to tab n (Ctrl+1..9 where the terminal reports it)"},
+	{label: "gt / gT", context: contextGlobal, help: "Next / previous tab"},
+	{action: "help", keys: []string{"ctrl+h"}, context: contextGlobal, help: "Show this help"},
+	{action: "copy_summary", keys: []string{"ctrl+y"}, context: contextGlobal, help: "Copy conversation summary (Markdown) to clipboard"},
+	{action: "export", keys: []string{"ctrl+e"}, context: contextGlobal, help: "Export transcript (asks for the path, Markdown or JSONL)"},
+	{action: "context_panel", keys: []string{"ctrl+o"}, context: contextGlobal, help: "Show approximate context window composition"},
+	{action: "sessions_view", keys: []string{"ctrl+l"}, context: contextGlobal, help: "Show the session chain and fork from an earlier session"},
+	{action: "raw_stream", keys: []string{"ctrl+d"}, context: contextGlobal, help: "Show the raw stream-json lines from claude (filter, pretty-print, write)"},
+	{action: "stderr_pane", keys: []string{"ctrl+b"}, context: contextGlobal, help: "Show or hide claude's stderr; a footer badge counts unread warnings"},
+	{action: "stats_view", keys: []string{"ctrl+u"}, context: contextGlobal, help: "Show usage statistics: saved conversations and spending per day, model and project"},
+	{action: "templates", keys: []string{"ctrl+k"}, context: contextGlobal, help: "Pick a prompt template, fill its placeholders and send it (Ctrl+K, as Ctrl+T opens tabs)"},
+	{label: "Ctrl+P", context: contextGlobal, help: "While typing, preview the prompt as sent"},
+	{action: "edit_prompt", keys: []string{"ctrl+g"}, context: contextGlobal, help: "Write the prompt in $EDITOR; it is sent when the editor exits"},
+	{label: "Paste", context: contextGlobal, help: "Pasted text goes into the input as is; several lines start compose mode"},
+	{label: "Mouse", context: contextGlobal, help: "Wheel scrolls, click focuses a panel, drag selects and copies text"},
+	{action: "settings", keys: []string{"ctrl+s"}, context: contextGlobal, help: "Edit settings: a form previewed live, a for the whole tree"},
+	{action: "main_view", keys: []string{"ctrl+m"}, context: contextGlobal, help: "Return to main view"},
+	{action: "toggle_sidebar", keys: []string{"ctrl+\\"}, context: contextGlobal, help: "Hide or show the side panel"},
+	{action: "sidebar_wider", keys: []string{"ctrl+left"}, context: contextGlobal, help: "Widen the side panel (saved to config.toml)"},
+	{action: "sidebar_narrower", keys: []string{"ctrl+right"}, context: contextGlobal, help: "Narrow the side panel (saved to config.toml)"},
+	{label: "Esc", context: contextGlobal, help: "Cancel input or return to main"},
+	{action: "cancel_command", keys: []string{"ctrl+x"}, context: contextGlobal, help: "Cancel the running command (Esc also works)"},
+
+	{action: "scroll_up", keys: []string{"up", "k"}, context: contextConversation, help: "Scroll up one line"},
+	{action: "scroll_down", keys: []string{"down", "j"}, context: contextConversation, help: "Scroll down one line"},
+	{action: "page_up", keys: []string{"pgup"}, context: contextConversation, help: "Scroll page up"},
+	{action: "page_down", keys: []string{"pgdown"}, context: contextConversation, help: "Scroll page down"},
+	{action: "scroll_top", keys: []string{"home"}, context: contextConversation, help: "Jump to top"},
+	{action: "scroll_bottom", keys: []string{"end", "G"}, context: contextConversation, help: "Jump to bottom, clearing the \"new messages\" pill"},
+	{label: "f<n>Enter", context: contextConversation, help: "Jump to tool entry [n] referenced by a footnote"},
+	{action: "search", keys: []string{"/"}, context: contextConversation, help: "Search the conversation (Esc: clear)"},
+	{action: "next_match", keys: []string{"n"}, context: contextConversation, help: "Next search match"},
+	{action: "previous_match", keys: []string{"N"}, context: contextConversation, help: "Previous search match"},
+	{action: "next_tool", keys: []string{"tab"}, context: contextConversation, help: "Select next tool message (Enter: expand/collapse)"},
+	{action: "previous_tool", keys: []string{"shift+tab"}, context: contextConversation, help: "Select previous tool message"},
+	{action: "previous_message", keys: []string{"["}, context: contextConversation, help: "Select previous message"},
+	{action: "next_message", keys: []string{"]"}, context: contextConversation, help: "Select next message"},
+	{action: "copy_message", keys: []string{"y"}, context: contextConversation, help: "Copy the selected message; yc copies the code blocks of the last reply"},
+	{action: "fork_message", keys: []string{"B"}, context: contextConversation, help: "Branch: fork a new conversation from the selected reply"},
+	{action: "toggle_follow", keys: []string{"F"}, context: contextConversation, help: "Follow or pause new output"},
+	{action: "rerender", keys: []string{"v"}, context: contextConversation, help: "Re-render the selected message (raw, plain, wide, with thinking)"},
+	{action: "retry_prompt", keys: []string{"r"}, context: contextConversation, help: "Pre-fill a follow-up prompt for detected failures"},
+	{label: "1..3", context: contextConversation, help: "Insert a quick reply after a turn (quick_replies in config.toml)"},
+	{action: "toggle_superseded", keys: []string{"z"}, context: contextConversation, help: "Show/hide retried and cancelled attempts (hide_superseded in config.toml)"},
+	{label: ":set timestamps[=absolute|relative|off]", context: contextConversation, help: "Toggle or pick message times; days get separators"},
+}
+
+// Keymap translates pressed keys to the keys the application dispatches on:
+// the other default keys of an action, and keys configured in
+// [keybindings], which work alongside the defaults
+type Keymap struct {
+	aliases map[string]string
+	// configured holds the keys bound in [keybindings], by action
+	configured map[string]string
+}
+
+// NewKeymap builds a keymap from action -> key bindings. Unknown actions are
+// ignored.
+func NewKeymap(bindings map[string]string) *Keymap {
+	km := &Keymap{aliases: make(map[string]string), configured: make(map[string]string)}
+	for _, binding := range keyBindings {
+		if binding.action == "" {
+			continue
+		}
+		for _, key := range binding.keys[1:] {
+			km.aliases[key] = binding.keys[0]
+		}
+	}
+	// Configured keys win over the defaults of other actions
+	for _, binding := range keyBindings {
+		if key := bindings[binding.action]; binding.action != "" && key != "" {
+			km.aliases[key] = binding.keys[0]
+			km.configured[binding.action] = key
+		}
+	}
+	return km
+}
+
+// Resolve returns the key dispatched on for a pressed key. While typing in
+// the input line, single characters are vim commands and stay as they are.
+func (km *Keymap) Resolve(key string, typing bool) string {
+	if typing && utf8.RuneCountInString(key) == 1 {
+		return key
+	}
+	if builtin, ok := km.aliases[key]; ok {
+		return builtin
+	}
+	return key
+}
+
+// keys returns every key of a binding, the configured one last
+func (km *Keymap) keys(binding keyBinding) []string {
+	keys := binding.keys
+	if key, ok := km.configured[binding.action]; ok {
+		keys = append(keys[:len(keys):len(keys)], key)
+	}
+	return keys
+}
+
+// helpLines renders the help section of a context from the registry, with
+// the keys as currently bound
+func (km *Keymap) helpLines(context string) []string {
+	type row struct{ keys, help string }
+	var rows []row
+	width := 0
+	for _, binding := range keyBindings {
+		if binding.context != context {
+			continue
+		}
+		label := binding.label
+		if label == "" {
+			labels := make([]string, 0, len(binding.keys)+1)
+			for _, key := range km.keys(binding) {
+				labels = append(labels, keyLabel(key))
+			}
+			label = strings.Join(labels, " / ")
+		}
+		rows = append(rows, row{label, binding.help})
+		// Long labels are not padded to
+		if n := utf8.RuneCountInString(label); n <= 14 {
+			width = max(width, n)
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, r := range rows {
+		pad := max(0, width-utf8.RuneCountInString(r.keys))
+		lines = append(lines, fmt.Sprintf("  %s%s - %s", r.keys, strings.Repeat(" ", pad), r.help))
+	}
+	return lines
+}
+
+// keyNames are the help labels of named keys
+var keyNames = map[string]string{
+	"up": "↑", "down": "↓", "left": "←", "right": "→",
+	"pgup": "PgUp", "pgdown": "PgDn", "home": "Home", "end": "End",
+	"enter": "Enter", "esc": "Esc", "tab": "Tab", "backspace": "Backspace",
+	"delete": "Delete", " ": "Space",
+}
+
+// keyLabel renders a key as the help shows it, e.g. "ctrl+left" as Ctrl+←
+func keyLabel(key string) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	var parts []string
+	for {
+		modifier, rest, ok := strings.Cut(key, "+")
+		if !ok || rest == "" {
+			break
+		}
+		switch modifier {
+		case "ctrl", "alt", "shift":
+			parts = append(parts, strings.ToUpper(modifier[:1])+modifier[1:])
+			key = rest
+			continue
+		}
+		break
+	}
+	if name, ok := keyNames[key]; ok {
+		key = name
+	} else if len(parts) > 0 && utf8.RuneCountInString(key) == 1 || len(key) > 1 && key[0] == 'f' && key[1] >= '0' && key[1] <= '9' {
+		// Ctrl+A, F1
+		key = strings.ToUpper(key)
+	}
+	return strings.Join(append(parts, key), "+")
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// linearOutput tracks what the screen-reader friendly linear mode has
+// already printed
+type linearOutput struct {
+	printed    map[string]bool
+	lastStatus string
+	lastError  string
+	lastToast  int
+}
+
+// SetLinearMode switches to a plain-text output mode for screen readers: no
+// alternate screen or panels, each message is printed once as a line of text
+func (a *Application) SetLinearMode(enabled bool) {
+	a.linear = enabled
+	a.linearOut = linearOutput{printed: make(map[string]bool)}
+}
+
+// linearKey identifies a message for print-once tracking. Tool uses share
+// their assistant message ID, so the tool_use ID is part of the key.
+func linearKey(msg claude.ConversationMessage) string {
+	return msg.Type + "|" + msg.ID + "|" + msg.ToolUseID
+}
+
+// linearLine formats a message as announcement-friendly plain text
+func linearLine(msg claude.ConversationMessage) string {
+	content := strings.TrimSpace(msg.Content)
+	switch msg.Type {
+	case "user":
+		return "You: " + content
+	case "assistant":
+		return "Claude: " + content
+	case "tool_use":
+		if command, ok := bashCommand(msg); ok {
+			return fmt.Sprintf("Tool %s: %s", msg.ToolName, command)
+		}
+		return fmt.Sprintf("Tool: %s", msg.ToolName)
+	case "warning":
+		return "Warning: " + content
+	default:
+		if msg.IsError {
+			return "Error: " + content
+		}
+		return "System: " + content
+	}
+}
+
+// flushLinear prints conversation messages, status changes and errors not
+// yet printed. Messages still streaming hold back everything after them so
+// the output stays in order.
+func (a *Application) flushLinear() tea.Cmd {
+	var lines []string
+
+	for _, msg := range a.messages {
+		key := linearKey(msg)
+		if a.linearOut.printed[key] {
+			continue
+		}
+		if a.streaming[msg.ID] {
+			break
+		}
+		a.linearOut.printed[key] = true
+		lines = append(lines, linearLine(msg))
+	}
+
+	if n := len(a.errors); n > 0 {
+		latest := a.errors[n-1]
+		key := latest.Timestamp.String() + latest.Error.Error()
+		if key != a.linearOut.lastError {
+			a.linearOut.lastError = key
+			lines = append(lines, "Error: "+latest.Error.Error())
+		}
+	}
+
+	for _, t := range a.toasts {
+		if t.id > a.linearOut.lastToast {
+			a.linearOut.lastToast = t.id
+			lines = append(lines, "Notice: "+t.text)
+		}
+	}
+
+	if a.statusMessage != a.linearOut.lastStatus {
+		a.linearOut.lastStatus = a.statusMessage
+		if a.statusMessage != "" {
+			lines = append(lines, "Status: "+a.statusMessage)
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+	return tea.Println(strings.Join(lines, "\n"))
+}
+
+// renderLinearView renders the single prompt line shown below the printed
+// conversation
+func (a *Application) renderLinearView() string {
+	switch {
+	case a.isLoading:
+		return "Working... (Ctrl+X to cancel)"
+	case a.footnoteJump:
+		return "Jump to footnote: " + a.footnoteDigits
+	case a.inputActive:
+		mode := "normal"
+		if a.inputMode == InputModeInsert {
+			mode = "insert"
+		}
+		return fmt.Sprintf("Prompt (%s mode): %s", mode, a.inputBuffer)
+	}
+	prefix := ""
+	if tabBar := a.renderTabBar(); tabBar != "" {
+		prefix = "Tabs: " + tabBar + ". "
+	}
+	return prefix + "Press Enter to type a message, Ctrl+H for help."
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"time"
+
+	"complex/internal/claude"
+)
+
+// conversationLayout is the conversation laid out as panel lines at one
+// width. It is reused while the messages and how they are shown stay the
+// same, so scrolling never lays the conversation out again, and extended
+// rather than rebuilt when messages are appended.
+type conversationLayout struct {
+	view  layoutView
+	build int // bumped on every change to lines, 0 before the first
+
+	// The messages laid out, by count and by the ID, content length and
+	// day of the last one: streaming grows it in place
+	count   int
+	lastID  string
+	lastLen int
+	lastDay string
+
+	folds   map[int]foldedAttempts
+	lines   []string
+	offsets []int // first line of each message
+	starts  []int // first line of each message's block, separator included
+}
+
+// layoutView is everything besides the messages themselves that the
+// conversation's lines depend on
+type layoutView struct {
+	width           int
+	generation      int
+	firstID         string
+	model           string
+	selectedMessage int
+	selectedTool    string
+	showSuperseded  bool
+	timestamps      timestampMode
+	// minute is the current minute while times are shown relative
+	minute int64
+}
+
+// invalidateLayout makes the next use of the conversation's lines lay them
+// out again. Appending messages, moving the selection and resizing are
+// noticed on their own; anything changing a message in place or how one
+// is shown calls this.
+func (a *Application) invalidateLayout() {
+	a.layoutGeneration++
+}
+
+// currentLayoutView describes how the conversation is shown at width
+func (a *Application) currentLayoutView(width int) layoutView {
+	view := layoutView{
+		width:           width,
+		generation:      a.layoutGeneration,
+		model:           a.sessionManager.Model,
+		selectedMessage: a.selectedMessage,
+		selectedTool:    a.selectedTool,
+		showSuperseded:  a.showSuperseded,
+		timestamps:      a.timestamps,
+	}
+	if len(a.messages) > 0 {
+		view.firstID = a.messages[0].ID
+	}
+	if a.timestamps == timestampsRelative {
+		view.minute = time.Now().Unix() / 60
+	}
+	return view
+}
+
+// laidOutThrough reports whether the last message laid out is unchanged
+func (l *conversationLayout) laidOutThrough(a *Application) bool {
+	if l.count == 0 {
+		return true
+	}
+	if l.count > len(a.messages) {
+		return false
+	}
+	last := a.messages[l.count-1]
+	return last.ID == l.lastID && len(last.Content) == l.lastLen
+}
+
+// restartIndex returns the message from which appended messages are laid
+// out: the last one laid out, which gains the gap before the next, or the
+// start of the folded attempts it belongs to. It returns 0 when the
+// append changes the layout before that, by folding earlier messages or
+// giving the conversation a second day.
+func (a *Application) restartIndex(folds map[int]foldedAttempts) int {
+	l := &a.convLayout
+	restart := l.count - 1
+	for start, fold := range folds {
+		if start <= restart && restart < fold.end {
+			restart = start
+		}
+	}
+	for start, fold := range folds {
+		if start < restart && l.folds[start] != fold {
+			return 0
+		}
+	}
+	for start := range l.folds {
+		if _, ok := folds[start]; !ok && start < restart {
+			return 0
+		}
+	}
+	if a.timestamps != timestampsOff && messageDay(a.messages[len(a.messages)-1]) != l.lastDay {
+		return 0
+	}
+	return restart
+}
+
+// messageDay is the local date of a message, "" when it has no time
+func messageDay(msg claude.ConversationMessage) string {
+	if msg.Timestamp.IsZero() {
+		return ""
+	}
+	return msg.Timestamp.Local().Format("2006-01-02")
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+	"complex/internal/logging"
+)
+
+// defaultLogTail is how many log entries /log shows without a count
+const defaultLogTail = 20
+
+// handleLogCommand runs "/log [n]", adding the latest log entries to the
+// conversation
+func (a *Application) handleLogCommand(args []string) (tea.Model, tea.Cmd) {
+	n := defaultLogTail
+	if len(args) > 0 {
+		value, err := strconv.Atoi(args[0])
+		if err != nil || value <= 0 {
+			return a, func() tea.Msg {
+				return StatusMsg{Status: "log", Message: "Usage: /log [n]"}
+			}
+		}
+		n = value
+	}
+
+	content := "No log entries yet."
+	if lines := logging.Recent(n); len(lines) > 0 {
+		content = fmt.Sprintf("Last %d log entries:\n\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("log_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   content,
+		Timestamp: time.Now(),
+	})
+	a.scrollToBottomSafe()
+	return a, nil
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"complex/internal/claude"
+	"complex/internal/notify"
+)
+
+// notifyLongTurn announces a run that finished after a long time, or while
+// nobody was using the TUI. Cancelled runs were stopped from the TUI, so
+// they are not announced.
+func (a *Application) notifyLongTurn(msg CommandFinishedMsg) {
+	if msg.Started.IsZero() || errors.Is(msg.Err, claude.ErrCommandCancelled) {
+		return
+	}
+	took := time.Since(msg.Started)
+	if !a.config.Notify.IsLongTurn(took, time.Since(a.lastInput)) {
+		return
+	}
+
+	title := "Claude finished"
+	if msg.Err != nil {
+		title = "Claude failed"
+	}
+	a.notifier.Notify(notify.Notification{
+		Event: notify.EventLongTurn,
+		Title: title,
+		Body:  fmt.Sprintf("%s after %s", truncateString(msg.Prompt, 100), formatLatency(took)),
+	})
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"customclaude/pkg/mcp"
+)
+
+// mcpPanel holds the state of the MCP server panel
+type mcpPanel struct {
+	selected int
+	checking bool
+	health   map[string]mcp.Health
+}
+
+// MCPHealthMsg carries the results of an MCP health check
+type MCPHealthMsg struct {
+	Results []mcp.Health
+}
+
+// SetMCPManager enables the /mcp command and panel
+func (a *Application) SetMCPManager(manager *mcp.Manager) {
+	a.mcp = manager
+}
+
+// handleMCPCommand runs "/mcp [list|enable|disable|check] [name]"
+func (a *Application) handleMCPCommand(args []string) (tea.Model, tea.Cmd) {
+	if a.mcp == nil {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "mcp", Message: "MCP server management is not available"}
+		}
+	}
+
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "list":
+		return a.openMCPPanel()
+
+	case "check":
+		a.state = StateMCP
+		return a, a.checkMCPServers()
+
+	case "enable", "disable":
+		if len(args) < 2 {
+			return a, func() tea.Msg {
+				return StatusMsg{Status: "mcp", Message: fmt.Sprintf("Usage: /mcp %s <server>", action)}
+			}
+		}
+		name := args[1]
+		if err := a.mcp.SetEnabled(name, action == "enable"); err != nil {
+			return a, func() tea.Msg {
+				return ErrorMsg{Error: err, Context: "mcp"}
+			}
+		}
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "mcp", Message: fmt.Sprintf("%sd %s (applies to the next prompt)", capitalize(action), name)}
+		}
+
+	default:
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "mcp", Message: "Usage: /mcp [list|check|enable <server>|disable <server>]"}
+		}
+	}
+}
+
+// capitalize upper-cases the first letter of an ASCII word
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
+
+// openMCPPanel switches to the MCP server panel
+func (a *Application) openMCPPanel() (tea.Model, tea.Cmd) {
+	if a.mcp == nil {
+		return a, nil
+	}
+	if err := a.mcp.Load(); err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "mcp"}
+		}
+	}
+	a.state = StateMCP
+	if servers := a.mcp.Servers(); a.mcpPanel.selected >= len(servers) {
+		a.mcpPanel.selected = max(0, len(servers)-1)
+	}
+	return a, nil
+}
+
+// checkMCPServers health-checks all servers in the background
+func (a *Application) checkMCPServers() tea.Cmd {
+	a.mcpPanel.checking = true
+	manager := a.mcp
+	ctx := a.ctx
+	return func() tea.Msg {
+		return MCPHealthMsg{Results: manager.CheckAll(ctx)}
+	}
+}
+
+// handleMCPHealth stores health check results for the panel
+func (a *Application) handleMCPHealth(msg MCPHealthMsg) (tea.Model, tea.Cmd) {
+	a.mcpPanel.checking = false
+	if a.mcpPanel.health == nil {
+		a.mcpPanel.health = make(map[string]mcp.Health)
+	}
+	failed := 0
+	for _, result := range msg.Results {
+		a.mcpPanel.health[result.Server] = result
+		if !result.OK {
+			failed++
+		}
+	}
+	a.statusMessage = fmt.Sprintf("[mcp] Checked %d servers, %d unreachable", len(msg.Results), failed)
+	return a, nil
+}
+
+// handleMCPKeyPress handles navigation and toggling inside the MCP panel
+func (a *Application) handleMCPKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	servers := a.mcp.Servers()
+	switch msg.String() {
+	case "up", "k":
+		if a.mcpPanel.selected > 0 {
+			a.mcpPanel.selected--
+		}
+	case "down", "j":
+		if a.mcpPanel.selected < len(servers)-1 {
+			a.mcpPanel.selected++
+		}
+	case " ", "enter":
+		if a.mcpPanel.selected < len(servers) {
+			server := servers[a.mcpPanel.selected]
+			if err := a.mcp.SetEnabled(server.Name, !server.Enabled); err != nil {
+				a.errors = append(a.errors, ErrorMsg{Error: err, Context: "mcp"})
+			}
+		}
+	case "h":
+		if !a.mcpPanel.checking {
+			return a, a.checkMCPServers()
+		}
+	case "r":
+		return a.openMCPPanel()
+	case "esc", "q", "ctrl+m":
+		a.state = StateMain
+	case "ctrl+c":
+		return a, tea.Quit
+	}
+	return a, nil
+}
+
+// renderMCPView renders the configured servers, their health and what the
+// last claude invocation reported about them
+func (a *Application) renderMCPView() string {
+	content := []string{
+		a.styles.Header.Render("CustomClaude TUI - MCP Servers"),
+		"",
+	}
+
+	init := a.sessionManager.LastInit()
+	connection := make(map[string]string)
+	for _, status := range init.MCPServers {
+		connection[status.Name] = status.Status
+	}
+
+	servers := a.mcp.Servers()
+	if len(servers) == 0 {
+		content = append(content, a.styles.Status.Render("  No MCP servers defined in the configured files"))
+	}
+
+	for i, server := range servers {
+		check := "[ ]"
+		if server.Enabled {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %-20s %-6s %s", check, truncateString(server.Name, 20), server.Type, truncateString(server.Target(), 40))
+		if i == a.mcpPanel.selected {
+			content = append(content, a.styles.Highlight.Render("> "+line))
+		} else {
+			content = append(content, "  "+line)
+		}
+
+		details := []string{"source " + server.Source}
+		if status, ok := connection[server.Name]; ok {
+			details = append(details, "claude: "+status)
+		}
+		if health, ok := a.mcpPanel.health[server.Name]; ok {
+			state := "healthy"
+			if !health.OK {
+				state = "unhealthy"
+			}
+			details = append(details, fmt.Sprintf("%s: %s", state, health.Detail))
+		}
+		style := a.styles.Status
+		if health, ok := a.mcpPanel.health[server.Name]; ok && !health.OK {
+			style = a.styles.Error
+		}
+		content = append(content, style.Render("      "+strings.Join(details, " | ")))
+
+		if tools := serverTools(init.Tools, server.Name); len(tools) > 0 {
+			content = append(content, a.styles.Tool.Render("      tools: "+strings.Join(tools, ", ")))
+		}
+	}
+
+	if a.mcpPanel.checking {
+		content = append(content, "", a.styles.Status.Render("Checking servers..."))
+	}
+	if len(init.MCPServers) == 0 {
+		content = append(content, "", a.styles.Footer.Render("Connection status and tools appear after the first prompt."))
+	}
+
+	content = append(content,
+		"",
+		"↑/↓ or j/k: Select | Space/Enter: Enable/disable | h: Health check | r: Reload | Esc: Back",
+	)
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
+
+// serverTools returns the short names of the tools an MCP server provides,
+// which claude lists as mcp__<server>__<tool>
+func serverTools(tools []string, server string) []string {
+	prefix := "mcp__" + server + "__"
+	var names []string
+	for _, tool := range tools {
+		if strings.HasPrefix(tool, prefix) {
+			names = append(names, strings.TrimPrefix(tool, prefix))
+		}
+	}
+	return names
+}
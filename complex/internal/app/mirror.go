@@ -0,0 +1,29 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleMirrorCommand runs "/mirror [path | off]": it shows or changes the
+// file or named pipe this tab's assistant text is copied to as it streams,
+// for example a pipe read by another pane (mkfifo /tmp/claude; cat /tmp/claude)
+func (a *Application) handleMirrorCommand(arg string) (tea.Model, tea.Cmd) {
+	switch arg {
+	case "":
+		if path := a.sessionManager.StreamMirror(); path != "" {
+			a.statusMessage = fmt.Sprintf("[mirror] Streaming assistant text to %s (/mirror off stops)", shortenHome(path))
+		} else {
+			a.statusMessage = "[mirror] Off. Use /mirror <file or named pipe> to copy assistant text as it streams"
+		}
+	case "off":
+		a.sessionManager.SetStreamMirror("")
+		a.statusMessage = "[mirror] Stopped mirroring assistant text"
+	default:
+		path := a.resolvePath(arg)
+		a.sessionManager.SetStreamMirror(path)
+		a.statusMessage = fmt.Sprintf("[mirror] Assistant text streams to %s from the next prompt", shortenHome(path))
+	}
+	return a, nil
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/clipboard"
+)
+
+// wheelLines is how far one mouse wheel notch scrolls the conversation
+const wheelLines = 3
+
+// mouseLayout records where the main view placed its panels on the last
+// render, so mouse coordinates can be mapped back to them
+type mouseLayout struct {
+	convTop   int // screen row of the first conversation line
+	convLeft  int // screen column of the first conversation column
+	convRows  int // visible conversation lines
+	convWidth int // width the conversation lines were wrapped to
+	sideLeft  int // screen column where the side panel begins
+	inputTop  int // screen row where the input panel begins
+}
+
+// textPos is a position in the rendered conversation: a line index into all
+// conversation lines and a display column within it
+type textPos struct {
+	line, col int
+}
+
+// before reports whether p comes before q
+func (p textPos) before(q textPos) bool {
+	return p.line < q.line || (p.line == q.line && p.col < q.col)
+}
+
+// textSelection is a click-drag selection in the conversation panel
+type textSelection struct {
+	dragging     bool
+	anchor, head textPos
+}
+
+// empty reports whether nothing is selected
+func (s textSelection) empty() bool {
+	return s.anchor == s.head
+}
+
+// bounds returns the selection start and end in reading order
+func (s textSelection) bounds() (textPos, textPos) {
+	if s.head.before(s.anchor) {
+		return s.head, s.anchor
+	}
+	return s.anchor, s.head
+}
+
+// handleMouse scrolls the conversation with the wheel, focuses the panel
+// that was clicked and selects conversation text by dragging
+func (a *Application) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if a.state != StateMain || a.linear || len(a.dialogs) > 0 {
+		return a, nil
+	}
+	layout := a.mouseLayout
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		for i := 0; i < wheelLines; i++ {
+			a.scrollUp()
+		}
+	case msg.Button == tea.MouseButtonWheelDown:
+		for i := 0; i < wheelLines; i++ {
+			a.scrollDown()
+		}
+
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+		switch {
+		case msg.Y >= layout.inputTop:
+			// Focus the input and type at the end of it
+			a.selection = textSelection{}
+			a.inputActive = true
+			a.inputMode = InputModeInsert
+			a.cursorPos = len(a.inputBuffer)
+		case a.follow.Unseen() > 0 && msg.Y == layout.convTop+layout.convRows && msg.X < layout.sideLeft:
+			// The "new messages" pill
+			a.jumpToNewMessages()
+		case msg.X < layout.sideLeft:
+			// Focus the conversation, keeping whatever was typed
+			a.inputActive = false
+			a.inputMode = InputModeNormal
+			pos := a.textPosAt(msg.X, msg.Y)
+			a.selection = textSelection{dragging: true, anchor: pos, head: pos}
+		default:
+			a.inputActive = false
+			a.inputMode = InputModeNormal
+			a.selection = textSelection{}
+		}
+
+	case msg.Action == tea.MouseActionMotion:
+		if !a.selection.dragging {
+			return a, nil
+		}
+		// Dragging past the panel edges scrolls the conversation
+		if msg.Y < layout.convTop {
+			a.scrollUp()
+		} else if msg.Y >= layout.convTop+layout.convRows {
+			a.scrollDown()
+		}
+		a.selection.head = a.textPosAt(msg.X, msg.Y)
+
+	case msg.Action == tea.MouseActionRelease:
+		if !a.selection.dragging {
+			return a, nil
+		}
+		a.selection.dragging = false
+		if a.selection.empty() {
+			a.selection = textSelection{}
+			return a, nil
+		}
+		text := a.selectedText()
+		clipboard.CopyWithFallback(text)
+		a.notify(toastSuccess, fmt.Sprintf("Copied %d characters", len([]rune(text))))
+	}
+	return a, nil
+}
+
+// textPosAt maps screen coordinates to a position in the conversation,
+// clamped to the visible lines
+func (a *Application) textPosAt(x, y int) textPos {
+	layout := a.mouseLayout
+	row := min(max(y-layout.convTop, 0), max(layout.convRows-1, 0))
+	col := min(max(x-layout.convLeft, 0), layout.convWidth)
+	return textPos{line: a.viewport.YOffset + row, col: col}
+}
+
+// selectedText returns the selected conversation text without styling
+func (a *Application) selectedText() string {
+	lines, _ := a.conversationLines(a.mouseLayout.convWidth)
+	start, end := a.selection.bounds()
+
+	var selected []string
+	for i := start.line; i <= end.line && i < len(lines); i++ {
+		plain := ansi.Strip(lines[i])
+		from, to := 0, ansi.StringWidth(plain)
+		if i == start.line {
+			from = start.col
+		}
+		if i == end.line {
+			to = min(to, end.col)
+		}
+		if from < to {
+			selected = append(selected, strings.TrimRight(ansi.Cut(plain, from, to), " "))
+		} else {
+			selected = append(selected, "")
+		}
+	}
+	return strings.Join(selected, "\n")
+}
+
+// highlightSelection renders the selected part of the conversation lines in
+// reverse video. The selected lines lose their own styling.
+func (a *Application) highlightSelection(lines []string) []string {
+	if a.selection.empty() {
+		return lines
+	}
+	start, end := a.selection.bounds()
+
+	highlighted := make([]string, len(lines))
+	copy(highlighted, lines)
+	for i := max(start.line, 0); i <= end.line && i < len(lines); i++ {
+		plain := ansi.Strip(lines[i])
+		width := ansi.StringWidth(plain)
+		from, to := 0, width
+		if i == start.line {
+			from = min(start.col, width)
+		}
+		if i == end.line {
+			to = min(end.col, width)
+		}
+		if from >= to {
+			highlighted[i] = plain
+			continue
+		}
+		highlighted[i] = ansi.Cut(plain, 0, from) +
+			a.styles.Selection.Render(ansi.Cut(plain, from, to)) +
+			ansi.Cut(plain, to, width)
+	}
+	return highlighted
+}
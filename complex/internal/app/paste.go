@@ -0,0 +1,51 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// normalizeNewlines turns the CRLF and CR line breaks terminals paste into
+// LF
+func normalizeNewlines(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+}
+
+// singleLinePaste joins the lines of a paste for fields of one line, such as
+// dialogs and the search prompt
+func singleLinePaste(msg tea.KeyMsg) tea.KeyMsg {
+	lines := strings.Split(strings.TrimSpace(normalizeNewlines(string(msg.Runes))), "\n")
+	msg.Runes = []rune(strings.Join(lines, " "))
+	return msg
+}
+
+// pasteInput inserts bracketed-paste text into the input verbatim, focusing
+// it if needed. Text of several lines starts compose mode, so its line
+// breaks are kept rather than sending the prompt.
+func (a *Application) pasteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.isLoading {
+		a.statusMessage = "[paste] Wait for the running command to finish"
+		return a, nil
+	}
+	text := normalizeNewlines(string(msg.Runes))
+	if text == "" {
+		return a, nil
+	}
+
+	if !a.inputActive {
+		a.inputActive = true
+		a.cursorPos = len(a.inputBuffer)
+	}
+	if a.inputMode != InputModeInsert && a.inputBuffer != "" {
+		// Paste after the cursor, as p does
+		a.cursorPos = min(len(a.inputBuffer), a.cursorPos+1)
+	}
+	a.inputMode = InputModeInsert
+	a.commandBuffer = ""
+	a.insertChar(text)
+	if strings.Contains(text, "\n") {
+		a.compose.active = true
+	}
+	return a, nil
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+	"customclaude/templates"
+)
+
+// promptPreview is the prompt being typed, expanded as it will be sent
+type promptPreview struct {
+	preview      claude.PromptPreview
+	template     string   // the /t template the input expands to
+	placeholders []string // asked for when the template is sent
+	command      bool     // a slash command, run here rather than sent
+	loading      bool
+	scroll       int
+}
+
+// PromptPreviewedMsg carries the expanded prompt for the preview
+type PromptPreviewedMsg struct {
+	Preview      claude.PromptPreview
+	Template     string
+	Placeholders []string
+}
+
+// openPromptPreview expands the input in the background and shows it
+func (a *Application) openPromptPreview() (tea.Model, tea.Cmd) {
+	typed := strings.TrimSpace(a.inputBuffer)
+	if typed == "" {
+		a.statusMessage = "[preview] Nothing to preview"
+		return a, nil
+	}
+	if a.isLoading {
+		a.statusMessage = "[preview] Wait for the running command to finish"
+		return a, nil
+	}
+
+	a.state = StatePromptPreview
+	a.preview = promptPreview{loading: true}
+	prompt, template, placeholders := typed, "", []string(nil)
+	if fields := strings.Fields(typed); fields[0] == "/t" && len(fields) > 1 {
+		list, _, ok := a.loadTemplates()
+		if t, found := templates.Find(list, fields[1]); ok && found {
+			prompt = strings.TrimSpace(t.Expand(nil))
+			template, placeholders = t.Name, t.Placeholders()
+		}
+	}
+	if strings.HasPrefix(prompt, "/") {
+		a.preview = promptPreview{command: true, preview: claude.PromptPreview{Typed: typed}}
+		return a, nil
+	}
+
+	sessionManager := a.sessionManager
+	ctx := a.ctx
+	tabID := a.tabs[a.activeTab].id
+	return a, func() tea.Msg {
+		return TabMsg{TabID: tabID, Msg: PromptPreviewedMsg{
+			Preview:      sessionManager.PreviewPrompt(ctx, prompt),
+			Template:     template,
+			Placeholders: placeholders,
+		}}
+	}
+}
+
+// handlePromptPreviewed shows an expanded prompt if the preview is still open
+func (a *Application) handlePromptPreviewed(msg PromptPreviewedMsg) (tea.Model, tea.Cmd) {
+	if a.state != StatePromptPreview || !a.preview.loading {
+		return a, nil
+	}
+	a.preview = promptPreview{
+		preview:      msg.Preview,
+		template:     msg.Template,
+		placeholders: msg.Placeholders,
+	}
+	return a, nil
+}
+
+// previewRows is the number of lines the preview can show
+func (a *Application) previewRows() int {
+	return max(5, a.height-10)
+}
+
+// handlePromptPreviewKeyPress sends, edits or discards the previewed prompt
+func (a *Application) handlePromptPreviewKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		a.preview.scroll--
+	case "down", "j":
+		a.preview.scroll++
+	case "pgup":
+		a.preview.scroll -= a.previewRows()
+	case "pgdown", " ":
+		a.preview.scroll += a.previewRows()
+	case "home", "g":
+		a.preview.scroll = 0
+	case "enter", "ctrl+s":
+		a.state = StateMain
+		return a, a.submitInput()
+	case "esc", "e", "ctrl+p":
+		// Back to editing
+		a.state = StateMain
+		a.inputActive = true
+	case "x":
+		// Discard the prompt; u brings it back
+		a.vim.undo = append(a.vim.undo, inputSnapshot{a.inputBuffer, a.cursorPos})
+		a.inputBuffer = ""
+		a.cursorPos = 0
+		a.inputActive = false
+		a.inputMode = InputModeNormal
+		a.compose = composeView{}
+		a.state = StateMain
+		a.statusMessage = "[preview] Prompt discarded"
+	case "ctrl+c":
+		return a, tea.Quit
+	}
+	a.preview.scroll = max(0, a.preview.scroll)
+	return a, nil
+}
+
+// renderPromptPreviewView renders the expanded prompt, the files claude
+// will read and the token estimate
+func (a *Application) renderPromptPreviewView() string {
+	view := &a.preview
+	preview := view.preview
+	content := []string{
+		a.styles.Header.Render("CustomClaude TUI - Prompt Preview"),
+		"",
+	}
+
+	var body []string
+	switch {
+	case view.loading:
+		content = append(content, a.styles.Status.Render("Expanding the prompt..."))
+	case view.command:
+		content = append(content, a.styles.Status.Render("A slash command: it runs here and sends nothing to Claude."))
+		body = strings.Split(preview.Typed, "\n")
+	default:
+		forecast := preview.Forecast
+		content = append(content, a.styles.Highlight.Render(fmt.Sprintf(
+			"~%s prompt tokens, %s with the conversation, ~$%s–$%s",
+			formatTokens(forecast.PromptTokens), formatTokens(forecast.PromptTokens+forecast.ContextTokens),
+			formatCost(forecast.Low), formatCost(forecast.High))))
+
+		var notes []string
+		if view.template != "" {
+			note := "Template " + view.template
+			if len(view.placeholders) > 0 {
+				note += fmt.Sprintf("; asks for %s when sent", strings.Join(view.placeholders, ", "))
+			}
+			notes = append(notes, note)
+		}
+		if preview.DiffContext {
+			notes = append(notes, "Working tree changes since the last turn are prepended")
+		}
+		if preview.SystemPrompt.Text != "" {
+			source := preview.SystemPrompt.Source
+			if source == "" {
+				source = "set this session"
+			}
+			notes = append(notes, fmt.Sprintf("System prompt appended (%s)", source))
+		}
+		if len(notes) > 0 {
+			content = append(content, a.styles.Status.Render(strings.Join(notes, " | ")))
+		}
+
+		width := max(20, a.width-8)
+		body = append(body, a.styles.Highlight.Render("Prompt as sent:"))
+		body = append(body, strings.Split(a.wrapContent(preview.Prompt, width), "\n")...)
+		for _, file := range preview.Files {
+			body = append(body, "", a.styles.Highlight.Render(fmt.Sprintf("@%s (read by Claude):", file.Path)))
+			if file.Err != nil {
+				body = append(body, a.styles.Error.Render(fmt.Sprintf("  cannot read: %v", file.Err)))
+				continue
+			}
+			for i, line := range a.highlighter.Highlight(file.Content, file.Path) {
+				gutter := a.styles.Status.Render(fmt.Sprintf("%4d  ", i+1))
+				body = append(body, gutter+ansi.Truncate(line, width-6, "…"))
+			}
+			if file.Truncated {
+				body = append(body, a.styles.Status.Render("  ... rest of the file not shown"))
+			}
+		}
+	}
+
+	rows := a.previewRows()
+	view.scroll = min(view.scroll, max(0, len(body)-rows))
+	end := min(len(body), view.scroll+rows)
+	content = append(content, "")
+	content = append(content, body[view.scroll:end]...)
+	if end < len(body) {
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("  ... %d more lines", len(body)-end)))
+	}
+
+	footer := "Enter: Send | Esc/e: Edit | x: Discard | ↑/↓ or j/k: Scroll | PgUp/PgDn: Page"
+	content = append(content, "", footer)
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// handleProfileCommand runs "/profile [<name>|none|auto]": without an
+// argument it lists the profiles, otherwise it switches this tab to one,
+// drops the active one, or goes back to the one the project picks
+func (a *Application) handleProfileCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		a.showProfiles()
+		return a, nil
+	}
+	if a.isLoading {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "profile", Message: "Wait for the current prompt to finish before switching profile"}
+		}
+	}
+
+	var err error
+	switch name := args[0]; name {
+	case "auto":
+		err = a.sessionManager.DetectProfile()
+	case "none":
+		err = a.sessionManager.UseProfile("")
+	default:
+		err = a.sessionManager.UseProfile(name)
+	}
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "profile"}
+		}
+	}
+
+	message := "No profile active; the configured settings apply from the next prompt"
+	if active := a.sessionManager.ActiveProfile(); active != nil {
+		message = fmt.Sprintf("Profile %s active (%s); it applies from the next prompt", active.Name, active.Profile.Summary())
+	}
+	return a, func() tea.Msg {
+		return StatusMsg{Status: "profile", Message: message}
+	}
+}
+
+// showProfiles adds the configured profiles, with the active one marked, to
+// the conversation
+func (a *Application) showProfiles() {
+	active := a.sessionManager.ActiveProfile()
+	lines := []string{"Profiles (/profile <name> switches this tab, /profile none drops it, /profile auto follows the project):", ""}
+	names := a.sessionManager.ProfileNames()
+	for _, name := range names {
+		profile, _ := a.sessionManager.Profile(name)
+		marker := "  "
+		if active != nil && active.Name == name {
+			marker = "▶ "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s - %s", marker, name, profile.Summary()))
+	}
+	if len(names) == 0 {
+		lines = append(lines, "No profiles are configured; add [profiles.<name>] tables to config.toml.")
+	}
+
+	lines = append(lines, "")
+	switch {
+	case active == nil:
+		lines = append(lines, fmt.Sprintf("No profile is active. A %s file in the project picks one.", claude.ProjectProfileFile))
+	case active.Source != "":
+		lines = append(lines, fmt.Sprintf("Active: %s (%s), picked by %s", active.Name, active.Profile.Summary(), shortenHome(active.Source)))
+	default:
+		lines = append(lines, fmt.Sprintf("Active: %s (%s), chosen with /profile", active.Name, active.Profile.Summary()))
+	}
+
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("profile_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   strings.Join(lines, "\n"),
+		Timestamp: time.Now(),
+	})
+	a.scrollToBottomSafe()
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxQuickReplies is how many replies get a key, 1 to 3
+const maxQuickReplies = 3
+
+// offerQuickReplies shows the configured follow-ups after an assistant turn
+func (a *Application) offerQuickReplies() {
+	a.quickReplies = nil
+	if !a.config.QuickReplies.Enabled {
+		return
+	}
+	for _, reply := range a.config.QuickReplies.Replies {
+		if reply = strings.TrimSpace(reply); reply != "" && len(a.quickReplies) < maxQuickReplies {
+			a.quickReplies = append(a.quickReplies, reply)
+		}
+	}
+}
+
+// quickReplyKey inserts the reply bound to key into the input line. It
+// reports false when key does not pick an offered reply.
+func (a *Application) quickReplyKey(key string) bool {
+	if a.inputActive || len(key) != 1 || key[0] < '1' || key[0] > '9' {
+		return false
+	}
+	index := int(key[0] - '1')
+	if index >= len(a.quickReplies) {
+		return false
+	}
+
+	reply := a.quickReplies[index]
+	a.quickReplies = nil
+	a.inputBuffer = reply
+	a.inputActive = true
+	a.inputMode = InputModeInsert
+	a.cursorPos = len(a.inputBuffer)
+	a.statusMessage = ""
+	return true
+}
+
+// quickRepliesLabel lists the offered replies with their keys
+func (a *Application) quickRepliesLabel() string {
+	parts := make([]string, 0, len(a.quickReplies))
+	for i, reply := range a.quickReplies {
+		parts = append(parts, fmt.Sprintf("%d: %s", i+1, reply))
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// rawLineLimit bounds the stream lines kept per tab for the raw stream view
+const rawLineLimit = 2000
+
+// rawStreamDumpTemplate names raw stream dumps; it takes the placeholders of
+// export path templates
+const rawStreamDumpTemplate = "debug/{date}-{time}-{session_id}-stream.jsonl"
+
+// rawStreamView holds the display settings of the raw stream view (Ctrl+D)
+type rawStreamView struct {
+	pretty bool
+	filter string // message type shown, empty for all
+	scroll int
+	follow bool // keep the newest line in view
+}
+
+// noteRawLine keeps a stream line in read order. Lines arrive on separate
+// goroutines, so a late one is moved back to its place.
+func (a *Application) noteRawLine(line claude.RawLine) {
+	a.rawLines = append(a.rawLines, line)
+	for i := len(a.rawLines) - 1; i > 0 && a.rawLines[i].Seq < a.rawLines[i-1].Seq; i-- {
+		a.rawLines[i], a.rawLines[i-1] = a.rawLines[i-1], a.rawLines[i]
+	}
+	if len(a.rawLines) > rawLineLimit {
+		a.rawLines = a.rawLines[len(a.rawLines)-rawLineLimit:]
+	}
+}
+
+// openRawStreamView shows the raw stream, following new lines
+func (a *Application) openRawStreamView() (tea.Model, tea.Cmd) {
+	a.state = StateRawStream
+	a.rawView.follow = true
+	return a, nil
+}
+
+// rawLineTypes returns the message types seen in the stream, in order of
+// first appearance
+func (a *Application) rawLineTypes() []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, line := range a.rawLines {
+		if !seen[line.Type] {
+			seen[line.Type] = true
+			types = append(types, line.Type)
+		}
+	}
+	return types
+}
+
+// filteredRawLines returns the stream lines passing the type filter
+func (a *Application) filteredRawLines() []claude.RawLine {
+	if a.rawView.filter == "" {
+		return a.rawLines
+	}
+	var lines []claude.RawLine
+	for _, line := range a.rawLines {
+		if line.Type == a.rawView.filter {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// cycleRawFilter moves the type filter to the next (dir 1) or previous
+// (dir -1) message type, passing through "all"
+func (a *Application) cycleRawFilter(dir int) {
+	filters := append([]string{""}, a.rawLineTypes()...)
+	current := 0
+	for i, filter := range filters {
+		if filter == a.rawView.filter {
+			current = i
+		}
+	}
+	a.rawView.filter = filters[(current+dir+len(filters))%len(filters)]
+	a.rawView.follow = true
+}
+
+// handleRawStreamKeyPress handles scrolling, filtering and dumping in the
+// raw stream view
+func (a *Application) handleRawStreamKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	view := &a.rawView
+	page := a.rawStreamRows()
+
+	switch msg.String() {
+	case "up", "k":
+		view.scroll--
+		view.follow = false
+	case "down", "j":
+		view.scroll++
+	case "pgup":
+		view.scroll -= page
+		view.follow = false
+	case "pgdown":
+		view.scroll += page
+	case "home", "g":
+		view.scroll = 0
+		view.follow = false
+	case "end", "G":
+		view.follow = true
+	case "p":
+		view.pretty = !view.pretty
+	case "t":
+		a.cycleRawFilter(1)
+	case "T":
+		a.cycleRawFilter(-1)
+	case "c":
+		a.rawLines = nil
+		view.follow = true
+	case "w":
+		a.promptRawStreamDump()
+	case "esc", "q", "ctrl+d", "ctrl+m":
+		a.state = StateMain
+	case "ctrl+c":
+		return a, tea.Quit
+	}
+	return a, nil
+}
+
+// promptRawStreamDump asks where to write the shown stream lines
+func (a *Application) promptRawStreamDump() {
+	lines := a.filteredRawLines()
+	if len(lines) == 0 {
+		a.statusMessage = "[stream] No stream lines to write"
+		return
+	}
+	d := newInputDialog(
+		"Write raw stream",
+		[]string{fmt.Sprintf("Write %d stream lines, one JSON object per line.", len(lines))},
+		"Path: ", a.sessionManager.ExpandExportPath(rawStreamDumpTemplate),
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.cancelled {
+				return a, nil
+			}
+			path := strings.TrimSpace(result.input)
+			if err := writeRawLines(path, lines); err != nil {
+				a.errors = append(a.errors, ErrorMsg{Error: err, Context: "stream", Timestamp: time.Now()})
+				return a, nil
+			}
+			a.notify(toastSuccess, fmt.Sprintf("Wrote %d stream lines to %s", len(lines), path))
+			return a, nil
+		},
+	)
+	d.accept = func(text string) bool { return strings.TrimSpace(text) != "" }
+	a.openDialog(d)
+}
+
+// writeRawLines writes stream lines to a file exactly as they were read
+func writeRawLines(path string, lines []claude.RawLine) error {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line.Line)
+		b.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create stream dump directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write stream dump: %w", err)
+	}
+	return nil
+}
+
+// rawStreamRows is the number of lines the raw stream view can show
+func (a *Application) rawStreamRows() int {
+	return max(5, a.height-9)
+}
+
+// renderRawStreamView renders the stream lines of the active tab
+func (a *Application) renderRawStreamView() string {
+	width := max(20, a.width-4)
+
+	var body []string
+	for _, line := range a.filteredRawLines() {
+		body = append(body, a.styles.Status.Render(fmt.Sprintf("#%d %s %s",
+			line.Seq, line.Received.Local().Format("15:04:05.000"), line.Type)))
+		if !a.rawView.pretty {
+			body = append(body, truncateString(line.Line, width))
+			continue
+		}
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(line.Line), "", "  "); err != nil {
+			body = append(body, truncateString(line.Line, width))
+			continue
+		}
+		for _, text := range strings.Split(pretty.String(), "\n") {
+			body = append(body, truncateString(text, width))
+		}
+	}
+
+	rows := a.rawStreamRows()
+	view := &a.rawView
+	bottom := max(0, len(body)-rows)
+	if view.follow || view.scroll > bottom {
+		view.scroll = bottom
+	}
+	view.scroll = max(0, view.scroll)
+	if view.scroll == bottom {
+		view.follow = true
+	}
+	end := min(len(body), view.scroll+rows)
+
+	filter := view.filter
+	if filter == "" {
+		filter = "all"
+	}
+	pretty := "off"
+	if view.pretty {
+		pretty = "on"
+	}
+	status := fmt.Sprintf("%d lines | Type: %s | Pretty: %s", len(a.rawLines), filter, pretty)
+	if view.follow {
+		status += " | Following"
+	}
+
+	content := []string{
+		a.styles.Header.Render("CustomClaude TUI - Raw Stream"),
+		a.styles.Status.Render(status),
+		"",
+	}
+	if len(body) == 0 {
+		content = append(content, a.styles.Status.Render("  No stream lines yet; they appear as claude writes them."))
+	} else {
+		content = append(content, body[view.scroll:end]...)
+	}
+	content = append(content,
+		"",
+		"↑/↓ or j/k: Scroll | g/G: Top/Follow | p: Pretty-print | t/T: Filter by type | w: Write to file | c: Clear | Esc: Back",
+	)
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
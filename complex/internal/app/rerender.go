@@ -0,0 +1,158 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+	"complex/internal/ui/components"
+)
+
+// renderMode is how a single message is rendered instead of the default
+type renderMode int
+
+const (
+	// renderRaw shows the exact text, unstyled, only broken to fit
+	renderRaw renderMode = iota
+	// renderPlain wraps the text without Markdown
+	renderPlain
+	// renderWide renders Markdown at the full panel width, ignoring word_wrap
+	renderWide
+	// renderThinking shows the extended thinking above the reply
+	renderThinking
+)
+
+// openRerenderDialog asks how to re-render the selected message
+func (a *Application) openRerenderDialog() (tea.Model, tea.Cmd) {
+	if !a.messageSelected() {
+		a.statusMessage = "[view] Select a message with [ or ] first"
+		return a, nil
+	}
+	msg := a.messages[a.selectedMessage]
+
+	options := []dialogOption{
+		{"d", "Default"},
+		{"r", "Raw: exact text, no styling"},
+		{"p", "Plain: wrapped, no Markdown"},
+		{"w", "Wide: Markdown at the full panel width"},
+	}
+	if msg.Thinking != "" {
+		options = append(options, dialogOption{"t", "With thinking shown"})
+	}
+	modes := map[string]renderMode{"r": renderRaw, "p": renderPlain, "w": renderWide, "t": renderThinking}
+
+	d := newChoiceDialog("Re-render message", []string{truncateString(msg.Content, 120)}, options,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.cancelled {
+				return a, nil
+			}
+			if mode, ok := modes[result.choice]; ok {
+				if a.renderModes == nil {
+					a.renderModes = make(map[string]renderMode)
+				}
+				a.renderModes[msg.ID] = mode
+			} else {
+				delete(a.renderModes, msg.ID)
+			}
+			a.invalidateLayout()
+			return a, nil
+		})
+	a.openDialog(d)
+	return a, nil
+}
+
+// renderMessageAs renders a message in a mode picked with openRerenderDialog
+func (a *Application) renderMessageAs(msg claude.ConversationMessage, content string, mode renderMode, msgWidth int) string {
+	switch mode {
+	case renderRaw:
+		return ansi.Hardwrap(msg.Content, max(1, msgWidth), true)
+
+	case renderPlain:
+		return a.styles.Message.Render(messagePrefix(msg) + a.wrapContent(content, msgWidth-4))
+
+	case renderWide:
+		if msg.Type != "assistant" {
+			break
+		}
+		renderer, err := a.wideMarkdown(msgWidth - 3)
+		if err != nil {
+			break
+		}
+		rendered, err := renderer.Render(content)
+		if err != nil {
+			break
+		}
+		lines := strings.Split(strings.TrimSpace(rendered), "\n")
+		for j := range lines {
+			if j == 0 {
+				lines[j] = "🤖 " + lines[j]
+			} else {
+				lines[j] = "   " + lines[j]
+			}
+		}
+		return strings.Join(lines, "\n")
+
+	case renderThinking:
+		if msg.Thinking != "" {
+			thinking := a.styles.Status.Render("💭 " + wrapMessage(msg.Thinking, msgWidth-4))
+			return thinking + "\n" + a.formatMessage(msg, content, msgWidth)
+		}
+	}
+	return a.formatMessage(msg, content, msgWidth)
+}
+
+// wideMarkdown returns a Markdown renderer for the given width, kept
+// between frames
+func (a *Application) wideMarkdown(width int) (*components.MarkdownRenderer, error) {
+	if a.wideRenderer == nil {
+		theme := a.config.Theme
+		if theme == "" {
+			theme = "dark"
+		}
+		renderer, err := components.NewMarkdownRendererWithStyle(width, theme)
+		if err != nil {
+			return nil, err
+		}
+		a.wideRenderer = renderer
+	}
+	return a.wideRenderer, a.wideRenderer.UpdateWidth(width)
+}
+
+// assistantMarkdown returns the renderer for assistant text that must fit
+// in width columns: the shared one unless it wraps wider, otherwise one of
+// that width, kept between frames. It returns nil when none can be made.
+func (a *Application) assistantMarkdown(width int) *components.MarkdownRenderer {
+	if a.markdownRenderer == nil || a.markdownRenderer.Width() <= width || width < 1 {
+		return a.markdownRenderer
+	}
+	if renderer, ok := a.fitRenderers[width]; ok {
+		return renderer
+	}
+	renderer, err := components.NewMarkdownRendererWithStyle(width, a.markdownRenderer.Style())
+	if err != nil {
+		return nil
+	}
+	if a.fitRenderers == nil {
+		a.fitRenderers = make(map[int]*components.MarkdownRenderer)
+	}
+	a.fitRenderers[width] = renderer
+	return renderer
+}
+
+// messagePrefix is the icon a message type is shown with
+func messagePrefix(msg claude.ConversationMessage) string {
+	switch msg.Type {
+	case "assistant":
+		return "🤖 "
+	case "user":
+		return "👤 "
+	case "tool_use":
+		return "🔧 "
+	case "warning":
+		return "⚠️  "
+	default:
+		return "ℹ️  "
+	}
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// resizeDebounce is how long the terminal size must hold still before the
+// layout follows it; dragging a window edge sends a storm of sizes
+const resizeDebounce = 80 * time.Millisecond
+
+// resizeSettledMsg applies the last size seen once resizing pauses
+type resizeSettledMsg struct {
+	seq int
+}
+
+// renderedMessage is a message formatted for the conversation panel and
+// split into lines, reused until the message, how it is shown, or the panel
+// width changes
+type renderedMessage struct {
+	key   string
+	lines []string
+}
+
+// scrollAnchor is the message the conversation panel keeps in place across
+// a resize
+type scrollAnchor struct {
+	follow bool // at the bottom, staying there
+	index  int  // anchored message, -1 for none
+	top    bool // the selected message, kept at the top
+	rows   int  // otherwise its distance from its first line to the bottom row
+}
+
+// handleResize defers a new terminal size until resizing pauses. The first
+// size is applied at once so the initial frame is laid out.
+func (a *Application) handleResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
+	if a.width == 0 || a.height == 0 {
+		a.applyResize(msg)
+		return a, nil
+	}
+	a.pendingResize = msg
+	a.resizeSeq++
+	seq := a.resizeSeq
+	return a, tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+		return resizeSettledMsg{seq: seq}
+	})
+}
+
+// handleResizeSettled applies the pending size unless a newer one arrived
+func (a *Application) handleResizeSettled(msg resizeSettledMsg) (tea.Model, tea.Cmd) {
+	if msg.seq == a.resizeSeq {
+		a.applyResize(a.pendingResize)
+	}
+	return a, nil
+}
+
+// applyResize lays the view out for a new size. Only a width change rewraps
+// messages, and the scroll position follows the selected or last visible
+// message rather than a line number.
+func (a *Application) applyResize(size tea.WindowSizeMsg) {
+	if size.Width == a.width && size.Height == a.height {
+		return
+	}
+	anchor := a.scrollAnchor()
+	widthChanged := size.Width != a.width
+	a.width = size.Width
+	a.height = size.Height
+	// Rewrapping moves the text out from under the selection
+	a.selection = textSelection{}
+
+	if widthChanged {
+		a.rewrap()
+	}
+	a.restoreScrollAnchor(anchor)
+}
+
+// rewrap drops the formatted messages after the conversation panel or the
+// wrap width changed and wraps the markdown renderer to the new width
+func (a *Application) rewrap() {
+	a.renderCache = nil
+	a.fitRenderers = nil
+	a.invalidateLayout()
+	// Update markdown renderer width using layout manager constraints
+	if a.markdownRenderer != nil {
+		lm := a.layout()
+		constraints := lm.GetConversationConstraints()
+		contentWidth := constraints.ConversationWidth - 4 // account for message prefix/padding
+		if a.config.WordWrap > 0 && contentWidth > a.config.WordWrap {
+			contentWidth = a.config.WordWrap
+		}
+		if contentWidth > 20 {
+			a.markdownRenderer.UpdateWidth(contentWidth)
+		}
+	}
+}
+
+// scrollAnchor records what the conversation panel shows at the current size
+func (a *Application) scrollAnchor() scrollAnchor {
+	if a.width == 0 || a.height == 0 || len(a.messages) == 0 {
+		return scrollAnchor{index: -1}
+	}
+	if a.messageSelected() {
+		return scrollAnchor{index: a.selectedMessage, top: true}
+	}
+	offsets := a.syncViewport()
+	if a.viewport.AtBottom() {
+		return scrollAnchor{follow: true, index: -1}
+	}
+
+	bottom := a.viewport.YOffset + a.viewport.Height - 1
+	index := 0
+	for i, offset := range offsets {
+		if offset <= bottom {
+			index = i
+		}
+	}
+	return scrollAnchor{index: index, rows: bottom - offsets[index]}
+}
+
+// restoreScrollAnchor scrolls back to the anchored message after a resize
+func (a *Application) restoreScrollAnchor(anchor scrollAnchor) {
+	switch {
+	case anchor.follow:
+		a.scrollToBottomSafe()
+		return
+	case anchor.index < 0 || anchor.index >= len(a.messages):
+		a.clampScrollPosition()
+		return
+	}
+
+	offsets := a.syncViewport()
+	if anchor.top {
+		a.viewport.SetYOffset(offsets[anchor.index])
+	} else {
+		a.viewport.SetYOffset(offsets[anchor.index] + anchor.rows - a.viewport.Height + 1)
+	}
+}
+
+// renderMessageCached formats a message for the conversation panel, reusing
+// the last result while nothing it depends on has changed
+func (a *Application) renderMessageCached(msg claude.ConversationMessage, content string, msgWidth int, next map[string]renderedMessage) []string {
+	mode, hasMode := a.renderModes[msg.ID]
+	key := fmt.Sprintf("%d|%v%d|%t|%t|%s|%t|%d|%d\x00%s\x00%s\x00%s",
+		msgWidth, hasMode, mode,
+		msg.ToolUseID != "" && msg.ToolUseID == a.selectedTool, a.expandedTools[msg.ToolUseID],
+		msg.ToolStatus, msg.IsError, len(msg.ToolResult), len(msg.Thinking),
+		content, msg.Diff, msg.Content)
+	if cached, ok := a.renderCache[msg.ID]; ok && cached.key == key && msg.ID != "" {
+		next[msg.ID] = cached
+		return cached.lines
+	}
+
+	lines := strings.Split(a.renderBoundary("message "+msg.ID, msgWidth, func() string {
+		if hasMode {
+			return a.renderMessageAs(msg, content, mode, msgWidth)
+		}
+		return a.formatMessage(msg, content, msgWidth)
+	}), "\n")
+	if msg.ID != "" {
+		next[msg.ID] = renderedMessage{key: key, lines: lines}
+	}
+	return lines
+}
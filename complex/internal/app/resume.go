@@ -0,0 +1,410 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// resumeSort orders the conversations in the resume picker
+type resumeSort int
+
+const (
+	resumeSortDate resumeSort = iota
+	resumeSortCost
+	resumeSortSize
+)
+
+// String names the sort order for the picker header
+func (s resumeSort) String() string {
+	switch s {
+	case resumeSortCost:
+		return "cost"
+	case resumeSortSize:
+		return "size"
+	default:
+		return "date"
+	}
+}
+
+// resumePicker holds the state of the /resume conversation picker
+type resumePicker struct {
+	records  []claude.SessionRecord // all stored conversations
+	visible  []claude.SessionRecord // filtered and sorted for display
+	selected int
+	marked   map[string]bool
+	sortBy   resumeSort
+
+	showArchived bool
+	status       string
+}
+
+// openResumePicker loads stored conversations and switches to the picker
+func (a *Application) openResumePicker() (tea.Model, tea.Cmd) {
+	records, err := a.sessionManager.ListConversations()
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "resume"}
+		}
+	}
+	if len(records) == 0 {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "resume", Message: "No saved conversations"}
+		}
+	}
+
+	a.resume = resumePicker{records: records, marked: make(map[string]bool)}
+	a.resume.refresh()
+	a.state = StateResume
+	return a, nil
+}
+
+// refresh rebuilds the visible list from the loaded records
+func (p *resumePicker) refresh() {
+	p.visible = p.visible[:0]
+	for _, record := range p.records {
+		if record.Archived && !p.showArchived {
+			continue
+		}
+		p.visible = append(p.visible, record)
+	}
+
+	sort.SliceStable(p.visible, func(i, j int) bool {
+		a, b := p.visible[i], p.visible[j]
+		switch p.sortBy {
+		case resumeSortCost:
+			return a.Stats.CumulativeCost > b.Stats.CumulativeCost
+		case resumeSortSize:
+			return claude.RecordSize(a) > claude.RecordSize(b)
+		default:
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+	})
+
+	if p.selected >= len(p.visible) {
+		p.selected = max(0, len(p.visible)-1)
+	}
+}
+
+// targets returns the IDs of the marked conversations, or of the selected
+// one when none are marked
+func (p *resumePicker) targets() []string {
+	var ids []string
+	for _, record := range p.visible {
+		if p.marked[record.ID] {
+			ids = append(ids, record.ID)
+		}
+	}
+	if len(ids) == 0 && p.selected < len(p.visible) {
+		ids = append(ids, p.visible[p.selected].ID)
+	}
+	return ids
+}
+
+// reloadResumePicker re-reads the store after a bulk operation and clears
+// the marks
+func (a *Application) reloadResumePicker(status string) {
+	records, err := a.sessionManager.ListConversations()
+	if err != nil {
+		a.resume.status = err.Error()
+		return
+	}
+	a.resume.records = records
+	a.resume.marked = make(map[string]bool)
+	a.resume.status = status
+	a.resume.refresh()
+}
+
+// handleResumeKeyPress handles navigation and bulk operations inside the
+// resume picker
+func (a *Application) handleResumeKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &a.resume
+	p.status = ""
+	switch msg.String() {
+	case "up", "k":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down", "j":
+		if p.selected < len(p.visible)-1 {
+			p.selected++
+		}
+	case "pgup":
+		p.selected = max(0, p.selected-a.resumePageSize())
+	case "pgdown":
+		p.selected = max(0, min(len(p.visible)-1, p.selected+a.resumePageSize()))
+	case " ":
+		if p.selected < len(p.visible) {
+			id := p.visible[p.selected].ID
+			if p.marked[id] {
+				delete(p.marked, id)
+			} else {
+				p.marked[id] = true
+			}
+			if p.selected < len(p.visible)-1 {
+				p.selected++
+			}
+		}
+	case "*":
+		// Mark everything visible, or clear the marks if all are marked
+		all := len(p.marked) == len(p.visible)
+		p.marked = make(map[string]bool)
+		if !all {
+			for _, record := range p.visible {
+				p.marked[record.ID] = true
+			}
+		}
+	case "s":
+		p.sortBy = (p.sortBy + 1) % 3
+		p.refresh()
+	case "A":
+		p.showArchived = !p.showArchived
+		p.refresh()
+	case "d":
+		if len(p.visible) > 0 {
+			a.confirmResumeDelete()
+		}
+	case "a":
+		a.archiveResumeTargets()
+	case "e":
+		paths, err := a.sessionManager.ExportConversations(p.targets(), a.config.ExportPath)
+		if err != nil {
+			p.status = err.Error()
+		} else if len(paths) == 1 {
+			p.status = "Exported to " + paths[0]
+		} else {
+			p.status = fmt.Sprintf("Exported %d conversations to %s", len(paths), filepath.Dir(paths[0]))
+		}
+	case "t":
+		if len(p.visible) > 0 {
+			a.promptResumeTag()
+		}
+	case "esc", "q":
+		a.state = StateMain
+	case "ctrl+c":
+		return a, tea.Quit
+	case "enter":
+		if p.selected >= len(p.visible) {
+			return a, nil
+		}
+		record := p.visible[p.selected]
+		a.state = StateMain
+		return a.resumeConversation(record.ID)
+	}
+	return a, nil
+}
+
+// archiveResumeTargets archives the targeted conversations, or unarchives
+// them when all of them are archived already
+func (a *Application) archiveResumeTargets() {
+	ids := a.resume.targets()
+	archived := make(map[string]bool)
+	for _, record := range a.resume.records {
+		archived[record.ID] = record.Archived
+	}
+	archive := false
+	for _, id := range ids {
+		archive = archive || !archived[id]
+	}
+
+	n, err := a.sessionManager.ArchiveConversations(ids, archive)
+	status := fmt.Sprintf("Archived %d conversations", n)
+	if !archive {
+		status = fmt.Sprintf("Unarchived %d conversations", n)
+	}
+	if err != nil {
+		status = err.Error()
+	}
+	a.reloadResumePicker(status)
+}
+
+// confirmResumeDelete asks before deleting the targeted conversations
+func (a *Application) confirmResumeDelete() {
+	ids := a.resume.targets()
+	d := newConfirmDialog(
+		"Delete conversations",
+		[]string{fmt.Sprintf("Delete %d conversations? This cannot be undone.", len(ids))},
+		false,
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.choice != "y" {
+				a.resume.status = "Delete cancelled"
+				return a, nil
+			}
+			deleted, err := a.sessionManager.DeleteConversations(ids)
+			status := fmt.Sprintf("Deleted %d conversations", deleted)
+			if err != nil {
+				status = err.Error()
+			} else if deleted < len(ids) {
+				status += " (the active conversation was kept)"
+			}
+			a.reloadResumePicker(status)
+			return a, nil
+		},
+	)
+	d.danger = true
+	a.openDialog(d)
+}
+
+// promptResumeTag asks for a tag to add to the targeted conversations; a
+// leading "-" removes the tag instead
+func (a *Application) promptResumeTag() {
+	ids := a.resume.targets()
+	d := newInputDialog(
+		"Tag conversations",
+		[]string{fmt.Sprintf("Tag %d conversations (prefix - to remove).", len(ids))},
+		"#", "",
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.cancelled {
+				return a, nil
+			}
+			tag := strings.TrimSpace(result.input)
+			add := !strings.HasPrefix(tag, "-")
+			tag = strings.TrimPrefix(tag, "-")
+			n, err := a.sessionManager.TagConversations(ids, tag, add)
+			status := fmt.Sprintf("Tagged %d conversations with #%s", n, tag)
+			if !add {
+				status = fmt.Sprintf("Removed #%s from %d conversations", tag, n)
+			}
+			if err != nil {
+				status = err.Error()
+			}
+			a.reloadResumePicker(status)
+			return a, nil
+		},
+	)
+	// Tags are single words
+	d.allow = func(r rune) bool { return !unicode.IsSpace(r) }
+	d.accept = func(text string) bool { return strings.TrimPrefix(text, "-") != "" }
+	a.openDialog(d)
+}
+
+// resumePageSize is the number of conversations shown at once
+func (a *Application) resumePageSize() int {
+	return max(5, a.height-10)
+}
+
+// resumeConversation reattaches to a stored conversation and restores its
+// transcript in the conversation panel
+func (a *Application) resumeConversation(id string) (tea.Model, tea.Cmd) {
+	record, err := a.sessionManager.ResumeConversation(id)
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "resume"}
+		}
+	}
+
+	a.messages = append([]claude.ConversationMessage(nil), record.Messages...)
+	a.invalidateLayout()
+	a.currentSession = a.sessionManager.GetCurrentSession()
+	a.sessionStats = a.sessionManager.GetStats()
+	if holder, locked := a.sessionManager.SessionLockHolder(); locked {
+		a.messages = append(a.messages, claude.ConversationMessage{
+			ID:   fmt.Sprintf("lock_%s", record.ID),
+			Type: "warning",
+			Content: fmt.Sprintf("This session is in use by PID %d on %s. Prompts will fail until it exits or you /takeover.",
+				holder.PID, holder.Host),
+			Timestamp: time.Now(),
+		})
+	}
+	a.scrollToBottomSafe()
+
+	title := record.Title
+	if title == "" {
+		title = record.ID
+	}
+	return a, func() tea.Msg {
+		return StatusMsg{
+			Status:  "resume",
+			Message: fmt.Sprintf("Resumed: %s", title),
+		}
+	}
+}
+
+// renderResumeView renders the conversation picker
+func (a *Application) renderResumeView() string {
+	p := &a.resume
+	header := fmt.Sprintf("CustomClaude TUI - Resume Conversation (%d, by %s", len(p.visible), p.sortBy)
+	if p.showArchived {
+		header += ", with archived"
+	}
+	if len(p.marked) > 0 {
+		header += fmt.Sprintf(", %d marked", len(p.marked))
+	}
+	content := []string{
+		a.styles.Header.Render(header + ")"),
+		"",
+	}
+
+	if len(p.visible) == 0 {
+		content = append(content, a.styles.Status.Render("  No conversations (A shows archived ones)"))
+	}
+
+	// Keep the selection inside the window of rows that fits the screen
+	page := a.resumePageSize()
+	first := max(0, min(p.selected-page/2, len(p.visible)-page))
+	last := min(len(p.visible), first+page)
+
+	for i := first; i < last; i++ {
+		record := p.visible[i]
+		title := record.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		mark := "[ ]"
+		if p.marked[record.ID] {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf("%s %s  %-40s  %3d turns  $%-8.4f %7s",
+			mark,
+			record.UpdatedAt.Local().Format("2006-01-02 15:04"),
+			truncateString(title, 40),
+			record.Stats.CumulativeTurns,
+			record.Stats.CumulativeCost,
+			formatSize(claude.RecordSize(record)),
+		)
+		for _, tag := range record.Tags {
+			line += " #" + tag
+		}
+		if record.Archived {
+			line += " (archived)"
+		}
+		if i == p.selected {
+			content = append(content, a.styles.Highlight.Render("> "+line))
+		} else {
+			content = append(content, "  "+line)
+		}
+	}
+	if last < len(p.visible) {
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("  ... %d more", len(p.visible)-last)))
+	}
+
+	content = append(content, "")
+	if p.status != "" {
+		content = append(content, a.styles.Status.Render(p.status))
+	}
+
+	content = append(content,
+		"↑/↓ or j/k: Select | Space: Mark | *: Mark all | Enter: Resume | Esc: Cancel",
+		"d: Delete | a: Archive/unarchive | e: Export | t: Tag | s: Sort (date/cost/size) | A: Show archived",
+	)
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
+
+// formatSize renders a byte count with a binary unit
+func formatSize(bytes int) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(bytes)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", bytes)
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"complex/internal/claude"
+	"complex/internal/retry"
+)
+
+// offerRetry looks for recognizable failures in a finished turn and, if any,
+// announces follow-up prompts that "r" pre-fills
+func (a *Application) offerRetry(turn claude.TurnResult) {
+	runs := make([]retry.Run, 0, len(turn.ToolRuns))
+	for _, run := range turn.ToolRuns {
+		runs = append(runs, retry.Run{Command: run.Command, Output: run.Output, IsError: run.IsError})
+	}
+
+	a.retrySuggestions = a.retry.Suggest(runs)
+	a.retryIndex = 0
+	if len(a.retrySuggestions) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(a.retrySuggestions))
+	for _, suggestion := range a.retrySuggestions {
+		names = append(names, suggestion.Name)
+	}
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("retry_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   fmt.Sprintf("Detected %s. Press r to pre-fill a follow-up prompt (again to cycle).", strings.Join(names, ", ")),
+		Timestamp: time.Now(),
+	})
+	a.followNewContent(true)
+}
+
+// prefillRetry puts the next suggested follow-up prompt in the input line
+func (a *Application) prefillRetry() {
+	if len(a.retrySuggestions) == 0 {
+		return
+	}
+	suggestion := a.retrySuggestions[a.retryIndex%len(a.retrySuggestions)]
+	a.retryIndex++
+
+	a.inputBuffer = suggestion.Prompt
+	a.inputActive = true
+	a.inputMode = InputModeInsert
+	a.cursorPos = len(a.inputBuffer)
+	a.statusMessage = fmt.Sprintf("[retry] Pre-filled follow-up for %s", suggestion.Name)
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+	"complex/internal/review"
+)
+
+// handleReviewCommand runs "/review [focus]": it asks claude to review the
+// uncommitted changes and to list its findings as JSON for /findings
+func (a *Application) handleReviewCommand(focus string) (tea.Model, tea.Cmd) {
+	if a.isLoading {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "review", Message: "Wait for the current prompt to finish before starting a review"}
+		}
+	}
+	a.isLoading = true
+	return a.handlePromptInput(PromptInputMsg{
+		Prompt: review.Prompt(focus),
+		Resume: a.sessionManager.CurrentSessionID != "",
+	})
+}
+
+// latestFindings returns the findings of the most recent answer that has
+// them
+func (a *Application) latestFindings() ([]review.Finding, error) {
+	var parseErr error
+	for i := len(a.messages) - 1; i >= 0; i-- {
+		if a.messages[i].Type != "assistant" {
+			continue
+		}
+		findings, found, err := review.Parse(a.messages[i].Content)
+		if found {
+			return findings, nil
+		}
+		if err != nil && parseErr == nil {
+			parseErr = err
+		}
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return nil, fmt.Errorf("no review findings in this conversation; run /review first")
+}
+
+// handleFindingsCommand runs "/findings [path]": it lists the findings of
+// the last review, or exports them as SARIF (.sarif, .sarif.json) or JUnit
+// XML (.xml) for code review and CI tools
+func (a *Application) handleFindingsCommand(path string) (tea.Model, tea.Cmd) {
+	findings, err := a.latestFindings()
+	if err != nil {
+		return a, func() tea.Msg { return ErrorMsg{Error: err, Context: "findings"} }
+	}
+
+	if path == "" {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d review findings. Export them with /findings report.sarif or /findings report.xml.\n", len(findings))
+		for _, f := range findings {
+			location := f.File
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s:%d", f.File, f.Line)
+			}
+			fmt.Fprintf(&b, "\n[%s] %s %s: %s", f.Severity, location, f.Rule, f.Title)
+		}
+		a.messages = append(a.messages, claude.ConversationMessage{
+			ID:        fmt.Sprintf("findings_%d", time.Now().UnixNano()),
+			Type:      "system",
+			Content:   b.String(),
+			Timestamp: time.Now(),
+		})
+		a.scrollToBottomSafe()
+		return a, nil
+	}
+
+	path = a.resolvePath(path)
+	if err := review.Export(path, findings); err != nil {
+		return a, func() tea.Msg { return ErrorMsg{Error: err, Context: "findings"} }
+	}
+	a.notify(toastSuccess, fmt.Sprintf("%d findings written to %s", len(findings), shortenHome(path)))
+	return a, nil
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// conversationSearch is the state of "/" search over the conversation panel
+type conversationSearch struct {
+	editing bool   // typing a query in the input panel
+	input   string // query being typed
+	query   string // active query, empty when not searching
+	current int    // display line of the current match, -1 before the first jump
+	matches int    // number of matching lines at the last render
+}
+
+// startSearch opens the search prompt
+func (a *Application) startSearch() {
+	a.search.editing = true
+	a.search.input = ""
+}
+
+// clearSearch drops the active query and its highlighting
+func (a *Application) clearSearch() {
+	a.search = conversationSearch{current: -1}
+}
+
+// handleSearchKeyPress edits the query typed after "/" and runs it on enter
+func (a *Application) handleSearchKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		a.search.editing = false
+	case tea.KeyBackspace:
+		if runes := []rune(a.search.input); len(runes) > 0 {
+			a.search.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeyEnter:
+		a.search.editing = false
+		if a.search.input == "" {
+			return a, nil
+		}
+		a.search.query = a.search.input
+		a.search.current = -1
+		a.jumpToMatch(1)
+	case tea.KeySpace:
+		a.search.input += " "
+	case tea.KeyRunes:
+		a.search.input += string(msg.Runes)
+	}
+	return a, nil
+}
+
+// searchMatches returns the indexes of the display lines containing query,
+// ignoring case and styling
+func searchMatches(lines []string, query string) []int {
+	if query == "" {
+		return nil
+	}
+	needle := strings.ToLower(query)
+	var matches []int
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(ansi.Strip(line)), needle) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// jumpToMatch scrolls to the next (dir 1) or previous (dir -1) matching line,
+// wrapping around the conversation
+func (a *Application) jumpToMatch(dir int) {
+	if a.search.query == "" {
+		return
+	}
+
+	lines, _ := a.conversationLines(a.conversationContentWidth())
+	matches := searchMatches(lines, a.search.query)
+	a.search.matches = len(matches)
+	if len(matches) == 0 {
+		a.statusMessage = fmt.Sprintf("[search] No matches for %q", a.search.query)
+		return
+	}
+
+	// Before the first jump, search from the top of the viewport
+	from := a.search.current
+	if from < 0 {
+		from = a.viewport.YOffset - 1
+		if dir < 0 {
+			from = a.viewport.YOffset + 1
+		}
+	}
+
+	index := -1
+	if dir > 0 {
+		for i, line := range matches {
+			if line > from {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			index = 0
+		}
+	} else {
+		for i := len(matches) - 1; i >= 0; i-- {
+			if matches[i] < from {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			index = len(matches) - 1
+		}
+	}
+
+	a.search.current = matches[index]
+	a.scrollTo(a.search.current)
+	a.statusMessage = fmt.Sprintf("[search] Match %d of %d for %q", index+1, len(matches), a.search.query)
+}
+
+// highlightSearch marks the occurrences of the active query in the display
+// lines and records the match count for the status bar
+func (a *Application) highlightSearch(lines []string) []string {
+	matches := searchMatches(lines, a.search.query)
+	a.search.matches = len(matches)
+	if len(matches) == 0 {
+		return lines
+	}
+
+	highlighted := make([]string, len(lines))
+	copy(highlighted, lines)
+	for _, i := range matches {
+		style := a.styles.Match
+		if i == a.search.current {
+			style = a.styles.CurrentMatch
+		}
+		highlighted[i] = highlightLine(lines[i], a.search.query, style)
+	}
+	return highlighted
+}
+
+// highlightLine renders the occurrences of query in line with style. The
+// line's own styling is dropped so the highlight stays readable.
+func highlightLine(line, query string, style lipgloss.Style) string {
+	plain := ansi.Strip(line)
+	lower := strings.ToLower(plain)
+	needle := strings.ToLower(query)
+	if len(lower) != len(plain) {
+		// Case folding changed byte offsets; highlight the whole line
+		return style.Render(plain)
+	}
+
+	var b strings.Builder
+	for {
+		idx := strings.Index(lower, needle)
+		if idx < 0 {
+			b.WriteString(plain)
+			break
+		}
+		end := idx + len(needle)
+		b.WriteString(plain[:idx])
+		b.WriteString(style.Render(plain[idx:end]))
+		plain, lower = plain[end:], lower[end:]
+	}
+	return b.String()
+}
+
+// searchStatus summarizes the active search for the footer
+func (a *Application) searchStatus() string {
+	if a.search.query == "" {
+		return ""
+	}
+	return fmt.Sprintf("Search %q: %d matches (n/N)", a.search.query, a.search.matches)
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// openSessionsView shows the session chain with the latest session selected
+func (a *Application) openSessionsView() (tea.Model, tea.Cmd) {
+	a.state = StateSessions
+	a.sessionsSelected = max(0, len(a.sessionManager.GetSessionChain())-1)
+	return a, nil
+}
+
+// handleSessionsKeyPress handles navigation and forking in the sessions view
+func (a *Application) handleSessionsKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	links := a.sessionManager.ChainLinks()
+	switch msg.String() {
+	case "up", "k":
+		if a.sessionsSelected > 0 {
+			a.sessionsSelected--
+		}
+	case "down", "j":
+		if a.sessionsSelected < len(links)-1 {
+			a.sessionsSelected++
+		}
+	case "enter":
+		if len(links) == 0 {
+			return a, nil
+		}
+		if a.sessionsSelected == len(links)-1 {
+			a.state = StateMain
+			return a, func() tea.Msg {
+				return StatusMsg{Status: "sessions", Message: "Already on the latest session"}
+			}
+		}
+		return a.forkFromSession(a.sessionsSelected)
+	case "esc", "q", "ctrl+m", "ctrl+l":
+		a.state = StateMain
+	case "ctrl+c":
+		return a, tea.Quit
+	}
+	return a, nil
+}
+
+// forkFromSession continues the conversation from an earlier session as a
+// new conversation and shows its transcript up to that point
+func (a *Application) forkFromSession(index int) (tea.Model, tea.Cmd) {
+	if a.isLoading {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "sessions", Message: "Cannot fork while a command is running"}
+		}
+	}
+	total := len(a.sessionManager.ChainLinks())
+	record, err := a.sessionManager.ForkFromSession(index)
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "sessions"}
+		}
+	}
+
+	a.state = StateMain
+	a.messages = append([]claude.ConversationMessage(nil), record.Messages...)
+	a.invalidateLayout()
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("fork_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   fmt.Sprintf("Forked from session %d of %d (%s); the next prompt continues from there.", index+1, total, record.CurrentSessionID),
+		Timestamp: time.Now(),
+	})
+	a.currentSession = a.sessionManager.GetCurrentSession()
+	a.sessionStats = a.sessionManager.GetStats()
+	a.scrollToBottomSafe()
+	return a, nil
+}
+
+// renderSessionsView renders the session chain as a timeline
+func (a *Application) renderSessionsView() string {
+	links := a.sessionManager.ChainLinks()
+	content := []string{
+		a.styles.Header.Render("CustomClaude TUI - Session Chain"),
+		"",
+	}
+
+	if len(links) == 0 {
+		content = append(content, a.styles.Status.Render("  No sessions yet; the chain grows with every completed prompt."))
+	}
+
+	for i, link := range links {
+		marker := "●"
+		if i == len(links)-1 {
+			marker = "◉"
+		}
+		line := fmt.Sprintf("%s %2d  %s", marker, i+1, truncateString(link.SessionID, 36))
+		if link.Messages > 0 {
+			line += fmt.Sprintf("  %s  %2d turns  $%.4f  %s",
+				link.Timestamp.Local().Format("15:04:05"),
+				link.Turns,
+				link.Cost,
+				(time.Duration(link.DurationMs) * time.Millisecond).Round(100*time.Millisecond))
+		}
+		if i == len(links)-1 {
+			line += "  (current)"
+		}
+
+		if i == a.sessionsSelected {
+			content = append(content, a.styles.Highlight.Render("> "+line))
+		} else {
+			content = append(content, "  "+line)
+		}
+		if i < len(links)-1 {
+			content = append(content, a.styles.Status.Render("  │"))
+		}
+	}
+
+	content = append(content,
+		"",
+		a.styles.Footer.Render("Resuming an earlier session forks the conversation; the original stays in /resume."),
+		"",
+		"↑/↓ or j/k: Select | Enter: Fork from session | Esc: Back",
+	)
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
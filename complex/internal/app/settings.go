@@ -0,0 +1,127 @@
+package app
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+
+	"complex/internal/claude"
+	"complex/internal/config"
+	"complex/internal/ui/components"
+)
+
+// settingsForm lists the settings of the form the editor opens on, in
+// order. They apply to the running application as soon as they are edited.
+var settingsForm = []string{
+	"model",
+	"theme",
+	"word_wrap",
+	"auto_scroll",
+	"budget.conversation",
+	"budget.daily",
+	"budget.hard_limit",
+	"keymap_preset",
+}
+
+// settingsPreview is the reply rendered under the form with the theme and
+// wrap width being edited
+const settingsPreview = "A **preview** of replies with these settings, wrapped at the chosen width so long sentences like this one show where lines break.\n\n```go\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n```"
+
+// formFields returns the settings of the form, flattened to one level
+func (e *configEditor) formFields() []config.Field {
+	byPath := make(map[string]config.Field)
+	for _, field := range e.doc.Fields() {
+		byPath[field.Path] = field
+	}
+	fields := make([]config.Field, 0, len(settingsForm))
+	for _, path := range settingsForm {
+		if field, ok := byPath[path]; ok {
+			field.Key, field.Depth = field.Path, 0
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// setConfigField edits a setting and previews the change in the running
+// application
+func (a *Application) setConfigField(path, value string) bool {
+	e := &a.configEditor
+	before := e.doc.Config
+	if !e.set(path, value) {
+		return false
+	}
+	a.applyLiveSettings(before, e.doc.Config)
+	return true
+}
+
+// applyLiveSettings applies the settings of the form that differ between
+// two versions of config.toml to the running application. Settings left
+// alone keep their CC_CUSTOM_* overrides.
+func (a *Application) applyLiveSettings(from, to config.Config) {
+	if to.Model != from.Model {
+		a.config.Model = to.Model
+		for _, t := range a.tabs {
+			t.sessionManager.SetModel(to.Model)
+		}
+	}
+	if to.Theme != from.Theme {
+		a.setTheme(to.Theme)
+	}
+	if to.WordWrap != from.WordWrap {
+		a.config.WordWrap = to.WordWrap
+		a.rewrap()
+	}
+	if to.AutoScroll != from.AutoScroll {
+		a.config.AutoScroll = to.AutoScroll
+	}
+	if !reflect.DeepEqual(to.Budget, from.Budget) {
+		a.config.Budget = to.Budget
+		a.setBudget(to.Budget)
+	}
+	if to.KeymapPreset != from.KeymapPreset || !maps.Equal(to.Keybindings, from.Keybindings) {
+		a.config.KeymapPreset, a.config.Keybindings = to.KeymapPreset, to.Keybindings
+		a.keymap = NewKeymap(a.config.EffectiveKeybindings())
+	}
+}
+
+// setTheme switches the markdown and code highlighting theme, keeping the
+// current one when the new one cannot be loaded
+func (a *Application) setTheme(theme string) {
+	renderer, err := components.NewMarkdownRendererWithStyle(80, theme)
+	if err != nil {
+		a.configEditor.status = "Theme not applied: " + err.Error()
+		return
+	}
+	a.config.Theme = theme
+	a.markdownRenderer = renderer
+	a.highlighter = components.NewHighlighter(theme)
+	a.wideRenderer = nil
+	a.fitRenderers = nil
+	a.rewrap()
+}
+
+// setBudget applies spending limits to the conversations of every tab
+func (a *Application) setBudget(budget claude.Budget) {
+	for _, t := range a.tabs {
+		t.sessionManager.Budget = budget
+	}
+}
+
+// renderSettingsPreview renders a sample reply as the conversation panel
+// would with the settings being edited
+func (a *Application) renderSettingsPreview() []string {
+	msg := claude.ConversationMessage{ID: "settings_preview", Type: "assistant", Content: settingsPreview}
+	width := min(a.conversationContentWidth(), max(1, a.width-4))
+	preview := a.formatMessage(msg, msg.Content, width)
+
+	lines := []string{a.styles.Highlight.Render("Preview:")}
+	for _, line := range strings.Split(preview, "\n") {
+		lines = append(lines, "  "+line)
+	}
+	follow := "new output scrolls to the bottom"
+	if !a.config.AutoScroll {
+		follow = "new output is counted below the reading position"
+	}
+	return append(lines, a.styles.Status.Render("  Auto-scroll: "+follow))
+}
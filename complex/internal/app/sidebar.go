@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/config"
+	"complex/internal/ui/components"
+)
+
+// sidebarStep is how many columns Ctrl+Left/Right move the split by
+const sidebarStep = 2
+
+// minConversationWidth is the narrowest the conversation panel is squeezed
+// to by widening the side panel
+const minConversationWidth = 40
+
+// sidebarSaveDelay is how long the side panel must stay put before its
+// size is written to config.toml, so holding a key saves once
+const sidebarSaveDelay = time.Second
+
+// sidebarSaveMsg saves the side panel's size unless it changed again since
+type sidebarSaveMsg struct {
+	seq int
+}
+
+// sidePanelWidth is the side panel's style width, 0 while it is collapsed
+func (a *Application) sidePanelWidth() int {
+	if a.sidebarHidden {
+		return 0
+	}
+	if a.sidebarWidth == 0 {
+		return components.DefaultSidebarWidth
+	}
+	return a.sidebarWidth
+}
+
+// toggleSidebar collapses or expands the side panel (Ctrl+\)
+func (a *Application) toggleSidebar() (tea.Model, tea.Cmd) {
+	a.setSidebar(a.sidePanelWidth(), !a.sidebarHidden)
+	if a.sidebarHidden {
+		a.statusMessage = "[layout] Side panel hidden (Ctrl+\\ to show)"
+	} else {
+		a.statusMessage = "[layout] Side panel shown"
+	}
+	return a, a.scheduleSidebarSave()
+}
+
+// resizeSidebar moves the split between the conversation and the side panel
+// by delta columns, expanding a collapsed panel first (Ctrl+Left/Right)
+func (a *Application) resizeSidebar(delta int) (tea.Model, tea.Cmd) {
+	if a.sidebarHidden {
+		return a.toggleSidebar()
+	}
+	limit := min(config.MaxSidebarWidth, a.width-minConversationWidth-5)
+	width := min(max(a.sidePanelWidth()+delta, config.MinSidebarWidth), max(limit, config.MinSidebarWidth))
+	if width == a.sidePanelWidth() {
+		return a, nil
+	}
+	a.setSidebar(width, false)
+	a.statusMessage = fmt.Sprintf("[layout] Side panel %d columns", width)
+	return a, a.scheduleSidebarSave()
+}
+
+// setSidebar applies a side panel size, rewrapping the conversation to the
+// width it is left with and keeping the same messages in view
+func (a *Application) setSidebar(width int, hidden bool) {
+	anchor := a.scrollAnchor()
+	a.sidebarWidth, a.sidebarHidden = width, hidden
+	a.selection = textSelection{}
+	a.rewrap()
+	a.restoreScrollAnchor(anchor)
+}
+
+// scheduleSidebarSave saves the side panel's size once it stops changing
+func (a *Application) scheduleSidebarSave() tea.Cmd {
+	a.sidebarSaveSeq++
+	seq := a.sidebarSaveSeq
+	return tea.Tick(sidebarSaveDelay, func(time.Time) tea.Msg {
+		return sidebarSaveMsg{seq: seq}
+	})
+}
+
+// handleSidebarSave writes the side panel's size to config.toml
+func (a *Application) handleSidebarSave(msg sidebarSaveMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != a.sidebarSaveSeq {
+		return a, nil
+	}
+	if a.sidebarWidth == a.config.SidebarWidth && a.sidebarHidden == a.config.SidebarHidden {
+		return a, nil
+	}
+
+	doc, err := config.OpenDocument()
+	if err == nil {
+		err = doc.Set("sidebar_width", strconv.Itoa(a.sidebarWidth))
+	}
+	if err == nil {
+		err = doc.Set("sidebar_hidden", strconv.FormatBool(a.sidebarHidden))
+	}
+	if err == nil {
+		err = doc.Save()
+	}
+	if err != nil {
+		return a, func() tea.Msg {
+			return ErrorMsg{Error: fmt.Errorf("failed to save the side panel size: %w", err), Context: "layout"}
+		}
+	}
+	a.config.SidebarWidth, a.config.SidebarHidden = a.sidebarWidth, a.sidebarHidden
+	return a, nil
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// needsSplit reports whether a prompt is above the configured split threshold
+func (a *Application) needsSplit(prompt string) bool {
+	threshold := a.config.SplitThreshold
+	return threshold > 0 && claude.EstimatePromptTokens(prompt) > threshold
+}
+
+// offerSplit holds an oversized prompt and asks whether to split it
+func (a *Application) offerSplit(msg PromptInputMsg) (tea.Model, tea.Cmd) {
+	turns := claude.SplitPrompt(msg.Prompt, a.config.SplitThreshold)
+	a.openDialog(newChoiceDialog(
+		"Split prompt?",
+		[]string{fmt.Sprintf("Prompt is ~%d tokens, above the split threshold of %d.",
+			claude.EstimatePromptTokens(msg.Prompt), a.config.SplitThreshold)},
+		[]dialogOption{
+			{"y", fmt.Sprintf("Split into %d turns", len(turns))},
+			{"n", "Send as is"},
+			{"e", "Edit"},
+		},
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			switch result.choice {
+			case "y":
+				msg.Turns = turns
+				return a.handlePromptInput(msg)
+			case "n":
+				msg.Turns = []string{msg.Prompt}
+				return a.handlePromptInput(msg)
+			}
+			a.restorePrompt(msg.Prompt)
+			return a, nil
+		},
+	))
+	return a, nil
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// isStale reports whether the conversation has been idle longer than the
+// configured threshold
+func (a *Application) isStale() bool {
+	last := a.sessionManager.LastActivity()
+	return a.config.StaleAfter > 0 && !last.IsZero() && time.Since(last) > a.config.StaleAfter
+}
+
+// formatAgo renders the time since t as a short relative duration
+func formatAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// stalenessBadge renders the time since the last result for the side panel
+func (a *Application) stalenessBadge() string {
+	last := a.sessionManager.LastActivity()
+	if last.IsZero() {
+		return ""
+	}
+	badge := "Last activity: " + formatAgo(last)
+	if a.isStale() {
+		return a.styles.Error.Render(badge + " (stale)")
+	}
+	return a.styles.Status.Render(badge)
+}
+
+// offerStaleChoice holds a prompt for a stale session and asks how to send it
+func (a *Application) offerStaleChoice(msg PromptInputMsg) (tea.Model, tea.Cmd) {
+	msg.StaleChecked = true
+	a.openDialog(newChoiceDialog(
+		"Stale session",
+		[]string{fmt.Sprintf("This session was last active %s; very old sessions often behave poorly.",
+			formatAgo(a.sessionManager.LastActivity()))},
+		[]dialogOption{
+			{"r", "Resume anyway"},
+			{"c", "Compact first"},
+			{"n", "New conversation"},
+			{"e", "Edit"},
+		},
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			switch result.choice {
+			case "r":
+				return a.handlePromptInput(msg)
+			case "c":
+				msg.Turns = []string{claude.CompactCommand, msg.Prompt}
+				return a.handlePromptInput(msg)
+			case "n":
+				a.sessionManager.StartNewConversation()
+				msg.Resume = false
+				return a.handlePromptInput(msg)
+			}
+			a.restorePrompt(msg.Prompt)
+			return a, nil
+		},
+	))
+	return a, nil
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+	"complex/internal/ui/components"
+)
+
+// statsBarWidth is the width of the cost bars in the stats view
+const statsBarWidth = 20
+
+// statsLedgerDays is the period of the usage ledger report by default
+const statsLedgerDays = 7
+
+// statsView is the usage dashboard built from the stored conversations and
+// the usage ledger
+type statsView struct {
+	history    claude.UsageHistory
+	historyErr error
+	report     claude.UsageReport
+	reportErr  error
+	days       int
+	scroll     int
+}
+
+// openStatsView loads the stored conversations and the usage ledger of the
+// last days days and shows their usage
+func (a *Application) openStatsView(days int) (tea.Model, tea.Cmd) {
+	history, historyErr := a.sessionManager.UsageHistory()
+	report, reportErr := a.sessionManager.UsageReport(days)
+	a.stats = statsView{history: history, historyErr: historyErr, report: report, reportErr: reportErr, days: days}
+	a.state = StateStats
+	return a, nil
+}
+
+// handleUsageCommand runs "/usage [days]": the stats view with the ledger
+// report over the given number of days
+func (a *Application) handleUsageCommand(args []string) (tea.Model, tea.Cmd) {
+	days := statsLedgerDays
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			a.statusMessage = "[usage] Usage: /usage [days]"
+			return a, nil
+		}
+		days = n
+	}
+	return a.openStatsView(days)
+}
+
+// statsRows is the number of lines the stats view can show
+func (a *Application) statsRows() int {
+	return max(5, a.height-8)
+}
+
+// handleStatsKeyPress handles scrolling in the stats view
+func (a *Application) handleStatsKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		a.stats.scroll--
+	case "down", "j":
+		a.stats.scroll++
+	case "pgup":
+		a.stats.scroll -= a.statsRows()
+	case "pgdown", " ":
+		a.stats.scroll += a.statsRows()
+	case "home", "g":
+		a.stats.scroll = 0
+	case "r":
+		scroll := a.stats.scroll
+		model, cmd := a.openStatsView(a.stats.days)
+		a.stats.scroll = scroll
+		return model, cmd
+	case "esc", "q", "ctrl+m", "ctrl+u":
+		a.state = StateMain
+	case "ctrl+c":
+		return a, tea.Quit
+	}
+	a.stats.scroll = max(0, a.stats.scroll)
+	return a, nil
+}
+
+// renderStatsView renders the totals, per-turn charts and the cost of each
+// conversation
+func (a *Application) renderStatsView() string {
+	view := &a.stats
+	history := view.history
+	content := []string{
+		a.styles.Header.Render("CustomClaude TUI - Usage Statistics"),
+		"",
+	}
+
+	var body []string
+	switch {
+	case view.historyErr != nil:
+		body = append(body, a.styles.Error.Render(fmt.Sprintf("Saved conversations: %v", view.historyErr)), "")
+	case len(history.Conversations) == 0:
+		body = append(body, a.styles.Status.Render("No saved conversations yet."), "")
+	default:
+		usage := history.Usage
+		body = append(body,
+			a.styles.Highlight.Render("Totals"),
+			fmt.Sprintf("  Conversations     %d", len(history.Conversations)),
+			fmt.Sprintf("  Turns             %d", len(history.Turns)),
+			fmt.Sprintf("  Cost              $%s", formatCost(history.Cost)),
+			fmt.Sprintf("  Tokens            %s in · %s out", formatTokens(usage.InputTokens+usage.CacheCreationInputTokens+usage.CacheReadInputTokens), formatTokens(usage.OutputTokens)),
+			fmt.Sprintf("  Cache hit ratio   %.0f%% (%s read from cache)", claude.CacheHitRatio(usage)*100, formatTokens(usage.CacheReadInputTokens)),
+			fmt.Sprintf("  Avg turn latency  %s", history.AverageLatency().Round(100*time.Millisecond)),
+			"",
+		)
+		body = append(body, a.turnCharts(history.Turns)...)
+	}
+	body = append(body, a.ledgerReport()...)
+	if len(history.Conversations) > 0 {
+		body = append(body, a.conversationCostTable(history.Conversations)...)
+	}
+
+	rows := a.statsRows()
+	view.scroll = min(view.scroll, max(0, len(body)-rows))
+	end := min(len(body), view.scroll+rows)
+	content = append(content, body[view.scroll:end]...)
+	if end < len(body) {
+		content = append(content, a.styles.Status.Render(fmt.Sprintf("  ... %d more lines", len(body)-end)))
+	}
+
+	content = append(content, "", "↑/↓ or j/k: Scroll | PgUp/PgDn: Page | r: Reload | Esc: Back")
+	return a.styles.App.Render(strings.Join(content, "\n"))
+}
+
+// turnCharts renders sparklines of the tokens, cost and latency of the most
+// recent turns that fit the view, with their range
+func (a *Application) turnCharts(turns []claude.ChainLink) []string {
+	if len(turns) == 0 {
+		return nil
+	}
+	turns = turns[max(0, len(turns)-max(10, a.width-40)):]
+	tokens := make([]float64, len(turns))
+	costs := make([]float64, len(turns))
+	latencies := make([]float64, len(turns))
+	for i, turn := range turns {
+		usage := turn.Usage
+		tokens[i] = float64(usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens + usage.OutputTokens)
+		costs[i] = turn.Cost
+		latencies[i] = float64(turn.DurationMs)
+	}
+
+	return []string{
+		a.styles.Highlight.Render(fmt.Sprintf("Last %d turns", len(turns))),
+		fmt.Sprintf("  Tokens   %s  max %s", components.Sparkline(tokens), formatTokens(int(peak(tokens)))),
+		fmt.Sprintf("  Cost     %s  max $%s", components.Sparkline(costs), formatCost(peak(costs))),
+		fmt.Sprintf("  Latency  %s  max %s", components.Sparkline(latencies),
+			(time.Duration(peak(latencies)) * time.Millisecond).Round(100*time.Millisecond)),
+		"",
+	}
+}
+
+// ledgerReport renders the usage ledger's spending per day, model and
+// project
+func (a *Application) ledgerReport() []string {
+	view := a.stats
+	report := view.report
+	title := a.styles.Highlight.Render(fmt.Sprintf("Spending since %s (%d days)", report.Since, view.days))
+	switch {
+	case view.reportErr != nil:
+		return []string{title, a.styles.Error.Render(fmt.Sprintf("  %v", view.reportErr)), ""}
+	case report.Total.Results == 0:
+		return []string{title, a.styles.Status.Render("  Nothing recorded in the usage ledger yet."), ""}
+	}
+
+	lines := []string{title, fmt.Sprintf("  $%s over %d results", formatCost(report.Total.Cost), report.Total.Results), ""}
+	for _, group := range []struct {
+		heading string
+		totals  []claude.UsageTotal
+	}{
+		{"Per day", report.ByDay},
+		{"Per model", report.ByModel},
+		{"Per project", report.ByProject},
+	} {
+		costs := make([]float64, len(group.totals))
+		for i, total := range group.totals {
+			costs[i] = total.Cost
+		}
+		top := peak(costs)
+		keyWidth := max(10, min(40, a.width-statsBarWidth-40))
+		lines = append(lines, a.styles.Status.Render("  "+group.heading))
+		for _, total := range group.totals {
+			key := total.Key
+			if width := ansi.StringWidth(key); width > keyWidth {
+				// Keep the end of long project paths
+				key = "…" + ansi.TruncateLeft(key, width-keyWidth+1, "")
+			}
+			key += strings.Repeat(" ", max(0, keyWidth-ansi.StringWidth(key)))
+			lines = append(lines, fmt.Sprintf("  %s  %s  %9s  %4d results",
+				key, meterBar(int(total.Cost*10000), int(top*10000), statsBarWidth),
+				"$"+formatCost(total.Cost), total.Results))
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// conversationCostTable renders the cost of each conversation as a bar
+// scaled to the most expensive one
+func (a *Application) conversationCostTable(conversations []claude.ConversationUsage) []string {
+	costs := make([]float64, len(conversations))
+	for i, conversation := range conversations {
+		costs[i] = conversation.Cost
+	}
+	top := peak(costs)
+
+	titleWidth := max(12, min(40, a.width-statsBarWidth-50))
+	lines := []string{
+		a.styles.Highlight.Render("Cost per conversation"),
+		a.styles.Status.Render(fmt.Sprintf("  %-*s  %-*s  %9s  %5s  %5s  %s",
+			titleWidth, "Conversation", statsBarWidth, "", "Cost", "Turns", "Cache", "Updated")),
+	}
+	for _, conversation := range conversations {
+		title := conversation.Title
+		if title == "" {
+			title = conversation.ID
+		}
+		// Hundredths of a cent keep the integer bar scale fine enough
+		bar := meterBar(int(conversation.Cost*10000), int(top*10000), statsBarWidth)
+		lines = append(lines, fmt.Sprintf("  %-*s  %s  %9s  %5d  %4.0f%%  %s",
+			titleWidth, truncateString(title, titleWidth), bar,
+			"$"+formatCost(conversation.Cost), conversation.Prompts,
+			claude.CacheHitRatio(conversation.Usage)*100,
+			conversation.UpdatedAt.Local().Format("2006-01-02 15:04")))
+	}
+	return lines
+}
+
+// peak is the largest of values, 0 for none
+func peak(values []float64) float64 {
+	top := 0.0
+	for _, v := range values {
+		if v > top {
+			top = v
+		}
+	}
+	return top
+}
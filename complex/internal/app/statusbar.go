@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+// statusTickInterval is how often the status bar redraws while a request
+// is in flight
+const statusTickInterval = 100 * time.Millisecond
+
+// spinnerFrames animate the status bar
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// runStatus tracks the request in flight for the status bar
+type runStatus struct {
+	started time.Time
+	frame   int
+	// tokens approximates the assistant text streamed so far, by message ID
+	tokens map[string]int
+}
+
+// statusTickMsg advances the spinner and elapsed time
+type statusTickMsg struct{}
+
+// trackRun starts the status of a request when one goes in flight and
+// clears it once it is done
+func (a *Application) trackRun() {
+	switch {
+	case a.isLoading && a.run.started.IsZero():
+		a.run = runStatus{started: time.Now(), tokens: make(map[string]int)}
+	case !a.isLoading && !a.run.started.IsZero():
+		a.run = runStatus{}
+	}
+}
+
+// noteStreamedTokens counts the assistant text of a request in flight
+func (a *Application) noteStreamedTokens(msg claude.ConversationMessage) {
+	if a.run.tokens == nil || msg.Type != "assistant" {
+		return
+	}
+	a.run.tokens[msg.ID] = claude.CountTokens(msg.Content)
+}
+
+// scheduleStatusTick returns the next status bar tick while a request is in
+// flight, unless one is already pending. Linear mode prints no spinner.
+func (a *Application) scheduleStatusTick() tea.Cmd {
+	if a.statusTicking || !a.isLoading || a.linear {
+		return nil
+	}
+	a.statusTicking = true
+	return tea.Tick(statusTickInterval, func(time.Time) tea.Msg {
+		return statusTickMsg{}
+	})
+}
+
+// advanceStatus moves the spinner on by one frame
+func (a *Application) advanceStatus() {
+	a.statusTicking = false
+	a.run.frame++
+}
+
+// currentTool returns the tool being executed, if any
+func (a *Application) currentTool() string {
+	for i := len(a.toolActivity) - 1; i >= 0; i-- {
+		if a.toolActivity[i].Status == "running" {
+			return a.toolActivity[i].Activity
+		}
+	}
+	return ""
+}
+
+// renderStatusBar renders the spinner, elapsed time, running tool and
+// streamed tokens shown in place of the input while a request is in flight
+func (a *Application) renderStatusBar(width int) string {
+	spinner := spinnerFrames[a.run.frame%len(spinnerFrames)]
+	status := fmt.Sprintf("%s Working %s", spinner, formatElapsed(time.Since(a.run.started)))
+
+	if tool := a.currentTool(); tool != "" {
+		status += " · " + truncateString(tool, 40)
+	}
+	tokens := 0
+	for _, n := range a.run.tokens {
+		tokens += n
+	}
+	if tokens > 0 {
+		status += fmt.Sprintf(" · ~%s tokens", formatTokens(tokens))
+	}
+	status += " · Ctrl+X to cancel"
+	return a.styles.Status.Render(truncateString(status, max(1, width)))
+}
+
+// formatElapsed renders a running time to the second
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+	"customclaude/pkg/claudecli"
+)
+
+// stderrLineLimit bounds the stderr lines kept per tab
+const stderrLineLimit = 500
+
+// stderrPaneRows is how many lines the open stderr pane shows
+const stderrPaneRows = 6
+
+// StderrMsg carries a line claude wrote to stderr
+type StderrMsg struct {
+	Line claude.StderrLine
+}
+
+// stderrPane holds the display settings of the stderr pane (Ctrl+B), shared
+// by all tabs
+type stderrPane struct {
+	open     bool
+	severity claudecli.Severity // least severe line shown
+}
+
+// noteStderr keeps a stderr line; warnings and errors arriving while the
+// pane is closed count as unread
+func (a *Application) noteStderr(line claude.StderrLine) {
+	a.stderrLines = append(a.stderrLines, line)
+	if len(a.stderrLines) > stderrLineLimit {
+		a.stderrLines = a.stderrLines[len(a.stderrLines)-stderrLineLimit:]
+	}
+	if !a.stderrPane.open && line.Severity >= claudecli.SeverityWarning {
+		a.stderrUnread++
+	}
+}
+
+// toggleStderrPane opens or collapses the stderr pane; opening it marks
+// every line read
+func (a *Application) toggleStderrPane() (tea.Model, tea.Cmd) {
+	a.stderrPane.open = !a.stderrPane.open
+	if a.stderrPane.open {
+		a.stderrUnread = 0
+	}
+	return a, nil
+}
+
+// handleStderrCommand runs "/stderr [all|warn|error|clear]": without an
+// argument it toggles the pane, otherwise it sets the severity shown, opening
+// the pane, or forgets the tab's lines
+func (a *Application) handleStderrCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		return a.toggleStderrPane()
+	}
+	if args[0] == "clear" {
+		a.stderrLines = nil
+		a.stderrUnread = 0
+		a.statusMessage = "[stderr] Cleared"
+		return a, nil
+	}
+	severity, err := claudecli.ParseSeverity(args[0])
+	if err != nil {
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "stderr", Message: "Usage: /stderr [all|warn|error|clear]"}
+		}
+	}
+	a.stderrPane.severity = severity
+	a.stderrPane.open = true
+	a.stderrUnread = 0
+	a.statusMessage = fmt.Sprintf("[stderr] Showing %s and above", severity)
+	return a, nil
+}
+
+// filteredStderr returns the lines at or above the pane's severity
+func (a *Application) filteredStderr() []claude.StderrLine {
+	var lines []claude.StderrLine
+	for _, line := range a.stderrLines {
+		if line.Severity >= a.stderrPane.severity {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// stderrPaneHeight is the height the open pane takes from the panels above
+func (a *Application) stderrPaneHeight() int {
+	if !a.stderrPane.open {
+		return 0
+	}
+	return stderrPaneRows + 5 // title, border and padding
+}
+
+// renderStderrPane renders the newest stderr lines passing the filter
+func (a *Application) renderStderrPane(width int) string {
+	lines := a.filteredStderr()
+	title := fmt.Sprintf("claude stderr: %s and above (%d of %d lines) | /stderr all|warn|error|clear | Ctrl+B: Hide",
+		a.stderrPane.severity, len(lines), len(a.stderrLines))
+	rows := []string{a.styles.Highlight.Render(ansi.Truncate(title, width, "…"))}
+
+	if len(lines) == 0 {
+		rows = append(rows, a.styles.Status.Render("Nothing at this severity."))
+	}
+	if len(lines) > stderrPaneRows {
+		lines = lines[len(lines)-stderrPaneRows:]
+	}
+	for _, line := range lines {
+		text := ansi.Truncate(fmt.Sprintf("%s %-7s %s", line.Received.Format("15:04:05"), line.Severity, line.Text), width, "…")
+		switch line.Severity {
+		case claudecli.SeverityError:
+			text = a.styles.Error.Render(text)
+		case claudecli.SeverityWarning:
+			text = a.styles.Highlight.Render(text)
+		default:
+			text = a.styles.Status.Render(text)
+		}
+		rows = append(rows, text)
+	}
+	for len(rows) < stderrPaneRows+1 {
+		rows = append(rows, "")
+	}
+	return strings.Join(rows, "\n")
+}
+
+// stderrBadge returns the footer badge for unread warnings and errors, or ""
+func (a *Application) stderrBadge() string {
+	if a.stderrUnread == 0 {
+		return ""
+	}
+	noun := "warnings"
+	if a.stderrUnread == 1 {
+		noun = "warning"
+	}
+	return a.styles.Error.Render(fmt.Sprintf("stderr: %d %s (Ctrl+B)", a.stderrUnread, noun))
+}
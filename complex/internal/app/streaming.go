@@ -0,0 +1,48 @@
+package app
+
+// applyStreamedMessage merges partial assistant text into the conversation.
+// It returns true when the message was fully handled and must not be
+// appended: a partial update for a message already on screen, a completed
+// message replacing its partial, or a stale partial arriving after the
+// completed text.
+func (a *Application) applyStreamedMessage(msg MessageStreamMsg) bool {
+	id := msg.Message.ID
+	idx := a.findMessage(id, msg.Message.Type)
+
+	if !msg.IsPartial {
+		if !a.streaming[id] || idx < 0 {
+			return false
+		}
+		delete(a.streaming, id)
+		a.messages[idx] = msg.Message
+		a.invalidateLayout()
+		return true
+	}
+
+	if idx < 0 {
+		a.streaming[id] = true
+		a.messages = append(a.messages, msg.Message)
+		return true
+	}
+	if !a.streaming[id] {
+		// Completed text already arrived; ignore late deltas
+		return true
+	}
+	// Events may be delivered out of order; never shrink the streamed text
+	if len(msg.Message.Content) > len(a.messages[idx].Content) {
+		a.messages[idx].Content = msg.Message.Content
+		a.invalidateLayout()
+	}
+	return true
+}
+
+// findMessage returns the index of the most recent message with the given
+// ID and type, or -1
+func (a *Application) findMessage(id, msgType string) int {
+	for i := len(a.messages) - 1; i >= 0; i-- {
+		if a.messages[i].ID == id && a.messages[i].Type == msgType {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"complex/internal/clipboard"
+)
+
+// gitDiffstat returns the short diffstat of uncommitted changes in dir, or
+// an empty string outside a git repository
+func gitDiffstat(dir string) string {
+	cmd := exec.Command("git", "diff", "--shortstat", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// summaryMarkdown formats the conversation summary as Markdown
+func (a *Application) summaryMarkdown() string {
+	stats := a.sessionManager.GetStats()
+	usage := stats.CumulativeUsage
+	totalTokens := usage.InputTokens + usage.CacheCreationInputTokens +
+		usage.CacheReadInputTokens + usage.OutputTokens
+
+	var b strings.Builder
+	b.WriteString("## Conversation Summary\n\n")
+	fmt.Fprintf(&b, "- **Duration:** %s\n", time.Since(stats.ConversationStart).Round(time.Second))
+	fmt.Fprintf(&b, "- **Sessions:** %d\n", len(a.sessionManager.GetSessionChain()))
+	fmt.Fprintf(&b, "- **Turns:** %d\n", stats.CumulativeTurns)
+	fmt.Fprintf(&b, "- **Cost:** $%.4f\n", stats.CumulativeCost)
+	fmt.Fprintf(&b, "- **Tokens:** %d total (input %d, output %d, cache read %d, cache creation %d)\n",
+		totalTokens, usage.InputTokens, usage.OutputTokens,
+		usage.CacheReadInputTokens, usage.CacheCreationInputTokens)
+	if diffstat := gitDiffstat(a.sessionManager.Dir()); diffstat != "" {
+		fmt.Fprintf(&b, "- **Changes:** %s\n", diffstat)
+	}
+	return b.String()
+}
+
+// copySummary copies the Markdown summary to the clipboard
+func (a *Application) copySummary() {
+	clipboard.CopyWithFallback(a.summaryMarkdown())
+	a.notify(toastSuccess, "Conversation summary copied")
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"complex/internal/claude"
+)
+
+// foldedAttempts is a run of consecutive superseded attempts shown as one
+// line: the partial output of turns that were retried or cancelled
+type foldedAttempts struct {
+	end       int // index after the run's last message
+	attempts  int
+	retried   int
+	cancelled int
+}
+
+// label is the line shown in place of the folded messages
+func (f foldedAttempts) label() string {
+	noun := "attempt"
+	if f.attempts > 1 {
+		noun = "attempts"
+	}
+	var reasons []string
+	if f.retried > 0 {
+		reasons = append(reasons, "retried")
+	}
+	if f.cancelled > 0 {
+		reasons = append(reasons, "cancelled")
+	}
+	return fmt.Sprintf("⋯ %d hidden %s (%s) - z to show", f.attempts, noun, strings.Join(reasons, ", "))
+}
+
+// supersedes reports whether msg ends an attempt that did not produce the
+// turn's answer: a retry notice or an interruption note
+func supersedes(msg claude.ConversationMessage) bool {
+	return strings.HasPrefix(msg.ID, "retrying_") || msg.Interruption != nil
+}
+
+// supersededAttempts finds the attempts to fold, keyed by the index of their
+// first message. An attempt runs from the prompt, or the end of the attempt
+// before it, to its retry notice or interruption note; the prompt itself
+// stays, and the attempts of one turn fold together.
+func supersededAttempts(messages []claude.ConversationMessage) map[int]foldedAttempts {
+	folds := make(map[int]foldedAttempts)
+	start := 0  // first message of the current attempt
+	first := -1 // first message of the turn's folded attempts
+	for i, msg := range messages {
+		switch {
+		case msg.Type == "user":
+			start, first = i+1, -1
+		case supersedes(msg):
+			if first < 0 {
+				first = start
+			}
+			fold := folds[first]
+			fold.end = i + 1
+			fold.attempts++
+			if msg.Interruption != nil {
+				fold.cancelled++
+			} else {
+				fold.retried++
+			}
+			folds[first] = fold
+			start = i + 1
+		}
+	}
+	return folds
+}
+
+// hiddenAttempts is the folded attempts of the conversation, none when they
+// are shown
+func (a *Application) hiddenAttempts() map[int]foldedAttempts {
+	if a.showSuperseded {
+		return nil
+	}
+	return supersededAttempts(a.messages)
+}
+
+// messageHidden reports whether message i is folded away
+func (a *Application) messageHidden(i int) bool {
+	for start, fold := range a.hiddenAttempts() {
+		if i >= start && i < fold.end {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleSuperseded shows or folds the retried and cancelled attempts
+func (a *Application) toggleSuperseded() {
+	total := 0
+	for _, fold := range supersededAttempts(a.messages) {
+		total += fold.attempts
+	}
+	if total == 0 {
+		a.statusMessage = "[attempts] No retried or cancelled attempts in this conversation"
+		return
+	}
+
+	a.showSuperseded = !a.showSuperseded
+	if a.showSuperseded {
+		a.statusMessage = fmt.Sprintf("[attempts] Showing %d superseded attempts (z to hide)", total)
+	} else {
+		a.statusMessage = fmt.Sprintf("[attempts] Hiding %d superseded attempts (z to show)", total)
+	}
+	a.clampScrollPosition()
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+	"customclaude/pkg/claudecli"
+)
+
+// SystemPromptEditedMsg reports that the system prompt editor exited
+type SystemPromptEditedMsg struct {
+	Path string
+	Err  error
+}
+
+// handleSystemCommand runs "/system [show|edit|set <text>|clear|reset]"
+func (a *Application) handleSystemCommand(args string) (tea.Model, tea.Cmd) {
+	action, rest, _ := strings.Cut(args, " ")
+
+	switch action {
+	case "", "show":
+		a.showSystemPrompt()
+		return a, nil
+
+	case "edit":
+		return a, a.editSystemPrompt()
+
+	case "set":
+		if strings.TrimSpace(rest) == "" {
+			return a, func() tea.Msg {
+				return StatusMsg{Status: "system", Message: "Usage: /system set <text>"}
+			}
+		}
+		a.sessionManager.SetSystemPrompt(rest)
+		a.showSystemPrompt()
+		return a, nil
+
+	case "clear":
+		a.sessionManager.SetSystemPrompt("")
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "system", Message: "System prompt disabled for this session"}
+		}
+
+	case "reset":
+		a.sessionManager.ResetSystemPrompt()
+		a.showSystemPrompt()
+		return a, nil
+
+	default:
+		return a, func() tea.Msg {
+			return StatusMsg{Status: "system", Message: "Usage: /system [show|edit|set <text>|clear|reset]"}
+		}
+	}
+}
+
+// showSystemPrompt adds the active system prompt and its source to the
+// conversation
+func (a *Application) showSystemPrompt() {
+	prompt := a.sessionManager.SystemPrompt()
+
+	var content string
+	switch {
+	case prompt.Text == "" && prompt.Source == "":
+		content = fmt.Sprintf("No system prompt is appended. Create %s or CLAUDE.md, or use /system set <text>.",
+			claudecli.SystemPromptFiles[0])
+	case prompt.Source == "":
+		content = "System prompt (set for this session):\n\n" + prompt.Text
+	default:
+		content = fmt.Sprintf("System prompt (from %s):\n\n%s", prompt.Source, prompt.Text)
+	}
+
+	a.messages = append(a.messages, claude.ConversationMessage{
+		ID:        fmt.Sprintf("system_prompt_%d", time.Now().UnixNano()),
+		Type:      "system",
+		Content:   content,
+		Timestamp: time.Now(),
+	})
+	a.scrollToBottomSafe()
+}
+
+// editSystemPrompt opens the project system prompt file in $EDITOR,
+// suspending the TUI until the editor exits
+func (a *Application) editSystemPrompt() tea.Cmd {
+	path, err := a.sessionManager.SystemPromptPath()
+	if err != nil {
+		return func() tea.Msg {
+			return ErrorMsg{Error: err, Context: "system"}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return func() tea.Msg {
+			return ErrorMsg{Error: fmt.Errorf("failed to create system prompt directory: %w", err), Context: "system"}
+		}
+	}
+
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return SystemPromptEditedMsg{Path: path, Err: err}
+	})
+}
+
+// handleSystemPromptEdited applies the edited file to the next prompt
+func (a *Application) handleSystemPromptEdited(msg SystemPromptEditedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		return a, tea.Batch(a.restoreTerminal(), func() tea.Msg {
+			return ErrorMsg{Error: fmt.Errorf("failed to run editor: %w", msg.Err), Context: "system"}
+		})
+	}
+	a.sessionManager.ResetSystemPrompt()
+	a.showSystemPrompt()
+	return a, a.restoreTerminal()
+}
@@ -0,0 +1,392 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+	"complex/internal/retry"
+	"complex/internal/ui/components"
+)
+
+// maxTabs is the number of tabs reachable with the numbered shortcuts
+const maxTabs = 9
+
+// tab holds the state of one independent conversation. The active tab's
+// state lives in the Application fields and is swapped in and out on switch.
+type tab struct {
+	id             int
+	sessionManager *claude.SessionManager
+	eventBus       *EventBus
+	eventProcessor *EventProcessor
+
+	currentSession claude.SessionInfo
+	sessionStats   claude.SessionStats
+	messages       []claude.ConversationMessage
+	errors         []ErrorMsg
+	toolActivity   []ToolActivityMsg
+	lastTurn       *claude.TurnResult
+	turnUsage      []turnUsage
+	isLoading      bool
+	cancelCommand  context.CancelFunc
+	run            runStatus
+	scrollPosition int
+	follow         components.FollowMode
+	streaming      map[string]bool
+	search         conversationSearch
+	selectedTool   string
+	expandedTools  map[string]bool
+	renderModes    map[string]renderMode
+
+	selectedMessage int
+	nextModel       string
+
+	retrySuggestions []retry.Suggestion
+	retryIndex       int
+	quickReplies     []string
+
+	rawLines []claude.RawLine
+
+	stderrLines  []claude.StderrLine
+	stderrUnread int
+
+	// unseen is set when the conversation changes while in the background
+	unseen bool
+}
+
+// TabMsg routes a message produced by a tab's session to that tab
+type TabMsg struct {
+	TabID int
+	Msg   tea.Msg
+}
+
+// SetSessionFactory sets the function used to create session managers for
+// new tabs. Without it only the initial conversation is available.
+func (a *Application) SetSessionFactory(factory func() *claude.SessionManager) {
+	a.newSession = factory
+}
+
+// Shutdown releases the session locks held by all tabs
+func (a *Application) Shutdown() {
+	for _, t := range a.tabs {
+		t.sessionManager.ReleaseSessionLock()
+	}
+}
+
+// newTab wires a session manager to its own event bus and processor
+func (a *Application) newTab(sessionManager *claude.SessionManager) *tab {
+	a.nextTabID++
+	eventBus := NewEventBus(a.ctx)
+	eventProcessor := NewEventProcessor(a.ctx, eventBus)
+	eventProcessor.tabID = a.nextTabID
+	sessionManager.AddEventHandler(eventBus)
+
+	t := &tab{
+		id:             a.nextTabID,
+		sessionManager: sessionManager,
+		eventBus:       eventBus,
+		eventProcessor: eventProcessor,
+		messages:       make([]claude.ConversationMessage, 0),
+		errors:         make([]ErrorMsg, 0),
+		toolActivity:   make([]ToolActivityMsg, 0),
+		streaming:      make(map[string]bool),
+		search:         conversationSearch{current: -1},
+		expandedTools:  make(map[string]bool),
+
+		selectedMessage: -1,
+	}
+	if a.program != nil {
+		t.eventBus.SetProgram(a.program)
+		t.eventProcessor.ProcessEvents(a.program)
+	}
+	a.tabs = append(a.tabs, t)
+	return t
+}
+
+// saveActiveTab copies the active conversation state back into its tab
+func (a *Application) saveActiveTab() {
+	t := a.tabs[a.activeTab]
+	t.sessionManager = a.sessionManager
+	t.eventBus = a.eventBus
+	t.eventProcessor = a.eventProcessor
+	t.currentSession = a.currentSession
+	t.sessionStats = a.sessionStats
+	t.messages = a.messages
+	t.errors = a.errors
+	t.toolActivity = a.toolActivity
+	t.lastTurn = a.lastTurn
+	t.turnUsage = a.turnUsage
+	t.isLoading = a.isLoading
+	t.cancelCommand = a.cancelCommand
+	t.run = a.run
+	t.scrollPosition = a.viewport.YOffset
+	t.follow = a.follow
+	t.streaming = a.streaming
+	t.search = a.search
+	t.selectedTool = a.selectedTool
+	t.expandedTools = a.expandedTools
+	t.renderModes = a.renderModes
+	t.selectedMessage = a.selectedMessage
+	t.nextModel = a.nextModel
+	t.retrySuggestions = a.retrySuggestions
+	t.retryIndex = a.retryIndex
+	t.quickReplies = a.quickReplies
+	t.rawLines = a.rawLines
+	t.stderrLines = a.stderrLines
+	t.stderrUnread = a.stderrUnread
+}
+
+// loadTab makes the tab at index the active one and lays it out afresh
+func (a *Application) loadTab(index int) {
+	a.swapInTab(index)
+	a.invalidateLayout()
+}
+
+// swapInTab copies the state of the tab at index into the active fields,
+// leaving the rendered conversation alone
+func (a *Application) swapInTab(index int) {
+	t := a.tabs[index]
+	a.activeTab = index
+	a.sessionManager = t.sessionManager
+	a.eventBus = t.eventBus
+	a.eventProcessor = t.eventProcessor
+	a.currentSession = t.currentSession
+	a.sessionStats = t.sessionStats
+	a.messages = t.messages
+	a.errors = t.errors
+	a.toolActivity = t.toolActivity
+	a.lastTurn = t.lastTurn
+	a.turnUsage = t.turnUsage
+	a.isLoading = t.isLoading
+	a.cancelCommand = t.cancelCommand
+	a.run = t.run
+	a.viewport.YOffset = t.scrollPosition
+	a.follow = t.follow
+	a.streaming = t.streaming
+	a.search = t.search
+	a.selectedTool = t.selectedTool
+	a.expandedTools = t.expandedTools
+	a.renderModes = t.renderModes
+	a.selectedMessage = t.selectedMessage
+	a.nextModel = t.nextModel
+	a.retrySuggestions = t.retrySuggestions
+	a.retryIndex = t.retryIndex
+	a.quickReplies = t.quickReplies
+	a.rawLines = t.rawLines
+	a.stderrLines = t.stderrLines
+	a.stderrUnread = t.stderrUnread
+	t.unseen = false
+}
+
+// switchTab activates the tab at index
+func (a *Application) switchTab(index int) {
+	if index < 0 || index >= len(a.tabs) || index == a.activeTab {
+		return
+	}
+	a.saveActiveTab()
+	a.loadTab(index)
+	a.clampScrollPosition()
+	a.selection = textSelection{}
+}
+
+// openTab starts a new conversation in its own tab and switches to it
+func (a *Application) openTab() (tea.Model, tea.Cmd) {
+	if a.newSession == nil {
+		a.statusMessage = "[tabs] Tabs are not available"
+		return a, nil
+	}
+	if len(a.tabs) >= maxTabs {
+		a.statusMessage = fmt.Sprintf("[tabs] At most %d tabs can be open", maxTabs)
+		return a, nil
+	}
+	a.newTab(a.newSession())
+	a.switchTab(len(a.tabs) - 1)
+	a.log.Info("opened tab", "tab", a.tabs[a.activeTab].id, "tabs", len(a.tabs))
+	a.notify(toastInfo, fmt.Sprintf("Opened tab %d", len(a.tabs)))
+	return a, nil
+}
+
+// closeTab cancels and removes the active tab
+func (a *Application) closeTab() (tea.Model, tea.Cmd) {
+	if len(a.tabs) == 1 {
+		a.statusMessage = "[tabs] Cannot close the last tab"
+		return a, nil
+	}
+	if a.cancelCommand != nil {
+		a.cancelCommand()
+	}
+	a.eventBus.Shutdown()
+	a.sessionManager.ReleaseSessionLock()
+
+	closed := a.activeTab
+	a.log.Info("closed tab", "tab", a.tabs[closed].id, "tabs", len(a.tabs)-1)
+	a.tabs = append(a.tabs[:closed], a.tabs[closed+1:]...)
+	a.loadTab(min(closed, len(a.tabs)-1))
+	a.clampScrollPosition()
+	a.selection = textSelection{}
+	a.notify(toastInfo, fmt.Sprintf("Closed tab %d", closed+1))
+	return a, nil
+}
+
+// handleTabMsg applies a message to the tab that produced it. Messages for
+// background tabs are applied with that tab temporarily swapped in, around
+// the active tab's rendered conversation so that stays valid. Commands are
+// routed back to the tab whichever is active when they finish.
+func (a *Application) handleTabMsg(msg TabMsg) (tea.Model, tea.Cmd) {
+	index := -1
+	for i, t := range a.tabs {
+		if t.id == msg.TabID {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return a, nil
+	}
+	if index == a.activeTab {
+		_, cmd := a.update(msg.Msg)
+		return a, routeToTab(msg.TabID, cmd)
+	}
+
+	active, rendered := a.activeTab, a.saveRendered()
+	a.saveActiveTab()
+	a.swapInTab(index)
+	_, cmd := a.update(msg.Msg)
+	a.saveActiveTab()
+	a.swapInTab(active)
+	a.restoreRendered(rendered)
+
+	switch msg.Msg.(type) {
+	case MessageStreamMsg, TurnCompleteMsg, ErrorMsg, CommandCancelledMsg:
+		a.tabs[index].unseen = true
+	}
+	return a, routeToTab(msg.TabID, cmd)
+}
+
+// renderedConversation is the active tab's conversation as laid out and
+// loaded into the viewport
+type renderedConversation struct {
+	layout        conversationLayout
+	generation    int
+	cache         map[string]renderedMessage
+	viewport      viewport.Model
+	viewportBuild int
+}
+
+// saveRendered sets the active tab's rendered conversation aside, leaving
+// an empty one for a background tab to lay itself out into
+func (a *Application) saveRendered() renderedConversation {
+	rendered := renderedConversation{
+		layout:        a.convLayout,
+		generation:    a.layoutGeneration,
+		cache:         a.renderCache,
+		viewport:      a.viewport,
+		viewportBuild: a.viewportBuild,
+	}
+	a.convLayout = conversationLayout{}
+	a.renderCache = nil
+	a.viewportBuild = 0
+	return rendered
+}
+
+// restoreRendered puts back a rendered conversation set aside by
+// saveRendered
+func (a *Application) restoreRendered(rendered renderedConversation) {
+	a.convLayout = rendered.layout
+	a.layoutGeneration = rendered.generation
+	a.renderCache = rendered.cache
+	a.viewport = rendered.viewport
+	a.viewportBuild = rendered.viewportBuild
+}
+
+// routeToTab wraps cmd so that the message it produces is applied to the
+// tab with the given ID, not to whichever tab is active by then
+func routeToTab(id int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case TabMsg:
+			return msg
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				cmds[i] = routeToTab(id, c)
+			}
+			return cmds
+		default:
+			return TabMsg{TabID: id, Msg: msg}
+		}
+	}
+}
+
+// handleTabKey handles tab shortcuts outside of insert mode: Ctrl+T opens,
+// Ctrl+W closes, Ctrl/Alt+1..9 jump and gt/gT cycle
+func (a *Application) handleTabKey(key string) (bool, tea.Model, tea.Cmd) {
+	if a.tabPrefix {
+		a.tabPrefix = false
+		switch key {
+		case "t":
+			a.switchTab((a.activeTab + 1) % len(a.tabs))
+			return true, a, nil
+		case "T":
+			a.switchTab((a.activeTab + len(a.tabs) - 1) % len(a.tabs))
+			return true, a, nil
+		}
+	}
+
+	switch key {
+	case "ctrl+t":
+		model, cmd := a.openTab()
+		return true, model, cmd
+	case "ctrl+w":
+		model, cmd := a.closeTab()
+		return true, model, cmd
+	case "g":
+		if !a.inputActive {
+			a.tabPrefix = true
+			return true, a, nil
+		}
+	}
+
+	for _, prefix := range []string{"ctrl+", "alt+"} {
+		if digit, ok := strings.CutPrefix(key, prefix); ok && len(digit) == 1 && digit >= "1" && digit <= "9" {
+			a.switchTab(int(digit[0] - '1'))
+			return true, a, nil
+		}
+	}
+	return false, a, nil
+}
+
+// renderTabBar renders the tab labels, marking the active tab and tabs with
+// unseen activity. It is empty while only one tab is open.
+func (a *Application) renderTabBar() string {
+	if len(a.tabs) < 2 {
+		return ""
+	}
+	labels := make([]string, 0, len(a.tabs))
+	for i, t := range a.tabs {
+		title := t.sessionManager.Title()
+		if title == "" {
+			title = "new"
+		}
+		label := fmt.Sprintf("%d:%s", i+1, truncateString(title, 16))
+		switch {
+		case i == a.activeTab:
+			label = "[" + label + "]"
+		case t.isLoading:
+			label += " ⟳"
+		case t.unseen:
+			label += " ●"
+		}
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, "  ")
+}
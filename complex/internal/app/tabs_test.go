@@ -0,0 +1,68 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/claude"
+)
+
+func TestBackgroundTabKeepsActiveLayout(t *testing.T) {
+	active := []claude.ConversationMessage{{ID: "msg_1", Type: "user", Content: "hello"}}
+	a := &Application{
+		tabs: []*tab{
+			{id: 1},
+			{id: 2, messages: []claude.ConversationMessage{{ID: "toolu_1", Type: "tool_use", ToolUseID: "toolu_1", Content: "Bash"}}},
+		},
+		messages:         active,
+		convLayout:       conversationLayout{build: 3, count: 1, lines: []string{"hello"}},
+		layoutGeneration: 7,
+		viewportBuild:    3,
+	}
+
+	// Completing a tool changes the background conversation in place
+	a.handleTabMsg(TabMsg{TabID: 2, Msg: ToolActivityMsg{ToolUseID: "toolu_1", Status: "completed", Output: "ok"}})
+
+	if a.layoutGeneration != 7 || a.convLayout.build != 3 || a.viewportBuild != 3 {
+		t.Errorf("active layout changed: generation %d, build %d, viewport build %d",
+			a.layoutGeneration, a.convLayout.build, a.viewportBuild)
+	}
+	if a.activeTab != 0 || len(a.messages) != 1 || a.messages[0].ID != "msg_1" {
+		t.Errorf("active tab %d shows %+v", a.activeTab, a.messages)
+	}
+	if got := a.tabs[1].messages[0].ToolResult; got != "ok" {
+		t.Errorf("background tool result = %q, want ok", got)
+	}
+	if len(a.tabs[1].toolActivity) != 1 {
+		t.Errorf("background tool activity = %+v", a.tabs[1].toolActivity)
+	}
+}
+
+func TestRouteToTabWrapsCommandResults(t *testing.T) {
+	status := func() tea.Msg { return StatusMsg{Message: "done"} }
+	routed := TabMsg{TabID: 3, Msg: StatusMsg{Message: "done"}}
+
+	if got := routeToTab(2, status)(); got != (TabMsg{TabID: 2, Msg: StatusMsg{Message: "done"}}) {
+		t.Errorf("routed = %#v", got)
+	}
+	if got := routeToTab(2, func() tea.Msg { return routed })(); got != routed {
+		t.Errorf("already routed = %#v, want %#v", got, routed)
+	}
+	if routeToTab(2, nil) != nil {
+		t.Error("nil command was wrapped")
+	}
+	if got := routeToTab(2, func() tea.Msg { return nil })(); got != nil {
+		t.Errorf("empty result = %#v", got)
+	}
+
+	batch, ok := routeToTab(2, tea.Batch(status, status))().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("batch = %#v", batch)
+	}
+	for _, cmd := range batch {
+		if got := cmd(); got != (TabMsg{TabID: 2, Msg: StatusMsg{Message: "done"}}) {
+			t.Errorf("batched = %#v", got)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"customclaude/pkg/platform"
+	"customclaude/templates"
+)
+
+// templateListLimit bounds the templates listed in the picker
+const templateListLimit = 12
+
+// loadTemplates reads the prompt templates from the config directory. They
+// are read on every use so edits apply without a restart.
+func (a *Application) loadTemplates() ([]templates.Template, string, bool) {
+	dir, err := platform.ConfigDir()
+	if err == nil {
+		dir = templates.Dir(dir)
+		var list []templates.Template
+		if list, err = templates.Load(dir); err == nil {
+			return list, dir, true
+		}
+	}
+	a.errors = append(a.errors, ErrorMsg{Error: err, Context: "templates"})
+	return nil, dir, false
+}
+
+// handleTemplateCommand runs "/t [name]", filling in and sending the named
+// template or picking one when no name is given
+func (a *Application) handleTemplateCommand(args []string) (tea.Model, tea.Cmd) {
+	list, dir, ok := a.loadTemplates()
+	if !ok {
+		return a, nil
+	}
+	if len(list) == 0 {
+		a.statusMessage = fmt.Sprintf("[templates] No templates yet; add <name>.md files to %s", dir)
+		return a, nil
+	}
+	if len(args) == 0 {
+		a.openTemplatePicker(list)
+		return a, nil
+	}
+
+	t, found := templates.Find(list, args[0])
+	if !found {
+		a.statusMessage = fmt.Sprintf("[templates] No template matches %q", args[0])
+		return a, nil
+	}
+	return a.fillTemplate(t, nil)
+}
+
+// openTemplatePicker asks for a template by name, listing the available ones
+func (a *Application) openTemplatePicker(list []templates.Template) {
+	var body []string
+	for _, t := range list[:min(len(list), templateListLimit)] {
+		body = append(body, fmt.Sprintf("  %s - %s", t.Name, truncateString(t.Summary(), 50)))
+	}
+	if len(list) > templateListLimit {
+		body = append(body, fmt.Sprintf("  … %d more", len(list)-templateListLimit))
+	}
+
+	d := newInputDialog("Prompt template", body, "Template: ", "",
+		func(result dialogResult) (tea.Model, tea.Cmd) {
+			if result.cancelled {
+				return a, nil
+			}
+			t, _ := templates.Find(list, strings.TrimSpace(result.input))
+			return a.fillTemplate(t, nil)
+		},
+	)
+	d.accept = func(text string) bool {
+		_, found := templates.Find(list, strings.TrimSpace(text))
+		return found
+	}
+	d.hint = "Enter a name or a unique prefix"
+	a.openDialog(d)
+}
+
+// fillTemplate asks for the placeholders not yet in values, one dialog at a
+// time, then sends the expanded prompt
+func (a *Application) fillTemplate(t templates.Template, values map[string]string) (tea.Model, tea.Cmd) {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	placeholders := t.Placeholders()
+	for i, name := range placeholders {
+		if _, done := values[name]; done {
+			continue
+		}
+		a.openDialog(newInputDialog(
+			"Template: "+t.Name,
+			[]string{fmt.Sprintf("Placeholder %d of %d", i+1, len(placeholders))},
+			name+": ", "",
+			func(result dialogResult) (tea.Model, tea.Cmd) {
+				if result.cancelled {
+					return a, nil
+				}
+				values[name] = result.input
+				return a.fillTemplate(t, values)
+			},
+		))
+		return a, nil
+	}
+
+	prompt := strings.TrimSpace(t.Expand(values))
+	if prompt == "" {
+		a.statusMessage = fmt.Sprintf("[templates] Template %s is empty", t.Name)
+		return a, nil
+	}
+	a.isLoading = true
+	return a.handlePromptInput(PromptInputMsg{
+		Prompt: prompt,
+		Resume: a.sessionManager.CurrentSessionID != "",
+	})
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+)
+
+// timestampMode decides how message times are shown in the conversation
+type timestampMode int
+
+const (
+	timestampsOff timestampMode = iota
+	timestampsAbsolute
+	timestampsRelative
+)
+
+// timestampWidth is the column taken by a message time and its gap
+const timestampWidth = 9
+
+// parseTimestampMode reads the timestamps setting; empty is off
+func parseTimestampMode(value string) (timestampMode, error) {
+	switch value {
+	case "", "off":
+		return timestampsOff, nil
+	case "absolute", "on":
+		return timestampsAbsolute, nil
+	case "relative":
+		return timestampsRelative, nil
+	}
+	return timestampsOff, fmt.Errorf("unknown timestamps setting %q (absolute, relative or off)", value)
+}
+
+// String is the name of the mode as :set takes it
+func (m timestampMode) String() string {
+	switch m {
+	case timestampsAbsolute:
+		return "absolute"
+	case timestampsRelative:
+		return "relative"
+	}
+	return "off"
+}
+
+// handleSetCommand runs ":set <option>". Only timestamps is known:
+// ":set timestamps" toggles them in the configured style, absolute unless
+// set, ":set timestamps=relative" (or absolute, off) picks the style and
+// ":set notimestamps" hides them.
+func (a *Application) handleSetCommand(args []string) (tea.Model, tea.Cmd) {
+	a.isLoading = false
+
+	if len(args) == 0 {
+		a.statusMessage = fmt.Sprintf("[set] timestamps=%s", a.timestamps)
+		return a, nil
+	}
+
+	option, value, hasValue := strings.Cut(args[0], "=")
+	switch {
+	case option == "notimestamps":
+		a.timestamps = timestampsOff
+	case option == "timestamps" && hasValue:
+		mode, err := parseTimestampMode(value)
+		if err != nil {
+			a.statusMessage = "[set] " + err.Error()
+			return a, nil
+		}
+		a.timestamps = mode
+	case option == "timestamps":
+		if a.timestamps != timestampsOff {
+			a.timestamps = timestampsOff
+		} else if configured, _ := parseTimestampMode(a.config.Timestamps); configured != timestampsOff {
+			a.timestamps = configured
+		} else {
+			a.timestamps = timestampsAbsolute
+		}
+	default:
+		a.statusMessage = fmt.Sprintf("[set] Unknown option: %s", option)
+		return a, nil
+	}
+
+	a.statusMessage = fmt.Sprintf("[set] timestamps=%s", a.timestamps)
+	return a, nil
+}
+
+// timestampLabel renders the time of a message in a fixed-width column, or
+// blanks for messages without one
+func (a *Application) timestampLabel(t time.Time, now time.Time) string {
+	label := ""
+	if !t.IsZero() {
+		if a.timestamps == timestampsRelative {
+			label = relativeTime(now.Sub(t))
+		} else {
+			label = t.Local().Format("15:04:05")
+		}
+	}
+	return a.styles.Status.Render(fmt.Sprintf("%-*s", timestampWidth, label))
+}
+
+// relativeTime renders how long ago something happened in at most eight
+// characters
+func relativeTime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+// dateSeparator returns the line starting a new day before msg, or "" when
+// msg is on the same day as prev. The first message gets one only when the
+// conversation spans several days.
+func (a *Application) dateSeparator(prev, msg claude.ConversationMessage, first bool, width int) string {
+	if msg.Timestamp.IsZero() {
+		return ""
+	}
+	day := msg.Timestamp.Local().Format("2006-01-02")
+	if first {
+		last := a.messages[len(a.messages)-1].Timestamp
+		if last.IsZero() || last.Local().Format("2006-01-02") == day {
+			return ""
+		}
+	} else if prev.Timestamp.IsZero() || prev.Timestamp.Local().Format("2006-01-02") == day {
+		return ""
+	}
+
+	label := " " + msg.Timestamp.Local().Format("Mon Jan 2, 2006") + " "
+	side := max(2, (width-ansi.StringWidth(label))/2)
+	return a.styles.Status.Render(truncateString(strings.Repeat("─", side)+label+strings.Repeat("─", side), width))
+}
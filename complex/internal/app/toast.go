@@ -0,0 +1,115 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Toast limits: how long one stays up, how many stack at once and how wide
+// they are
+const (
+	toastTimeout = 4 * time.Second
+	maxToasts    = 4
+	toastWidth   = 44
+)
+
+// toastLevel colors a toast by what it reports
+type toastLevel int
+
+const (
+	toastInfo toastLevel = iota
+	toastSuccess
+	toastWarning
+	toastError
+)
+
+// toast is a transient notification stacked in the top-right corner
+type toast struct {
+	id      int
+	level   toastLevel
+	text    string
+	expires time.Time
+}
+
+// toastTickMsg is sent when the oldest toast is due to expire
+type toastTickMsg struct{}
+
+// notify shows a toast. Feedback that is done once the user has seen it goes
+// here rather than in the status line.
+func (a *Application) notify(level toastLevel, text string) {
+	a.nextToastID++
+	a.toasts = append(a.toasts, toast{
+		id:      a.nextToastID,
+		level:   level,
+		text:    text,
+		expires: time.Now().Add(toastTimeout),
+	})
+	if len(a.toasts) > maxToasts {
+		a.toasts = a.toasts[len(a.toasts)-maxToasts:]
+	}
+}
+
+// scheduleToastExpiry returns a tick for the next toast to expire, unless
+// one is already pending
+func (a *Application) scheduleToastExpiry() tea.Cmd {
+	if a.toastTicking || len(a.toasts) == 0 {
+		return nil
+	}
+	a.toastTicking = true
+	return tea.Tick(time.Until(a.toasts[0].expires), func(time.Time) tea.Msg {
+		return toastTickMsg{}
+	})
+}
+
+// expireToasts drops the toasts whose time is up
+func (a *Application) expireToasts() {
+	a.toastTicking = false
+	now := time.Now()
+	kept := a.toasts[:0]
+	for _, t := range a.toasts {
+		if t.expires.After(now) {
+			kept = append(kept, t)
+		}
+	}
+	a.toasts = kept
+}
+
+// overlayToasts draws the toasts over the top-right corner of a rendered view
+func (a *Application) overlayToasts(view string) string {
+	if len(a.toasts) == 0 || a.width < toastWidth+10 {
+		return view
+	}
+
+	var boxes []string
+	for i := len(a.toasts) - 1; i >= 0; i-- {
+		t := a.toasts[i]
+		style := a.styles.Toast
+		switch t.level {
+		case toastSuccess:
+			style = style.BorderForeground(lipgloss.Color("42"))
+		case toastWarning:
+			style = style.BorderForeground(lipgloss.Color("214"))
+		case toastError:
+			style = style.BorderForeground(lipgloss.Color("196"))
+		}
+		boxes = append(boxes, style.Width(toastWidth).Render(wordWrap(t.text, toastWidth-2)))
+	}
+	stack := strings.Split(lipgloss.JoinVertical(lipgloss.Right, boxes...), "\n")
+
+	lines := strings.Split(view, "\n")
+	left := a.width - lipgloss.Width(stack[0]) - 1
+	for i, box := range stack {
+		row := i + 1
+		for len(lines) <= row {
+			lines = append(lines, "")
+		}
+		base := ansi.Truncate(lines[row], left, "")
+		pad := max(0, left-ansi.StringWidth(base))
+		lines[row] = base + "\x1b[0m" + strings.Repeat(" ", pad) + box
+	}
+	return strings.Join(lines, "\n")
+}
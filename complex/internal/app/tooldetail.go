@@ -0,0 +1,205 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"complex/internal/claude"
+)
+
+// maxToolDetailLines caps the output shown when a tool message is expanded
+const maxToolDetailLines = 200
+
+// toolArgumentKeys are the input fields that best describe a tool call, in
+// order of preference
+var toolArgumentKeys = []string{"command", "file_path", "notebook_path", "path", "pattern", "url", "query", "description", "prompt"}
+
+// attachToolResult stores a finished tool's output on its tool_use message
+func (a *Application) attachToolResult(msg ToolActivityMsg) {
+	if msg.ToolUseID == "" || msg.Status == "running" {
+		return
+	}
+	for i := len(a.messages) - 1; i >= 0; i-- {
+		if a.messages[i].Type == "tool_use" && a.messages[i].ToolUseID == msg.ToolUseID {
+			a.messages[i].ToolResult = msg.Output
+			a.messages[i].ToolStatus = msg.Status
+			a.messages[i].IsError = msg.Status == "failed"
+			a.invalidateLayout()
+			return
+		}
+	}
+}
+
+// toolArgument returns the main argument of a tool call from its input
+func toolArgument(input json.RawMessage) string {
+	if len(input) == 0 {
+		return ""
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return ""
+	}
+	for _, key := range toolArgumentKeys {
+		if value, ok := fields[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// toolSummary is the one-line form of a tool message: the tool, its main
+// argument and the outcome once the result is in
+func toolSummary(msg claude.ConversationMessage) string {
+	if msg.ToolName == "" {
+		return msg.Content
+	}
+
+	summary := msg.ToolName
+	if arg := toolArgument(msg.ToolInput); arg != "" {
+		first, _, multiline := strings.Cut(arg, "\n")
+		if multiline {
+			first += " ..."
+		}
+		summary += ": " + truncateString(first, 60)
+	}
+	if stat := diffStat(msg); stat != "" {
+		summary += " (" + stat + ")"
+	}
+
+	switch msg.ToolStatus {
+	case "running":
+		summary += " (running)"
+	case "failed":
+		summary += " → failed"
+	case "completed":
+		if n := countLines(msg.ToolResult); n > 0 {
+			summary += fmt.Sprintf(" → %d lines", n)
+		} else {
+			summary += " → done"
+		}
+	}
+	return summary
+}
+
+// countLines counts the lines of text, ignoring a trailing newline
+func countLines(text string) int {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return 0
+	}
+	return strings.Count(text, "\n") + 1
+}
+
+// toolDetail renders the full input and output of an expanded tool message,
+// with code in them highlighted
+func (a *Application) toolDetail(msg claude.ConversationMessage, width int) string {
+	var lines []string
+	var code []bool
+	add := func(line string, isCode bool) {
+		lines, code = append(lines, line), append(code, isCode)
+	}
+
+	if len(msg.ToolInput) > 0 && string(msg.ToolInput) != "null" {
+		var pretty bytes.Buffer
+		input := string(msg.ToolInput)
+		language := ""
+		if err := json.Indent(&pretty, msg.ToolInput, "", "  "); err == nil {
+			input, language = pretty.String(), "json"
+		}
+		add("Input:", false)
+		for _, line := range a.highlighter.Highlight(input, language) {
+			add("  "+line, language != "")
+		}
+	}
+
+	switch {
+	case msg.ToolStatus == "running":
+		add("Output: (running)", false)
+	case msg.ToolResult == "":
+		add("Output: (empty)", false)
+	default:
+		add("Output:", false)
+		output := strings.Split(strings.TrimRight(msg.ToolResult, "\n"), "\n")
+		hidden := 0
+		if len(output) > maxToolDetailLines {
+			hidden = len(output) - maxToolDetailLines
+			output = output[:maxToolDetailLines]
+		}
+		highlighted, isCode := a.highlightToolOutput(msg, output)
+		for i, line := range highlighted {
+			add("  "+line, isCode[i])
+		}
+		if hidden > 0 {
+			add(fmt.Sprintf("  ... %d more lines", hidden), false)
+		}
+	}
+
+	// Code keeps its indentation, so it is broken rather than reflowed
+	var wrapped []string
+	for i, line := range lines {
+		if code[i] {
+			wrapped = append(wrapped, strings.Split(ansi.Hardwrap(line, max(1, width-3), true), "\n")...)
+		} else {
+			wrapped = append(wrapped, strings.Split(wordWrap(line, width-3), "\n")...)
+		}
+	}
+	for i, line := range wrapped {
+		wrapped[i] = "   " + line
+	}
+
+	style := a.styles.Status
+	if msg.IsError {
+		style = a.styles.Error
+	}
+	return style.Render(strings.Join(wrapped, "\n"))
+}
+
+// selectTool moves the tool message selection to the next (dir 1) or previous
+// (dir -1) tool message and scrolls it into view
+func (a *Application) selectTool(dir int) {
+	var tools []int
+	current := -1
+	for i, msg := range a.messages {
+		if msg.Type != "tool_use" || msg.ToolUseID == "" {
+			continue
+		}
+		if msg.ToolUseID == a.selectedTool {
+			current = len(tools)
+		}
+		tools = append(tools, i)
+	}
+	if len(tools) == 0 {
+		a.statusMessage = "[tools] No tool messages to select"
+		return
+	}
+
+	next := current + dir
+	switch {
+	case current < 0 && dir < 0:
+		next = len(tools) - 1
+	case next < 0:
+		next = len(tools) - 1
+	case next >= len(tools):
+		next = 0
+	}
+
+	index := tools[next]
+	a.selectedTool = a.messages[index].ToolUseID
+	a.scrollToMessage(index)
+	a.statusMessage = fmt.Sprintf("[tools] %s selected (Enter: expand/collapse)", a.messages[index].ToolName)
+}
+
+// toggleSelectedTool expands or collapses the selected tool message
+func (a *Application) toggleSelectedTool() {
+	if a.expandedTools[a.selectedTool] {
+		delete(a.expandedTools, a.selectedTool)
+	} else {
+		a.expandedTools[a.selectedTool] = true
+	}
+	a.invalidateLayout()
+	a.clampScrollPosition()
+}
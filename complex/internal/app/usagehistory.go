@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+
+	"complex/internal/claude"
+	"complex/internal/ui/components"
+)
+
+// sparklineTurns is how many recent turns the sidebar sparklines cover
+const sparklineTurns = 12
+
+// turnUsage is the tokens and cost of one finished turn
+type turnUsage struct {
+	tokens int
+	cost   float64
+}
+
+// recordTurnUsage adds a finished turn to the sidebar sparklines
+func (a *Application) recordTurnUsage(turn claude.TurnResult) {
+	usage := turn.Usage
+	a.turnUsage = append(a.turnUsage, turnUsage{
+		tokens: usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens + usage.OutputTokens,
+		cost:   turn.CostUSD,
+	})
+	if len(a.turnUsage) > sparklineTurns {
+		a.turnUsage = a.turnUsage[len(a.turnUsage)-sparklineTurns:]
+	}
+}
+
+// usageLines renders the sidebar's token usage: sparklines of tokens and
+// cost per turn, with the last turn's figures and whether it cost more than
+// the turns before it, then the conversation totals
+func (a *Application) usageLines() []string {
+	total := a.sessionStats.CumulativeUsage
+	if total.InputTokens == 0 && len(a.turnUsage) == 0 {
+		return nil
+	}
+
+	lines := []string{a.styles.Highlight.Render("Token Usage")}
+	if n := len(a.turnUsage); n > 0 {
+		tokens := make([]float64, n)
+		costs := make([]float64, n)
+		for i, turn := range a.turnUsage {
+			tokens[i], costs[i] = float64(turn.tokens), turn.cost
+		}
+		last := a.turnUsage[n-1]
+		lines = append(lines,
+			fmt.Sprintf("Tok  %-*s %s%s", sparklineTurns, components.Sparkline(tokens), formatTokens(last.tokens), trendMarker(tokens)),
+			fmt.Sprintf("Cost %-*s $%s%s", sparklineTurns, components.Sparkline(costs), formatCost(last.cost), trendMarker(costs)),
+		)
+	}
+	lines = append(lines,
+		fmt.Sprintf("In %s · Out %s", formatTokens(total.InputTokens), formatTokens(total.OutputTokens)),
+		fmt.Sprintf("Cache %s", formatTokens(total.CacheReadInputTokens)),
+	)
+	return lines
+}
+
+// trendMarker compares the last value with the average of the ones before
+// it: ↑ for a fifth or more above, ↓ for a fifth or more below
+func trendMarker(values []float64) string {
+	if len(values) < 2 {
+		return ""
+	}
+	sum := 0.0
+	for _, v := range values[:len(values)-1] {
+		sum += v
+	}
+	avg := sum / float64(len(values)-1)
+	last := values[len(values)-1]
+	switch {
+	case avg <= 0:
+		return ""
+	case last >= avg*1.2:
+		return " ↑"
+	case last <= avg*0.8:
+		return " ↓"
+	}
+	return ""
+}
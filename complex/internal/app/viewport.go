@@ -0,0 +1,85 @@
+package app
+
+import "strings"
+
+// conversationSize is the width and height of the conversation viewport:
+// the panel's inner size less the two rows kept for the "new messages" pill
+func (a *Application) conversationSize() (int, int) {
+	dims := a.layout().CalculatePanelDimensions()
+	return max(1, dims.ConversationWidth-4), max(1, max(1, dims.ConversationHeight-4)-2)
+}
+
+// conversationContentWidth returns the inner width of the conversation panel
+func (a *Application) conversationContentWidth() int {
+	width, _ := a.conversationSize()
+	return width
+}
+
+// syncViewport lays the conversation out at the current size and loads the
+// lines into the viewport, keeping the scroll offset in range. It returns
+// the index of the first line of each message. The viewport is only
+// reloaded when the layout changed, so scrolling costs the same however
+// long the conversation is.
+func (a *Application) syncViewport() []int {
+	width, height := a.conversationSize()
+	lines, offsets := a.conversationLines(width)
+	a.viewport.Width, a.viewport.Height = width, height
+	if a.viewportBuild != a.convLayout.build {
+		a.viewport.SetContent(strings.Join(lines, "\n"))
+		a.viewportBuild = a.convLayout.build
+	}
+	a.viewport.SetYOffset(a.viewport.YOffset)
+	return offsets
+}
+
+// atTail reports whether the conversation panel shows its last line
+func (a *Application) atTail() bool {
+	a.syncViewport()
+	return a.viewport.AtBottom()
+}
+
+// scrollTo puts a conversation line at the top of the panel, as far as the
+// conversation reaches
+func (a *Application) scrollTo(line int) {
+	a.syncViewport()
+	a.viewport.SetYOffset(line)
+}
+
+// scrollToMessage puts the first line of a message at the top of the panel
+func (a *Application) scrollToMessage(index int) {
+	offsets := a.syncViewport()
+	a.viewport.SetYOffset(offsets[index])
+}
+
+func (a *Application) clampScrollPosition() {
+	a.syncViewport()
+}
+
+func (a *Application) scrollToBottomSafe() {
+	a.syncViewport()
+	a.viewport.GotoBottom()
+}
+
+// Scrolling methods
+func (a *Application) scrollUp() {
+	a.viewport.ScrollUp(1)
+}
+
+func (a *Application) scrollDown() {
+	a.syncViewport()
+	a.viewport.ScrollDown(1)
+}
+
+func (a *Application) scrollPageUp() {
+	a.syncViewport()
+	a.viewport.PageUp()
+}
+
+func (a *Application) scrollPageDown() {
+	a.syncViewport()
+	a.viewport.PageDown()
+}
+
+func (a *Application) scrollToTop() {
+	a.viewport.GotoTop()
+}
@@ -0,0 +1,393 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"complex/internal/clipboard"
+)
+
+// maxUndo caps the undo history of the input line
+const maxUndo = 100
+
+// vimState holds the input editor's visual selection, registers, undo
+// history and the last change, which "." repeats
+type vimState struct {
+	anchor int // the other end of the visual selection
+
+	registers        map[string]register
+	register         string // chosen with "x for the next yank, delete or put
+	awaitingRegister bool
+
+	undo, redo []inputSnapshot
+
+	// Keys of the change being typed and the input before it; once the
+	// editor is back in normal mode a change that edited the input becomes
+	// the one "." replays
+	keys        []tea.KeyMsg
+	before      inputSnapshot
+	lastChange  []tea.KeyMsg
+	replaying   bool
+	skipHistory bool // undo and redo are not changes themselves
+}
+
+// register is yanked or deleted text; linewise text is put on its own line
+type register struct {
+	text     string
+	linewise bool
+}
+
+// inputSnapshot is the input line at one point of the undo history
+type inputSnapshot struct {
+	buffer string
+	cursor int
+}
+
+// beginVimKey records a key of the change being typed
+func (a *Application) beginVimKey(msg tea.KeyMsg) {
+	if len(a.vim.keys) == 0 {
+		a.vim.before = inputSnapshot{a.inputBuffer, a.cursorPos}
+		a.vim.skipHistory = false
+	}
+	a.vim.keys = append(a.vim.keys, msg)
+}
+
+// endVimKey closes the change once the editor is idle in normal mode: an
+// edit goes on the undo history and becomes the change "." repeats
+func (a *Application) endVimKey() {
+	if !a.inputActive {
+		a.vim.keys = nil
+		return
+	}
+	if a.inputMode != InputModeNormal || a.commandBuffer != "" || a.vim.awaitingRegister {
+		return
+	}
+
+	keys := a.vim.keys
+	a.vim.keys = nil
+	if a.vim.skipHistory || a.inputBuffer == a.vim.before.buffer {
+		return
+	}
+	a.vim.undo = append(a.vim.undo, a.vim.before)
+	if len(a.vim.undo) > maxUndo {
+		a.vim.undo = a.vim.undo[1:]
+	}
+	a.vim.redo = nil
+	if keys[0].String() != "." {
+		a.vim.lastChange = keys
+	}
+}
+
+// handleVimKey handles the normal and visual mode commands beyond the
+// basic motions: visual selection, registers, undo/redo and repeat
+func (a *Application) handleVimKey(key string) (bool, tea.Model, tea.Cmd) {
+	if a.vim.awaitingRegister {
+		a.vim.awaitingRegister = false
+		if len(key) == 1 && (key == "\"" || key == "+" || (key[0] >= 'a' && key[0] <= 'z')) {
+			a.vim.register = key
+		}
+		return true, a, nil
+	}
+	if key == "\"" {
+		a.vim.awaitingRegister = true
+		return true, a, nil
+	}
+
+	if a.inputMode == InputModeVisual || a.inputMode == InputModeVisualLine {
+		return true, a, a.handleVisualKey(key)
+	}
+
+	if handled, cmd := a.handleComposeNormalKey(key); handled {
+		return true, a, cmd
+	}
+
+	switch {
+	case key == "v" || key == "V":
+		a.startVisual(key)
+	case key == "y" && a.commandBuffer == "y":
+		// yy - yank the line
+		start, end := lineBounds(a.inputBuffer, a.cursorPos)
+		a.yank(a.inputBuffer[start:end], true)
+		a.commandBuffer = ""
+	case key == "y":
+		a.commandBuffer = "y"
+	case key == "w" && a.commandBuffer == "y":
+		// yw - yank to the start of the next word
+		start := a.cursorPos
+		a.moveWordForward()
+		end := a.cursorPos
+		if end == len(a.inputBuffer)-1 {
+			end = len(a.inputBuffer)
+		}
+		a.cursorPos = start
+		a.yank(a.inputBuffer[start:end], false)
+		a.commandBuffer = ""
+	case key == "p" || key == "P":
+		a.put(key == "P")
+	case key == "u":
+		a.undoInput()
+	case key == "ctrl+r":
+		a.redoInput()
+	case key == ".":
+		a.repeatChange()
+	default:
+		return false, a, nil
+	}
+	return true, a, nil
+}
+
+// startVisual enters characterwise (v) or linewise (V) visual mode
+func (a *Application) startVisual(key string) {
+	a.vim.anchor = a.cursorPos
+	a.inputMode = InputModeVisual
+	if key == "V" {
+		a.inputMode = InputModeVisualLine
+	}
+	a.commandBuffer = ""
+}
+
+// handleVisualKey moves and acts on the visual selection
+func (a *Application) handleVisualKey(key string) tea.Cmd {
+	switch key {
+	case "h", "left":
+		if a.cursorPos > 0 {
+			a.cursorPos--
+		}
+	case "l", "right":
+		if a.cursorPos < len(a.inputBuffer)-1 {
+			a.cursorPos++
+		}
+	case "w":
+		a.moveWordForward()
+	case "b":
+		a.moveWordBackward()
+	case "0":
+		a.cursorPos = 0
+	case "$":
+		a.cursorPos = max(0, len(a.inputBuffer)-1)
+	case "o":
+		a.vim.anchor, a.cursorPos = a.cursorPos, a.vim.anchor
+	case "v", "V":
+		mode := InputModeVisual
+		if key == "V" {
+			mode = InputModeVisualLine
+		}
+		if a.inputMode == mode {
+			a.inputMode = InputModeNormal
+		} else {
+			a.inputMode = mode
+		}
+	case "y":
+		start, end := a.visualBounds()
+		a.yank(a.inputBuffer[start:end], a.inputMode == InputModeVisualLine)
+		a.inputMode = InputModeNormal
+		a.cursorPos = min(start, max(0, len(a.inputBuffer)-1))
+	case "d", "x":
+		a.deleteVisual()
+		a.inputMode = InputModeNormal
+	case "c":
+		a.cursorPos = a.deleteVisual()
+		a.inputMode = InputModeInsert
+	case "p":
+		// Put over the selection; the replaced text goes to the unnamed
+		// register, as in vim
+		reg := a.vim.registers[a.registerName()]
+		a.deleteVisual()
+		a.inputMode = InputModeNormal
+		a.insertText(reg.text, false)
+	case "esc", "ctrl+c":
+		a.inputMode = InputModeNormal
+		if key == "ctrl+c" {
+			return tea.Quit
+		}
+	}
+	return nil
+}
+
+// visualBounds returns the byte range of the visual selection
+func (a *Application) visualBounds() (int, int) {
+	start, end := min(a.vim.anchor, a.cursorPos), max(a.vim.anchor, a.cursorPos)
+	if a.inputMode == InputModeVisualLine {
+		start, _ = lineBounds(a.inputBuffer, start)
+		_, end = lineBounds(a.inputBuffer, end)
+		return start, end
+	}
+	return start, min(len(a.inputBuffer), end+1)
+}
+
+// deleteVisual deletes the visual selection into the chosen register,
+// returning where it started
+func (a *Application) deleteVisual() int {
+	start, end := a.visualBounds()
+	linewise := a.inputMode == InputModeVisualLine
+	if linewise && end < len(a.inputBuffer) {
+		end++ // the line break
+	} else if linewise && start > 0 {
+		start--
+	}
+	a.yank(a.inputBuffer[start:end], linewise)
+	a.inputBuffer = a.inputBuffer[:start] + a.inputBuffer[end:]
+	a.cursorPos = start
+	if a.cursorPos >= len(a.inputBuffer) && len(a.inputBuffer) > 0 {
+		a.cursorPos = len(a.inputBuffer) - 1
+	}
+	return start
+}
+
+// lineBounds returns the range of the line of text containing pos, without
+// its line break
+func lineBounds(text string, pos int) (int, int) {
+	pos = min(pos, len(text))
+	start := strings.LastIndexByte(text[:pos], '\n') + 1
+	end := len(text)
+	if i := strings.IndexByte(text[pos:], '\n'); i >= 0 {
+		end = pos + i
+	}
+	return start, end
+}
+
+// registerName returns the register chosen with "x for this command, or the
+// unnamed one, and resets the choice
+func (a *Application) registerName() string {
+	name := a.vim.register
+	a.vim.register = ""
+	if name == "" {
+		name = "\""
+	}
+	return name
+}
+
+// yank stores text in the chosen register and the unnamed one; the "+
+// register also copies it to the system clipboard
+func (a *Application) yank(text string, linewise bool) {
+	if a.vim.registers == nil {
+		a.vim.registers = make(map[string]register)
+	}
+	name := a.registerName()
+	reg := register{text: text, linewise: linewise}
+	a.vim.registers[name] = reg
+	a.vim.registers["\""] = reg
+	if name == "+" {
+		clipboard.CopyWithFallback(text)
+	}
+}
+
+// put inserts the chosen register after the cursor, or before it for P.
+// Linewise text goes on a line of its own.
+func (a *Application) put(before bool) {
+	reg, ok := a.vim.registers[a.registerName()]
+	if !ok || reg.text == "" {
+		a.statusMessage = "[vim] Register is empty"
+		return
+	}
+	if !reg.linewise {
+		if !before && a.inputBuffer != "" {
+			a.cursorPos = min(len(a.inputBuffer), a.cursorPos+1)
+		}
+		a.insertText(reg.text, false)
+		return
+	}
+
+	start, end := lineBounds(a.inputBuffer, a.cursorPos)
+	text := strings.TrimSuffix(reg.text, "\n")
+	switch {
+	case a.inputBuffer == "":
+		a.inputBuffer = text
+		a.cursorPos = 0
+	case before:
+		a.inputBuffer = a.inputBuffer[:start] + text + "\n" + a.inputBuffer[start:]
+		a.cursorPos = start
+	default:
+		a.inputBuffer = a.inputBuffer[:end] + "\n" + text + a.inputBuffer[end:]
+		a.cursorPos = end + 1
+	}
+}
+
+// insertText inserts text at the cursor, leaving the cursor on its last
+// character, or after it when after is set
+func (a *Application) insertText(text string, after bool) {
+	pos := min(a.cursorPos, len(a.inputBuffer))
+	a.inputBuffer = a.inputBuffer[:pos] + text + a.inputBuffer[pos:]
+	a.cursorPos = pos + len(text)
+	if !after && len(text) > 0 {
+		a.cursorPos--
+	}
+	if a.cursorPos >= len(a.inputBuffer) && len(a.inputBuffer) > 0 {
+		a.cursorPos = len(a.inputBuffer) - 1
+	}
+}
+
+// undoInput restores the input line before the last change
+func (a *Application) undoInput() {
+	a.vim.skipHistory = true
+	if len(a.vim.undo) == 0 {
+		a.statusMessage = "[vim] Already at oldest change"
+		return
+	}
+	last := a.vim.undo[len(a.vim.undo)-1]
+	a.vim.undo = a.vim.undo[:len(a.vim.undo)-1]
+	a.vim.redo = append(a.vim.redo, inputSnapshot{a.inputBuffer, a.cursorPos})
+	a.inputBuffer, a.cursorPos = last.buffer, last.cursor
+}
+
+// redoInput reapplies the last undone change
+func (a *Application) redoInput() {
+	a.vim.skipHistory = true
+	if len(a.vim.redo) == 0 {
+		a.statusMessage = "[vim] Already at newest change"
+		return
+	}
+	next := a.vim.redo[len(a.vim.redo)-1]
+	a.vim.redo = a.vim.redo[:len(a.vim.redo)-1]
+	a.vim.undo = append(a.vim.undo, inputSnapshot{a.inputBuffer, a.cursorPos})
+	a.inputBuffer, a.cursorPos = next.buffer, next.cursor
+}
+
+// repeatChange replays the keys of the last change at the cursor
+func (a *Application) repeatChange() {
+	if len(a.vim.lastChange) == 0 || a.vim.replaying {
+		return
+	}
+	a.vim.replaying = true
+	defer func() { a.vim.replaying = false }()
+	for _, msg := range a.vim.lastChange {
+		a.handleKeyPress(msg)
+		if !a.inputActive {
+			return
+		}
+	}
+}
+
+// vimModeLabel names the input mode for the input panel
+func (a *Application) vimModeLabel() string {
+	label := map[InputMode]string{
+		InputModeNormal:     "NORMAL",
+		InputModeInsert:     "INSERT",
+		InputModeVisual:     "VISUAL",
+		InputModeVisualLine: "VISUAL LINE",
+	}[a.inputMode]
+	if pending := a.vim.pendingLabel() + a.commandBuffer; pending != "" {
+		label += ":" + pending
+	}
+	return fmt.Sprintf("[%s]", label)
+}
+
+// pendingLabel shows a register being chosen or chosen
+func (v *vimState) pendingLabel() string {
+	switch {
+	case v.awaitingRegister:
+		return "\""
+	case v.register != "":
+		return "\"" + v.register
+	}
+	return ""
+}
+
+// renderVisualInput renders the input line with the visual selection
+// highlighted
+func (a *Application) renderVisualInput() string {
+	start, end := a.visualBounds()
+	return a.inputBuffer[:start] + a.styles.Selection.Render(a.inputBuffer[start:end]) + a.inputBuffer[end:]
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"complex/internal/clipboard"
+)
+
+// selectMessage moves the message cursor to the next (dir 1) or previous
+// (dir -1) message and scrolls it into view
+func (a *Application) selectMessage(dir int) {
+	if len(a.messages) == 0 {
+		a.statusMessage = "[yank] No messages to select"
+		return
+	}
+
+	next := a.selectedMessage + dir
+	switch {
+	case a.selectedMessage < 0 || a.selectedMessage >= len(a.messages):
+		// Start from the most recent message
+		next, dir = len(a.messages)-1, -1
+	case next < 0:
+		next = 0
+	case next >= len(a.messages):
+		next = len(a.messages) - 1
+	}
+	// Step over folded attempts
+	for a.messageHidden(next) && next+dir >= 0 && next+dir < len(a.messages) {
+		next += dir
+	}
+
+	a.selectedMessage = next
+	a.scrollToMessage(next)
+	a.statusMessage = fmt.Sprintf("[yank] Message %d of %d selected (y: copy, yc: copy code blocks, v: re-render, B: fork)", next+1, len(a.messages))
+}
+
+// messageSelected reports whether the message cursor is on a message
+func (a *Application) messageSelected() bool {
+	return a.selectedMessage >= 0 && a.selectedMessage < len(a.messages)
+}
+
+// yankMessage copies the raw content of the selected message. Tool messages
+// also carry their input and output.
+func (a *Application) yankMessage() {
+	if !a.messageSelected() {
+		a.statusMessage = "[yank] Select a message with [ or ] first (yc copies code blocks)"
+		return
+	}
+	msg := a.messages[a.selectedMessage]
+
+	text := msg.Content
+	if msg.Type == "tool_use" {
+		parts := []string{msg.Content}
+		if len(msg.ToolInput) > 0 && string(msg.ToolInput) != "null" {
+			parts = append(parts, string(msg.ToolInput))
+		}
+		if msg.ToolResult != "" {
+			parts = append(parts, msg.ToolResult)
+		}
+		text = strings.Join(parts, "\n\n")
+	}
+
+	clipboard.CopyWithFallback(text)
+	a.notify(toastSuccess, fmt.Sprintf("Copied %s message (%d characters)", msg.Type, len([]rune(text))))
+}
+
+// yankCodeBlocks copies the fenced code blocks of the last assistant message,
+// separated by blank lines
+func (a *Application) yankCodeBlocks() {
+	for i := len(a.messages) - 1; i >= 0; i-- {
+		if a.messages[i].Type != "assistant" {
+			continue
+		}
+		blocks := codeBlocks(a.messages[i].Content)
+		if len(blocks) == 0 {
+			a.statusMessage = "[yank] The last reply has no code blocks"
+			return
+		}
+		clipboard.CopyWithFallback(strings.Join(blocks, "\n\n"))
+		a.notify(toastSuccess, fmt.Sprintf("Copied %d code block(s) from the last reply", len(blocks)))
+		return
+	}
+	a.statusMessage = "[yank] No assistant reply yet"
+}
+
+// codeBlocks returns the contents of the ``` and ~~~ fenced blocks in
+// Markdown text. An unterminated block runs to the end of the text.
+func codeBlocks(text string) []string {
+	var blocks []string
+	var current []string
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+				fence = trimmed[:3]
+				current = nil
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			fence = ""
+			continue
+		}
+		current = append(current, line)
+	}
+	if fence != "" && len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
@@ -0,0 +1,300 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"complex/internal/guard"
+)
+
+// Behaviors understood by Claude's --permission-prompt-tool
+const (
+	BehaviorAllow = "allow"
+	BehaviorDeny  = "deny"
+)
+
+// Request is a permission prompt forwarded by the MCP permission server
+type Request struct {
+	ID        string          `json:"id"`
+	ToolName  string          `json:"tool_name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Received  time.Time       `json:"received"`
+}
+
+// Decision is the answer returned to the permission server, in the shape the
+// Claude CLI expects from a permission prompt tool
+type Decision struct {
+	Behavior     string          `json:"behavior"`
+	Message      string          `json:"message,omitempty"`
+	UpdatedInput json.RawMessage `json:"updatedInput,omitempty"`
+}
+
+// Allow returns an allow decision that passes the input through unchanged
+func Allow(input json.RawMessage) Decision {
+	return Decision{Behavior: BehaviorAllow, UpdatedInput: input}
+}
+
+// Deny returns a deny decision with a reason shown to Claude
+func Deny(message string) Decision {
+	return Decision{Behavior: BehaviorDeny, Message: message}
+}
+
+// Config controls the approval broker
+type Config struct {
+	Listen string `toml:"listen" json:"listen"`
+	// Socket is the unix socket the bundled MCP permission server reaches
+	// the broker on; empty for one per process in the temp directory
+	Socket string `toml:"socket" json:"socket"`
+	// Policy is the rules file deciding requests before the user is asked;
+	// empty for policy.toml in the config directory
+	Policy string `toml:"policy" json:"policy"`
+}
+
+// DefaultListen is the address the broker listens on when not configured
+const DefaultListen = "127.0.0.1:8765"
+
+// Broker receives permission requests over HTTP and blocks each one until
+// the UI resolves it.
+//
+// Protocol: POST /approval with a JSON body {"tool_name", "input",
+// "tool_use_id"}; the response body is a Decision.
+type Broker struct {
+	onRequest      func(Request)
+	onAutoDecision func(Request, Decision, string)
+	policy         policySource
+	guard          *guard.Detector
+
+	mutex        sync.Mutex
+	pending      map[string]chan Decision
+	alwaysAllow  map[string]bool
+	counter      int
+	server       *http.Server
+	listenerAddr string
+	socket       string
+}
+
+// NewBroker creates a broker that calls onRequest for every request that
+// needs a user decision
+func NewBroker(onRequest func(Request)) *Broker {
+	return &Broker{
+		onRequest:   onRequest,
+		pending:     make(map[string]chan Decision),
+		alwaysAllow: make(map[string]bool),
+	}
+}
+
+// OnAutoDecision sets a function called for requests decided without
+// asking, by the policy or because their tool is always allowed, with the
+// reason for the decision
+func (b *Broker) OnAutoDecision(fn func(req Request, decision Decision, reason string)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.onAutoDecision = fn
+}
+
+// SetGuard sets the detector of risky shell commands. A risky command is
+// always asked about, even for a tool marked always-allow.
+func (b *Broker) SetGuard(detector *guard.Detector) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.guard = detector
+}
+
+// Risks returns the guard rules matched by a Bash request
+func (b *Broker) Risks(req Request) []string {
+	b.mutex.Lock()
+	detector := b.guard
+	b.mutex.Unlock()
+	if detector == nil || req.ToolName != "Bash" {
+		return nil
+	}
+	var input struct {
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal(req.Input, &input); err != nil {
+		return nil
+	}
+	return detector.Check(input.Command)
+}
+
+// SetPolicyFile sets the policy file consulted before asking. It is re-read
+// when it changes; while it is broken, every request is asked about.
+func (b *Broker) SetPolicyFile(path string) {
+	b.policy.mutex.Lock()
+	defer b.policy.mutex.Unlock()
+	b.policy.path = path
+	b.policy.policy, b.policy.err = nil, nil
+}
+
+// Policy returns the policy in force and its file
+func (b *Broker) Policy() (*Policy, string, error) {
+	policy, err := b.policy.current()
+	b.policy.mutex.Lock()
+	defer b.policy.mutex.Unlock()
+	return policy, b.policy.path, err
+}
+
+// Start listens on the TCP addr, and on the unix socket the bundled MCP
+// permission server connects to when socket is set, and serves until ctx is
+// cancelled. A listener that fails is reported; the others still serve.
+func (b *Broker) Start(ctx context.Context, addr, socket string) error {
+	if addr == "" {
+		addr = DefaultListen
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/approval", b.handleApproval)
+	b.server = &http.Server{Handler: mux}
+
+	var listeners []net.Listener
+	var errs []error
+	if listener, err := net.Listen("tcp", addr); err != nil {
+		errs = append(errs, fmt.Errorf("failed to listen on %s: %w", addr, err))
+	} else {
+		listeners = append(listeners, listener)
+		b.listenerAddr = listener.Addr().String()
+	}
+	if socket != "" {
+		// A socket file left by a crashed run would refuse the listener
+		os.Remove(socket)
+		if listener, err := net.Listen("unix", socket); err != nil {
+			errs = append(errs, fmt.Errorf("failed to listen on %s: %w", socket, err))
+		} else {
+			listeners = append(listeners, listener)
+			b.socket = socket
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		b.server.Close()
+		b.denyAll("TUI shut down")
+	}()
+	for _, listener := range listeners {
+		go b.server.Serve(listener)
+	}
+	return errors.Join(errs...)
+}
+
+// Addr returns the address the broker is listening on
+func (b *Broker) Addr() string {
+	return b.listenerAddr
+}
+
+// Socket returns the unix socket the broker is listening on, or ""
+func (b *Broker) Socket() string {
+	return b.socket
+}
+
+// Submit registers a request and blocks until it is resolved or ctx ends.
+// A policy deny is final. Requests allowed by the policy or by a tool
+// marked always-allow are approved without asking, unless the guard finds
+// the command risky.
+func (b *Broker) Submit(ctx context.Context, req Request) Decision {
+	risky := len(b.Risks(req)) > 0
+
+	if policy, err := b.policy.current(); err == nil {
+		if decision, rule, ok := policy.Evaluate(req); ok && (decision.Behavior == BehaviorDeny || !risky) {
+			b.autoDecide(req, decision, "policy: "+rule.String())
+			return decision
+		}
+	}
+
+	b.mutex.Lock()
+	if b.alwaysAllow[req.ToolName] && !risky {
+		b.mutex.Unlock()
+		decision := Allow(req.Input)
+		b.autoDecide(req, decision, "always allow")
+		return decision
+	}
+	b.counter++
+	req.ID = fmt.Sprintf("approval_%d", b.counter)
+	req.Received = time.Now()
+	ch := make(chan Decision, 1)
+	b.pending[req.ID] = ch
+	b.mutex.Unlock()
+
+	if b.onRequest != nil {
+		b.onRequest(req)
+	}
+
+	select {
+	case decision := <-ch:
+		return decision
+	case <-ctx.Done():
+		b.mutex.Lock()
+		delete(b.pending, req.ID)
+		b.mutex.Unlock()
+		return Deny("permission request was abandoned")
+	}
+}
+
+// autoDecide reports a request decided without asking
+func (b *Broker) autoDecide(req Request, decision Decision, reason string) {
+	b.mutex.Lock()
+	onAutoDecision := b.onAutoDecision
+	b.mutex.Unlock()
+	if onAutoDecision != nil {
+		onAutoDecision(req, decision, reason)
+	}
+}
+
+// Resolve answers a pending request
+func (b *Broker) Resolve(id string, decision Decision) error {
+	b.mutex.Lock()
+	ch, ok := b.pending[id]
+	delete(b.pending, id)
+	b.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no pending approval %s", id)
+	}
+	ch <- decision
+	return nil
+}
+
+// AlwaysAllow approves every future request for a tool in this session
+func (b *Broker) AlwaysAllow(toolName string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.alwaysAllow[toolName] = true
+}
+
+// denyAll rejects every pending request
+func (b *Broker) denyAll(reason string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for id, ch := range b.pending {
+		ch <- Deny(reason)
+		delete(b.pending, id)
+	}
+}
+
+func (b *Broker) handleApproval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.ToolName == "" {
+		http.Error(w, "tool_name is required", http.StatusBadRequest)
+		return
+	}
+
+	decision := b.Submit(r.Context(), req)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decision)
+}
@@ -0,0 +1,138 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"complex/internal/guard"
+	"customclaude/pkg/mcpapproval"
+)
+
+// newTestBroker returns a broker guarded by the default rules, with a policy
+// file allowing every Bash command. Requests it asks about are denied.
+func newTestBroker(t *testing.T) (*Broker, *[]Request) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), DefaultPolicyFile)
+	policy := "[[rules]]\naction = \"allow\"\ntool = \"Bash\"\n\n[[rules]]\naction = \"deny\"\ntool = \"Bash\"\npattern = \"^shutdown\"\n"
+	if err := os.WriteFile(file, []byte(policy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	detector, err := guard.NewDetector(guard.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var asked []Request
+	var b *Broker
+	b = NewBroker(func(req Request) {
+		asked = append(asked, req)
+		if err := b.Resolve(req.ID, Deny("asked")); err != nil {
+			t.Error(err)
+		}
+	})
+	b.SetGuard(detector)
+	b.SetPolicyFile(file)
+	return b, &asked
+}
+
+func bashRequest(command string) Request {
+	input, _ := json.Marshal(map[string]string{"command": command})
+	return Request{ToolName: "Bash", Input: input}
+}
+
+func TestSubmitGuardsPolicyAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		behavior string
+		asked    bool
+	}{
+		{name: "safe command allowed by the policy", command: "ls -la", behavior: BehaviorAllow},
+		{name: "guarded command asked about", command: "rm -rf build", behavior: BehaviorDeny, asked: true},
+		{name: "policy deny is final", command: "shutdown now", behavior: BehaviorDeny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, asked := newTestBroker(t)
+			decision := b.Submit(context.Background(), bashRequest(tt.command))
+			if decision.Behavior != tt.behavior {
+				t.Errorf("behavior = %q, want %q", decision.Behavior, tt.behavior)
+			}
+			if got := len(*asked) > 0; got != tt.asked {
+				t.Errorf("asked = %v, want %v", got, tt.asked)
+			}
+		})
+	}
+}
+
+func TestSubmitGuardsAlwaysAllow(t *testing.T) {
+	b, asked := newTestBroker(t)
+	b.SetPolicyFile("")
+	b.AlwaysAllow("Bash")
+
+	if decision := b.Submit(context.Background(), bashRequest("go test ./...")); decision.Behavior != BehaviorAllow {
+		t.Errorf("safe command: behavior = %q, want %q", decision.Behavior, BehaviorAllow)
+	}
+	if decision := b.Submit(context.Background(), bashRequest("git push --force origin main")); decision.Behavior != BehaviorDeny {
+		t.Errorf("guarded command: behavior = %q, want %q", decision.Behavior, BehaviorDeny)
+	}
+	if len(*asked) != 1 {
+		t.Errorf("asked %d times, want 1", len(*asked))
+	}
+}
+
+func TestBrokerSocket(t *testing.T) {
+	// Unix socket paths are limited to about 100 bytes, which the test
+	// temporary directory can exceed on macOS
+	dir, err := os.MkdirTemp("", "cc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "approval.sock")
+
+	var b *Broker
+	b = NewBroker(func(req Request) {
+		if err := b.Resolve(req.ID, Deny("not "+req.ToolName)); err != nil {
+			t.Error(err)
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := b.Start(ctx, "127.0.0.1:0", socket); err != nil {
+		t.Fatal(err)
+	}
+	if b.Socket() != socket {
+		t.Fatalf("Socket() = %q, want %q", b.Socket(), socket)
+	}
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"approval_prompt","arguments":{"tool_name":"Write","input":{"file_path":"main.go"}}}}`
+	var out bytes.Buffer
+	server := &mcpapproval.Server{Socket: socket}
+	if err := server.Serve(ctx, strings.NewReader(call+"\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var resp struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil || len(resp.Result.Content) != 1 {
+		t.Fatalf("response %s: %v", out.String(), err)
+	}
+	var decision Decision
+	if err := json.Unmarshal([]byte(resp.Result.Content[0].Text), &decision); err != nil {
+		t.Fatal(err)
+	}
+	if decision.Behavior != BehaviorDeny || decision.Message != "not Write" {
+		t.Errorf("decision = %+v, want the broker's deny", decision)
+	}
+}
@@ -0,0 +1,218 @@
+package approval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultPolicyFile is the policy file looked for in the config directory
+// when none is configured
+const DefaultPolicyFile = "policy.toml"
+
+// Rule actions
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+)
+
+// argumentFields are the input fields a rule without a field matches, in
+// order; the first one present is used, the whole input otherwise
+var argumentFields = []string{"command", "file_path", "notebook_path", "path", "url", "pattern"}
+
+// Rule pre-approves or blocks requests of a tool whose argument matches a
+// pattern
+type Rule struct {
+	Action string `toml:"action"`
+	// Tool is a tool name or glob, such as "Read" or "mcp__github__*"; empty
+	// for every tool
+	Tool string `toml:"tool"`
+	// Field is the input field the pattern is matched against; empty for the
+	// tool's main argument (command, file_path, path, url...)
+	Field string `toml:"field"`
+	// Pattern is a regular expression the argument must match; empty for
+	// any argument
+	Pattern string `toml:"pattern"`
+	// Reason is told to Claude when the rule denies a request
+	Reason string `toml:"reason"`
+}
+
+// String describes the rule for the UI
+func (r Rule) String() string {
+	tool := r.Tool
+	if tool == "" {
+		tool = "*"
+	}
+	if r.Pattern == "" {
+		return fmt.Sprintf("%s %s", r.Action, tool)
+	}
+	return fmt.Sprintf("%s %s matching %q", r.Action, tool, r.Pattern)
+}
+
+type compiledPolicyRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// Policy decides permission requests without asking. Deny rules win over
+// allow rules; a request no rule matches is left to the user.
+type Policy struct {
+	rules []compiledPolicyRule
+}
+
+// policyFile is the layout of a policy file
+type policyFile struct {
+	Rules []Rule `toml:"rules"`
+}
+
+// NewPolicy compiles rules
+func NewPolicy(rules []Rule) (*Policy, error) {
+	p := &Policy{}
+	for i, rule := range rules {
+		if rule.Action != ActionAllow && rule.Action != ActionDeny {
+			return nil, fmt.Errorf("policy rule %d: action must be %s or %s", i+1, ActionAllow, ActionDeny)
+		}
+		if _, err := path.Match(rule.Tool, ""); err != nil {
+			return nil, fmt.Errorf("policy rule %d: invalid tool pattern %q: %w", i+1, rule.Tool, err)
+		}
+		compiled := compiledPolicyRule{Rule: rule}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("policy rule %d: %w", i+1, err)
+			}
+			compiled.re = re
+		}
+		p.rules = append(p.rules, compiled)
+	}
+	return p, nil
+}
+
+// LoadPolicy reads a policy file. A missing file is an empty policy.
+func LoadPolicy(file string) (*Policy, error) {
+	var content policyFile
+	if _, err := toml.DecodeFile(file, &content); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Policy{}, nil
+		}
+		return nil, fmt.Errorf("failed to read policy %s: %w", file, err)
+	}
+	policy, err := NewPolicy(content.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	return policy, nil
+}
+
+// Len returns the number of rules
+func (p *Policy) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.rules)
+}
+
+// Evaluate returns the decision for a request and the rule that made it, or
+// false when the user has to decide. An allow is not final: the broker
+// still asks about commands the guard finds risky.
+func (p *Policy) Evaluate(req Request) (Decision, Rule, bool) {
+	if p == nil {
+		return Decision{}, Rule{}, false
+	}
+	var allowed *Rule
+	for i := range p.rules {
+		rule := &p.rules[i]
+		if !rule.matches(req) {
+			continue
+		}
+		if rule.Action == ActionDeny {
+			reason := rule.Reason
+			if reason == "" {
+				reason = "Blocked by the permission policy: " + rule.String()
+			}
+			return Deny(reason), rule.Rule, true
+		}
+		if allowed == nil {
+			allowed = &rule.Rule
+		}
+	}
+	if allowed != nil {
+		return Allow(req.Input), *allowed, true
+	}
+	return Decision{}, Rule{}, false
+}
+
+func (r *compiledPolicyRule) matches(req Request) bool {
+	if r.Tool != "" {
+		if ok, _ := path.Match(r.Tool, req.ToolName); !ok {
+			return false
+		}
+	}
+	return r.re == nil || r.re.MatchString(argument(req.Input, r.Field))
+}
+
+// argument returns the text of an input field, or of the tool's main
+// argument when field is empty
+func argument(input json.RawMessage, field string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return string(input)
+	}
+	names := argumentFields
+	if field != "" {
+		names = []string{field}
+	}
+	for _, name := range names {
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var text string
+		if json.Unmarshal(value, &text) == nil {
+			return text
+		}
+		return string(value)
+	}
+	if field != "" {
+		return ""
+	}
+	return string(input)
+}
+
+// policySource is a policy file reloaded when it changes
+type policySource struct {
+	mutex   sync.Mutex
+	path    string
+	modTime time.Time
+	policy  *Policy
+	err     error
+}
+
+// current returns the policy, re-reading the file when it changed
+func (s *policySource) current() (*Policy, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.path == "" {
+		return nil, nil
+	}
+	var modTime time.Time
+	if info, err := os.Stat(s.path); err == nil {
+		modTime = info.ModTime()
+	}
+	if s.policy != nil || s.err != nil {
+		if modTime.Equal(s.modTime) {
+			return s.policy, s.err
+		}
+	}
+	s.modTime = modTime
+	s.policy, s.err = LoadPolicy(s.path)
+	return s.policy, s.err
+}
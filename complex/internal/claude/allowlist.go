@@ -0,0 +1,131 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectAllowlistFile is the file in the working directory naming the
+// allowlist preset of the project
+var ProjectAllowlistFile = filepath.Join(".cc-custom", "allowlist")
+
+// Allowlist is a preset of the tools claude may use and its permission mode
+type Allowlist struct {
+	Name           string
+	Description    string
+	Allowed        []string
+	Disallowed     []string
+	PermissionMode string
+}
+
+// readTools are the tools that only look at the workspace
+var readTools = []string{"Read", "Glob", "Grep", "LS", "TodoWrite", "Task", "WebFetch", "WebSearch"}
+
+// AllowlistPresets are the built-in allowlists, safest first
+var AllowlistPresets = []Allowlist{
+	{
+		Name:           "explore",
+		Description:    "read-only exploration: reads and searches, no edits or shell",
+		Allowed:        readTools,
+		Disallowed:     []string{"Edit", "MultiEdit", "Write", "NotebookEdit", "Bash"},
+		PermissionMode: "plan",
+	},
+	{
+		Name:        "docs",
+		Description: "docs only: edits Markdown files, asks before anything else, no shell",
+		Allowed: append(append([]string(nil), readTools...),
+			"Edit(**/*.md)", "MultiEdit(**/*.md)", "Write(**/*.md)"),
+		Disallowed:     []string{"Bash", "NotebookEdit"},
+		PermissionMode: "default",
+	},
+	{
+		Name:        "full-dev",
+		Description: "full development: edits accepted, shell commands asked for",
+		Allowed: append(append([]string(nil), readTools...),
+			"Edit", "MultiEdit", "Write", "NotebookEdit"),
+		PermissionMode: "acceptEdits",
+	},
+}
+
+// AllowlistNames lists the presets by name
+func AllowlistNames() []string {
+	names := make([]string, len(AllowlistPresets))
+	for i, preset := range AllowlistPresets {
+		names[i] = preset.Name
+	}
+	return names
+}
+
+// FindAllowlist returns the preset with the given name
+func FindAllowlist(name string) (Allowlist, bool) {
+	for _, preset := range AllowlistPresets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Allowlist{}, false
+}
+
+// ProjectAllowlist reads the preset named in dir's allowlist file. A
+// missing file is not an error; the returned name is empty.
+func ProjectAllowlist(dir string) (string, error) {
+	path := filepath.Join(dir, ProjectAllowlistFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read allowlist %s: %w", path, err)
+	}
+	name := strings.TrimSpace(string(data))
+	if _, ok := FindAllowlist(name); !ok {
+		return "", fmt.Errorf("unknown allowlist %q in %s; use one of %s", name, path, strings.Join(AllowlistNames(), ", "))
+	}
+	return name, nil
+}
+
+// SetAllowlist sets the preset used when the project names none; empty for
+// claude's own defaults
+func (sm *SessionManager) SetAllowlist(name string) {
+	sm.allowlist = name
+}
+
+// Allowlist returns the active preset: the project's, or the configured
+// one. The project file is re-read on every call so changes apply to the
+// next prompt.
+func (sm *SessionManager) Allowlist() (Allowlist, bool) {
+	name, err := ProjectAllowlist(sm.Dir())
+	if err != nil {
+		sm.emitEvent(EventError, err)
+	}
+	if name == "" {
+		name = sm.allowlist
+	}
+	return FindAllowlist(name)
+}
+
+// SetProjectAllowlist writes the project's allowlist file; an empty name
+// removes it, going back to the configured preset
+func (sm *SessionManager) SetProjectAllowlist(name string) (string, error) {
+	path := filepath.Join(sm.Dir(), ProjectAllowlistFile)
+	if name == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to remove allowlist: %w", err)
+		}
+		return path, nil
+	}
+	if _, ok := FindAllowlist(name); !ok {
+		return "", fmt.Errorf("unknown allowlist %q; use one of %s", name, strings.Join(AllowlistNames(), ", "))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write allowlist: %w", err)
+	}
+	return path, nil
+}
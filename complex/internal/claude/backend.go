@@ -0,0 +1,61 @@
+package claude
+
+import (
+	"fmt"
+	"os"
+
+	"customclaude/pkg/claudecli"
+)
+
+// Backend kinds accepted in BackendConfig.Kind
+const (
+	BackendCLI = "cli"
+	BackendAPI = "api"
+)
+
+// DefaultAPIKeyEnv is the variable the API backend reads its key from
+const DefaultAPIKeyEnv = "ANTHROPIC_API_KEY"
+
+// BackendConfig selects how prompts reach Claude: the claude CLI, or the
+// Messages API directly for machines without the CLI. The API backend has
+// no tools, MCP servers or permission prompts; Claude only answers in text.
+type BackendConfig struct {
+	Kind      string `toml:"kind"`        // cli or api; empty for cli
+	BaseURL   string `toml:"base_url"`    // api: empty for the public API
+	APIKeyEnv string `toml:"api_key_env"` // api: variable holding the key
+	MaxTokens int    `toml:"max_tokens"`  // api: longest answer in tokens
+}
+
+// NewRunner creates the runner of a backend; nil runs the claude CLI
+func NewRunner(cfg BackendConfig) (claudecli.Runner, error) {
+	switch cfg.Kind {
+	case "", BackendCLI:
+		return nil, nil
+	case BackendAPI:
+		env := cfg.APIKeyEnv
+		if env == "" {
+			env = DefaultAPIKeyEnv
+		}
+		key := os.Getenv(env)
+		if key == "" {
+			return nil, fmt.Errorf("the api backend needs an API key in $%s", env)
+		}
+		return &claudecli.APIRunner{
+			APIKey:    key,
+			BaseURL:   cfg.BaseURL,
+			MaxTokens: cfg.MaxTokens,
+			Price:     PriceUsage,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend: %s", cfg.Kind)
+	}
+}
+
+// PriceUsage returns the cost in USD of usage on model, by PricingFor
+func PriceUsage(model string, usage Usage) float64 {
+	pricing := PricingFor(model)
+	return (float64(usage.InputTokens)*pricing.Input +
+		float64(usage.OutputTokens)*pricing.Output +
+		float64(usage.CacheCreationInputTokens)*pricing.CacheWrite +
+		float64(usage.CacheReadInputTokens)*pricing.CacheRead) / 1e6
+}
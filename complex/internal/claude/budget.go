@@ -0,0 +1,209 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBudgetWarnAt are the fractions of a limit that raise a warning
+var DefaultBudgetWarnAt = []float64{0.5, 0.8, 1.0}
+
+// ledgerDays is how many days of spending the ledger keeps
+const ledgerDays = 31
+
+// Budget limits spending per conversation and per calendar day, in USD. A
+// zero limit is not enforced.
+type Budget struct {
+	Conversation float64 `toml:"conversation" json:"conversation"`
+	Daily        float64 `toml:"daily" json:"daily"`
+	// WarnAt are fractions of a limit at which a warning is raised once
+	WarnAt []float64 `toml:"warn_at" json:"warn_at"`
+	// HardLimit refuses new prompts once a limit is reached, unless the
+	// user confirms the override
+	HardLimit bool `toml:"hard_limit" json:"hard_limit"`
+}
+
+// BudgetScope names the limit a budget alert refers to
+type BudgetScope string
+
+const (
+	BudgetConversation BudgetScope = "conversation"
+	BudgetDaily        BudgetScope = "daily"
+)
+
+// BudgetAlert reports spending that crossed a threshold of a limit
+type BudgetAlert struct {
+	Scope     BudgetScope
+	Spent     float64
+	Limit     float64
+	Threshold float64
+}
+
+// Exceeded reports whether the limit itself was reached
+func (a BudgetAlert) Exceeded() bool {
+	return a.Spent >= a.Limit
+}
+
+// String describes the alert for the status bar
+func (a BudgetAlert) String() string {
+	if a.Exceeded() {
+		return fmt.Sprintf("%s budget exceeded: $%.2f of $%.2f", a.Scope, a.Spent, a.Limit)
+	}
+	return fmt.Sprintf("%s budget at %.0f%%: $%.2f of $%.2f", a.Scope, a.Threshold*100, a.Spent, a.Limit)
+}
+
+// ledgerMu serializes ledger updates from the session managers of all tabs
+var ledgerMu sync.Mutex
+
+// spendLedger records the cost spent per day in a JSON file shared by all
+// conversations and instances
+type spendLedger struct {
+	path string
+}
+
+// day returns the ledger key for t
+func day(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// read loads the ledger; a missing file is an empty ledger
+func (l spendLedger) read() (map[string]float64, error) {
+	spent := make(map[string]float64)
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return spent, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spend ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &spent); err != nil {
+		return nil, fmt.Errorf("failed to parse spend ledger: %w", err)
+	}
+	return spent, nil
+}
+
+// today returns the amount spent today
+func (l spendLedger) today() (float64, error) {
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	spent, err := l.read()
+	if err != nil {
+		return 0, err
+	}
+	return spent[day(time.Now())], nil
+}
+
+// add records a cost against today and returns today's total
+func (l spendLedger) add(cost float64) (float64, error) {
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	spent, err := l.read()
+	if err != nil {
+		return 0, err
+	}
+	today := day(time.Now())
+	spent[today] += cost
+
+	// Drop the oldest days
+	days := make([]string, 0, len(spent))
+	for d := range spent {
+		days = append(days, d)
+	}
+	sort.Strings(days)
+	for len(days) > ledgerDays {
+		delete(spent, days[0])
+		days = days[1:]
+	}
+
+	data, err := json.MarshalIndent(spent, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode spend ledger: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create spend ledger directory: %w", err)
+	}
+	if err := os.WriteFile(l.path, data, 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write spend ledger: %w", err)
+	}
+	return spent[today], nil
+}
+
+// SetBudget sets the spending limits; daily spending is tracked in the
+// ledger file at ledgerPath
+func (sm *SessionManager) SetBudget(budget Budget, ledgerPath string) {
+	sm.Budget = budget
+	sm.ledger = spendLedger{path: ledgerPath}
+	if ledgerPath == "" {
+		return
+	}
+	if spent, err := sm.ledger.today(); err != nil {
+		sm.emitEvent(EventError, err)
+	} else {
+		sm.dailyCost = spent
+	}
+}
+
+// noteSpend adds the cost of a result to the daily ledger and raises an
+// alert for every warning threshold the spending crossed
+func (sm *SessionManager) noteSpend(cost float64) {
+	conversationBefore := sm.CumulativeCost - cost
+	dailyBefore := sm.dailyCost
+	if sm.ledger.path != "" {
+		if spent, err := sm.ledger.add(cost); err != nil {
+			sm.emitEvent(EventError, err)
+			sm.dailyCost += cost
+		} else {
+			// Other tabs and instances may have spent in the meantime
+			dailyBefore = spent - cost
+			sm.dailyCost = spent
+		}
+	}
+
+	alert := func(scope BudgetScope, before, after, limit float64) {
+		if limit <= 0 {
+			return
+		}
+		thresholds := sm.Budget.WarnAt
+		if len(thresholds) == 0 {
+			thresholds = []float64{1}
+		}
+		// Only the highest threshold crossed is reported
+		crossed := 0.0
+		for _, threshold := range thresholds {
+			if before < threshold*limit && after >= threshold*limit && threshold > crossed {
+				crossed = threshold
+			}
+		}
+		if crossed > 0 {
+			sm.emitEvent(EventBudgetAlert, BudgetAlert{Scope: scope, Spent: after, Limit: limit, Threshold: crossed})
+		}
+	}
+	alert(BudgetConversation, conversationBefore, sm.CumulativeCost, sm.Budget.Conversation)
+	if sm.ledger.path != "" {
+		alert(BudgetDaily, dailyBefore, sm.dailyCost, sm.Budget.Daily)
+	}
+}
+
+// BudgetExceeded returns the first limit that spending has reached. The
+// daily total is re-read so spending by other instances counts.
+func (sm *SessionManager) BudgetExceeded() (BudgetAlert, bool) {
+	if limit := sm.Budget.Conversation; limit > 0 && sm.CumulativeCost >= limit {
+		return BudgetAlert{Scope: BudgetConversation, Spent: sm.CumulativeCost, Limit: limit, Threshold: 1}, true
+	}
+	if limit := sm.Budget.Daily; limit > 0 && sm.ledger.path != "" {
+		if spent, err := sm.ledger.today(); err == nil {
+			sm.dailyCost = spent
+		}
+		if sm.dailyCost >= limit {
+			return BudgetAlert{Scope: BudgetDaily, Spent: sm.dailyCost, Limit: limit, Threshold: 1}, true
+		}
+	}
+	return BudgetAlert{}, false
+}
@@ -0,0 +1,107 @@
+package claude
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RecordSize approximates how much text a stored conversation holds, in bytes
+func RecordSize(record SessionRecord) int {
+	size := 0
+	for _, msg := range record.Messages {
+		size += len(msg.Content) + len(msg.ToolInput) + len(msg.ToolResult)
+	}
+	return size
+}
+
+// DeleteConversations removes stored conversations. The active conversation
+// is skipped, since its next result would save it again.
+func (sm *SessionManager) DeleteConversations(ids []string) (int, error) {
+	if sm.store == nil {
+		return 0, fmt.Errorf("session storage is not configured")
+	}
+	deleted := 0
+	for _, id := range ids {
+		if id == sm.conversationID {
+			continue
+		}
+		if err := sm.store.Delete(id); err != nil {
+			return deleted, fmt.Errorf("failed to delete session %s: %w", id, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// ArchiveConversations sets or clears the archived flag of stored
+// conversations. Archived conversations stay in the store but are hidden
+// from the browser by default.
+func (sm *SessionManager) ArchiveConversations(ids []string, archived bool) (int, error) {
+	return sm.updateConversations(ids, func(record *SessionRecord) {
+		record.Archived = archived
+	})
+}
+
+// TagConversations adds a tag to, or removes it from, stored conversations
+func (sm *SessionManager) TagConversations(ids []string, tag string, add bool) (int, error) {
+	if tag == "" {
+		return 0, fmt.Errorf("tag must not be empty")
+	}
+	update := func(tags []string) []string {
+		tags = slices.DeleteFunc(tags, func(t string) bool { return t == tag })
+		if add {
+			tags = append(tags, tag)
+		}
+		return tags
+	}
+
+	// The active conversation is rebuilt from memory on every save
+	if slices.Contains(ids, sm.conversationID) {
+		sm.tags = update(sm.tags)
+	}
+	return sm.updateConversations(ids, func(record *SessionRecord) {
+		record.Tags = update(record.Tags)
+	})
+}
+
+// ExportConversations writes each stored conversation to the file named by
+// the export path template and returns the paths written
+func (sm *SessionManager) ExportConversations(ids []string, template string) ([]string, error) {
+	if sm.store == nil {
+		return nil, fmt.Errorf("session storage is not configured")
+	}
+	var paths []string
+	for _, id := range ids {
+		record, err := sm.store.Load(id)
+		if err != nil {
+			return paths, fmt.Errorf("failed to load session %s: %w", id, err)
+		}
+		path, err := exportRecord(template, record)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// updateConversations loads, modifies and saves stored conversations,
+// keeping their update time so the browser order does not change
+func (sm *SessionManager) updateConversations(ids []string, update func(*SessionRecord)) (int, error) {
+	if sm.store == nil {
+		return 0, fmt.Errorf("session storage is not configured")
+	}
+	updated := 0
+	for _, id := range ids {
+		record, err := sm.store.Load(id)
+		if err != nil {
+			return updated, fmt.Errorf("failed to load session %s: %w", id, err)
+		}
+		update(&record)
+		if err := sm.store.Save(record); err != nil {
+			return updated, fmt.Errorf("failed to save session %s: %w", id, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
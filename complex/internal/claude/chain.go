@@ -0,0 +1,113 @@
+package claude
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChainLink is one Claude session in a conversation's chain, with the stats
+// of the turn that produced it
+type ChainLink struct {
+	SessionID  string    `json:"session_id"`
+	Cost       float64   `json:"cost"`
+	Turns      int       `json:"turns"`
+	DurationMs int       `json:"duration_ms"`
+	Usage      Usage     `json:"usage"`
+	Timestamp  time.Time `json:"timestamp"`
+	// Messages is the length of the transcript when the session ended
+	Messages int `json:"messages"`
+	// Model is the model of the last turn in the session
+	Model string `json:"model,omitempty"`
+}
+
+// noteChainLink records the session and stats reported by a result
+func (sm *SessionManager) noteChainLink(msg Message) {
+	link := ChainLink{
+		SessionID:  msg.SessionID,
+		Cost:       msg.TotalCostUSD,
+		Turns:      msg.NumTurns,
+		DurationMs: msg.DurationMs,
+		Timestamp:  sm.eventTime(),
+		Messages:   len(sm.transcript),
+		Model:      sm.turnModel,
+	}
+	if msg.Usage != nil {
+		link.Usage = *msg.Usage
+	}
+	sm.chainLinks = append(sm.chainLinks, link)
+}
+
+// ChainLinks returns the session chain with per-session stats. Sessions
+// from before stats were kept have links with only a session ID.
+func (sm *SessionManager) ChainLinks() []ChainLink {
+	links := make([]ChainLink, len(sm.SessionChain))
+	for i, id := range sm.SessionChain {
+		links[i] = ChainLink{SessionID: id}
+	}
+	// Stats are kept for the most recent sessions of the chain
+	if offset := len(links) - len(sm.chainLinks); offset >= 0 {
+		copy(links[offset:], sm.chainLinks)
+	}
+	return links
+}
+
+// ChainIndex returns the position of a session in the chain, or -1 when it
+// is not part of it
+func (sm *SessionManager) ChainIndex(sessionID string) int {
+	for i := len(sm.SessionChain) - 1; i >= 0; i-- {
+		if sessionID != "" && sm.SessionChain[i] == sessionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// ForkFromSession starts a new conversation that resumes an earlier session
+// of the chain. The transcript and stats are cut back to that session, and
+// the original conversation is left as it was.
+func (sm *SessionManager) ForkFromSession(index int) (SessionRecord, error) {
+	links := sm.ChainLinks()
+	if index < 0 || index >= len(links) {
+		return SessionRecord{}, fmt.Errorf("no session at position %d of the chain", index+1)
+	}
+	link := links[index]
+
+	sm.ReleaseSessionLock()
+	sm.conversationID = newConversationID()
+	if sm.title != "" {
+		sm.title += fmt.Sprintf(" (fork at %d)", index+1)
+	}
+	sm.compactedInto = ""
+	sm.CurrentSessionID = link.SessionID
+	sm.SessionChain = append([]string(nil), sm.SessionChain[:index+1]...)
+	sm.chainLinks = links[:index+1]
+
+	// Sessions from old records have no stats, so the transcript and totals
+	// are only cut back when they are known
+	if link.Messages > 0 && link.Messages <= len(sm.transcript) {
+		sm.transcript = append([]ConversationMessage(nil), sm.transcript[:link.Messages]...)
+		sm.lastActivity = link.Timestamp
+	}
+	// Stats cover the end of the chain, so the first link having them means
+	// all do
+	if links[0].Messages > 0 {
+		sm.CumulativeCost, sm.CumulativeTurns, sm.CumulativeDuration = 0, 0, 0
+		sm.CumulativeUsage = Usage{}
+		for _, l := range sm.chainLinks {
+			sm.CumulativeCost += l.Cost
+			sm.CumulativeTurns += l.Turns
+			sm.CumulativeDuration += l.DurationMs
+			sm.CumulativeUsage.InputTokens += l.Usage.InputTokens
+			sm.CumulativeUsage.CacheCreationInputTokens += l.Usage.CacheCreationInputTokens
+			sm.CumulativeUsage.CacheReadInputTokens += l.Usage.CacheReadInputTokens
+			sm.CumulativeUsage.OutputTokens += l.Usage.OutputTokens
+		}
+	}
+	sm.Interruptions = 0
+	sm.context.reset()
+	sm.persist()
+
+	sm.emitEvent(EventSessionUpdate, sm.getCurrentSessionInfo())
+	sm.emitEvent(EventStatsUpdate, sm.getSessionStats())
+	return sm.record(), nil
+}
@@ -0,0 +1,68 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// compactSummaryPrompt asks for the summary a compacted conversation is
+// seeded with
+const compactSummaryPrompt = "Summarize this conversation so that it can be continued in a fresh session " +
+	"that cannot see it. Cover the goal, the decisions made and why, the files created or changed, " +
+	"the current state of the work, and what remains to be done. Reply with the summary only."
+
+// compactSeedPrompt starts the new session with the summary
+const compactSeedPrompt = "This session continues an earlier conversation that was compacted. " +
+	"Its summary follows; use it as context for my next messages and reply only with \"Ready.\"\n\n" +
+	"<summary>\n%s\n</summary>"
+
+// Compaction records a conversation replaced by a summarized one
+type Compaction struct {
+	From    string // conversation ID of the summarized conversation
+	Into    string // conversation ID of the new one
+	Summary string
+}
+
+// Compact keeps a long conversation cheap: it asks claude for a summary,
+// starts a new conversation seeded with it, and links the two in the
+// session store. The old conversation stays saved as it was.
+func (sm *SessionManager) Compact(ctx context.Context) (Compaction, error) {
+	if sm.CurrentSessionID == "" {
+		return Compaction{}, errors.New("nothing to compact: the conversation has no session yet")
+	}
+
+	sm.lastResult = ""
+	if err := sm.ExecuteCommand(ctx, compactSummaryPrompt, true); err != nil {
+		return Compaction{}, fmt.Errorf("failed to summarize the conversation: %w", err)
+	}
+	summary := strings.TrimSpace(sm.lastResult)
+	if summary == "" {
+		return Compaction{}, errors.New("failed to summarize the conversation: claude returned no summary")
+	}
+
+	from, title, tags := sm.conversationID, sm.title, append([]string(nil), sm.tags...)
+	sm.log.Info("compacting conversation", "conversation", from, "summary_chars", len(summary))
+	sm.StartNewConversation()
+	sm.tags = tags
+	if title != "" {
+		sm.title = title + " (compacted)"
+	}
+	sm.compactedFrom = from
+
+	if err := sm.ExecuteCommand(ctx, fmt.Sprintf(compactSeedPrompt, summary), false); err != nil {
+		return Compaction{}, fmt.Errorf("failed to start the compacted session: %w", err)
+	}
+
+	compaction := Compaction{From: from, Into: sm.conversationID, Summary: summary}
+	if sm.store != nil {
+		_, err := sm.updateConversations([]string{from}, func(record *SessionRecord) {
+			record.CompactedInto = compaction.Into
+		})
+		if err != nil {
+			sm.emitEvent(EventError, fmt.Errorf("failed to link the compacted conversation: %w", err))
+		}
+	}
+	return compaction, nil
+}
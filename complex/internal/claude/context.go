@@ -0,0 +1,148 @@
+package claude
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// DefaultContextWindow is the context window of Claude models
+const DefaultContextWindow = 200000
+
+// Rough baseline for the built-in system prompt and tool definitions, used
+// until a turn reports how large the prompt actually was
+const (
+	baseSystemPromptTokens = 3000
+	tokensPerToolSchema    = 400
+)
+
+// ContextComposition is an approximate breakdown of what occupies the
+// context window
+type ContextComposition struct {
+	SystemPrompt int
+	Memory       int
+	Conversation int
+	ToolResults  int
+	Window       int
+	// Measured is true when the total is based on the prompt size reported
+	// by the last turn rather than on estimates alone
+	Measured    bool
+	MemoryFiles []string
+}
+
+// Total returns the estimated number of tokens in the context window
+func (c ContextComposition) Total() int {
+	return c.SystemPrompt + c.Memory + c.Conversation + c.ToolResults
+}
+
+// contextTracker collects the inputs needed to estimate context composition
+type contextTracker struct {
+	cwd             string
+	toolCount       int
+	promptTokens    int
+	toolResultChars int
+}
+
+// estimateTokens approximates a token count from text length
+func estimateTokens(chars int) int {
+	return (chars + 3) / 4
+}
+
+// noteInit records the working directory and tool count of a session
+func (ct *contextTracker) noteInit(init SystemInit) {
+	ct.cwd = init.CWD
+	ct.toolCount = len(init.Tools)
+}
+
+// notePrompt records the prompt size reported in an assistant message
+func (ct *contextTracker) notePrompt(usage *Usage) {
+	if usage == nil {
+		return
+	}
+	ct.promptTokens = usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+}
+
+// noteToolResult adds the size of a tool_result block
+func (ct *contextTracker) noteToolResult(content interface{}) {
+	switch c := content.(type) {
+	case string:
+		ct.toolResultChars += len(c)
+	case nil:
+	default:
+		if data, err := json.Marshal(c); err == nil {
+			ct.toolResultChars += len(data)
+		}
+	}
+}
+
+// reset clears per-conversation measurements
+func (ct *contextTracker) reset() {
+	ct.promptTokens = 0
+	ct.toolResultChars = 0
+}
+
+// memoryFiles returns the CLAUDE.md files Claude loads for a directory: the
+// user-level file plus CLAUDE.md and CLAUDE.local.md in the directory and
+// each of its parents
+func memoryFiles(cwd string) []string {
+	var candidates []string
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".claude", "CLAUDE.md"))
+	}
+	for dir := cwd; dir != ""; {
+		candidates = append(candidates,
+			filepath.Join(dir, "CLAUDE.md"),
+			filepath.Join(dir, "CLAUDE.local.md"))
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	var files []string
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// ContextComposition estimates what currently occupies the context window
+func (sm *SessionManager) ContextComposition() ContextComposition {
+	cwd := sm.context.cwd
+	if cwd == "" {
+		cwd = sm.Dir()
+	}
+
+	comp := ContextComposition{
+		Window:      sm.ContextWindow(),
+		ToolResults: estimateTokens(sm.context.toolResultChars),
+		MemoryFiles: memoryFiles(cwd),
+	}
+
+	for _, path := range comp.MemoryFiles {
+		if info, err := os.Stat(path); err == nil {
+			comp.Memory += estimateTokens(int(info.Size()))
+		}
+	}
+
+	var chars int
+	for _, msg := range sm.transcript {
+		chars += len(msg.Content) + len(msg.ToolInput)
+	}
+	comp.Conversation = estimateTokens(chars)
+
+	comp.SystemPrompt = baseSystemPromptTokens + tokensPerToolSchema*sm.context.toolCount +
+		estimateTokens(len(sm.SystemPrompt().Text))
+	if sm.context.promptTokens > 0 {
+		// Whatever the measured prompt holds beyond our estimates is
+		// attributed to the system prompt and tool definitions
+		comp.Measured = true
+		if rest := sm.context.promptTokens - comp.Memory - comp.Conversation - comp.ToolResults; rest > 0 {
+			comp.SystemPrompt = rest
+		}
+	}
+	return comp
+}
@@ -0,0 +1,268 @@
+package claude
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultDebugBundleTemplate names debug bundles; it takes the placeholders
+// of export path templates
+const DefaultDebugBundleTemplate = "debug/{date}-{time}-{session_id}.zip"
+
+// Bounds on what is kept in memory for debug bundles
+const (
+	debugTurnLimit = 20
+	debugLineLimit = 5000
+)
+
+// DebugInvocation is a resolved claude command line
+type DebugInvocation struct {
+	Args    []string  `json:"args"`
+	Env     []string  `json:"env,omitempty"` // session variables added to the environment
+	Dir     string    `json:"dir"`
+	Started time.Time `json:"started"`
+}
+
+// DebugEvent is an emitted event with its data encoded as JSON
+type DebugEvent struct {
+	Type      EventType       `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DebugTurn is everything recorded while a prompt ran: the invocations,
+// including retries, the raw stream lines and the emitted events
+type DebugTurn struct {
+	Index       int               `json:"index"`
+	Prompt      string            `json:"prompt"`
+	Started     time.Time         `json:"started"`
+	Finished    time.Time         `json:"finished"`
+	Error       string            `json:"error,omitempty"`
+	Invocations []DebugInvocation `json:"-"`
+	Lines       []string          `json:"-"`
+	Events      []DebugEvent      `json:"-"`
+	// Truncated is set when stream lines beyond the limit were dropped
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// debugRecorder keeps the most recent turns of the conversation. Events are
+// emitted from several goroutines, hence the lock.
+type debugRecorder struct {
+	mu      sync.Mutex
+	turns   []*DebugTurn
+	current *DebugTurn
+	next    int
+}
+
+// reset forgets the turns of a previous conversation
+func (dr *debugRecorder) reset() {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.turns, dr.current, dr.next = nil, nil, 0
+}
+
+// beginTurn starts recording a prompt
+func (dr *debugRecorder) beginTurn(prompt string) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.next++
+	dr.current = &DebugTurn{Index: dr.next, Prompt: prompt, Started: time.Now()}
+	dr.turns = append(dr.turns, dr.current)
+	if len(dr.turns) > debugTurnLimit {
+		dr.turns = dr.turns[len(dr.turns)-debugTurnLimit:]
+	}
+}
+
+// endTurn stops recording the current prompt
+func (dr *debugRecorder) endTurn(err error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if dr.current == nil {
+		return
+	}
+	dr.current.Finished = time.Now()
+	if err != nil {
+		dr.current.Error = err.Error()
+	}
+	dr.current = nil
+}
+
+// noteInvocation records a claude command line of the current turn
+func (dr *debugRecorder) noteInvocation(args, env []string, dir string) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if dr.current == nil {
+		return
+	}
+	dr.current.Invocations = append(dr.current.Invocations, DebugInvocation{
+		Args:    append([]string{"claude"}, args...),
+		Env:     env,
+		Dir:     dir,
+		Started: time.Now(),
+	})
+}
+
+// noteLine records a raw stream line of the current turn
+func (dr *debugRecorder) noteLine(line string) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if dr.current == nil {
+		return
+	}
+	if len(dr.current.Lines) >= debugLineLimit {
+		dr.current.Truncated = true
+		return
+	}
+	dr.current.Lines = append(dr.current.Lines, line)
+}
+
+// noteEvent records an emitted event of the current turn
+func (dr *debugRecorder) noteEvent(event Event) {
+	var data interface{} = event.Data
+	if err, ok := event.Data.(error); ok {
+		data = map[string]string{"error": err.Error()}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		encoded, _ = json.Marshal(fmt.Sprintf("%v", event.Data))
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	if dr.current == nil {
+		return
+	}
+	dr.current.Events = append(dr.current.Events, DebugEvent{
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		Data:      encoded,
+	})
+}
+
+// DebugTurns returns the indexes of the turns available for a debug bundle
+func (sm *SessionManager) DebugTurns() []int {
+	sm.debug.mu.Lock()
+	defer sm.debug.mu.Unlock()
+	indexes := make([]int, len(sm.debug.turns))
+	for i, turn := range sm.debug.turns {
+		indexes[i] = turn.Index
+	}
+	return indexes
+}
+
+// WriteDebugBundle writes a zip file, named by the path template, with the
+// recorded turns of the conversation: their invocations, raw stream lines
+// and events. A turn above zero limits the bundle to that turn. Extra files,
+// such as UI state snapshots, are added under their names.
+func (sm *SessionManager) WriteDebugBundle(template string, turn int, extra map[string][]byte) (string, error) {
+	if template == "" {
+		template = DefaultDebugBundleTemplate
+	}
+
+	sm.debug.mu.Lock()
+	var turns []DebugTurn
+	for _, t := range sm.debug.turns {
+		if turn <= 0 || t.Index == turn {
+			turns = append(turns, *t)
+		}
+	}
+	sm.debug.mu.Unlock()
+	if len(turns) == 0 {
+		if turn > 0 {
+			return "", fmt.Errorf("turn %d was not recorded", turn)
+		}
+		return "", fmt.Errorf("no turns recorded yet")
+	}
+
+	path := expandExportPath(template, sm.record())
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create debug bundle directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create debug bundle: %w", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	files := map[string]interface{}{
+		"manifest.json": map[string]interface{}{
+			"conversation_id": sm.conversationID,
+			"session_id":      sm.CurrentSessionID,
+			"session_chain":   sm.GetSessionChain(),
+			"model":           sm.Model,
+			"read_only":       sm.ReadOnly,
+			"stats":           sm.getSessionStats(),
+			"created":         time.Now(),
+		},
+	}
+	for _, t := range turns {
+		dir := fmt.Sprintf("turn-%03d/", t.Index)
+		files[dir+"turn.json"] = t
+		files[dir+"invocations.json"] = t.Invocations
+		if err := writeZipLines(zw, dir+"stream.jsonl", t.Lines); err != nil {
+			return "", err
+		}
+		events := make([]string, len(t.Events))
+		for i, event := range t.Events {
+			data, _ := json.Marshal(event)
+			events[i] = string(data)
+		}
+		if err := writeZipLines(zw, dir+"events.jsonl", events); err != nil {
+			return "", err
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		if err := writeZipFile(zw, name, data); err != nil {
+			return "", err
+		}
+	}
+	for name, data := range extra {
+		if err := writeZipFile(zw, name, data); err != nil {
+			return "", err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to write debug bundle: %w", err)
+	}
+	return path, nil
+}
+
+// writeZipLines adds a file with one line per entry to a zip archive
+func writeZipLines(zw *zip.Writer, name string, lines []string) error {
+	var data []byte
+	for _, line := range lines {
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	return writeZipFile(zw, name, data)
+}
+
+// writeZipFile adds a file to a zip archive
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to debug bundle: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to debug bundle: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultDiffContextTokens is the default budget for an injected diff
+const DefaultDiffContextTokens = 4000
+
+// DiffContext controls prepending the working tree changes made since the
+// last turn to each prompt, so Claude sees edits made outside the session
+type DiffContext struct {
+	Enabled bool `toml:"enabled" json:"enabled"`
+	// MaxTokens is the approximate budget for the diff; longer diffs are cut
+	MaxTokens int `toml:"max_tokens" json:"max_tokens"`
+}
+
+// diffTracker remembers the working tree as it was at the end of the last turn
+type diffTracker struct {
+	snapshot  string          // commit capturing tracked files
+	untracked map[string]bool // untracked files present at the snapshot
+}
+
+// reset forgets the snapshot, so the next prompt gets no diff
+func (d *diffTracker) reset() {
+	d.snapshot = ""
+	d.untracked = nil
+}
+
+// SetDiffContext sets whether and how working tree changes are injected
+func (sm *SessionManager) SetDiffContext(cfg DiffContext) {
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = DefaultDiffContextTokens
+	}
+	sm.DiffContext = cfg
+}
+
+// snapshotWorkingTree records the working tree at the end of a turn. It uses
+// "git stash create", which builds a commit without touching the index, HEAD
+// or the stash list.
+func (sm *SessionManager) snapshotWorkingTree() {
+	if !sm.DiffContext.Enabled {
+		return
+	}
+	sm.diff.reset()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	snapshot, err := git(ctx, sm.Dir(), "stash", "create")
+	if err != nil {
+		return
+	}
+	if snapshot == "" {
+		// Clean working tree
+		if snapshot, err = git(ctx, sm.Dir(), "rev-parse", "HEAD"); err != nil {
+			return
+		}
+	}
+	untracked, err := untrackedFiles(ctx, sm.Dir())
+	if err != nil {
+		return
+	}
+
+	sm.diff.snapshot = snapshot
+	sm.diff.untracked = make(map[string]bool, len(untracked))
+	for _, path := range untracked {
+		sm.diff.untracked[path] = true
+	}
+}
+
+// withDiffContext prepends the changes made since the last snapshot to the
+// prompt, truncated to the configured token budget
+func (sm *SessionManager) withDiffContext(ctx context.Context, prompt string) string {
+	if !sm.DiffContext.Enabled || sm.diff.snapshot == "" {
+		return prompt
+	}
+
+	diff, err := git(ctx, sm.Dir(), "diff", sm.diff.snapshot, "--")
+	if err != nil {
+		return prompt
+	}
+	var added []string
+	if untracked, err := untrackedFiles(ctx, sm.Dir()); err == nil {
+		for _, path := range untracked {
+			if !sm.diff.untracked[path] {
+				added = append(added, path)
+			}
+		}
+	}
+	if diff == "" && len(added) == 0 {
+		return prompt
+	}
+
+	var b strings.Builder
+	b.WriteString("<working_tree_changes>\n")
+	b.WriteString("The user changed these files since your last turn:\n")
+	if diff != "" {
+		b.WriteString("```diff\n")
+		b.WriteString(truncateDiff(diff, sm.DiffContext.MaxTokens*4))
+		b.WriteString("\n```\n")
+	}
+	if len(added) > 0 {
+		b.WriteString("New untracked files: " + strings.Join(added, ", ") + "\n")
+	}
+	b.WriteString("</working_tree_changes>\n\n")
+	b.WriteString(prompt)
+	return b.String()
+}
+
+// truncateDiff cuts a diff to at most maxChars at a line boundary
+func truncateDiff(diff string, maxChars int) string {
+	if len(diff) <= maxChars {
+		return diff
+	}
+	cut := strings.LastIndex(diff[:maxChars], "\n")
+	if cut < 0 {
+		cut = 0
+	}
+	omitted := strings.Count(diff[cut:], "\n")
+	return fmt.Sprintf("%s\n... diff truncated (%d more lines)", diff[:cut], omitted)
+}
+
+// untrackedFiles lists untracked files that are not ignored
+func untrackedFiles(ctx context.Context, dir string) ([]string, error) {
+	out, err := git(ctx, dir, "ls-files", "--others", "--exclude-standard")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// git runs a git command in dir and returns its trimmed
+// output
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
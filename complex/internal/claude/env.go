@@ -0,0 +1,43 @@
+package claude
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// envName matches the variable names SetEnv accepts
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidEnvName reports whether name can be used as an environment variable
+func ValidEnvName(name string) bool {
+	return envName.MatchString(name)
+}
+
+// SetEnv sets a variable in the environment of the claude process, so its
+// tools, such as Bash, run against it. An empty value removes the variable.
+func (sm *SessionManager) SetEnv(name, value string) error {
+	if !ValidEnvName(name) {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	if value == "" {
+		delete(sm.env, name)
+		return nil
+	}
+	if sm.env == nil {
+		sm.env = make(map[string]string)
+	}
+	sm.env[name] = value
+	return nil
+}
+
+// Env returns the session's environment variables as NAME=value pairs,
+// sorted by name
+func (sm *SessionManager) Env() []string {
+	pairs := make([]string, 0, len(sm.env))
+	for name, value := range sm.env {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
@@ -0,0 +1,110 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"customclaude/pkg/transcript"
+)
+
+// ExpandExportPath fills the placeholders of an export path template:
+// {date}, {time}, {session_id} and {conversation_id}
+func (sm *SessionManager) ExpandExportPath(template string) string {
+	return expandExportPath(template, sm.record())
+}
+
+// expandExportPath fills the placeholders of an export path template for a
+// conversation record
+func expandExportPath(template string, record SessionRecord) string {
+	return transcript.ExpandPath(template, record.CurrentSessionID, record.ID)
+}
+
+// ExportTranscript writes the full conversation to a file named by the path
+// template. Files ending in .jsonl (or .json) are written as JSON lines, with
+// a trailing stats record; anything else is written as Markdown.
+func (sm *SessionManager) ExportTranscript(template string) (string, error) {
+	return exportRecord(template, sm.record())
+}
+
+// exportRecord writes a conversation record to the file named by the path
+// template
+func exportRecord(template string, record SessionRecord) (string, error) {
+	path := expandExportPath(template, record)
+	var data []byte
+	var err error
+	if transcript.IsJSONL(path) {
+		data, err = transcriptJSONL(record)
+	} else {
+		data = []byte(transcriptMarkdown(record))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := transcript.Write(path, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// transcriptJSONL encodes each message and the final stats as JSON lines
+func transcriptJSONL(record SessionRecord) ([]byte, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, msg := range record.Messages {
+		if err := enc.Encode(msg); err != nil {
+			return nil, fmt.Errorf("failed to encode transcript: %w", err)
+		}
+	}
+	stats := struct {
+		Type         string       `json:"type"`
+		SessionID    string       `json:"session_id"`
+		SessionChain []string     `json:"session_chain"`
+		Model        string       `json:"model"`
+		Stats        SessionStats `json:"stats"`
+	}{"stats", record.CurrentSessionID, record.SessionChain, record.Model, record.Stats}
+	if err := enc.Encode(stats); err != nil {
+		return nil, fmt.Errorf("failed to encode transcript: %w", err)
+	}
+	return []byte(b.String()), nil
+}
+
+// transcriptMarkdown renders the conversation and stats as Markdown
+func transcriptMarkdown(record SessionRecord) string {
+	var b strings.Builder
+
+	title := record.Title
+	if title == "" {
+		title = "Conversation"
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- **Session:** %s\n", record.CurrentSessionID)
+	if record.Model != "" {
+		fmt.Fprintf(&b, "- **Model:** %s\n", record.Model)
+	}
+	if len(record.Tags) > 0 {
+		fmt.Fprintf(&b, "- **Tags:** %s\n", strings.Join(record.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "- **Started:** %s\n\n", record.CreatedAt.Format(time.RFC3339))
+
+	entries := make([]transcript.Entry, len(record.Messages))
+	for i, msg := range record.Messages {
+		entries[i] = transcript.Entry{Type: msg.Type, Content: msg.Content, ToolInput: msg.ToolInput, Timestamp: msg.Timestamp}
+	}
+	b.WriteString(transcript.Markdown(entries))
+
+	stats := record.Stats
+	usage := stats.CumulativeUsage
+	b.WriteString("## Stats\n\n")
+	fmt.Fprintf(&b, "- **Turns:** %d\n", stats.CumulativeTurns)
+	fmt.Fprintf(&b, "- **Duration:** %s\n", (time.Duration(stats.CumulativeDuration) * time.Millisecond).Round(time.Second))
+	fmt.Fprintf(&b, "- **Cost:** $%.4f\n", stats.CumulativeCost)
+	fmt.Fprintf(&b, "- **Tokens:** input %d, output %d, cache read %d, cache creation %d\n",
+		usage.InputTokens, usage.OutputTokens, usage.CacheReadInputTokens, usage.CacheCreationInputTokens)
+	if len(record.SessionChain) > 1 {
+		b.WriteString("- **Session chain:** " + strings.Join(record.SessionChain, " → ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package claude
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// overloadMarkers are substrings of Claude CLI failures that indicate the
+// model is temporarily overloaded or unavailable
+var overloadMarkers = []string{
+	"overloaded",
+	"model unavailable",
+	"model is currently unavailable",
+	"service unavailable",
+}
+
+// SetFallbackModel sets the model to retry on when the current model is
+// overloaded. An empty model disables fallback.
+func (sm *SessionManager) SetFallbackModel(model string) {
+	sm.FallbackModel = model
+}
+
+// isOverloadError reports whether a failure message indicates overload
+func isOverloadError(text string) bool {
+	text = strings.ToLower(text)
+	for _, marker := range overloadMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// resetFailure clears the failure recorded for the previous invocation
+func (sm *SessionManager) resetFailure() {
+	sm.failureMutex.Lock()
+	defer sm.failureMutex.Unlock()
+	sm.lastFailure = ""
+}
+
+// noteFailure remembers the first overload failure seen in an invocation
+func (sm *SessionManager) noteFailure(text string) {
+	if !isOverloadError(text) {
+		return
+	}
+	sm.failureMutex.Lock()
+	defer sm.failureMutex.Unlock()
+	if sm.lastFailure == "" {
+		sm.lastFailure = text
+	}
+}
+
+// shouldFallback returns the fallback model if the last invocation failed
+// because the model was overloaded and a different fallback is configured
+func (sm *SessionManager) shouldFallback() (string, bool) {
+	sm.failureMutex.Lock()
+	failed := sm.lastFailure != ""
+	sm.failureMutex.Unlock()
+
+	if !failed || sm.FallbackModel == "" || sm.FallbackModel == sm.Model {
+		return "", false
+	}
+	return sm.FallbackModel, true
+}
+
+// announceFallback adds a notice to the conversation before retrying
+func (sm *SessionManager) announceFallback(fallback string) {
+	current := sm.Model
+	if current == "" {
+		current = "the default model"
+	}
+	notice := ConversationMessage{
+		ID:   fmt.Sprintf("fallback_%d", time.Now().UnixNano()),
+		Type: "system",
+		Content: fmt.Sprintf(
+			"%s is overloaded; retrying this prompt on fallback model %s. Pricing for the fallback model may differ.",
+			current, fallback,
+		),
+		Timestamp: time.Now(),
+	}
+	sm.recordMessage(notice)
+	sm.emitEvent(EventMessageReceived, notice)
+}
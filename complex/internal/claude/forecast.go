@@ -0,0 +1,157 @@
+package claude
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ModelPricing is the price of a model in USD per million tokens
+type ModelPricing struct {
+	Input      float64
+	Output     float64
+	CacheWrite float64
+	CacheRead  float64
+}
+
+// modelPricing lists prices by model family, matched against the model name
+var modelPricing = []struct {
+	family  string
+	pricing ModelPricing
+}{
+	{"opus", ModelPricing{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.5}},
+	{"sonnet", ModelPricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3}},
+	{"haiku", ModelPricing{Input: 0.8, Output: 4, CacheWrite: 1, CacheRead: 0.08}},
+}
+
+// defaultModelFamily is priced when no model is set; claude runs Sonnet
+const defaultModelFamily = "sonnet"
+
+// PricingFor returns the pricing of a model. Unknown models are priced as
+// the default model.
+func PricingFor(model string) ModelPricing {
+	model = strings.ToLower(model)
+	if model == "" {
+		model = defaultModelFamily
+	}
+	for _, entry := range modelPricing {
+		if strings.Contains(model, entry.family) {
+			return entry.pricing
+		}
+	}
+	return PricingFor(defaultModelFamily)
+}
+
+// Output/input token ratios assumed until enough history is available
+const (
+	defaultOutputRatioLow  = 0.005
+	defaultOutputRatioHigh = 0.05
+	minRatioSamples        = 3
+)
+
+// CostForecast is the projected cost range of sending a prompt
+type CostForecast struct {
+	PromptTokens  int
+	ContextTokens int
+	// AttachedFiles are @path mentions that claude will read into context
+	AttachedFiles []string
+	OutputLow     int
+	OutputHigh    int
+	Low           float64
+	High          float64
+}
+
+// forecastHistory holds output/input token ratios of stored conversations
+type forecastHistory struct {
+	mu     sync.Mutex
+	ratios []float64
+}
+
+// outputRatio returns the output/input token ratio of a usage total
+func outputRatio(usage Usage) (float64, bool) {
+	input := usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens
+	if input == 0 || usage.OutputTokens == 0 {
+		return 0, false
+	}
+	return float64(usage.OutputTokens) / float64(input), true
+}
+
+// loadForecastHistory collects output/input ratios from the stored
+// conversations; it runs in the background when a store is set
+func (sm *SessionManager) loadForecastHistory(store SessionStore) {
+	records, err := store.List()
+	if err != nil {
+		return
+	}
+	var ratios []float64
+	for _, record := range records {
+		if ratio, ok := outputRatio(record.Stats.CumulativeUsage); ok {
+			ratios = append(ratios, ratio)
+		}
+	}
+	sm.forecast.mu.Lock()
+	sm.forecast.ratios = ratios
+	sm.forecast.mu.Unlock()
+}
+
+// outputRatioRange returns the interquartile range of historical output/input
+// ratios, including the current conversation
+func (sm *SessionManager) outputRatioRange() (float64, float64) {
+	sm.forecast.mu.Lock()
+	ratios := append([]float64(nil), sm.forecast.ratios...)
+	sm.forecast.mu.Unlock()
+	if ratio, ok := outputRatio(sm.CumulativeUsage); ok {
+		ratios = append(ratios, ratio)
+	}
+	if len(ratios) < minRatioSamples {
+		return defaultOutputRatioLow, defaultOutputRatioHigh
+	}
+	sort.Float64s(ratios)
+	return ratios[len(ratios)/4], ratios[len(ratios)*3/4]
+}
+
+// attachedFiles returns the files mentioned as @path in a prompt and their
+// total size in bytes
+func attachedFiles(prompt string) ([]string, int) {
+	var files []string
+	size := 0
+	for _, field := range strings.Fields(prompt) {
+		path, ok := strings.CutPrefix(field, "@")
+		if !ok || path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+			size += int(info.Size())
+		}
+	}
+	return files, size
+}
+
+// ForecastCost projects the cost of sending a prompt in the current
+// conversation. The low end assumes the context is read from the prompt
+// cache and a short answer; the high end assumes the cache expired and a long
+// answer, with output sized by the output/input ratios seen so far.
+func (sm *SessionManager) ForecastCost(prompt string) CostForecast {
+	files, size := attachedFiles(prompt)
+	forecast := CostForecast{
+		PromptTokens:  EstimatePromptTokens(prompt) + estimateTokens(size),
+		ContextTokens: sm.ContextComposition().Total(),
+		AttachedFiles: files,
+	}
+
+	input := forecast.PromptTokens + forecast.ContextTokens
+	low, high := sm.outputRatioRange()
+	forecast.OutputLow = int(float64(input) * low)
+	forecast.OutputHigh = int(float64(input) * high)
+
+	pricing := PricingFor(sm.Model)
+	perToken := func(price float64) float64 { return price / 1e6 }
+	forecast.Low = float64(forecast.ContextTokens)*perToken(pricing.CacheRead) +
+		float64(forecast.PromptTokens)*perToken(pricing.CacheWrite) +
+		float64(forecast.OutputLow)*perToken(pricing.Output)
+	forecast.High = float64(input)*perToken(pricing.CacheWrite) +
+		float64(forecast.OutputHigh)*perToken(pricing.Output)
+	return forecast
+}
@@ -0,0 +1,121 @@
+package claude
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// InterruptReason says why a turn ended without a result
+type InterruptReason string
+
+const (
+	// InterruptCancelled means the user cancelled the turn
+	InterruptCancelled InterruptReason = "cancelled"
+	// InterruptTimedOut means the turn ran longer than the turn timeout
+	InterruptTimedOut InterruptReason = "timed_out"
+	// InterruptCircuitBroken means retries were exhausted and the turn was
+	// given up on
+	InterruptCircuitBroken InterruptReason = "circuit_broken"
+)
+
+// TurnInterruption records a turn that ended without a result, with what
+// was spent on it before it stopped
+type TurnInterruption struct {
+	Reason   InterruptReason `json:"reason"`
+	Prompt   string          `json:"prompt"`
+	Elapsed  time.Duration   `json:"elapsed"`
+	Attempts int             `json:"attempts"`
+	// Usage are the token counts reported by assistant messages of the turn
+	// so far; no result message carried the final numbers
+	Usage Usage  `json:"usage"`
+	Error string `json:"error,omitempty"`
+}
+
+// Summary describes the interruption in one line for the transcript
+func (ti TurnInterruption) Summary() string {
+	var what string
+	switch ti.Reason {
+	case InterruptTimedOut:
+		what = "Turn timed out"
+	case InterruptCircuitBroken:
+		what = fmt.Sprintf("Turn abandoned after %d failed attempts", ti.Attempts)
+	default:
+		what = "Turn cancelled"
+	}
+
+	parts := []string{fmt.Sprintf("%s after %s", what, ti.Elapsed.Round(100*time.Millisecond))}
+	if ti.Usage.InputTokens+ti.Usage.OutputTokens > 0 {
+		parts = append(parts, fmt.Sprintf("partial tokens: %d in / %d out",
+			ti.Usage.InputTokens+ti.Usage.CacheReadInputTokens+ti.Usage.CacheCreationInputTokens,
+			ti.Usage.OutputTokens))
+	}
+	if ti.Error != "" {
+		parts = append(parts, "last error: "+ti.Error)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// partialUsage sums the usage of assistant messages seen during a turn. A
+// message may be repeated while streaming, so the latest usage per message
+// ID counts.
+type partialUsage struct {
+	byMessage map[string]Usage
+}
+
+// reset discards usage from a previous turn
+func (pu *partialUsage) reset() {
+	pu.byMessage = nil
+}
+
+// note records the usage reported by an assistant message
+func (pu *partialUsage) note(id string, usage *Usage) {
+	if usage == nil {
+		return
+	}
+	if pu.byMessage == nil {
+		pu.byMessage = make(map[string]Usage)
+	}
+	pu.byMessage[id] = *usage
+}
+
+// total returns the summed usage
+func (pu *partialUsage) total() Usage {
+	var sum Usage
+	for _, usage := range pu.byMessage {
+		sum.InputTokens += usage.InputTokens
+		sum.CacheCreationInputTokens += usage.CacheCreationInputTokens
+		sum.CacheReadInputTokens += usage.CacheReadInputTokens
+		sum.OutputTokens += usage.OutputTokens
+	}
+	return sum
+}
+
+// SetTurnTimeout limits how long a single command may run, including
+// retries; 0 disables the limit
+func (sm *SessionManager) SetTurnTimeout(timeout time.Duration) {
+	sm.TurnTimeout = timeout
+}
+
+// recordInterruption adds a system message explaining why the turn stopped
+// to the transcript and counts its partial usage in the session stats
+func (sm *SessionManager) recordInterruption(interruption TurnInterruption) {
+	sm.Interruptions++
+	sm.CumulativeUsage.InputTokens += interruption.Usage.InputTokens
+	sm.CumulativeUsage.CacheCreationInputTokens += interruption.Usage.CacheCreationInputTokens
+	sm.CumulativeUsage.CacheReadInputTokens += interruption.Usage.CacheReadInputTokens
+	sm.CumulativeUsage.OutputTokens += interruption.Usage.OutputTokens
+
+	sm.recordMessage(ConversationMessage{
+		ID:           fmt.Sprintf("interrupted_%d", time.Now().UnixNano()),
+		Type:         "system",
+		Content:      interruption.Summary(),
+		Timestamp:    time.Now(),
+		IsError:      true,
+		Interruption: &interruption,
+	})
+	sm.persist()
+
+	sm.emitEvent(EventCommandCancelled, interruption)
+	sm.emitEvent(EventStatsUpdate, sm.getSessionStats())
+}
@@ -0,0 +1,17 @@
+package claude
+
+import "customclaude/pkg/claudecli"
+
+// DefaultMaxLineBytes is the longest stream line parsed when no limit is
+// configured
+const DefaultMaxLineBytes = claudecli.DefaultMaxLineBytes
+
+// LineTooLongError reports a stream line that was skipped for exceeding the
+// line limit
+type LineTooLongError = claudecli.LineTooLongError
+
+// SetMaxLineBytes limits the length of stream lines that are parsed; longer
+// ones are skipped with an error event. 0 uses DefaultMaxLineBytes.
+func (sm *SessionManager) SetMaxLineBytes(limit int) {
+	sm.MaxLineBytes = limit
+}
@@ -0,0 +1,27 @@
+package claude
+
+import (
+	"fmt"
+
+	"customclaude/pkg/sessionlock"
+)
+
+// ReleaseSessionLock removes the lock held by this session manager, if any
+func (sm *SessionManager) ReleaseSessionLock() {
+	sm.locks.Release()
+}
+
+// TakeOverSession forcibly takes the lock of the current session from
+// another instance
+func (sm *SessionManager) TakeOverSession() error {
+	if sm.CurrentSessionID == "" {
+		return fmt.Errorf("no active session to take over")
+	}
+	return sm.locks.Acquire(sm.CurrentSessionID, true)
+}
+
+// SessionLockHolder returns the live owner of the current session when it is
+// locked by another instance
+func (sm *SessionManager) SessionLockHolder() (sessionlock.Lock, bool) {
+	return sm.locks.Holder(sm.CurrentSessionID)
+}
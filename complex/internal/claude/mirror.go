@@ -0,0 +1,101 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// mirrorBuffer is the number of text deltas held for a slow reader before
+// further ones are dropped
+const mirrorBuffer = 1024
+
+// streamMirror copies the assistant text of each turn to a file or named
+// pipe as it streams. The file is truncated and the pipe reopened at the
+// start of every turn and closed at its end, so a reader such as
+// `while true; do cat pipe; done` sees one turn per open.
+type streamMirror struct {
+	path   string
+	deltas chan string
+	wrote  bool // text written this turn, to separate text blocks
+}
+
+// SetStreamMirror mirrors assistant text to path from the next turn on; an
+// empty path stops mirroring
+func (sm *SessionManager) SetStreamMirror(path string) {
+	sm.mirror.path = path
+}
+
+// StreamMirror returns the path assistant text is mirrored to, or ""
+func (sm *SessionManager) StreamMirror() string {
+	return sm.mirror.path
+}
+
+// begin opens the mirror for a turn. A named pipe without a reader is
+// skipped for the turn rather than blocking it.
+func (m *streamMirror) begin() error {
+	if m.path == "" {
+		return nil
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if info, err := os.Stat(m.path); err == nil && info.Mode()&fs.ModeNamedPipe != 0 {
+		flags = os.O_WRONLY | syscall.O_NONBLOCK
+	}
+	f, err := os.OpenFile(m.path, flags, 0o644)
+	if errors.Is(err, syscall.ENXIO) {
+		return nil // nobody is reading the pipe
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open stream mirror: %w", err)
+	}
+
+	m.deltas = make(chan string, mirrorBuffer)
+	m.wrote = false
+	go func(deltas <-chan string) {
+		defer f.Close()
+		failed := false
+		for text := range deltas {
+			// Keep draining after a failure, such as the reader going away
+			if !failed {
+				_, err := f.WriteString(text)
+				failed = err != nil
+			}
+		}
+	}(m.deltas)
+	return nil
+}
+
+// blockStart separates a new text block from the text before it
+func (m *streamMirror) blockStart() {
+	if m.wrote {
+		m.write("\n\n")
+	}
+}
+
+// write queues text for the mirror, dropping it when the reader is too far
+// behind
+func (m *streamMirror) write(text string) {
+	if m.deltas == nil {
+		return
+	}
+	m.wrote = true
+	select {
+	case m.deltas <- text:
+	default:
+	}
+}
+
+// end finishes the turn's text. The mirror closes once the queued text is
+// written, without holding up the turn for a slow reader.
+func (m *streamMirror) end() {
+	if m.deltas == nil {
+		return
+	}
+	if m.wrote {
+		m.write("\n")
+	}
+	close(m.deltas)
+	m.deltas = nil
+}
@@ -0,0 +1,133 @@
+package claude
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxTitleLength bounds the conversation title derived from the first prompt
+const maxTitleLength = 60
+
+// SetStore enables persistence of conversations to the given store
+func (sm *SessionManager) SetStore(store SessionStore) {
+	sm.store = store
+	go sm.loadForecastHistory(store)
+}
+
+// Store returns the configured session store, or nil if persistence is off
+func (sm *SessionManager) Store() SessionStore {
+	return sm.store
+}
+
+// ConversationID returns the local identifier of the current conversation
+func (sm *SessionManager) ConversationID() string {
+	return sm.conversationID
+}
+
+// Title returns the conversation title, taken from its first prompt
+func (sm *SessionManager) Title() string {
+	return sm.title
+}
+
+// recordMessage appends a message to the persisted transcript
+func (sm *SessionManager) recordMessage(msg ConversationMessage) {
+	sm.transcript = append(sm.transcript, msg)
+	if sm.title == "" && msg.Type == "user" {
+		sm.title = msg.Content
+		if len(sm.title) > maxTitleLength {
+			sm.title = sm.title[:maxTitleLength-3] + "..."
+		}
+	}
+}
+
+// recordToolResult attaches a tool's output to its tool_use message
+func (sm *SessionManager) recordToolResult(toolUseID, output, status string) {
+	for i := len(sm.transcript) - 1; i >= 0; i-- {
+		if sm.transcript[i].Type == "tool_use" && sm.transcript[i].ToolUseID == toolUseID {
+			sm.transcript[i].ToolResult = output
+			sm.transcript[i].ToolStatus = status
+			sm.transcript[i].IsError = status == "failed"
+			return
+		}
+	}
+}
+
+// persist saves the current conversation to the store
+func (sm *SessionManager) persist() {
+	if sm.store == nil || sm.CurrentSessionID == "" {
+		return
+	}
+
+	if err := sm.store.Save(sm.record()); err != nil {
+		sm.emitEvent(EventError, fmt.Errorf("failed to save session: %w", err))
+	}
+}
+
+// record builds the persisted form of the current conversation. Continuing
+// a conversation makes it active again, so it is never saved as archived.
+func (sm *SessionManager) record() SessionRecord {
+	return SessionRecord{
+		ID:               sm.conversationID,
+		Title:            sm.title,
+		Model:            sm.Model,
+		CurrentSessionID: sm.CurrentSessionID,
+		SessionChain:     sm.GetSessionChain(),
+		ChainLinks:       append([]ChainLink(nil), sm.chainLinks...),
+		Stats:            sm.getSessionStats(),
+		Messages:         append([]ConversationMessage(nil), sm.transcript...),
+		Tags:             append([]string(nil), sm.tags...),
+		CompactedFrom:    sm.compactedFrom,
+		CompactedInto:    sm.compactedInto,
+		CreatedAt:        sm.ConversationStart,
+		UpdatedAt:        time.Now(),
+	}
+}
+
+// ListConversations returns stored conversations, most recent first
+func (sm *SessionManager) ListConversations() ([]SessionRecord, error) {
+	if sm.store == nil {
+		return nil, fmt.Errorf("session storage is not configured")
+	}
+	return sm.store.List()
+}
+
+// ResumeConversation reattaches to a stored conversation so that the next
+// prompt resumes its latest Claude session
+func (sm *SessionManager) ResumeConversation(id string) (SessionRecord, error) {
+	if sm.store == nil {
+		return SessionRecord{}, fmt.Errorf("session storage is not configured")
+	}
+
+	record, err := sm.store.Load(id)
+	if err != nil {
+		return SessionRecord{}, fmt.Errorf("failed to load session %s: %w", id, err)
+	}
+
+	sm.ReleaseSessionLock()
+	sm.conversationID = record.ID
+	sm.title = record.Title
+	sm.tags = append([]string(nil), record.Tags...)
+	sm.compactedFrom, sm.compactedInto = record.CompactedFrom, record.CompactedInto
+	sm.transcript = append([]ConversationMessage(nil), record.Messages...)
+	sm.CurrentSessionID = record.CurrentSessionID
+	sm.Model = record.Model
+	sm.SessionChain = append([]string(nil), record.SessionChain...)
+	sm.chainLinks = append([]ChainLink(nil), record.ChainLinks...)
+	sm.CumulativeDuration = record.Stats.CumulativeDuration
+	sm.CumulativeTurns = record.Stats.CumulativeTurns
+	sm.CumulativeCost = record.Stats.CumulativeCost
+	sm.CumulativeUsage = record.Stats.CumulativeUsage
+	sm.Interruptions = record.Stats.Interruptions
+	sm.ConversationStart = record.CreatedAt
+	sm.lastActivity = record.UpdatedAt
+	sm.debug.reset()
+
+	sm.emitEvent(EventSessionUpdate, sm.getCurrentSessionInfo())
+	sm.emitEvent(EventStatsUpdate, sm.getSessionStats())
+	return record, nil
+}
+
+// newConversationID generates a local identifier for a conversation
+func newConversationID() string {
+	return fmt.Sprintf("conv_%d", time.Now().UnixNano())
+}
@@ -0,0 +1,59 @@
+package claude
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// previewFileLines caps the lines of an attached file shown in a preview
+const previewFileLines = 200
+
+// PromptPreview is a prompt as it will be sent: the working tree changes
+// prepended, the @path files claude will read and the cost forecast
+type PromptPreview struct {
+	Typed        string
+	Prompt       string // as sent to claude
+	DiffContext  bool   // working tree changes were prepended
+	SystemPrompt SystemPrompt
+	Files        []AttachedFile
+	Forecast     CostForecast
+}
+
+// AttachedFile is a file mentioned as @path, which claude reads into context
+type AttachedFile struct {
+	Path      string
+	Content   string
+	Truncated bool // cut to previewFileLines
+	Err       error
+}
+
+// PreviewPrompt expands a prompt the way ExecuteCommand would without
+// sending it. The diff context compares against the last snapshot, so it may
+// run git.
+func (sm *SessionManager) PreviewPrompt(ctx context.Context, prompt string) PromptPreview {
+	preview := PromptPreview{
+		Typed:        prompt,
+		Prompt:       sm.withDiffContext(ctx, prompt),
+		SystemPrompt: sm.SystemPrompt(),
+		Forecast:     sm.ForecastCost(prompt),
+	}
+	preview.DiffContext = preview.Prompt != prompt
+
+	for _, path := range preview.Forecast.AttachedFiles {
+		file := AttachedFile{Path: path}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			file.Err = err
+		} else {
+			lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+			if len(lines) > previewFileLines {
+				lines = lines[:previewFileLines]
+				file.Truncated = true
+			}
+			file.Content = strings.Join(lines, "\n")
+		}
+		preview.Files = append(preview.Files, file)
+	}
+	return preview
+}
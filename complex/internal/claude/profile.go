@@ -0,0 +1,258 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProjectProfileFile picks the profile of a project. It is looked for in the
+// working directory and its parents up to the project root, and holds either
+// the name of a configured profile (profile = "work"), settings of its own,
+// or both, the file's settings taking precedence.
+const ProjectProfileFile = ".cc-custom.toml"
+
+// ProjectProfileName names a profile defined only by a project file
+const ProjectProfileName = "project"
+
+// Profile is a named set of session settings. Empty settings keep the
+// configured ones.
+type Profile struct {
+	Model        string `toml:"model"`
+	SystemPrompt string `toml:"system_prompt"`
+	MCPConfig    string `toml:"mcp_config"`
+	Allowlist    string `toml:"allowlist"` // tool allowlist preset
+	// Budget replaces the configured limits when set
+	Budget *Budget `toml:"budget"`
+}
+
+// merge returns p with the non-empty settings of other laid over it
+func (p Profile) merge(other Profile) Profile {
+	if other.Model != "" {
+		p.Model = other.Model
+	}
+	if other.SystemPrompt != "" {
+		p.SystemPrompt = other.SystemPrompt
+	}
+	if other.MCPConfig != "" {
+		p.MCPConfig = other.MCPConfig
+	}
+	if other.Allowlist != "" {
+		p.Allowlist = other.Allowlist
+	}
+	if other.Budget != nil {
+		p.Budget = other.Budget
+	}
+	return p
+}
+
+// Summary describes the settings a profile changes
+func (p Profile) Summary() string {
+	var parts []string
+	if p.Model != "" {
+		parts = append(parts, "model "+p.Model)
+	}
+	if p.SystemPrompt != "" {
+		parts = append(parts, "system prompt")
+	}
+	if p.MCPConfig != "" {
+		parts = append(parts, "MCP "+p.MCPConfig)
+	}
+	if p.Allowlist != "" {
+		parts = append(parts, "allowlist "+p.Allowlist)
+	}
+	if p.Budget != nil {
+		parts = append(parts, fmt.Sprintf("budget $%.2f/conversation $%.2f/day", p.Budget.Conversation, p.Budget.Daily))
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// projectProfile is the content of a project profile file
+type projectProfile struct {
+	Use string `toml:"profile"`
+	Profile
+}
+
+// ActiveProfile describes the profile a session uses
+type ActiveProfile struct {
+	Name    string
+	Profile Profile
+	// Source is the project file that picked the profile; empty when it was
+	// chosen with /profile
+	Source string
+}
+
+// profileState holds the configured profiles, the active one and the
+// settings it replaced
+type profileState struct {
+	enabled  bool // project files are followed
+	profiles map[string]Profile
+	active   *ActiveProfile
+	base     *Profile // settings in force without a profile
+}
+
+// FindProjectProfile returns the nearest project profile file from dir up to
+// root, or "" when there is none
+func FindProjectProfile(dir, root string) string {
+	for {
+		path := filepath.Join(dir, ProjectProfileFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readProjectProfile resolves a project profile file against the configured
+// profiles. A relative mcp_config is taken from the file's directory.
+func readProjectProfile(path string, profiles map[string]Profile) (string, Profile, error) {
+	var file projectProfile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return "", Profile{}, fmt.Errorf("failed to read profile %s: %w", path, err)
+	}
+	if file.MCPConfig != "" && !filepath.IsAbs(file.MCPConfig) {
+		file.MCPConfig = filepath.Join(filepath.Dir(path), file.MCPConfig)
+	}
+	if file.Use == "" {
+		return ProjectProfileName, file.Profile, nil
+	}
+	named, ok := profiles[file.Use]
+	if !ok {
+		return "", Profile{}, fmt.Errorf("unknown profile %q in %s", file.Use, path)
+	}
+	return file.Use, named.merge(file.Profile), nil
+}
+
+// SetProfiles sets the configured profiles and activates the one picked by
+// the project, then again whenever the directory changes. Call it once the
+// other settings are in place; they are restored when no profile is active.
+func (sm *SessionManager) SetProfiles(profiles map[string]Profile) {
+	sm.profiles.enabled = true
+	sm.profiles.profiles = profiles
+	sm.followProjectProfile()
+}
+
+// followProjectProfile applies the project's profile, reporting a broken file
+// as an error event
+func (sm *SessionManager) followProjectProfile() {
+	if err := sm.DetectProfile(); err != nil {
+		sm.log.Warn("project profile not applied", "err", err)
+		sm.emitEvent(EventError, err)
+	}
+}
+
+// ProfileNames returns the configured profile names in order
+func (sm *SessionManager) ProfileNames() []string {
+	names := make([]string, 0, len(sm.profiles.profiles))
+	for name := range sm.profiles.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns a configured profile
+func (sm *SessionManager) Profile(name string) (Profile, bool) {
+	profile, ok := sm.profiles.profiles[name]
+	return profile, ok
+}
+
+// ActiveProfile returns the profile in use, or nil
+func (sm *SessionManager) ActiveProfile() *ActiveProfile {
+	return sm.profiles.active
+}
+
+// DetectProfile activates the profile picked by the nearest project profile
+// file, or goes back to the configured settings when there is none
+func (sm *SessionManager) DetectProfile() error {
+	path := FindProjectProfile(sm.Dir(), sm.ProjectRoot())
+	if path == "" {
+		sm.applyProfile(nil)
+		return nil
+	}
+	name, profile, err := readProjectProfile(path, sm.profiles.profiles)
+	if err != nil {
+		sm.applyProfile(nil)
+		return err
+	}
+	sm.applyProfile(&ActiveProfile{Name: name, Profile: profile, Source: path})
+	return nil
+}
+
+// UseProfile activates a configured profile by name; an empty name goes back
+// to the configured settings
+func (sm *SessionManager) UseProfile(name string) error {
+	if name == "" {
+		sm.applyProfile(nil)
+		return nil
+	}
+	profile, ok := sm.profiles.profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	sm.applyProfile(&ActiveProfile{Name: name, Profile: profile})
+	return nil
+}
+
+// applyProfile lays a profile over the configured settings. The settings in
+// force when the first profile is applied are the ones restored without one.
+func (sm *SessionManager) applyProfile(active *ActiveProfile) {
+	if active == nil && sm.profiles.active == nil {
+		return
+	}
+	if sm.profiles.base == nil {
+		budget := sm.Budget
+		sm.profiles.base = &Profile{Model: sm.Model, MCPConfig: sm.MCPConfigPath, Allowlist: sm.allowlist, Budget: &budget}
+	}
+	settings := *sm.profiles.base
+	if active != nil {
+		settings = settings.merge(active.Profile)
+	}
+	sm.Model = settings.Model
+	sm.MCPConfigPath = settings.MCPConfig
+	sm.allowlist = settings.Allowlist
+	if settings.Budget != nil {
+		sm.Budget = *settings.Budget
+		if len(sm.Budget.WarnAt) == 0 {
+			sm.Budget.WarnAt = DefaultBudgetWarnAt
+		}
+	}
+	sm.systemPrompt.profile = ""
+	if active != nil {
+		sm.systemPrompt.profile = active.Profile.SystemPrompt
+	}
+
+	sm.profiles.active = active
+	name := "none"
+	if active != nil {
+		name = active.Name
+	}
+	sm.log.Info("profile applied", "profile", name)
+	sm.emitEvent(EventSessionUpdate, "profile_changed")
+}
+
+// ValidateProfile reports settings of a profile that cannot be used
+func ValidateProfile(profile Profile) error {
+	var errs []error
+	if profile.Allowlist != "" {
+		if _, ok := FindAllowlist(profile.Allowlist); !ok {
+			errs = append(errs, fmt.Errorf("allowlist must be one of %s", strings.Join(AllowlistNames(), ", ")))
+		}
+	}
+	if profile.Budget != nil && (profile.Budget.Conversation < 0 || profile.Budget.Daily < 0) {
+		errs = append(errs, errors.New("budget limits must not be negative"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,7 @@
+package claude
+
+// SetReadOnly toggles the read-only profile: write tools are disallowed and
+// Claude runs in plan permission mode
+func (sm *SessionManager) SetReadOnly(readOnly bool) {
+	sm.ReadOnly = readOnly
+}
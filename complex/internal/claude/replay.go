@@ -0,0 +1,38 @@
+package claude
+
+import (
+	"time"
+
+	"customclaude/pkg/claudecli"
+)
+
+// SetRunner sets what starts claude invocations; nil runs the claude binary
+func (sm *SessionManager) SetRunner(runner claudecli.Runner) {
+	sm.runner = runner
+	sm.replay = ""
+}
+
+// SetReplay makes every prompt replay the stream-json recording at path
+// instead of running claude, pausing delay before each line; an empty path
+// runs claude again
+func (sm *SessionManager) SetReplay(path string, delay time.Duration) {
+	if path == "" {
+		sm.SetRunner(nil)
+		return
+	}
+	sm.SetRunner(claudecli.ReplayRunner{Path: path, Delay: delay})
+	sm.replay = path
+}
+
+// Replay returns the recording prompts are answered from, or ""
+func (sm *SessionManager) Replay() string {
+	return sm.replay
+}
+
+// claudeRunner returns the configured runner, or one for the binary
+func (sm *SessionManager) claudeRunner() claudecli.Runner {
+	if sm.runner == nil {
+		return claudecli.ExecRunner{}
+	}
+	return sm.runner
+}
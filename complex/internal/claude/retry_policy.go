@@ -0,0 +1,51 @@
+package claude
+
+import (
+	"time"
+)
+
+// RetryPolicy controls how failed claude invocations are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts; 1 or less disables retries
+	MaxAttempts    int           `toml:"max_attempts" json:"max_attempts"`
+	InitialBackoff time.Duration `toml:"initial_backoff" json:"initial_backoff"`
+	MaxBackoff     time.Duration `toml:"max_backoff" json:"max_backoff"`
+}
+
+// DefaultRetryPolicy retries twice, waiting 2s and then 4s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 2 * time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// RetryInfo describes an upcoming retry of a failed invocation
+type RetryInfo struct {
+	Attempt     int           `json:"attempt"`
+	MaxAttempts int           `json:"max_attempts"`
+	Delay       time.Duration `json:"delay"`
+	Err         string        `json:"error"`
+}
+
+// backoff returns the delay before the attempt following failed, or false
+// when no attempts are left. The delay doubles per attempt up to MaxBackoff.
+func (p RetryPolicy) backoff(failed int) (time.Duration, bool) {
+	if failed >= p.MaxAttempts {
+		return 0, false
+	}
+	delay := p.InitialBackoff
+	for i := 1; i < failed; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay, true
+}
+
+// SetRetryPolicy sets how failed invocations are retried
+func (sm *SessionManager) SetRetryPolicy(policy RetryPolicy) {
+	sm.RetryPolicy = policy
+}
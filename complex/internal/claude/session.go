@@ -4,14 +4,23 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"os/exec"
-	"strings"
+	"log/slog"
 	"sync"
 	"time"
+
+	"complex/internal/logging"
+	"customclaude/pkg/claudecli"
+	"customclaude/pkg/procs"
+	"customclaude/pkg/sessionlock"
 )
 
+// ErrCommandCancelled is returned by ExecuteCommand when its context is
+// cancelled while the claude process is running
+var ErrCommandCancelled = errors.New("command cancelled")
+
 // EventHandler defines the interface for handling session events
 type EventHandler interface {
 	HandleEvent(event Event)
@@ -28,16 +37,130 @@ type SessionManager struct {
 	CumulativeUsage    Usage
 	ConversationStart  time.Time
 
+	log *slog.Logger
+
 	// Event handling
 	eventHandlers []EventHandler
 	eventMutex    sync.RWMutex
+
+	// Per-turn latency measurement
+	latency claudecli.LatencyTracker
+
+	// Context window composition estimates
+	context contextTracker
+
+	// Tool output captured during the current command
+	toolRuns toolRunState
+
+	// Timestamp of the stream line being processed, and the number of lines
+	// read so far
+	lineTime time.Time
+	lineSeq  int64
+
+	// Longest stream line parsed; 0 uses DefaultMaxLineBytes
+	MaxLineBytes int
+
+	// Partial message streaming
+	stream streamState
+
+	// Assistant text mirrored to a file or named pipe as it streams
+	mirror streamMirror
+
+	// Starts claude invocations; nil runs the binary. replay is the
+	// recording a ReplayRunner plays, for display.
+	runner claudecli.Runner
+	replay string
+
+	// Thinking of the current API message, waiting for its text block
+	pendingThinking string
+
+	// Persistence
+	store          SessionStore
+	conversationID string
+	title          string
+	tags           []string
+	compactedFrom  string // conversation this one was compacted from
+	compactedInto  string // conversation this one was compacted into
+	transcript     []ConversationMessage
+	chainLinks     []ChainLink
+
+	// Advisory lock on the Claude session this manager resumes
+	locks sessionlock.Locker
+
+	// CLI invocation settings
+	MCPConfigPath  string
+	PermissionTool string
+	ReadOnly       bool
+	allowlist      string // preset used when the project names none
+	env            map[string]string
+	dir            string // empty for the process working directory
+	projectRoot    string // cached by ProjectRoot
+	contextWindow  int    // overrides the model's window when set
+
+	// Retries of failed invocations
+	RetryPolicy RetryPolicy
+
+	// Working tree changes injected into prompts
+	DiffContext DiffContext
+	diff        diffTracker
+
+	// Last init message, for the tools and MCP servers claude reported
+	lastInit SystemInit
+
+	// When the last result arrived, to tell how stale the session is
+	lastActivity time.Time
+
+	// Final text of the last turn, read by Compact
+	lastResult string
+	// Result of the last turn of the running command, returned by RunTurn
+	lastTurn TurnResult
+
+	// System prompt set during the session, replacing the project's
+	systemPrompt systemPromptState
+
+	// Configured profiles and the one in use
+	profiles profileState
+
+	// Spending limits and today's spending across all conversations
+	Budget    Budget
+	ledger    spendLedger
+	dailyCost float64
+
+	// Every result's usage, per day and project, for usage reports
+	usage usageLedger
+
+	// Historical output/input ratios for cost forecasts
+	forecast forecastHistory
+
+	// Raw lines, events and invocations of recent turns for debug bundles
+	debug debugRecorder
+
+	// Turns ended without a result, and usage seen during the current turn
+	TurnTimeout   time.Duration
+	Interruptions int
+	partial       partialUsage
+
+	// Model asked for the current prompt when it overrides the session
+	// model, and the model claude reported running it on
+	modelOverride string
+	turnModel     string
+
+	// Model fallback on overload errors
+	FallbackModel string
+	failureMutex  sync.Mutex
+	lastFailure   string
 }
 
 // NewSessionManager creates a new session manager
 func NewSessionManager() *SessionManager {
 	return &SessionManager{
 		ConversationStart: time.Now(),
+		log:               logging.For("session"),
 		eventHandlers:     make([]EventHandler, 0),
+		conversationID:    newConversationID(),
+		MCPConfigPath:     "config.json",
+		PermissionTool:    "mcp__permission__approval_prompt",
+		RetryPolicy:       DefaultRetryPolicy(),
 	}
 }
 
@@ -50,15 +173,24 @@ func (sm *SessionManager) AddEventHandler(handler EventHandler) {
 
 // emitEvent sends an event to all registered handlers
 func (sm *SessionManager) emitEvent(eventType EventType, data interface{}) {
+	sm.emitEventAt(eventType, data, time.Now())
+}
+
+// emitEventAt sends an event with an explicit timestamp
+func (sm *SessionManager) emitEventAt(eventType EventType, data interface{}, timestamp time.Time) {
 	sm.eventMutex.RLock()
 	defer sm.eventMutex.RUnlock()
 
 	event := Event{
 		Type:      eventType,
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: timestamp,
 	}
 
+	if eventType != EventRawLine {
+		// Raw lines are kept in the stream file of the bundle already
+		sm.debug.noteEvent(event)
+	}
 	for _, handler := range sm.eventHandlers {
 		go handler.HandleEvent(event)
 	}
@@ -66,58 +198,217 @@ func (sm *SessionManager) emitEvent(eventType EventType, data interface{}) {
 
 // ExecuteCommand executes a Claude CLI command with event emission
 func (sm *SessionManager) ExecuteCommand(ctx context.Context, prompt string, resume bool) error {
-	args := []string{
-		"--output-format", "stream-json",
-		"--verbose",
-		"-p",
-		"--permission-prompt-tool", "mcp__permission__approval_prompt",
-		"--model", "claude-sonnet-4-20250514",
-		"--mcp-config", "config.json",
+	return sm.ExecuteCommandWithModel(ctx, prompt, resume, "")
+}
+
+// RunTurn is ExecuteCommandWithModel for callers without an event loop: it
+// returns the result of the turn instead of only emitting it, as event
+// handlers run asynchronously. The result is zero when claude sent none.
+func (sm *SessionManager) RunTurn(ctx context.Context, prompt string, resume bool, model string) (TurnResult, error) {
+	if err := sm.ExecuteCommandWithModel(ctx, prompt, resume, model); err != nil {
+		return TurnResult{}, err
 	}
+	return sm.lastTurn, nil
+}
 
-	if sm.Model != "" {
-		args = append(args, "--model", sm.Model)
+// ExecuteCommandWithModel runs a single prompt on model while the session
+// keeps its own model for later prompts. An empty model uses the session's.
+func (sm *SessionManager) ExecuteCommandWithModel(ctx context.Context, prompt string, resume bool, model string) (err error) {
+	sm.toolRuns.reset()
+	sm.lastTurn = TurnResult{}
+	sm.modelOverride, sm.turnModel = model, ""
+	defer func() { sm.modelOverride = "" }()
+	if resume {
+		if err := sm.locks.Acquire(sm.CurrentSessionID, false); err != nil {
+			sm.log.Warn("session lock not acquired", "session_id", sm.CurrentSessionID, "err", err)
+			sm.emitEvent(EventError, err)
+			return err
+		}
 	}
+	sm.recordMessage(ConversationMessage{
+		ID:        fmt.Sprintf("user_%d", time.Now().UnixNano()),
+		Type:      "user",
+		Content:   prompt,
+		Timestamp: time.Now(),
+		Model:     model,
+	})
+	sm.debug.beginTurn(prompt)
+	defer func() { sm.debug.endTurn(err) }()
+	if err := sm.mirror.begin(); err != nil {
+		sm.log.Warn("stream mirror disabled for this turn", "path", sm.mirror.path, "err", err)
+		sm.emitEvent(EventError, err)
+	}
+	defer sm.mirror.end()
+
+	// Claude sees the working tree changes since the last turn, but the
+	// transcript keeps the prompt as typed. A failed turn keeps the old
+	// snapshot so the changes are offered again.
+	defer func() {
+		if err == nil || errors.Is(err, ErrCommandCancelled) {
+			sm.snapshotWorkingTree()
+		}
+	}()
+
+	started := time.Now()
+	typed := prompt
+	if sm.TurnTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sm.TurnTimeout)
+		defer cancel()
+	}
+	prompt = sm.withDiffContext(ctx, prompt)
+	sm.partial.reset()
 
-	if resume && sm.CurrentSessionID != "" {
-		args = append(args, "--resume", sm.CurrentSessionID)
+	attempts, err := sm.runWithRetries(ctx, prompt, resume)
+	if err == nil {
+		return nil
 	}
 
-	args = append(args, prompt)
+	// Explain turns that ended without a result
+	interruption := TurnInterruption{
+		Prompt:   typed,
+		Elapsed:  time.Since(started),
+		Attempts: attempts,
+		Usage:    sm.partial.total(),
+	}
+	sm.log.Warn("turn ended without a result", "attempts", attempts, "elapsed", interruption.Elapsed, "err", err)
+	switch {
+	case errors.Is(err, ErrCommandCancelled):
+		interruption.Reason = InterruptCancelled
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			interruption.Reason = InterruptTimedOut
+		}
+		sm.recordInterruption(interruption)
+	case attempts > 1:
+		interruption.Reason = InterruptCircuitBroken
+		interruption.Error = err.Error()
+		sm.recordInterruption(interruption)
+	}
+	return err
+}
 
-	cmd := exec.CommandContext(ctx, "claude", args...)
+// runWithRetries runs a command, switching to the fallback model on overload
+// and retrying failures per the retry policy. It returns the number of
+// attempts made.
+func (sm *SessionManager) runWithRetries(ctx context.Context, prompt string, resume bool) (int, error) {
+	// Retries resume the session the command started from
+	sessionID := sm.CurrentSessionID
+	model := sm.modelOverride
+	fellBack := false
+	for failed := 0; ; {
+		err := sm.runCommand(ctx, prompt, resume, model)
+		if errors.Is(err, ErrCommandCancelled) {
+			return failed + 1, err
+		}
+		if !fellBack {
+			if fallback, ok := sm.shouldFallback(); ok {
+				sm.log.Warn("model overloaded, falling back", "fallback_model", fallback)
+				sm.announceFallback(fallback)
+				model = fallback
+				fellBack = true
+				sm.CurrentSessionID = sessionID
+				continue
+			}
+		}
+		if err == nil {
+			return failed + 1, nil
+		}
 
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		sm.emitEvent(EventError, fmt.Errorf("failed to create stdout pipe: %w", err))
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+		failed++
+		delay, ok := sm.RetryPolicy.backoff(failed)
+		if !ok {
+			return failed, err
+		}
+		sm.log.Warn("command failed, retrying", "attempt", failed+1, "max_attempts", sm.RetryPolicy.MaxAttempts, "delay", delay, "err", err)
+		sm.emitEvent(EventRetry, RetryInfo{
+			Attempt:     failed + 1,
+			MaxAttempts: sm.RetryPolicy.MaxAttempts,
+			Delay:       delay,
+			Err:         err.Error(),
+		})
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return failed, ErrCommandCancelled
+		}
+		sm.CurrentSessionID = sessionID
 	}
+}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		sm.emitEvent(EventError, fmt.Errorf("failed to create stderr pipe: %w", err))
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+// runCommand runs a single claude invocation. A non-empty model overrides the
+// session model for this invocation only.
+func (sm *SessionManager) runCommand(ctx context.Context, prompt string, resume bool, model string) error {
+	if model == "" {
+		model = sm.Model
+	}
+	if model == "" {
+		model = "claude-sonnet-4-20250514"
+	}
+	allowlist, _ := sm.Allowlist()
+	opts := claudecli.Options{
+		Model:           model,
+		PermissionTool:  sm.PermissionTool,
+		MCPConfig:       sm.MCPConfigPath,
+		SystemPrompt:    sm.SystemPrompt().Text,
+		ReadOnly:        sm.ReadOnly,
+		AllowedTools:    allowlist.Allowed,
+		DisallowedTools: allowlist.Disallowed,
+		PermissionMode:  allowlist.PermissionMode,
+		PartialMessages: true,
+		Env:             sm.Env(),
+		Dir:             sm.dir,
+	}
+	sessionID := ""
+	if resume {
+		sessionID = sm.CurrentSessionID
 	}
 
-	if err := cmd.Start(); err != nil {
-		sm.emitEvent(EventError, fmt.Errorf("failed to start command: %w", err))
-		return fmt.Errorf("failed to start command: %w", err)
+	sm.resetFailure()
+	sm.pendingThinking = ""
+	sm.latency.Begin(time.Now())
+	sm.log.Debug("starting claude", "model", model, "resume", resume, "session_id", sm.CurrentSessionID)
+	run, err := sm.claudeRunner().Start(ctx, opts, prompt, sessionID)
+	if err != nil {
+		sm.log.Error("failed to start claude", "err", err)
+		sm.emitEvent(EventError, err)
+		return err
+	}
+	sm.debug.noteInvocation(opts.Args(prompt, sessionID), opts.Env, sm.Dir())
+	if pid := run.PID(); pid != 0 {
+		if err := procs.Track(pid, sm.CurrentSessionID); err != nil {
+			// Only orphan detection is lost
+			sm.log.Warn("failed to track claude process", "pid", pid, "err", err)
+		}
+		defer procs.Untrack(pid)
 	}
 
-	// Handle stderr in background
+	// Handle stderr in background; the lines go to the stderr pane rather
+	// than the error list
 	go func() {
-		scanner := bufio.NewScanner(stderr)
+		scanner := bufio.NewScanner(run.Stderr())
 		for scanner.Scan() {
-			sm.emitEvent(EventError, fmt.Errorf("stderr: %s", scanner.Text()))
+			line := claudecli.NewStderrLine(scanner.Text())
+			sm.noteFailure(line.Text)
+			if line.Severity == claudecli.SeverityInfo {
+				sm.log.Debug("claude stderr", "line", line.Text)
+			} else {
+				sm.log.Warn("claude stderr", "line", line.Text, "severity", line.Severity)
+			}
+			sm.emitEvent(EventStderr, line)
 		}
 	}()
 
-	if err := sm.ProcessStream(stdout); err != nil {
+	if err := sm.ProcessStream(run.Stdout()); err != nil && ctx.Err() == nil {
 		sm.emitEvent(EventError, fmt.Errorf("failed to process stream: %w", err))
 		return fmt.Errorf("failed to process stream: %w", err)
 	}
 
-	if err := cmd.Wait(); err != nil {
+	if err := run.Wait(); err != nil {
+		if ctx.Err() != nil {
+			sm.log.Info("command cancelled", "cause", ctx.Err())
+			return ErrCommandCancelled
+		}
+		sm.log.Error("claude exited with an error", "err", err)
 		sm.emitEvent(EventError, fmt.Errorf("command failed: %w", err))
 		return fmt.Errorf("command failed: %w", err)
 	}
@@ -125,115 +416,215 @@ func (sm *SessionManager) ExecuteCommand(ctx context.Context, prompt string, res
 	return nil
 }
 
+// emitRawLine publishes a stream line as read, tagged with its message type
+func (sm *SessionManager) emitRawLine(line string) {
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(line), &typed); err != nil {
+		typed.Type = "invalid"
+	}
+	sm.lineSeq++
+	sm.emitEvent(EventRawLine, RawLine{
+		Seq:      sm.lineSeq,
+		Type:     typed.Type,
+		Line:     line,
+		Received: time.Now(),
+	})
+}
+
 // ProcessStream processes the JSON stream from Claude CLI with event emission
 func (sm *SessionManager) ProcessStream(reader io.Reader) error {
-	scanner := bufio.NewScanner(reader)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
-			continue
+	defer func() { sm.lineTime = time.Time{} }()
+
+	err := claudecli.ProcessStream(reader, sm.MaxLineBytes, func(line string, event claudecli.Event, err error) {
+		var tooLong *LineTooLongError
+		if errors.As(err, &tooLong) {
+			sm.log.Warn("skipped oversized stream line", "size", tooLong.Size, "limit", tooLong.Limit)
+			sm.emitEvent(EventError, tooLong)
+			return
 		}
 
-		// Parse the JSON line directly without our Message wrapper
-		sm.processJSONLine(line)
-	}
+		// Stamp events with the line's generation time, or its receipt time
+		sm.lineTime = claudecli.StreamTimestamp(line, time.Now())
+		sm.debug.noteLine(line)
+		sm.emitRawLine(line)
 
-	if err := scanner.Err(); err != nil {
-		sm.emitEvent(EventError, fmt.Errorf("scanner error: %w", err))
-		return fmt.Errorf("scanner error: %w", err)
+		if err != nil {
+			sm.log.Warn("unparseable stream line", "bytes", len(line), "err", err)
+			sm.emitStreamEvent(EventError, fmt.Errorf("parse error: %w", err))
+			return
+		}
+		sm.processEvent(line, event)
+	})
+	if err != nil {
+		sm.emitEvent(EventError, err)
 	}
-
-	return nil
+	return err
 }
 
-// processJSONLine processes a raw JSON line from Claude CLI
-func (sm *SessionManager) processJSONLine(line string) {
-	// First, determine the message type
-	var msgType struct {
-		Type    string `json:"type"`
-		Subtype string `json:"subtype,omitempty"`
-	}
-
-	if err := json.Unmarshal([]byte(line), &msgType); err != nil {
-		sm.emitEvent(EventError, fmt.Errorf("parse error: %s", line))
-		return
-	}
-
-	switch msgType.Type {
-	case "system":
-		if msgType.Subtype == "init" {
-			var init SystemInit
-			if err := json.Unmarshal([]byte(line), &init); err == nil {
-				sm.CurrentSessionID = init.SessionID
-				sm.Model = init.Model
-				sm.emitEvent(EventSessionInit, init)
-			}
+// processEvent handles a decoded stream line; line is kept for the events
+// that are still read from the raw JSON
+func (sm *SessionManager) processEvent(line string, event claudecli.Event) {
+	switch e := event.(type) {
+	case *claudecli.InitEvent:
+		init := e.Init
+		sm.CurrentSessionID = init.SessionID
+		sm.turnModel = init.Model
+		if sm.modelOverride == "" {
+			sm.Model = init.Model
 		}
-
-	case "assistant":
-		// Use the exact same parsing as the original simple CLI
-		var assistantData struct {
-			Message AssistantMessage `json:"message"`
+		sm.log.Info("session started", "session_id", init.SessionID, "model", init.Model, "tools", len(init.Tools))
+		sm.context.noteInit(init)
+		sm.lastInit = init
+		sm.emitStreamEvent(EventSessionInit, init)
+
+	case *claudecli.AssistantEvent:
+		sm.processAssistantMessage(e.Message)
+
+	case *claudecli.PartialEvent:
+		sm.processStreamEvent(line)
+
+	case *claudecli.UserEvent:
+		// Tool results - emit tool activity event. Tool runs are tracked from
+		// the raw items, so re-read the content generically.
+		var userData struct {
+			Message struct {
+				Content json.RawMessage `json:"content"`
+			} `json:"message"`
 		}
-		if err := json.Unmarshal([]byte(line), &assistantData); err == nil {
-			sm.processAssistantMessage(assistantData.Message)
-		} else {
-			sm.emitEvent(EventError, fmt.Errorf("failed to parse assistant message: %w", err))
+		if err := json.Unmarshal([]byte(line), &userData); err == nil {
+			var content []map[string]interface{}
+			if err := json.Unmarshal(userData.Message.Content, &content); err == nil {
+				for _, item := range content {
+					if id, ok := item["tool_use_id"].(string); ok {
+						sm.latency.ToolFinished(id, sm.eventTime())
+					}
+					if item["type"] == "tool_result" {
+						sm.context.noteToolResult(item["content"])
+						run := sm.toolRuns.completed(item)
+						status := "completed"
+						if run.IsError {
+							status = "failed"
+						}
+						sm.recordToolResult(run.ToolUseID, run.Output, status)
+						sm.emitStreamEvent(EventToolActivity, ToolActivity{
+							ToolUseID: run.ToolUseID,
+							ToolName:  run.ToolName,
+							Status:    status,
+							Output:    run.Output,
+						})
+					}
+				}
+			}
 		}
 
-	case "user":
-		// Tool results - emit tool activity event
-		sm.emitEvent(EventToolActivity, "tool_execution_progress")
-
-	case "result":
-		var result Message
-		if err := json.Unmarshal([]byte(line), &result); err == nil {
-			if result.Subtype == "success" {
-				sm.updateSessionStats(result)
-				sm.emitEvent(EventSessionUpdate, sm.getCurrentSessionInfo())
-				sm.emitEvent(EventStatsUpdate, sm.getSessionStats())
-			} else if result.IsError {
-				sm.emitEvent(EventError, fmt.Errorf("result error: %s", result.Result))
-			}
+	case *claudecli.ResultEvent:
+		result := e.Result
+		if result.Subtype == "success" {
+			sm.updateSessionStats(result)
+			sm.persist()
+			sm.emitStreamEvent(EventSessionUpdate, sm.getCurrentSessionInfo())
+			sm.emitStreamEvent(EventStatsUpdate, sm.getSessionStats())
+		} else if result.IsError {
+			sm.log.Warn("result error", "subtype", result.Subtype, "result", result.Result)
+			sm.noteFailure(result.Result)
+			sm.emitStreamEvent(EventError, fmt.Errorf("result error: %s", result.Result))
 		}
+		sm.lastActivity = sm.eventTime()
+		sm.log.Info("turn complete", "session_id", result.SessionID, "cost_usd", result.TotalCostUSD,
+			"turns", result.NumTurns, "duration_ms", result.DurationMs, "is_error", result.IsError)
+		sm.lastResult = result.Result
+		turn := newTurnResult(result)
+		turn.Model = sm.turnModel
+		turn.Latency = sm.latency.Finish(sm.eventTime())
+		turn.ToolRuns = append([]ToolRun(nil), sm.toolRuns.finished...)
+		sm.lastTurn = turn
+		sm.emitStreamEvent(EventTurnComplete, turn)
 	}
 }
 
 // processAssistantMessage processes assistant messages and emits conversation events
 func (sm *SessionManager) processAssistantMessage(assistantMsg AssistantMessage) {
 	var content []map[string]interface{}
+	sm.context.notePrompt(assistantMsg.Usage)
+	sm.partial.note(assistantMsg.ID, assistantMsg.Usage)
 	if err := json.Unmarshal(assistantMsg.Content, &content); err == nil {
+		sm.latency.MarkOutput(sm.eventTime())
 		for _, item := range content {
 			if item["type"] == "text" {
 				if text, ok := item["text"].(string); ok {
 					convMsg := ConversationMessage{
-						ID:        assistantMsg.ID,
+						ID:        sm.claimPartialID(assistantMsg.ID),
 						Type:      "assistant",
 						Content:   text,
-						Timestamp: time.Now(),
+						Timestamp: sm.eventTime(),
 						IsError:   false,
+						Model:     assistantMsg.Model,
+						SessionID: sm.CurrentSessionID,
+						Thinking:  sm.pendingThinking,
 					}
-					sm.emitEvent(EventMessageReceived, convMsg)
+					sm.pendingThinking = ""
+					sm.recordMessage(convMsg)
+					sm.emitStreamEvent(EventMessageReceived, convMsg)
+				}
+			} else if item["type"] == "thinking" {
+				// Kept for the text block that follows it
+				if thinking, ok := item["thinking"].(string); ok {
+					sm.pendingThinking += thinking
 				}
 			} else if item["type"] == "tool_use" {
+				if id, ok := item["id"].(string); ok {
+					sm.latency.ToolStarted(id, sm.eventTime())
+				}
 				if toolName, ok := item["name"].(string); ok {
-					sm.emitEvent(EventToolActivity, fmt.Sprintf("executing_tool_%s", toolName))
-					convMsg := ConversationMessage{
-						ID:        assistantMsg.ID,
-						Type:      "tool_use",
-						Content:   fmt.Sprintf("Using tool: %s", toolName),
-						Timestamp: time.Now(),
-						IsError:   false,
+					toolUseID, _ := item["id"].(string)
+					sm.toolRuns.started(toolUseID, toolName, item["input"])
+					sm.emitStreamEvent(EventToolActivity, ToolActivity{
+						ToolUseID: toolUseID,
 						ToolName:  toolName,
+						Status:    "running",
+					})
+					toolInput, _ := json.Marshal(item["input"])
+					convMsg := ConversationMessage{
+						ID:         assistantMsg.ID,
+						Type:       "tool_use",
+						Content:    fmt.Sprintf("Using tool: %s", toolName),
+						Timestamp:  sm.eventTime(),
+						IsError:    false,
+						ToolName:   toolName,
+						ToolUseID:  toolUseID,
+						ToolInput:  toolInput,
+						ToolStatus: "running",
+						SessionID:  sm.CurrentSessionID,
 					}
-					sm.emitEvent(EventMessageReceived, convMsg)
+					if input, ok := item["input"].(map[string]interface{}); ok {
+						convMsg.Diff = toolDiff(toolName, input)
+					}
+					sm.recordMessage(convMsg)
+					sm.emitStreamEvent(EventMessageReceived, convMsg)
 				}
 			}
 		}
 	}
 }
 
+// newTurnResult converts a result message into a TurnResult
+func newTurnResult(msg Message) TurnResult {
+	turn := TurnResult{
+		SessionID:  msg.SessionID,
+		IsError:    msg.IsError || msg.Subtype != "success",
+		Result:     msg.Result,
+		DurationMs: msg.DurationMs,
+		NumTurns:   msg.NumTurns,
+		CostUSD:    msg.TotalCostUSD,
+	}
+	if msg.Usage != nil {
+		turn.Usage = *msg.Usage
+	}
+	return turn
+}
+
 // updateSessionStats updates session statistics
 func (sm *SessionManager) updateSessionStats(msg Message) {
 	// Update current session ID - this is critical for session continuity
@@ -241,11 +632,19 @@ func (sm *SessionManager) updateSessionStats(msg Message) {
 
 	// Add to session chain (matching original simple CLI behavior)
 	sm.SessionChain = append(sm.SessionChain, msg.SessionID)
+	sm.noteChainLink(msg)
+
+	// Follow the conversation to its latest session
+	if err := sm.locks.Acquire(msg.SessionID, false); err != nil {
+		sm.emitEvent(EventError, err)
+	}
 
 	// Update cumulative statistics
 	sm.CumulativeDuration += msg.DurationMs
 	sm.CumulativeTurns += msg.NumTurns
 	sm.CumulativeCost += msg.TotalCostUSD
+	sm.noteSpend(msg.TotalCostUSD)
+	sm.noteUsage(msg)
 
 	if msg.Usage != nil {
 		sm.CumulativeUsage.InputTokens += msg.Usage.InputTokens
@@ -277,22 +676,48 @@ func (sm *SessionManager) getSessionStats() SessionStats {
 		CumulativeCost:     sm.CumulativeCost,
 		CumulativeUsage:    sm.CumulativeUsage,
 		ConversationStart:  sm.ConversationStart,
+		Interruptions:      sm.Interruptions,
+		DailyCost:          sm.dailyCost,
 	}
 }
 
+// LastInit returns the init message of the most recent invocation, which
+// lists the available tools and the MCP servers claude connected to
+func (sm *SessionManager) LastInit() SystemInit {
+	return sm.lastInit
+}
+
+// LastActivity returns when the conversation last received a result, or the
+// zero time if it never did
+func (sm *SessionManager) LastActivity() time.Time {
+	return sm.lastActivity
+}
+
 // StartNewConversation resets the session manager for a new conversation
 func (sm *SessionManager) StartNewConversation() {
 	if len(sm.SessionChain) > 0 {
 		sm.emitEvent(EventSessionUpdate, "conversation_ended")
 	}
 
+	sm.ReleaseSessionLock()
 	sm.CurrentSessionID = ""
 	sm.SessionChain = nil
+	sm.chainLinks = nil
 	sm.CumulativeDuration = 0
 	sm.CumulativeTurns = 0
 	sm.CumulativeCost = 0
 	sm.CumulativeUsage = Usage{}
 	sm.ConversationStart = time.Now()
+	sm.conversationID = newConversationID()
+	sm.title = ""
+	sm.tags = nil
+	sm.compactedFrom, sm.compactedInto = "", ""
+	sm.transcript = nil
+	sm.context.reset()
+	sm.diff.reset()
+	sm.debug.reset()
+	sm.lastActivity = time.Time{}
+	sm.Interruptions = 0
 
 	sm.emitEvent(EventSessionInit, "new_conversation_started")
 }
@@ -0,0 +1,274 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"customclaude/pkg/claudecli"
+)
+
+// fakeTurn is what one invocation of a fakeRunner does
+type fakeTurn struct {
+	stdout string
+	// waitErr is returned by Wait once stdout is read
+	waitErr error
+	// hang keeps the invocation running until it is cancelled
+	hang bool
+}
+
+// fakeRunner answers invocations with scripted turns, in order, and records
+// how it was started
+type fakeRunner struct {
+	mutex    sync.Mutex
+	turns    []fakeTurn
+	models   []string
+	sessions []string
+}
+
+func (r *fakeRunner) Start(ctx context.Context, opts claudecli.Options, _, sessionID string) (claudecli.Process, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	n := len(r.models)
+	if n >= len(r.turns) {
+		return nil, fmt.Errorf("unexpected invocation %d", n+1)
+	}
+	r.models = append(r.models, opts.Model)
+	r.sessions = append(r.sessions, sessionID)
+	return &fakeProcess{ctx: ctx, turn: r.turns[n]}, nil
+}
+
+func (r *fakeRunner) calls() ([]string, []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.models...), append([]string(nil), r.sessions...)
+}
+
+type fakeProcess struct {
+	ctx  context.Context
+	turn fakeTurn
+}
+
+func (p *fakeProcess) Stdout() io.Reader {
+	if p.turn.hang {
+		return &blockingReader{ctx: p.ctx}
+	}
+	return strings.NewReader(p.turn.stdout)
+}
+
+func (p *fakeProcess) Stderr() io.Reader { return strings.NewReader("") }
+func (p *fakeProcess) PID() int          { return 0 }
+func (p *fakeProcess) Kill() error       { return nil }
+
+func (p *fakeProcess) Wait() error {
+	if p.turn.hang {
+		<-p.ctx.Done()
+		return p.ctx.Err()
+	}
+	return p.turn.waitErr
+}
+
+// blockingReader reads nothing until its context ends, like the stdout of a
+// claude that is still thinking
+type blockingReader struct {
+	ctx context.Context
+}
+
+func (r *blockingReader) Read([]byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, io.EOF
+}
+
+// eventFunc adapts a function to an EventHandler
+type eventFunc func(Event)
+
+func (f eventFunc) HandleEvent(event Event) { f(event) }
+
+// fakeStream returns the stream-json of a turn on model ending in result
+func fakeStream(sessionID, model, result string, isError bool) string {
+	subtype := "success"
+	if isError {
+		subtype = "error_during_execution"
+	}
+	return strings.Join([]string{
+		fmt.Sprintf(`{"type":"system","subtype":"init","session_id":%q,"model":%q,"tools":[]}`, sessionID, model),
+		fmt.Sprintf(`{"type":"assistant","message":{"id":"msg_%s","type":"message","role":"assistant","model":%q,"content":[{"type":"text","text":%q}]}}`, sessionID, model, result),
+		fmt.Sprintf(`{"type":"result","subtype":%q,"is_error":%v,"num_turns":1,"result":%q,"session_id":%q,"total_cost_usd":0.01}`, subtype, isError, result, sessionID),
+	}, "\n") + "\n"
+}
+
+// newTestSession returns a session answered by runner, retrying without
+// waiting, whose session locks are kept in a temporary directory
+func newTestSession(t *testing.T, runner *fakeRunner) *SessionManager {
+	t.Setenv("CC_CUSTOM_CONFIG_DIR", t.TempDir())
+	sm := NewSessionManager()
+	t.Cleanup(sm.ReleaseSessionLock)
+	sm.SetRunner(runner)
+	sm.SetModel("model-a")
+	sm.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	return sm
+}
+
+func TestExecuteCommandRetries(t *testing.T) {
+	runner := &fakeRunner{turns: []fakeTurn{
+		{waitErr: errors.New("exit status 1")},
+		{stdout: fakeStream("session-1", "model-a", "done", false)},
+	}}
+	sm := newTestSession(t, runner)
+	sm.CurrentSessionID = "session-0"
+
+	if err := sm.ExecuteCommandWithModel(context.Background(), "hello", true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	models, sessions := runner.calls()
+	if len(models) != 2 {
+		t.Fatalf("%d invocations, want 2", len(models))
+	}
+	// The retry resumes the session the command started from
+	if sessions[0] != "session-0" || sessions[1] != "session-0" {
+		t.Errorf("resumed sessions %v, want session-0 twice", sessions)
+	}
+	if sm.CurrentSessionID != "session-1" || sm.lastResult != "done" {
+		t.Errorf("session %q result %q, want session-1 and done", sm.CurrentSessionID, sm.lastResult)
+	}
+	if sm.Interruptions != 0 {
+		t.Errorf("%d interruptions recorded for a turn that succeeded", sm.Interruptions)
+	}
+}
+
+func TestExecuteCommandGivesUpAfterMaxAttempts(t *testing.T) {
+	failed := fakeTurn{waitErr: errors.New("exit status 1")}
+	runner := &fakeRunner{turns: []fakeTurn{failed, failed, failed}}
+	sm := newTestSession(t, runner)
+
+	err := sm.ExecuteCommandWithModel(context.Background(), "hello", false, "")
+	if err == nil {
+		t.Fatal("command succeeded")
+	}
+	if models, _ := runner.calls(); len(models) != 3 {
+		t.Errorf("%d invocations, want 3", len(models))
+	}
+	last := sm.transcript[len(sm.transcript)-1]
+	if last.Interruption == nil || last.Interruption.Reason != InterruptCircuitBroken || last.Interruption.Attempts != 3 {
+		t.Errorf("last message %+v, want a circuit broken interruption after 3 attempts", last)
+	}
+}
+
+func TestExecuteCommandFallsBackOnOverload(t *testing.T) {
+	runner := &fakeRunner{turns: []fakeTurn{
+		{stdout: fakeStream("session-1", "model-a", "API Error: model is overloaded", true)},
+		{stdout: fakeStream("session-2", "model-b", "done", false)},
+	}}
+	sm := newTestSession(t, runner)
+	sm.SetFallbackModel("model-b")
+
+	if err := sm.ExecuteCommandWithModel(context.Background(), "hello", false, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	models, _ := runner.calls()
+	if strings.Join(models, ",") != "model-a,model-b" {
+		t.Errorf("models %v, want model-a then model-b", models)
+	}
+	var notice bool
+	for _, msg := range sm.transcript {
+		if msg.Type == "system" && strings.Contains(msg.Content, "fallback model model-b") {
+			notice = true
+		}
+	}
+	if !notice {
+		t.Error("fallback not announced in the transcript")
+	}
+	if sm.CurrentSessionID != "session-2" || sm.lastResult != "done" {
+		t.Errorf("session %q result %q, want session-2 and done", sm.CurrentSessionID, sm.lastResult)
+	}
+}
+
+func TestExecuteCommandTimesOut(t *testing.T) {
+	runner := &fakeRunner{turns: []fakeTurn{{hang: true}}}
+	sm := newTestSession(t, runner)
+	sm.SetTurnTimeout(20 * time.Millisecond)
+
+	started := time.Now()
+	err := sm.ExecuteCommandWithModel(context.Background(), "hello", false, "")
+	if !errors.Is(err, ErrCommandCancelled) {
+		t.Fatalf("err = %v, want ErrCommandCancelled", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("timed out after %v", elapsed)
+	}
+	if models, _ := runner.calls(); len(models) != 1 {
+		t.Errorf("%d invocations, want no retry after the timeout", len(models))
+	}
+	last := sm.transcript[len(sm.transcript)-1]
+	if last.Interruption == nil || last.Interruption.Reason != InterruptTimedOut {
+		t.Errorf("last message %+v, want a timed out interruption", last)
+	}
+}
+
+func TestProcessStreamTurnComplete(t *testing.T) {
+	t.Setenv("CC_CUSTOM_CONFIG_DIR", t.TempDir())
+	sm := NewSessionManager()
+	t.Cleanup(sm.ReleaseSessionLock)
+	var mutex sync.Mutex
+	var turns []TurnResult
+	sm.AddEventHandler(eventFunc(func(event Event) {
+		if turn, ok := event.Data.(TurnResult); ok && event.Type == EventTurnComplete {
+			mutex.Lock()
+			turns = append(turns, turn)
+			mutex.Unlock()
+		}
+	}))
+
+	err := sm.ProcessStream(strings.NewReader(fakeStream("session-1", "model-a", "done", false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sm.CurrentSessionID != "session-1" || sm.Model != "model-a" {
+		t.Errorf("session %q model %q, want session-1 and model-a", sm.CurrentSessionID, sm.Model)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mutex.Lock()
+		n := len(turns)
+		mutex.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(turns) != 1 || turns[0].Model != "model-a" {
+		t.Errorf("turn results %+v, want one on model-a", turns)
+	}
+}
+
+func TestRunTurnReturnsResult(t *testing.T) {
+	runner := &fakeRunner{turns: []fakeTurn{
+		{stdout: fakeStream("session-1", "model-a", "first", false)},
+		{waitErr: errors.New("exit status 1")},
+	}}
+	sm := newTestSession(t, runner)
+	sm.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+
+	turn, err := sm.RunTurn(context.Background(), "hello", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if turn.Result != "first" || turn.CostUSD != 0.01 {
+		t.Errorf("turn %+v, want result first costing 0.01", turn)
+	}
+
+	// A failed turn does not return the previous one
+	if turn, err := sm.RunTurn(context.Background(), "again", true, ""); err == nil || turn.Result != "" {
+		t.Errorf("turn %+v, err %v, want an error and no result", turn, err)
+	}
+}
@@ -0,0 +1,78 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultSplitThreshold is the prompt size, in estimated tokens, above which
+// splitting is offered
+const DefaultSplitThreshold = 50000
+
+// EstimatePromptTokens approximates the token count of a prompt
+func EstimatePromptTokens(prompt string) int {
+	return CountTokens(prompt)
+}
+
+// SplitPrompt breaks an oversized prompt into turns of at most maxTokens
+// each. Every part asks Claude to wait for the rest, and a final turn asks it
+// to answer using all parts.
+func SplitPrompt(prompt string, maxTokens int) []string {
+	chunks := splitChunks(prompt, maxTokens*4)
+	if len(chunks) < 2 {
+		return []string{prompt}
+	}
+
+	turns := make([]string, 0, len(chunks)+1)
+	for i, chunk := range chunks {
+		turns = append(turns, fmt.Sprintf(
+			"This is part %d of %d of a long input. Do not respond to it yet; reply only with \"OK\".\n\n%s",
+			i+1, len(chunks), chunk))
+	}
+	turns = append(turns, fmt.Sprintf(
+		"All %d parts have been sent. Now answer the request they contain, using the full input.",
+		len(chunks)))
+	return turns
+}
+
+// splitChunks splits text into pieces of at most maxChars bytes, preferring
+// line boundaries and never splitting a UTF-8 sequence
+func splitChunks(text string, maxChars int) []string {
+	if maxChars <= 0 || len(text) <= maxChars {
+		return []string{text}
+	}
+
+	var chunks []string
+	for len(text) > maxChars {
+		cut := strings.LastIndexByte(text[:maxChars], '\n') + 1
+		if cut <= 0 {
+			cut = maxChars
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// CompactCommand asks claude to summarize a resumed session to shrink its
+// context; send it through ExecuteBatch ahead of the next prompt
+const CompactCommand = "/compact"
+
+// ExecuteBatch runs prompts as consecutive turns of one conversation,
+// stopping at the first failure
+func (sm *SessionManager) ExecuteBatch(ctx context.Context, prompts []string, resume bool, model string) error {
+	for i, prompt := range prompts {
+		if err := sm.ExecuteCommandWithModel(ctx, prompt, resume || i > 0, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"customclaude/pkg/platform"
+)
+
+// ErrSessionNotFound is returned by a SessionStore when a record does not exist
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRecord is the persisted form of a conversation
+type SessionRecord struct {
+	ID               string                `json:"id"`
+	Title            string                `json:"title"`
+	Model            string                `json:"model"`
+	CurrentSessionID string                `json:"current_session_id"`
+	SessionChain     []string              `json:"session_chain"`
+	ChainLinks       []ChainLink           `json:"chain_links,omitempty"`
+	Stats            SessionStats          `json:"stats"`
+	Messages         []ConversationMessage `json:"messages,omitempty"`
+	Tags             []string              `json:"tags,omitempty"`
+	Archived         bool                  `json:"archived,omitempty"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+
+	// CompactedFrom and CompactedInto link a conversation started by
+	// /compact with the one it summarized
+	CompactedFrom string `json:"compacted_from,omitempty"`
+	CompactedInto string `json:"compacted_into,omitempty"`
+}
+
+// SessionStore persists conversation records
+type SessionStore interface {
+	// Save creates or replaces a record
+	Save(record SessionRecord) error
+	// Load returns the record with the given ID or ErrSessionNotFound
+	Load(id string) (SessionRecord, error)
+	// List returns all records, most recently updated first
+	List() ([]SessionRecord, error)
+	// Delete removes a record; deleting a missing record is not an error
+	Delete(id string) error
+}
+
+// Store backend names accepted in StoreConfig.Backend
+const (
+	StoreBackendJSON   = "json"
+	StoreBackendSQLite = "sqlite"
+	StoreBackendS3     = "s3"
+)
+
+// StoreConfig selects and configures a SessionStore backend:
+//
+//   - json, the default, keeps one file per conversation in Path, or the
+//     sessions directory of the config directory
+//   - sqlite keeps them in the database at Path, or sessions.db in the config
+//     directory. It runs the sqlite3 command-line shell, which must be on
+//     PATH.
+//   - s3 keeps them in the S3-compatible bucket configured by S3
+//
+// The TUI falls back to json when the configured backend cannot be opened.
+type StoreConfig struct {
+	Backend string   `json:"backend" toml:"backend"`
+	Path    string   `json:"path,omitempty" toml:"path"`
+	S3      S3Config `json:"s3,omitempty" toml:"s3"`
+}
+
+// NewSessionStore creates the store selected by cfg. An empty backend
+// defaults to JSON files in the user config directory. A backend that cannot
+// work fails here rather than on every save, sqlite when the sqlite3 command
+// is missing among others.
+func NewSessionStore(cfg StoreConfig) (SessionStore, error) {
+	switch cfg.Backend {
+	case "", StoreBackendJSON:
+		path := cfg.Path
+		if path == "" {
+			dir, err := platform.ConfigDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(dir, "sessions")
+		}
+		return NewJSONStore(path)
+	case StoreBackendSQLite:
+		path := cfg.Path
+		if path == "" {
+			dir, err := platform.ConfigDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(dir, "sessions.db")
+		}
+		return NewSQLiteStore(path)
+	case StoreBackendS3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown session store backend: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,132 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SQLiteStore persists records in a SQLite database through the sqlite3
+// command-line shell, keeping the binary free of a cgo driver
+type SQLiteStore struct {
+	path    string
+	sqlite3 string // the shell, resolved once on PATH
+}
+
+// NewSQLiteStore opens (and if needed creates) the database at path. It fails
+// when the sqlite3 command is not on PATH.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	sqlite3, err := exec.LookPath("sqlite3")
+	if err != nil {
+		return nil, fmt.Errorf("the sqlite storage backend needs the sqlite3 command on PATH; install SQLite or set [storage] backend = %q: %w", StoreBackendJSON, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	store := &SQLiteStore{path: path, sqlite3: sqlite3}
+	_, err = store.exec(`CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		updated_at TEXT NOT NULL,
+		data TEXT NOT NULL
+	);`)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// exec runs SQL through the sqlite3 shell and returns its JSON output
+func (s *SQLiteStore) exec(sql string) ([]byte, error) {
+	cmd := exec.Command(s.sqlite3, "-json", s.path)
+	cmd.Stdin = strings.NewReader(sql)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("sqlite3 failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// query runs a SELECT returning the data column of each row
+func (s *SQLiteStore) query(sql string) ([]SessionRecord, error) {
+	out, err := s.exec(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(out, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode sqlite output: %w", err)
+	}
+
+	records := make([]SessionRecord, 0, len(rows))
+	for _, row := range rows {
+		var record SessionRecord
+		if err := json.Unmarshal([]byte(row.Data), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// quoteSQL renders s as a SQL string literal
+func quoteSQL(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Save upserts the record
+func (s *SQLiteStore) Save(record SessionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	_, err = s.exec(fmt.Sprintf(
+		"INSERT OR REPLACE INTO sessions (id, updated_at, data) VALUES (%s, %s, %s);",
+		quoteSQL(record.ID),
+		quoteSQL(record.UpdatedAt.UTC().Format(time.RFC3339Nano)),
+		quoteSQL(string(data)),
+	))
+	return err
+}
+
+// Load fetches a single record
+func (s *SQLiteStore) Load(id string) (SessionRecord, error) {
+	records, err := s.query(fmt.Sprintf("SELECT data FROM sessions WHERE id = %s;", quoteSQL(id)))
+	if err != nil {
+		return SessionRecord{}, err
+	}
+	if len(records) == 0 {
+		return SessionRecord{}, ErrSessionNotFound
+	}
+	return records[0], nil
+}
+
+// List fetches every record, most recently updated first
+func (s *SQLiteStore) List() ([]SessionRecord, error) {
+	records, err := s.query("SELECT data FROM sessions ORDER BY updated_at DESC;")
+	if err != nil {
+		return nil, err
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+// Delete removes a record
+func (s *SQLiteStore) Delete(id string) error {
+	_, err := s.exec(fmt.Sprintf("DELETE FROM sessions WHERE id = %s;", quoteSQL(id)))
+	return err
+}
@@ -0,0 +1,47 @@
+package claude
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewSessionStoreDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CC_CUSTOM_CONFIG_DIR", dir)
+
+	store, err := NewSessionStore(StoreConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(*JSONStore); !ok {
+		t.Fatalf("store is %T, want *JSONStore", store)
+	}
+	if err := store.Save(SessionRecord{ID: "conv-1", Title: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if record, err := store.Load("conv-1"); err != nil || record.Title != "hello" {
+		t.Errorf("Load = %+v, %v, want the saved record", record, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sessions")); err != nil {
+		t.Errorf("sessions directory not in the config directory: %v", err)
+	}
+	if _, err := store.Load("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestNewSessionStoreSQLiteWithoutShell(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	path := filepath.Join(t.TempDir(), "db", "sessions.db")
+
+	_, err := NewSessionStore(StoreConfig{Backend: StoreBackendSQLite, Path: path})
+	if err == nil || !strings.Contains(err.Error(), "sqlite3 command") {
+		t.Fatalf("err = %v, want the missing sqlite3 command reported", err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("database directory created without sqlite3: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PartialMessage carries the text streamed so far for an assistant content
+// block that has not been completed yet. It is sent as EventMessageDelta,
+// apart from completed messages: each delta holds the whole text so far, so
+// a listener falling behind may drop deltas but never a completed message.
+type PartialMessage struct {
+	Message ConversationMessage
+}
+
+// streamState accumulates text deltas from --include-partial-messages
+type streamState struct {
+	messageID string
+	blocks    map[int]*strings.Builder
+	// pending holds partial message IDs, per API message, in block order so
+	// that completed text blocks can take over the ID of their partial
+	pending map[string][]string
+}
+
+// partialID returns the conversation message ID used for a streamed block
+func partialID(messageID string, index int) string {
+	return fmt.Sprintf("%s_%d", messageID, index)
+}
+
+// processStreamEvent handles a raw API streaming event
+func (sm *SessionManager) processStreamEvent(line string) {
+	var data struct {
+		Event struct {
+			Type    string `json:"type"`
+			Index   int    `json:"index"`
+			Message struct {
+				ID string `json:"id"`
+			} `json:"message"`
+			ContentBlock struct {
+				Type string `json:"type"`
+			} `json:"content_block"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal([]byte(line), &data); err != nil {
+		return
+	}
+
+	st := &sm.stream
+	if st.pending == nil {
+		st.pending = make(map[string][]string)
+	}
+
+	switch data.Event.Type {
+	case "message_start":
+		st.messageID = data.Event.Message.ID
+		st.blocks = make(map[int]*strings.Builder)
+
+	case "content_block_start":
+		if data.Event.ContentBlock.Type == "text" && st.blocks != nil {
+			st.blocks[data.Event.Index] = &strings.Builder{}
+			id := partialID(st.messageID, data.Event.Index)
+			st.pending[st.messageID] = append(st.pending[st.messageID], id)
+			sm.mirror.blockStart()
+		}
+
+	case "content_block_delta":
+		if data.Event.Delta.Type != "text_delta" {
+			return
+		}
+		block, ok := st.blocks[data.Event.Index]
+		if !ok {
+			return
+		}
+		sm.latency.MarkOutput(sm.eventTime())
+		block.WriteString(data.Event.Delta.Text)
+		sm.mirror.write(data.Event.Delta.Text)
+		sm.emitStreamEvent(EventMessageDelta, PartialMessage{
+			Message: ConversationMessage{
+				ID:        partialID(st.messageID, data.Event.Index),
+				Type:      "assistant",
+				Content:   block.String(),
+				Timestamp: sm.eventTime(),
+				Model:     sm.turnModel,
+			},
+		})
+
+	case "message_stop":
+		st.blocks = nil
+	}
+}
+
+// claimPartialID returns the ID of the oldest streamed text block of an API
+// message, so the completed text replaces it in the UI. It returns the
+// message ID unchanged when nothing was streamed.
+func (sm *SessionManager) claimPartialID(messageID string) string {
+	pending := sm.stream.pending[messageID]
+	if len(pending) == 0 {
+		return messageID
+	}
+	id := pending[0]
+	if len(pending) == 1 {
+		delete(sm.stream.pending, messageID)
+	} else {
+		sm.stream.pending[messageID] = pending[1:]
+	}
+	return id
+}
@@ -0,0 +1,49 @@
+package claude
+
+import (
+	"strings"
+
+	"customclaude/pkg/claudecli"
+)
+
+// systemPromptState holds a system prompt set during the session, which
+// replaces the one detected from the project files, and the active profile's
+type systemPromptState struct {
+	override *string
+	profile  string
+}
+
+// SystemPrompt returns the active system prompt. Project files are re-read
+// on every call so edits apply to the next prompt.
+func (sm *SessionManager) SystemPrompt() SystemPrompt {
+	if sm.systemPrompt.override != nil {
+		return SystemPrompt{Text: *sm.systemPrompt.override}
+	}
+	if sm.systemPrompt.profile != "" {
+		return SystemPrompt{Text: sm.systemPrompt.profile, Source: "profile " + sm.profiles.active.Name}
+	}
+	prompt, err := claudecli.DetectSystemPrompt(sm.Dir())
+	if err != nil {
+		sm.emitEvent(EventError, err)
+	}
+	return prompt
+}
+
+// SetSystemPrompt replaces the project system prompt for the rest of the
+// session; an empty text sends no system prompt
+func (sm *SessionManager) SetSystemPrompt(text string) {
+	text = strings.TrimSpace(text)
+	sm.systemPrompt.override = &text
+}
+
+// ResetSystemPrompt goes back to the system prompt detected from the
+// project files
+func (sm *SessionManager) ResetSystemPrompt() {
+	sm.systemPrompt.override = nil
+}
+
+// SystemPromptPath returns the file to edit the system prompt in: the file
+// it was read from, or .cc-custom/system.md when there is none
+func (sm *SessionManager) SystemPromptPath() (string, error) {
+	return claudecli.SystemPromptPath(sm.Dir()), nil
+}
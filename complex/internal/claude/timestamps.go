@@ -0,0 +1,17 @@
+package claude
+
+import "time"
+
+// eventTime returns the timestamp of the stream line being processed, falling
+// back to the current time outside of stream processing
+func (sm *SessionManager) eventTime() time.Time {
+	if sm.lineTime.IsZero() {
+		return time.Now()
+	}
+	return sm.lineTime
+}
+
+// emitStreamEvent emits an event stamped with the stream line's timestamp
+func (sm *SessionManager) emitStreamEvent(eventType EventType, data interface{}) {
+	sm.emitEventAt(eventType, data, sm.eventTime())
+}
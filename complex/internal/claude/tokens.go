@@ -0,0 +1,78 @@
+package claude
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ContextWarnFraction is the share of the context window above which the
+// meter turns red and prompts are checked before sending
+const ContextWarnFraction = 0.8
+
+// longContextWindow is the window of models run with the 1M-token context,
+// selected in claude with a "[1m]" suffix such as "sonnet[1m]"
+const longContextWindow = 1000000
+
+// ContextWindowFor returns the context window of a model in tokens
+func ContextWindowFor(model string) int {
+	if strings.HasSuffix(strings.ToLower(model), "[1m]") {
+		return longContextWindow
+	}
+	return DefaultContextWindow
+}
+
+// SetContextWindow overrides the context window of the session's model;
+// zero goes back to the model's own
+func (sm *SessionManager) SetContextWindow(tokens int) {
+	sm.contextWindow = tokens
+}
+
+// ContextWindow returns the context window of the session's model
+func (sm *SessionManager) ContextWindow() int {
+	if sm.contextWindow > 0 {
+		return sm.contextWindow
+	}
+	return ContextWindowFor(sm.Model)
+}
+
+// CountTokens approximates how many tokens text takes, closer than a flat
+// four characters per token on code and non-English text. Words are split
+// into pieces of about five letters, digits go in threes, punctuation and
+// symbols are a token each, runs of whitespace beyond a single space are a
+// token, and characters outside ASCII are a token each.
+func CountTokens(text string) int {
+	tokens := 0
+	letters, digits, spaces := 0, 0, 0
+	flush := func() {
+		tokens += (letters + 4) / 5
+		tokens += (digits + 2) / 3
+		if spaces > 1 {
+			tokens++
+		}
+		letters, digits, spaces = 0, 0, 0
+	}
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '_'):
+			if digits > 0 || spaces > 0 {
+				flush()
+			}
+			letters++
+		case r < unicode.MaxASCII && unicode.IsDigit(r):
+			if letters > 0 || spaces > 0 {
+				flush()
+			}
+			digits++
+		case unicode.IsSpace(r):
+			if letters > 0 || digits > 0 {
+				flush()
+			}
+			spaces++
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,123 @@
+package claude
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"complex/internal/diff"
+)
+
+// maxDiffFileBytes is the largest file that edit previews read
+const maxDiffFileBytes = 2 << 20
+
+// fileEdit is one string replacement of an Edit or MultiEdit call
+type fileEdit struct {
+	old, new   string
+	replaceAll bool
+}
+
+// toolDiff previews the change an Edit, MultiEdit or Write call makes to its
+// file as a unified diff. It runs when the call is announced, which is
+// normally before the tool runs; edits found already applied are undone to
+// recover the original. It returns "" for other tools or when the change
+// cannot be worked out.
+func toolDiff(toolName string, input map[string]interface{}) string {
+	path, _ := input["file_path"].(string)
+	if path == "" {
+		return ""
+	}
+
+	var edits []fileEdit
+	switch toolName {
+	case "Edit":
+		edits = append(edits, parseEdit(input))
+	case "MultiEdit":
+		items, _ := input["edits"].([]interface{})
+		for _, item := range items {
+			if fields, ok := item.(map[string]interface{}); ok {
+				edits = append(edits, parseEdit(fields))
+			}
+		}
+	case "Write":
+	default:
+		return ""
+	}
+
+	current, err := readForDiff(path)
+	if err != nil {
+		return ""
+	}
+
+	if toolName == "Write" {
+		content, _ := input["content"].(string)
+		return diff.Unified(path, path, current, content, diff.DefaultContext)
+	}
+
+	if after, ok := applyEdits(current, edits); ok {
+		return diff.Unified(path, path, current, after, diff.DefaultContext)
+	}
+	// The tool may already have run: undo the edits in reverse
+	undo := make([]fileEdit, 0, len(edits))
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		undo = append(undo, fileEdit{old: e.new, new: e.old, replaceAll: e.replaceAll})
+	}
+	if original, ok := applyEdits(current, undo); ok {
+		return diff.Unified(path, path, original, current, diff.DefaultContext)
+	}
+	return ""
+}
+
+// parseEdit reads the replacement of an Edit input or a MultiEdit entry
+func parseEdit(fields map[string]interface{}) fileEdit {
+	var e fileEdit
+	e.old, _ = fields["old_string"].(string)
+	e.new, _ = fields["new_string"].(string)
+	e.replaceAll, _ = fields["replace_all"].(bool)
+	return e
+}
+
+// applyEdits applies the replacements in order; it fails when one finds
+// nothing to replace
+func applyEdits(text string, edits []fileEdit) (string, bool) {
+	for _, e := range edits {
+		if e.old == "" {
+			// Creating a file with an empty old_string
+			if text != "" {
+				return "", false
+			}
+			text = e.new
+			continue
+		}
+		if !strings.Contains(text, e.old) {
+			return "", false
+		}
+		if e.replaceAll {
+			text = strings.ReplaceAll(text, e.old, e.new)
+		} else {
+			text = strings.Replace(text, e.old, e.new, 1)
+		}
+	}
+	return text, true
+}
+
+// readForDiff reads a file to diff against; a missing file is empty
+func readForDiff(path string) (string, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxDiffFileBytes {
+		return "", errors.New("file too large to diff")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,72 @@
+package claude
+
+import (
+	"strings"
+)
+
+// ToolRun is a finished tool invocation with its captured output
+type ToolRun struct {
+	ToolUseID string `json:"tool_use_id"`
+	ToolName  string `json:"tool_name"`
+	Command   string `json:"command,omitempty"`
+	Output    string `json:"output"`
+	IsError   bool   `json:"is_error"`
+}
+
+// toolRunState pairs tool_use blocks with their tool_result blocks
+type toolRunState struct {
+	pending  map[string]ToolRun
+	finished []ToolRun
+}
+
+// reset discards runs from a previous command
+func (s *toolRunState) reset() {
+	s.pending = make(map[string]ToolRun)
+	s.finished = nil
+}
+
+// started records a tool_use block
+func (s *toolRunState) started(id, name string, input interface{}) {
+	if s.pending == nil {
+		s.pending = make(map[string]ToolRun)
+	}
+	run := ToolRun{ToolUseID: id, ToolName: name}
+	if fields, ok := input.(map[string]interface{}); ok {
+		run.Command, _ = fields["command"].(string)
+	}
+	s.pending[id] = run
+}
+
+// completed records a tool_result block and returns the finished run
+func (s *toolRunState) completed(item map[string]interface{}) ToolRun {
+	id, _ := item["tool_use_id"].(string)
+	run, ok := s.pending[id]
+	if !ok {
+		run = ToolRun{ToolUseID: id}
+	}
+	delete(s.pending, id)
+	run.IsError, _ = item["is_error"].(bool)
+	run.Output = toolResultText(item["content"])
+	s.finished = append(s.finished, run)
+	return run
+}
+
+// toolResultText extracts the text of a tool_result content field, which is
+// either a string or a list of content blocks
+func toolResultText(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var parts []string
+		for _, block := range c {
+			if fields, ok := block.(map[string]interface{}); ok {
+				if text, ok := fields["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
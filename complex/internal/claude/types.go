@@ -3,47 +3,21 @@ package claude
 import (
 	"encoding/json"
 	"time"
-)
-
-// Message represents a JSON stream message from Claude CLI
-type Message struct {
-	Type         string          `json:"type"`
-	Subtype      string          `json:"subtype,omitempty"`
-	Message      json.RawMessage `json:"message,omitempty"`
-	SessionID    string          `json:"session_id,omitempty"`
-	IsError      bool            `json:"is_error,omitempty"`
-	Result       string          `json:"result,omitempty"`
-	DurationMs   int             `json:"duration_ms,omitempty"`
-	NumTurns     int             `json:"num_turns,omitempty"`
-	TotalCostUSD float64         `json:"total_cost_usd,omitempty"`
-	Usage        *Usage          `json:"usage,omitempty"`
-}
 
-// Usage represents token usage statistics
-type Usage struct {
-	InputTokens              int `json:"input_tokens"`
-	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
-	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
-	OutputTokens             int `json:"output_tokens"`
-}
-
-// AssistantMessage represents an assistant response message
-type AssistantMessage struct {
-	ID         string          `json:"id"`
-	Type       string          `json:"type"`
-	Role       string          `json:"role"`
-	Model      string          `json:"model"`
-	Content    json.RawMessage `json:"content"`
-	StopReason string          `json:"stop_reason"`
-}
+	"customclaude/pkg/claudecli"
+)
 
-// SystemInit represents system initialization message
-type SystemInit struct {
-	CWD       string   `json:"cwd"`
-	SessionID string   `json:"session_id"`
-	Tools     []string `json:"tools"`
-	Model     string   `json:"model"`
-}
+// Stream wire types, shared with the simple CLI
+type (
+	Message          = claudecli.Message
+	Usage            = claudecli.Usage
+	AssistantMessage = claudecli.AssistantMessage
+	SystemInit       = claudecli.SystemInit
+	MCPServerStatus  = claudecli.MCPServerStatus
+	StderrLine       = claudecli.StderrLine
+	TurnLatency      = claudecli.TurnLatency
+	SystemPrompt     = claudecli.SystemPrompt
+)
 
 // SessionStats represents accumulated session statistics
 type SessionStats struct {
@@ -52,6 +26,11 @@ type SessionStats struct {
 	CumulativeCost     float64   `json:"cumulative_cost"`
 	CumulativeUsage    Usage     `json:"cumulative_usage"`
 	ConversationStart  time.Time `json:"conversation_start"`
+	// Interruptions counts turns cancelled, timed out or abandoned
+	Interruptions int `json:"interruptions"`
+	// DailyCost is today's spending across all conversations; it is not
+	// part of the conversation record
+	DailyCost float64 `json:"-"`
 }
 
 // Event represents events that can be emitted by the session manager
@@ -65,22 +44,89 @@ type Event struct {
 type EventType string
 
 const (
-	EventSessionInit     EventType = "session_init"
-	EventSessionUpdate   EventType = "session_update"
-	EventMessageReceived EventType = "message_received"
-	EventToolActivity    EventType = "tool_activity"
-	EventError           EventType = "error"
-	EventStatsUpdate     EventType = "stats_update"
+	EventSessionInit      EventType = "session_init"
+	EventSessionUpdate    EventType = "session_update"
+	EventMessageReceived  EventType = "message_received"
+	EventMessageDelta     EventType = "message_delta"
+	EventToolActivity     EventType = "tool_activity"
+	EventError            EventType = "error"
+	EventStatsUpdate      EventType = "stats_update"
+	EventTurnComplete     EventType = "turn_complete"
+	EventCommandCancelled EventType = "command_cancelled"
+	EventRetry            EventType = "retry"
+	EventBudgetAlert      EventType = "budget_alert"
+	EventRawLine          EventType = "raw_line"
+	EventStderr           EventType = "stderr"
 )
 
+// RawLine is a line of stream-json output as read from the claude process.
+// Events are delivered concurrently, so Seq restores the read order.
+type RawLine struct {
+	Seq      int64     `json:"seq"`
+	Type     string    `json:"type"`
+	Line     string    `json:"line"`
+	Received time.Time `json:"received"`
+}
+
+// ToolActivity reports a tool starting or finishing, matched by tool_use id
+type ToolActivity struct {
+	ToolUseID string `json:"tool_use_id"`
+	ToolName  string `json:"tool_name"`
+	Status    string `json:"status"` // "running", "completed" or "failed"
+	Output    string `json:"output,omitempty"`
+}
+
+// TurnResult summarizes a finished turn from the final result message
+type TurnResult struct {
+	SessionID  string  `json:"session_id"`
+	IsError    bool    `json:"is_error"`
+	Result     string  `json:"result"`
+	DurationMs int     `json:"duration_ms"`
+	NumTurns   int     `json:"num_turns"`
+	CostUSD    float64 `json:"cost_usd"`
+	Usage      Usage   `json:"usage"`
+	Model      string  `json:"model,omitempty"`
+
+	Latency TurnLatency `json:"latency"`
+
+	// ToolRuns are the tool invocations of the command, in completion order
+	ToolRuns []ToolRun `json:"tool_runs,omitempty"`
+}
+
 // ConversationMessage represents a processed message for UI display
 type ConversationMessage struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
-	IsError   bool      `json:"is_error"`
-	ToolName  string    `json:"tool_name,omitempty"`
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Content   string          `json:"content"`
+	Timestamp time.Time       `json:"timestamp"`
+	IsError   bool            `json:"is_error"`
+	ToolName  string          `json:"tool_name,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+
+	// Model is the model that wrote an assistant message, or the one asked
+	// for on a user message run on a different model than the session's
+	Model string `json:"model,omitempty"`
+
+	// SessionID is the Claude session that produced an assistant or tool_use
+	// message; resuming it forks the conversation right after that turn
+	SessionID string `json:"session_id,omitempty"`
+
+	// Thinking is the extended thinking that preceded an assistant reply
+	Thinking string `json:"thinking,omitempty"`
+
+	// ToolResult and ToolStatus are filled in on tool_use messages once the
+	// matching tool_result arrives
+	ToolResult string `json:"tool_result,omitempty"`
+	ToolStatus string `json:"tool_status,omitempty"`
+
+	// Diff is the unified diff of the file change made by an Edit, MultiEdit
+	// or Write tool_use
+	Diff string `json:"diff,omitempty"`
+
+	// Interruption is set on the system message recorded for a turn that
+	// ended without a result
+	Interruption *TurnInterruption `json:"interruption,omitempty"`
 }
 
 // SessionInfo represents session information for UI display
@@ -0,0 +1,193 @@
+package claude
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// UsageEntry is the usage and cost of one result, as kept in the usage
+// ledger
+type UsageEntry struct {
+	Time           time.Time `json:"time"`
+	Date           string    `json:"date"`    // local day, 2006-01-02
+	Project        string    `json:"project"` // directory claude ran in
+	Model          string    `json:"model,omitempty"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	SessionID      string    `json:"session_id,omitempty"`
+	Cost           float64   `json:"cost"`
+	Usage          Usage     `json:"usage"`
+}
+
+// usageLedger appends every result's usage to a JSON Lines file shared by
+// all conversations and instances
+type usageLedger struct {
+	path string
+}
+
+// add appends an entry to the ledger
+func (l usageLedger) add(entry UsageEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage entry: %w", err)
+	}
+
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create usage ledger directory: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+	return nil
+}
+
+// ReadUsageLedger loads the entries of the usage ledger at path. A missing
+// file is an empty ledger, and lines that do not parse are skipped.
+func ReadUsageLedger(path string) ([]UsageEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	var entries []UsageEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry UsageEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	return entries, nil
+}
+
+// UsageTotal sums the ledger entries sharing a day, model or project
+type UsageTotal struct {
+	Key     string
+	Cost    float64
+	Usage   Usage
+	Results int
+}
+
+// UsageReport is the spending of a period, per day, model and project
+type UsageReport struct {
+	Since     string // first day covered, 2006-01-02
+	Total     UsageTotal
+	ByDay     []UsageTotal // oldest first
+	ByModel   []UsageTotal // most expensive first
+	ByProject []UsageTotal // most expensive first
+}
+
+// SummarizeUsage totals the entries of the last days days, today included
+func SummarizeUsage(entries []UsageEntry, days int, now time.Time) UsageReport {
+	report := UsageReport{Since: day(now.AddDate(0, 0, 1-max(1, days)))}
+	byDay := make(map[string]*UsageTotal)
+	byModel := make(map[string]*UsageTotal)
+	byProject := make(map[string]*UsageTotal)
+	add := func(totals map[string]*UsageTotal, key string, entry UsageEntry) {
+		total, ok := totals[key]
+		if !ok {
+			total = &UsageTotal{Key: key}
+			totals[key] = total
+		}
+		total.Cost += entry.Cost
+		total.Usage.Add(entry.Usage)
+		total.Results++
+	}
+
+	for _, entry := range entries {
+		if entry.Date < report.Since {
+			continue
+		}
+		model := entry.Model
+		if model == "" {
+			model = "default"
+		}
+		add(byDay, entry.Date, entry)
+		add(byModel, model, entry)
+		add(byProject, entry.Project, entry)
+		report.Total.Cost += entry.Cost
+		report.Total.Usage.Add(entry.Usage)
+		report.Total.Results++
+	}
+
+	report.ByDay = sortedTotals(byDay, func(a, b UsageTotal) bool { return a.Key < b.Key })
+	byCost := func(a, b UsageTotal) bool { return a.Cost > b.Cost }
+	report.ByModel = sortedTotals(byModel, byCost)
+	report.ByProject = sortedTotals(byProject, byCost)
+	return report
+}
+
+// sortedTotals lists totals in the given order
+func sortedTotals(totals map[string]*UsageTotal, less func(a, b UsageTotal) bool) []UsageTotal {
+	list := make([]UsageTotal, 0, len(totals))
+	for _, total := range totals {
+		list = append(list, *total)
+	}
+	sort.Slice(list, func(i, j int) bool { return less(list[i], list[j]) })
+	return list
+}
+
+// SetUsageLedger sets the file every result's usage is appended to; an
+// empty path keeps no ledger
+func (sm *SessionManager) SetUsageLedger(path string) {
+	sm.usage = usageLedger{path: path}
+}
+
+// UsageReport summarizes the usage ledger over the last days days
+func (sm *SessionManager) UsageReport(days int) (UsageReport, error) {
+	if sm.usage.path == "" {
+		return UsageReport{}, fmt.Errorf("the usage ledger is not configured")
+	}
+	entries, err := ReadUsageLedger(sm.usage.path)
+	if err != nil {
+		return UsageReport{}, err
+	}
+	return SummarizeUsage(entries, days, time.Now()), nil
+}
+
+// noteUsage appends a result to the usage ledger
+func (sm *SessionManager) noteUsage(msg Message) {
+	if sm.usage.path == "" {
+		return
+	}
+	now := time.Now()
+	entry := UsageEntry{
+		Time:           now,
+		Date:           day(now),
+		Project:        sm.Dir(),
+		Model:          sm.turnModel,
+		ConversationID: sm.conversationID,
+		SessionID:      msg.SessionID,
+		Cost:           msg.TotalCostUSD,
+	}
+	if msg.Usage != nil {
+		entry.Usage = *msg.Usage
+	}
+	if err := sm.usage.add(entry); err != nil {
+		sm.emitEvent(EventError, err)
+	}
+}
@@ -0,0 +1,85 @@
+package claude
+
+import (
+	"sort"
+	"time"
+)
+
+// ConversationUsage is what one stored conversation spent
+type ConversationUsage struct {
+	ID        string
+	Title     string
+	UpdatedAt time.Time
+	Cost      float64
+	Prompts   int // prompts with stats, the turns of the chain
+	Usage     Usage
+}
+
+// UsageHistory sums the usage of the stored conversations
+type UsageHistory struct {
+	Conversations []ConversationUsage // most recently updated first
+	Turns         []ChainLink         // turns with stats, oldest first
+	Cost          float64
+	Usage         Usage
+	Duration      time.Duration // wall time of the turns
+}
+
+// BuildUsageHistory sums the stats persisted with each record. Sessions
+// from before stats were kept count toward the totals but have no turns.
+func BuildUsageHistory(records []SessionRecord) UsageHistory {
+	var history UsageHistory
+	for _, record := range records {
+		conversation := ConversationUsage{
+			ID:        record.ID,
+			Title:     record.Title,
+			UpdatedAt: record.UpdatedAt,
+			Cost:      record.Stats.CumulativeCost,
+			Usage:     record.Stats.CumulativeUsage,
+		}
+		for _, link := range record.ChainLinks {
+			if link.Messages == 0 {
+				continue
+			}
+			conversation.Prompts++
+			history.Turns = append(history.Turns, link)
+			history.Duration += time.Duration(link.DurationMs) * time.Millisecond
+		}
+		history.Conversations = append(history.Conversations, conversation)
+		history.Cost += conversation.Cost
+		history.Usage.Add(conversation.Usage)
+	}
+
+	sort.SliceStable(history.Conversations, func(i, j int) bool {
+		return history.Conversations[i].UpdatedAt.After(history.Conversations[j].UpdatedAt)
+	})
+	sort.SliceStable(history.Turns, func(i, j int) bool {
+		return history.Turns[i].Timestamp.Before(history.Turns[j].Timestamp)
+	})
+	return history
+}
+
+// AverageLatency is the mean wall time of a turn
+func (h UsageHistory) AverageLatency() time.Duration {
+	if len(h.Turns) == 0 {
+		return 0
+	}
+	return h.Duration / time.Duration(len(h.Turns))
+}
+
+// CacheHitRatio is the share of input tokens read from the prompt cache
+func CacheHitRatio(usage Usage) float64 {
+	input := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+	if input == 0 {
+		return 0
+	}
+	return float64(usage.CacheReadInputTokens) / float64(input)
+}
+
+// UsageHistory loads the stored conversations and sums their usage
+func (sm *SessionManager) UsageHistory() (UsageHistory, error) {
+	records, err := sm.ListConversations()
+	if err != nil {
+		return UsageHistory{}, err
+	}
+	return BuildUsageHistory(records), nil
+}
@@ -0,0 +1,75 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir returns the directory claude runs in: the one set with SetDir, or the
+// process working directory
+func (sm *SessionManager) Dir() string {
+	if sm.dir != "" {
+		return sm.dir
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// SetDir changes the directory claude runs in. Claude sessions belong to a
+// project directory and cannot be resumed from another one, so a change
+// ends the current conversation and starts a new one.
+func (sm *SessionManager) SetDir(dir string) error {
+	abs, err := ResolveDir(dir)
+	if err != nil {
+		return err
+	}
+	if abs == sm.Dir() {
+		return nil
+	}
+
+	sm.log.Info("changing directory", "from", sm.Dir(), "to", abs)
+	sm.dir = abs
+	sm.projectRoot = ""
+	if sm.profiles.enabled {
+		sm.followProjectProfile()
+	}
+	sm.StartNewConversation()
+	sm.emitEvent(EventSessionUpdate, "directory_changed")
+	return nil
+}
+
+// ResolveDir returns the absolute path of dir, checking it is a directory
+func ResolveDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", abs, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", abs)
+	}
+	return abs, nil
+}
+
+// ProjectRoot returns the root of the git repository containing the
+// directory claude runs in, or the directory itself outside a repository.
+// It is looked up once per directory, as the header shows it on every render.
+func (sm *SessionManager) ProjectRoot() string {
+	if sm.projectRoot != "" {
+		return sm.projectRoot
+	}
+	dir := sm.Dir()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	sm.projectRoot = dir
+	if root, err := git(ctx, dir, "rev-parse", "--show-toplevel"); err == nil && root != "" {
+		sm.projectRoot = root
+	}
+	return sm.projectRoot
+}
@@ -0,0 +1,55 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// commands lists the clipboard helpers we know how to drive, in the order
+// they are tried
+var commands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// ErrUnavailable is returned when no clipboard helper is installed
+var ErrUnavailable = errors.New("no clipboard utility found")
+
+// Copy places text on the system clipboard using the first available helper
+func Copy(text string) error {
+	for _, candidate := range commands {
+		path, err := exec.LookPath(candidate[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", candidate[0], err)
+		}
+		return nil
+	}
+	return ErrUnavailable
+}
+
+// CopyOSC52 asks the terminal to set the clipboard with an OSC 52 escape
+// sequence, which also works over SSH. Written to stderr so it does not
+// interleave with the TUI renderer.
+func CopyOSC52(text string) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	fmt.Fprintf(os.Stderr, "\x1b]52;c;%s\x07", encoded)
+}
+
+// CopyWithFallback tries a clipboard helper and falls back to OSC 52
+func CopyWithFallback(text string) {
+	if err := Copy(text); err != nil {
+		CopyOSC52(text)
+	}
+}
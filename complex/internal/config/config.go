@@ -0,0 +1,261 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"complex/internal/approval"
+	"complex/internal/claude"
+	"complex/internal/guard"
+	"complex/internal/notify"
+	"complex/internal/retry"
+	"complex/internal/schedule"
+	"complex/internal/sound"
+	"complex/internal/update"
+	"customclaude/pkg/mcp"
+	"customclaude/pkg/platform"
+	"customclaude/pkg/transcript"
+)
+
+// Config holds user settings loaded from config.toml
+type Config struct {
+	Model           string             `toml:"model"`
+	FallbackModel   string             `toml:"fallback_model"`
+	MCPConfig       string             `toml:"mcp_config"`
+	PermissionTool  string             `toml:"permission_tool"`
+	Theme           string             `toml:"theme"`
+	WordWrap        int                `toml:"word_wrap"`
+	AutoScroll      bool               `toml:"auto_scroll"`   // follow new output to the bottom; off keeps the reading position
+	KeymapPreset    string             `toml:"keymap_preset"` // extra key bindings: "emacs", "less" or "" for none
+	Keybindings     map[string]string  `toml:"keybindings"`
+	ExportPath      string             `toml:"export_path"`
+	DebugBundlePath string             `toml:"debug_bundle_path"`
+	SplitThreshold  int                `toml:"split_threshold_tokens"`
+	ContextWindow   int                `toml:"context_window"` // tokens; 0 for the model's own
+	Retries         claude.RetryPolicy `toml:"retries"`
+	DiffContext     claude.DiffContext `toml:"diff_context"`
+	StaleAfter      time.Duration      `toml:"stale_after"`
+	TurnTimeout     time.Duration      `toml:"turn_timeout"`
+	MaxLineBytes    int                `toml:"max_stream_line_bytes"`
+	Budget          claude.Budget      `toml:"budget"`
+	LogLevel        string             `toml:"log_level"`
+	LogFile         string             `toml:"log_file"`        // empty for ~/.local/state/cc-custom/app.log
+	KillOrphans     bool               `toml:"kill_orphans"`    // kill claude processes left by crashed runs on startup
+	Env             map[string]string  `toml:"env"`             // added to the environment of claude and its tools
+	StreamMirror    string             `toml:"stream_mirror"`   // file or named pipe receiving assistant text as it streams
+	HideSuperseded  bool               `toml:"hide_superseded"` // fold retried and cancelled attempts in the transcript
+	Allowlist       string             `toml:"allowlist"`       // tool allowlist preset, overridden by .cc-custom/allowlist
+	Timestamps      string             `toml:"timestamps"`      // message times: "absolute", "relative" or "off"
+	SidebarWidth    int                `toml:"sidebar_width"`   // side panel columns, changed with Ctrl+Left/Right
+	SidebarHidden   bool               `toml:"sidebar_hidden"`  // side panel collapsed, toggled with Ctrl+\
+
+	Sound    sound.Config         `toml:"sound"`
+	Storage  claude.StoreConfig   `toml:"storage"`
+	Backend  claude.BackendConfig `toml:"backend"`
+	Guard    guard.Config         `toml:"guard"`
+	Approval approval.Config      `toml:"approval"`
+	Retry    retry.Config         `toml:"retry"`
+	MCP      mcp.Config           `toml:"mcp"`
+	Notify   notify.Config        `toml:"notify"`
+	Update   update.Config        `toml:"update"`
+
+	QuickReplies QuickReplies `toml:"quick_replies"`
+
+	// Profiles are named settings picked by a project's .cc-custom.toml or
+	// with /profile
+	Profiles map[string]claude.Profile `toml:"profiles"`
+
+	// Schedule lists the prompts run by --daemon
+	Schedule []schedule.Job `toml:"schedule"`
+}
+
+// QuickReplies are follow-up prompts offered after each assistant turn and
+// inserted into the input line with a single key. Keep a config file per
+// profile (CC_CUSTOM_CONFIG) to vary them.
+type QuickReplies struct {
+	Enabled bool     `toml:"enabled"`
+	Replies []string `toml:"replies"` // the first three are offered
+}
+
+// Bounds of sidebar_width
+const (
+	MinSidebarWidth = 20
+	MaxSidebarWidth = 80
+)
+
+// KeymapPresets are sets of key bindings picked with keymap_preset, by
+// action as in [keybindings], which wins over them
+var KeymapPresets = map[string]map[string]string{
+	"emacs": {"page_down": "ctrl+v", "page_up": "alt+v", "scroll_top": "alt+<", "scroll_bottom": "alt+>"},
+	"less":  {"page_down": " ", "page_up": "b", "scroll_top": "<", "scroll_bottom": ">"},
+}
+
+// EffectiveKeybindings returns the keymap preset's bindings overlaid with
+// [keybindings]
+func (c Config) EffectiveKeybindings() map[string]string {
+	bindings := make(map[string]string)
+	for action, key := range KeymapPresets[c.KeymapPreset] {
+		bindings[action] = key
+	}
+	for action, key := range c.Keybindings {
+		bindings[action] = key
+	}
+	return bindings
+}
+
+// Default returns the settings used when no config file exists
+func Default() Config {
+	return Config{
+		MCPConfig:       "config.json",
+		PermissionTool:  "mcp__permission__approval_prompt",
+		Theme:           "dark",
+		AutoScroll:      true,
+		Keybindings:     make(map[string]string),
+		ExportPath:      transcript.DefaultPathTemplate,
+		DebugBundlePath: claude.DefaultDebugBundleTemplate,
+		SplitThreshold:  claude.DefaultSplitThreshold,
+		Retries:         claude.DefaultRetryPolicy(),
+		DiffContext:     claude.DiffContext{MaxTokens: claude.DefaultDiffContextTokens},
+		StaleAfter:      2 * time.Hour,
+		MaxLineBytes:    claude.DefaultMaxLineBytes,
+		Budget:          claude.Budget{WarnAt: claude.DefaultBudgetWarnAt},
+		LogLevel:        "info",
+		HideSuperseded:  true,
+		SidebarWidth:    30,
+		Backend:         claude.BackendConfig{Kind: claude.BackendCLI, APIKeyEnv: claude.DefaultAPIKeyEnv},
+		Approval:        approval.Config{Listen: approval.DefaultListen},
+		Update:          update.DefaultConfig(),
+		QuickReplies: QuickReplies{
+			Replies: []string{"continue", "explain more", "write tests for this"},
+		},
+	}
+}
+
+// Path returns the location of config.toml
+func Path() (string, error) {
+	if path := os.Getenv("CC_CUSTOM_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load reads config.toml (if present) on top of the defaults and then applies
+// environment variable overrides
+func Load() (Config, error) {
+	cfg := Default()
+
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := toml.DecodeFile(path, &cfg); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if cfg.Keybindings == nil {
+		cfg.Keybindings = make(map[string]string)
+	}
+
+	applyEnv(&cfg)
+	return cfg, nil
+}
+
+// applyEnv overrides settings from CC_CUSTOM_* environment variables
+func applyEnv(cfg *Config) {
+	overrides := map[string]*string{
+		"CC_CUSTOM_MODEL":           &cfg.Model,
+		"CC_CUSTOM_FALLBACK_MODEL":  &cfg.FallbackModel,
+		"CC_CUSTOM_MCP_CONFIG":      &cfg.MCPConfig,
+		"CC_CUSTOM_PERMISSION_TOOL": &cfg.PermissionTool,
+		"CC_CUSTOM_THEME":           &cfg.Theme,
+		"CC_CUSTOM_EXPORT_PATH":     &cfg.ExportPath,
+		"CC_CUSTOM_SOUND_PLAYER":    &cfg.Sound.Player,
+		"CC_CUSTOM_APPROVAL_LISTEN": &cfg.Approval.Listen,
+		"CC_CUSTOM_APPROVAL_POLICY": &cfg.Approval.Policy,
+		"CC_CUSTOM_LOG_LEVEL":       &cfg.LogLevel,
+		"CC_CUSTOM_LOG_FILE":        &cfg.LogFile,
+		"CC_CUSTOM_STREAM_MIRROR":   &cfg.StreamMirror,
+		"CC_CUSTOM_ALLOWLIST":       &cfg.Allowlist,
+		"CC_CUSTOM_BACKEND":         &cfg.Backend.Kind,
+	}
+	for env, field := range overrides {
+		if value, ok := os.LookupEnv(env); ok {
+			*field = value
+		}
+	}
+
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_WORD_WRAP")); err == nil {
+		cfg.WordWrap = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_SPLIT_THRESHOLD")); err == nil {
+		cfg.SplitThreshold = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_MAX_ATTEMPTS")); err == nil {
+		cfg.Retries.MaxAttempts = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_CONTEXT_WINDOW")); err == nil {
+		cfg.ContextWindow = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_MAX_STREAM_LINE_BYTES")); err == nil {
+		cfg.MaxLineBytes = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CC_CUSTOM_TURN_TIMEOUT")); err == nil {
+		cfg.TurnTimeout = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CC_CUSTOM_STALE_AFTER")); err == nil {
+		cfg.StaleAfter = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CC_CUSTOM_NOTIFY_LONG_TURN")); err == nil {
+		cfg.Notify.LongTurn = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CC_CUSTOM_NOTIFY_AWAY_AFTER")); err == nil {
+		cfg.Notify.AwayAfter = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("CC_CUSTOM_BUDGET_CONVERSATION"), 64); err == nil {
+		cfg.Budget.Conversation = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("CC_CUSTOM_BUDGET_DAILY"), 64); err == nil {
+		cfg.Budget.Daily = value
+	}
+	if value, ok := os.LookupEnv("CC_CUSTOM_DIFF_CONTEXT"); ok {
+		cfg.DiffContext.Enabled = value != "" && value != "off"
+	}
+	if value, ok := os.LookupEnv("CC_CUSTOM_KILL_ORPHANS"); ok {
+		cfg.KillOrphans = value != "" && value != "off"
+	}
+	if value, ok := os.LookupEnv("CC_CUSTOM_HIDE_SUPERSEDED"); ok {
+		cfg.HideSuperseded = value != "" && value != "off"
+	}
+	if value, ok := os.LookupEnv("CC_CUSTOM_QUICK_REPLIES"); ok {
+		cfg.QuickReplies.Enabled = value != "" && value != "off"
+	}
+	if value, ok := os.LookupEnv("CC_CUSTOM_UPDATE_CHECK"); ok {
+		cfg.Update.Check = value != "" && value != "off"
+	}
+	if value, ok := os.LookupEnv("CC_CUSTOM_SOUND"); ok {
+		cfg.Sound.Enabled = value != "" && value != "off"
+	}
+	for cue, env := range map[sound.Cue]string{
+		sound.CueTurnComplete: "CC_CUSTOM_SOUND_TURN",
+		sound.CueError:        "CC_CUSTOM_SOUND_ERROR",
+		sound.CueApproval:     "CC_CUSTOM_SOUND_APPROVAL",
+	} {
+		if path := os.Getenv(env); path != "" {
+			if cfg.Sound.Sounds == nil {
+				cfg.Sound.Sounds = make(map[sound.Cue]string)
+			}
+			cfg.Sound.Sounds[cue] = path
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"complex/internal/approval"
+	"complex/internal/claude"
+	"complex/internal/logging"
+	"complex/internal/schedule"
+)
+
+// Field kinds, which decide how a value is edited
+const (
+	KindText     = "text"
+	KindNumber   = "number"
+	KindBool     = "bool"
+	KindDuration = "duration"
+	KindList     = "list"  // comma-separated values
+	KindPairs    = "pairs" // comma-separated name=value pairs
+	KindOther    = "other" // tables of tables; edited in config.toml
+)
+
+// Field is one setting of the config tree
+type Field struct {
+	Path    string // dotted TOML key, e.g. "budget.daily"
+	Key     string // last element of the path
+	Depth   int
+	Section bool // a table of other fields
+	Kind    string
+	Value   string // the current value, formatted for editing
+	Changed bool   // differs from the default
+}
+
+// Editable reports whether the field can be changed in the editor
+func (f Field) Editable() bool {
+	return !f.Section && f.Kind != KindOther
+}
+
+// Document is config.toml opened for editing. Only the file's settings are
+// loaded, without CC_CUSTOM_* overrides, so saving does not persist them.
+type Document struct {
+	Path   string
+	Config Config
+	// defined holds the keys present in the file or edited since; saving
+	// writes only those, so settings left out keep following the defaults
+	defined map[string]bool
+}
+
+// OpenDocument reads config.toml for editing; a missing file is empty
+func OpenDocument() (*Document, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{Path: path, Config: Default(), defined: make(map[string]bool)}
+	meta, err := toml.DecodeFile(path, &doc.Config)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for _, key := range meta.Keys() {
+		doc.defined[key.String()] = true
+	}
+	return doc, nil
+}
+
+// Fields returns the settings as a tree in file order, tables after the
+// top-level settings as TOML requires
+func (d *Document) Fields() []Field {
+	defaults := reflect.ValueOf(Default())
+	var leaves, tables []Field
+	walkFields(reflect.ValueOf(d.Config), defaults, "", 0, &leaves, &tables)
+	return append(leaves, tables...)
+}
+
+// walkFields appends the fields of a struct value, recursing into tables
+func walkFields(v, def reflect.Value, prefix string, depth int, leaves, tables *[]Field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := tomlKey(sf)
+		if key == "" {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		value, defValue := v.Field(i), def.Field(i)
+
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Duration(0)) {
+			// Nested tables stay under their parent at deeper levels
+			section := []Field{{Path: path, Key: key, Depth: depth, Section: true}}
+			var subLeaves, subTables []Field
+			walkFields(value, defValue, path, depth+1, &subLeaves, &subTables)
+			section = append(section, subLeaves...)
+			section = append(section, subTables...)
+			if depth == 0 {
+				*tables = append(*tables, section...)
+			} else {
+				*leaves = append(*leaves, section...)
+			}
+			continue
+		}
+
+		kind := fieldKind(value.Type())
+		*leaves = append(*leaves, Field{
+			Path:    path,
+			Key:     key,
+			Depth:   depth,
+			Kind:    kind,
+			Value:   formatValue(value, kind),
+			Changed: !reflect.DeepEqual(value.Interface(), defValue.Interface()),
+		})
+	}
+}
+
+// tomlKey returns the TOML key of a struct field, or "" for skipped fields
+func tomlKey(sf reflect.StructField) string {
+	if !sf.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(sf.Tag.Get("toml"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// fieldKind classifies a value type for editing
+func fieldKind(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return KindDuration
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return KindText
+	case reflect.Bool:
+		return KindBool
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return KindNumber
+	case reflect.Slice:
+		switch t.Elem().Kind() {
+		case reflect.String, reflect.Float64, reflect.Int:
+			return KindList
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+			return KindPairs
+		}
+	}
+	return KindOther
+}
+
+// formatValue renders a value for editing
+func formatValue(v reflect.Value, kind string) string {
+	switch kind {
+	case KindDuration:
+		return time.Duration(v.Int()).String()
+	case KindList:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ", ")
+	case KindPairs:
+		pairs := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", key.String(), v.MapIndex(key).String()))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ", ")
+	case KindOther:
+		n := 0
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+			n = v.Len()
+		}
+		return fmt.Sprintf("%d entries (edit in config.toml)", n)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Set parses text into the setting at path
+func (d *Document) Set(path, text string) error {
+	v, err := fieldByPath(reflect.ValueOf(&d.Config).Elem(), path)
+	if err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+
+	switch kind := fieldKind(v.Type()); kind {
+	case KindText:
+		v.SetString(text)
+	case KindBool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", path)
+		}
+		v.SetBool(b)
+	case KindDuration:
+		dur, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("%s must be a duration such as 90s or 2h: %w", path, err)
+		}
+		v.SetInt(int64(dur))
+	case KindNumber:
+		if err := setNumber(v, text); err != nil {
+			return fmt.Errorf("%s must be a number: %w", path, err)
+		}
+	case KindList:
+		list := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range splitList(text) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if elem.Kind() == reflect.String {
+				elem.SetString(item)
+			} else if err := setNumber(elem, item); err != nil {
+				return fmt.Errorf("%s must be a list of numbers: %w", path, err)
+			}
+			list = reflect.Append(list, elem)
+		}
+		v.Set(list)
+	case KindPairs:
+		pairs := reflect.MakeMap(v.Type())
+		for _, item := range splitList(text) {
+			name, value, ok := strings.Cut(item, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("%s must be name=value pairs, got %q", path, item)
+			}
+			pairs.SetMapIndex(
+				reflect.ValueOf(strings.TrimSpace(name)).Convert(v.Type().Key()),
+				reflect.ValueOf(strings.TrimSpace(value)).Convert(v.Type().Elem()))
+		}
+		v.Set(pairs)
+	default:
+		return fmt.Errorf("%s can only be edited in config.toml", path)
+	}
+
+	d.defined[path] = true
+	return nil
+}
+
+// fieldByPath finds the settable value of a dotted TOML key
+func fieldByPath(v reflect.Value, path string) (reflect.Value, error) {
+	for _, key := range strings.Split(path, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown setting %s", path)
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if tomlKey(v.Type().Field(i)) == key {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown setting %s", path)
+		}
+	}
+	return v, nil
+}
+
+// setNumber parses text into an int or float value
+func setNumber(v reflect.Value, text string) error {
+	if v.Kind() == reflect.Float64 {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return err
+	}
+	v.SetInt(n)
+	return nil
+}
+
+// splitList splits comma-separated text, dropping empty items
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate reports settings that would fail or misbehave at startup
+func Validate(cfg Config) error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if _, err := logging.ParseLevel(cfg.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+	check(cfg.Theme != "", "theme must not be empty")
+	if cfg.KeymapPreset != "" {
+		_, ok := KeymapPresets[cfg.KeymapPreset]
+		check(ok, "keymap_preset must be emacs, less or empty")
+	}
+	check(cfg.WordWrap >= 0, "word_wrap must not be negative")
+	check(cfg.SidebarWidth == 0 || (cfg.SidebarWidth >= MinSidebarWidth && cfg.SidebarWidth <= MaxSidebarWidth),
+		"sidebar_width must be between %d and %d", MinSidebarWidth, MaxSidebarWidth)
+	check(cfg.SplitThreshold >= 0, "split_threshold_tokens must not be negative")
+	check(cfg.ContextWindow >= 0, "context_window must not be negative")
+	check(cfg.MaxLineBytes >= 0, "max_stream_line_bytes must not be negative")
+	check(cfg.StaleAfter >= 0, "stale_after must not be negative")
+	check(cfg.TurnTimeout >= 0, "turn_timeout must not be negative")
+	check(cfg.Update.Interval >= 0, "update.interval must not be negative")
+	check(cfg.Retries.InitialBackoff >= 0 && cfg.Retries.MaxBackoff >= 0, "retries backoffs must not be negative")
+	check(cfg.Budget.Conversation >= 0 && cfg.Budget.Daily >= 0, "budget limits must not be negative")
+	for _, fraction := range cfg.Budget.WarnAt {
+		check(fraction > 0 && fraction <= 1, "budget.warn_at values must be fractions between 0 and 1, got %g", fraction)
+	}
+	switch cfg.Storage.Backend {
+	case "", claude.StoreBackendJSON, claude.StoreBackendSQLite, claude.StoreBackendS3:
+	default:
+		check(false, "storage.backend must be %s, %s or %s", claude.StoreBackendJSON, claude.StoreBackendSQLite, claude.StoreBackendS3)
+	}
+	switch cfg.Backend.Kind {
+	case "", claude.BackendCLI, claude.BackendAPI:
+	default:
+		check(false, "backend.kind must be %s or %s", claude.BackendCLI, claude.BackendAPI)
+	}
+	check(cfg.Backend.MaxTokens >= 0, "backend.max_tokens must not be negative")
+	for name := range cfg.Env {
+		check(claude.ValidEnvName(name), "env has an invalid variable name %q", name)
+	}
+	if cfg.Allowlist != "" {
+		_, ok := claude.FindAllowlist(cfg.Allowlist)
+		check(ok, "allowlist must be one of %s", strings.Join(claude.AllowlistNames(), ", "))
+	}
+	if cfg.Approval.Policy != "" {
+		if _, err := approval.LoadPolicy(cfg.Approval.Policy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for name, profile := range cfg.Profiles {
+		if err := claude.ValidateProfile(profile); err != nil {
+			check(false, "profiles.%s: %v", name, err)
+		}
+	}
+	if len(cfg.Schedule) > 0 {
+		_, err := schedule.New(cfg.Schedule, nil)
+		check(err == nil, "%v", err)
+	}
+	return errors.Join(errs...)
+}
+
+// Save validates the settings and writes the keys present in the file or
+// edited since. The previous file is kept as config.toml.bak; comments in it
+// are not carried over.
+func (d *Document) Save() error {
+	if err := Validate(d.Config); err != nil {
+		return err
+	}
+
+	tree := make(map[string]interface{})
+	for _, field := range d.Fields() {
+		if field.Section || !d.defined[field.Path] {
+			continue
+		}
+		v, err := fieldByPath(reflect.ValueOf(&d.Config).Elem(), field.Path)
+		if err != nil {
+			return err
+		}
+		value := v.Interface()
+		switch field.Kind {
+		case KindDuration:
+			value = time.Duration(v.Int()).String()
+		case KindPairs:
+			// The encoder cannot write maps keyed by named string types
+			pairs := make(map[string]string, v.Len())
+			for _, key := range v.MapKeys() {
+				pairs[key.String()] = v.MapIndex(key).String()
+			}
+			value = pairs
+		}
+		insertPath(tree, strings.Split(field.Path, "."), value)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# cc-custom settings; keys left out use their defaults\n\n")
+	if err := toml.NewEncoder(&buf).Encode(tree); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if old, err := os.ReadFile(d.Path); err == nil {
+		if err := os.WriteFile(d.Path+".bak", old, 0o644); err != nil {
+			return fmt.Errorf("failed to back up config: %w", err)
+		}
+	}
+	if err := os.WriteFile(d.Path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// insertPath sets a value in nested maps, creating tables along the path
+func insertPath(tree map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		sub, ok := tree[key].(map[string]interface{})
+		if !ok {
+			sub = make(map[string]interface{})
+			tree[key] = sub
+		}
+		tree = sub
+	}
+	tree[path[len(path)-1]] = value
+}
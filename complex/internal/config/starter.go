@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Models are the model aliases offered by setup; claude resolves them to
+// the current version
+var Models = []string{"sonnet", "opus", "haiku"}
+
+// Themes are the markdown themes offered by setup
+var Themes = []string{"dark", "light", "dracula", "notty"}
+
+// Starter holds the choices made during setup
+type Starter struct {
+	Model     string
+	Theme     string
+	MCPConfig string // path of the MCP config file
+	Allowlist string // tool allowlist preset; empty for claude's defaults
+}
+
+// Exists reports whether config.toml has been written
+func Exists() (bool, error) {
+	path, err := Path()
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// StarterMCPConfig is the MCP config written by setup. It defines no
+// servers: the permission server that asks before claude's tools run is
+// bundled and added by the TUI, so only extra servers need listing here.
+func StarterMCPConfig() ([]byte, error) {
+	return json.MarshalIndent(map[string]interface{}{
+		"mcpServers": map[string]interface{}{},
+	}, "", "  ")
+}
+
+// Text returns the starter config.toml. Only the chosen settings are set;
+// the rest are listed as comments with their defaults.
+func (s Starter) Text() string {
+	defaults := Default()
+	var b strings.Builder
+	b.WriteString("# cc-custom settings written by init; keys left out use their defaults\n\n")
+	fmt.Fprintf(&b, "model = %s\n", strconv.Quote(s.Model))
+	fmt.Fprintf(&b, "theme = %s\n", strconv.Quote(s.Theme))
+	fmt.Fprintf(&b, "mcp_config = %s\n", strconv.Quote(s.MCPConfig))
+	if s.Allowlist != "" {
+		fmt.Fprintf(&b, "allowlist = %s\n", strconv.Quote(s.Allowlist))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "# fallback_model = \"haiku\"\n")
+	fmt.Fprintf(&b, "# permission_tool = %s\n", strconv.Quote(defaults.PermissionTool))
+	fmt.Fprintf(&b, "# stale_after = %s\n", strconv.Quote(defaults.StaleAfter.String()))
+	fmt.Fprintf(&b, "# log_level = %s\n", strconv.Quote(defaults.LogLevel))
+	b.WriteString("\n# [budget]\n# daily = 10.0\n")
+	return b.String()
+}
+
+// WriteStarter writes the starter config.toml, and the MCP config when it
+// does not exist yet, returning the path of config.toml
+func WriteStarter(s Starter) (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if _, err := os.Stat(s.MCPConfig); errors.Is(err, fs.ErrNotExist) {
+		data, err := StarterMCPConfig()
+		if err != nil {
+			return "", fmt.Errorf("failed to encode MCP config: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(s.MCPConfig), 0o755); err != nil {
+			return "", fmt.Errorf("failed to create MCP config directory: %w", err)
+		}
+		if err := os.WriteFile(s.MCPConfig, append(data, '\n'), 0o644); err != nil {
+			return "", fmt.Errorf("failed to write MCP config: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(s.Text()), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write config: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,78 @@
+package guard
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Rule describes a risky shell command pattern
+type Rule struct {
+	Name    string `toml:"name" json:"name"`
+	Pattern string `toml:"pattern" json:"pattern"`
+}
+
+// DefaultRules catch the most common destructive commands
+var DefaultRules = []Rule{
+	{Name: "recursive force delete", Pattern: `\brm\s+(-[a-zA-Z]*r[a-zA-Z]*f|-[a-zA-Z]*f[a-zA-Z]*r|--recursive\s+--force|--force\s+--recursive)\b`},
+	{Name: "git force push", Pattern: `\bgit\s+push\b.*(\s--force\b|\s-f\b|\s--force-with-lease\b)`},
+	{Name: "git hard reset", Pattern: `\bgit\s+reset\s+--hard\b`},
+	{Name: "git clean", Pattern: `\bgit\s+clean\s+-[a-zA-Z]*f`},
+	{Name: "pipe to shell", Pattern: `\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`},
+	{Name: "disk overwrite", Pattern: `\b(dd\s+.*of=/dev/|mkfs(\.\w+)?\s)`},
+	{Name: "recursive chmod/chown on root", Pattern: `\bch(mod|own)\s+-R\s+\S+\s+/(\s|$)`},
+	{Name: "sudo", Pattern: `(^|[;&|]\s*)sudo\b`},
+}
+
+// Config controls the detector
+type Config struct {
+	Disabled        bool   `toml:"disabled" json:"disabled"`
+	ReplaceDefaults bool   `toml:"replace_defaults" json:"replace_defaults"`
+	Rules           []Rule `toml:"rules" json:"rules"`
+}
+
+type compiledRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Detector flags risky shell commands
+type Detector struct {
+	rules []compiledRule
+}
+
+// NewDetector compiles the configured rules, on top of DefaultRules unless
+// ReplaceDefaults is set
+func NewDetector(cfg Config) (*Detector, error) {
+	d := &Detector{}
+	if cfg.Disabled {
+		return d, nil
+	}
+
+	rules := cfg.Rules
+	if !cfg.ReplaceDefaults {
+		rules = append(append([]Rule(nil), DefaultRules...), cfg.Rules...)
+	}
+
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid guard rule %q: %w", rule.Name, err)
+		}
+		d.rules = append(d.rules, compiledRule{name: rule.Name, re: re})
+	}
+	return d, nil
+}
+
+// Check returns the names of all rules matching command
+func (d *Detector) Check(command string) []string {
+	if d == nil {
+		return nil
+	}
+	var matches []string
+	for _, rule := range d.rules {
+		if rule.re.MatchString(command) {
+			matches = append(matches, rule.name)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"customclaude/pkg/platform"
+)
+
+// recentLimit bounds the log lines kept in memory for the /log command
+const recentLimit = 500
+
+// recent keeps the latest formatted log lines
+var recent = &ring{}
+
+// ring is a writer that keeps the last recentLimit lines written to it
+type ring struct {
+	mutex sync.Mutex
+	lines []string
+}
+
+// Write stores one or more complete log lines; slog handlers write a whole
+// record per call
+func (r *ring) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		r.lines = append(r.lines, line)
+	}
+	if len(r.lines) > recentLimit {
+		r.lines = append([]string(nil), r.lines[len(r.lines)-recentLimit:]...)
+	}
+	return len(p), nil
+}
+
+// DefaultPath returns the log file location, under $XDG_STATE_HOME when set,
+// ~/.local/state otherwise and %LocalAppData% on Windows
+func DefaultPath() (string, error) {
+	base, err := platform.StateHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "cc-custom", "app.log"), nil
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q (use debug, info, warn or error)", name)
+	}
+	return level, nil
+}
+
+// Setup sends the default slog logger to the file at path, appending to it.
+// Records are also kept in memory for Recent. When the file cannot be opened
+// the error is returned and records are only kept in memory, so nothing is
+// written over the TUI. The returned function closes the file.
+func Setup(path string, level slog.Level) (func() error, error) {
+	var out io.Writer = recent
+	closeFile := func() error { return nil }
+
+	var err error
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		err = fmt.Errorf("failed to create log directory: %w", err)
+	} else if file, openErr := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644); openErr != nil {
+		err = fmt.Errorf("failed to open log file: %w", openErr)
+	} else {
+		out = io.MultiWriter(file, recent)
+		closeFile = file.Close
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})))
+	return closeFile, err
+}
+
+// For returns the logger of a component. Call it after Setup; loggers keep
+// the handler that was the default when they were created.
+func For(component string) *slog.Logger {
+	return slog.Default().With("component", component)
+}
+
+// Recent returns up to n of the latest log lines, oldest first
+func Recent(n int) []string {
+	recent.mutex.Lock()
+	defer recent.mutex.Unlock()
+	start := max(0, len(recent.lines)-n)
+	return append([]string(nil), recent.lines[start:]...)
+}
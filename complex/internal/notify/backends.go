@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"complex/internal/sound"
+	"customclaude/pkg/platform"
+)
+
+// newBackend builds a configured backend
+func newBackend(cfg BackendConfig) (Notifier, error) {
+	switch cfg.Type {
+	case "desktop":
+		return desktopNotifier{}, nil
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, errors.New("webhook needs a url")
+		}
+		return webhookNotifier{url: cfg.URL, headers: cfg.Headers}, nil
+	case "slack":
+		if cfg.URL == "" {
+			return nil, errors.New("slack needs an incoming webhook url")
+		}
+		return slackNotifier{url: cfg.URL}, nil
+	case "command":
+		if cfg.Command == "" {
+			return nil, errors.New("command needs a command")
+		}
+		return commandNotifier{command: cfg.Command}, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q (use desktop, webhook, slack or command)", cfg.Type)
+	}
+}
+
+// SoundNotifier plays the cue of an event; events without a cue are silent
+type SoundNotifier struct {
+	Player *sound.Player
+}
+
+// eventCues maps events to the sound cues that announce them
+var eventCues = map[Event]sound.Cue{
+	EventTurnComplete: sound.CueTurnComplete,
+	EventError:        sound.CueError,
+	EventApproval:     sound.CueApproval,
+	EventBudget:       sound.CueError,
+}
+
+func (s SoundNotifier) Notify(ctx context.Context, n Notification) error {
+	if cue, ok := eventCues[n.Event]; ok {
+		s.Player.Play(cue)
+	}
+	return nil
+}
+
+// desktopNotifier shows a desktop notification with notify-send, osascript
+// on macOS or a PowerShell balloon tip on Windows
+type desktopNotifier struct{}
+
+// windowsBalloon shows a tray balloon tip; the title and body come from the
+// environment to avoid quoting them into the script
+const windowsBalloon = `Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(5000, $env:CC_CUSTOM_TITLE, $env:CC_CUSTOM_BODY, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`
+
+func (desktopNotifier) Notify(ctx context.Context, n Notification) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", n.Body, n.Title)
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", windowsBalloon)
+		cmd.Env = append(os.Environ(), "CC_CUSTOM_TITLE="+n.Title, "CC_CUSTOM_BODY="+n.Body)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=cc-custom", n.Title, n.Body)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to show desktop notification: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// bellNotifier rings the terminal bell. It writes to stderr, which shares the
+// terminal with the TUI without going through its renderer.
+type bellNotifier struct{}
+
+func (bellNotifier) Notify(ctx context.Context, n Notification) error {
+	if _, err := os.Stderr.WriteString("\a"); err != nil {
+		return fmt.Errorf("failed to ring the terminal bell: %w", err)
+	}
+	return nil
+}
+
+// webhookNotifier posts the notification as JSON
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+}
+
+func (w webhookNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.url, w.headers, map[string]any{
+		"event": n.Event,
+		"title": n.Title,
+		"body":  n.Body,
+		"time":  n.Time,
+	})
+}
+
+// slackNotifier posts to a Slack incoming webhook
+type slackNotifier struct {
+	url string
+}
+
+func (s slackNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, s.url, nil, map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", n.Title, n.Body),
+	})
+}
+
+// postJSON posts payload to url and fails on a non-2xx response
+func postJSON(ctx context.Context, url string, headers map[string]string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notification endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// commandNotifier runs a shell command with the notification in its
+// environment
+type commandNotifier struct {
+	command string
+}
+
+func (c commandNotifier) Notify(ctx context.Context, n Notification) error {
+	cmd := platform.ShellCommand(ctx, c.command)
+	cmd.Env = append(os.Environ(),
+		"CC_CUSTOM_EVENT="+string(n.Event),
+		"CC_CUSTOM_TITLE="+n.Title,
+		"CC_CUSTOM_BODY="+n.Body,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notification command failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
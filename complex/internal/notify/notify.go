@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"complex/internal/logging"
+)
+
+// Event identifies what a notification is about; routes select backends by
+// event
+type Event string
+
+const (
+	EventTurnComplete Event = "turn_complete"
+	EventError        Event = "error"
+	EventApproval     Event = "approval"
+	EventBudget       Event = "budget"
+	// EventLongTurn is sent when a run finishes after long_turn, or while
+	// nobody has touched the TUI for away_after
+	EventLongTurn Event = "long_turn"
+	// EventUpdate is sent when the update check finds a newer release
+	EventUpdate Event = "update"
+)
+
+// Events lists every event that can be routed
+var Events = []Event{EventTurnComplete, EventError, EventApproval, EventBudget, EventLongTurn, EventUpdate}
+
+// sendTimeout bounds how long a backend may take to deliver a notification
+const sendTimeout = 10 * time.Second
+
+// Notification is one alert handed to the backends its event routes to
+type Notification struct {
+	Event Event
+	Title string
+	Body  string
+	Time  time.Time
+}
+
+// Notifier delivers notifications through one channel
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// BackendConfig configures a named backend. Type is one of desktop,
+// webhook, slack or command; the built-in "sound" backend needs no entry.
+type BackendConfig struct {
+	Type    string            `toml:"type" json:"type"`
+	URL     string            `toml:"url" json:"url,omitempty"`         // webhook and slack
+	Headers map[string]string `toml:"headers" json:"headers,omitempty"` // webhook
+	Command string            `toml:"command" json:"command,omitempty"` // command, run with sh -c
+}
+
+// Route sends the listed events to the listed backends. An empty event list
+// matches every event.
+type Route struct {
+	Events   []Event  `toml:"events" json:"events"`
+	Backends []string `toml:"backends" json:"backends"`
+}
+
+// Config holds the backends and the routing rules. Without routes, turn
+// completions, errors and approvals go to the sound backend as before, and
+// long runs to the desktop and the terminal bell.
+type Config struct {
+	Backends map[string]BackendConfig `toml:"backends" json:"backends,omitempty"`
+	Routes   []Route                  `toml:"routes" json:"routes,omitempty"`
+
+	// LongTurn and AwayAfter enable long_turn events; 0 disables each
+	LongTurn  time.Duration `toml:"long_turn" json:"long_turn,omitempty"`
+	AwayAfter time.Duration `toml:"away_after" json:"away_after,omitempty"`
+}
+
+// DefaultRoutes are used when no routes are configured
+var DefaultRoutes = []Route{
+	{Events: []Event{EventTurnComplete, EventError, EventApproval}, Backends: []string{"sound"}},
+	{Events: []Event{EventLongTurn}, Backends: []string{"desktop", "bell"}},
+}
+
+// IsLongTurn reports whether a run that took took, finishing after idle
+// without input, should send a long_turn event. The terminal cannot tell
+// whether it has focus, so a long idle stands in for being away.
+func (c Config) IsLongTurn(took, idle time.Duration) bool {
+	return (c.LongTurn > 0 && took >= c.LongTurn) || (c.AwayAfter > 0 && idle >= c.AwayAfter)
+}
+
+// Dispatcher routes notifications to backends
+type Dispatcher struct {
+	backends map[string]Notifier
+	routes   []Route
+	log      *slog.Logger
+}
+
+// NewDispatcher builds the configured backends and checks that every route
+// names known events and backends. builtin backends, such as "sound", and
+// the desktop and bell backends are available without configuration.
+func NewDispatcher(cfg Config, builtin map[string]Notifier) (*Dispatcher, error) {
+	d := &Dispatcher{
+		backends: make(map[string]Notifier),
+		routes:   cfg.Routes,
+		log:      logging.For("notify"),
+	}
+	d.backends["desktop"] = desktopNotifier{}
+	d.backends["bell"] = bellNotifier{}
+	for name, backend := range builtin {
+		d.backends[name] = backend
+	}
+	for name, backend := range cfg.Backends {
+		notifier, err := newBackend(backend)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification backend %q: %w", name, err)
+		}
+		d.backends[name] = notifier
+	}
+
+	if len(d.routes) == 0 {
+		d.routes = DefaultRoutes
+	}
+	for i, route := range d.routes {
+		for _, event := range route.Events {
+			if !knownEvent(event) {
+				return nil, fmt.Errorf("notification route %d: unknown event %q", i+1, event)
+			}
+		}
+		for _, name := range route.Backends {
+			if _, ok := d.backends[name]; !ok {
+				return nil, fmt.Errorf("notification route %d: unknown backend %q", i+1, name)
+			}
+		}
+	}
+	return d, nil
+}
+
+func knownEvent(event Event) bool {
+	for _, known := range Events {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// backendsFor returns the backends routed for an event, each once
+func (d *Dispatcher) backendsFor(event Event) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, route := range d.routes {
+		matches := len(route.Events) == 0
+		for _, routed := range route.Events {
+			matches = matches || routed == event
+		}
+		if !matches {
+			continue
+		}
+		for _, name := range route.Backends {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Notify hands a notification to the backends routed for its event. Delivery
+// happens in the background; failures are logged.
+func (d *Dispatcher) Notify(n Notification) {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	for _, name := range d.backendsFor(n.Event) {
+		go func(name string, backend Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			if err := backend.Notify(ctx, n); err != nil {
+				d.log.Warn("notification failed", "backend", name, "event", n.Event, "err", err)
+			}
+		}(name, d.backends[name])
+	}
+}
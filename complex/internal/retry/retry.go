@@ -0,0 +1,138 @@
+package retry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxOutputLines limits how much captured output is pasted into a prompt
+const MaxOutputLines = 40
+
+// Template describes a recognizable failure and the follow-up prompt offered
+// for it. Command selects which tool runs are considered (any run when
+// empty); the last such run is checked against Match. {output} in Prompt is
+// replaced with that run's output.
+type Template struct {
+	Name    string `toml:"name" json:"name"`
+	Command string `toml:"command" json:"command"`
+	Match   string `toml:"match" json:"match"`
+	Prompt  string `toml:"prompt" json:"prompt"`
+}
+
+// DefaultTemplates cover failing tests, lint errors and broken builds
+var DefaultTemplates = []Template{
+	{
+		Name:    "failing tests",
+		Command: `\b(go test|npm (run )?test|yarn test|pnpm test|pytest|cargo test|jest|vitest|mvn test|gradle test)\b`,
+		Match:   `(?m)(^--- FAIL|^FAIL\b|\b\d+ failed\b|\bFAILED\b|AssertionError|^panic: )`,
+		Prompt:  "The tests are still failing. Fix the failing tests shown below:\n\n```\n{output}\n```",
+	},
+	{
+		Name:    "lint errors",
+		Command: `\b(golangci-lint|go vet|staticcheck|eslint|ruff|flake8|pylint|cargo clippy)\b`,
+		Match:   `(?m)(^\S+:\d+(:\d+)?:|\berror\b|\bwarning\b)`,
+		Prompt:  "The linter reports problems. Fix the lint errors shown below without changing behavior:\n\n```\n{output}\n```",
+	},
+	{
+		Name:    "build errors",
+		Command: `\b(go build|tsc|cargo build|npm run build|make)\b`,
+		Match:   `(?mi)(\berror\b|undefined:|cannot find)`,
+		Prompt:  "The build is failing. Fix the errors shown below:\n\n```\n{output}\n```",
+	},
+}
+
+// Config controls which templates are offered
+type Config struct {
+	Disabled        bool       `toml:"disabled" json:"disabled"`
+	ReplaceDefaults bool       `toml:"replace_defaults" json:"replace_defaults"`
+	Templates       []Template `toml:"templates" json:"templates"`
+}
+
+// Run is a finished tool run captured during a turn
+type Run struct {
+	Command string
+	Output  string
+	IsError bool
+}
+
+// Suggestion is a follow-up prompt offered for a recognized failure
+type Suggestion struct {
+	Name   string
+	Prompt string
+}
+
+type compiledTemplate struct {
+	name    string
+	command *regexp.Regexp
+	match   *regexp.Regexp
+	prompt  string
+}
+
+// Matcher recognizes failures in tool output
+type Matcher struct {
+	templates []compiledTemplate
+}
+
+// NewMatcher compiles the configured templates, on top of DefaultTemplates
+// unless ReplaceDefaults is set
+func NewMatcher(cfg Config) (*Matcher, error) {
+	m := &Matcher{}
+	if cfg.Disabled {
+		return m, nil
+	}
+
+	templates := cfg.Templates
+	if !cfg.ReplaceDefaults {
+		templates = append(append([]Template(nil), DefaultTemplates...), cfg.Templates...)
+	}
+
+	for _, tmpl := range templates {
+		compiled := compiledTemplate{name: tmpl.Name, prompt: tmpl.Prompt}
+		var err error
+		if tmpl.Command != "" {
+			if compiled.command, err = regexp.Compile(tmpl.Command); err != nil {
+				return nil, fmt.Errorf("invalid retry template %q command: %w", tmpl.Name, err)
+			}
+		}
+		if compiled.match, err = regexp.Compile(tmpl.Match); err != nil {
+			return nil, fmt.Errorf("invalid retry template %q match: %w", tmpl.Name, err)
+		}
+		m.templates = append(m.templates, compiled)
+	}
+	return m, nil
+}
+
+// Suggest returns a follow-up prompt for every template whose most recent
+// relevant run failed
+func (m *Matcher) Suggest(runs []Run) []Suggestion {
+	if m == nil {
+		return nil
+	}
+	var suggestions []Suggestion
+	for _, tmpl := range m.templates {
+		for i := len(runs) - 1; i >= 0; i-- {
+			run := runs[i]
+			if tmpl.command != nil && !tmpl.command.MatchString(run.Command) {
+				continue
+			}
+			if tmpl.match.MatchString(run.Output) {
+				suggestions = append(suggestions, Suggestion{
+					Name:   tmpl.name,
+					Prompt: strings.ReplaceAll(tmpl.prompt, "{output}", tailLines(run.Output, MaxOutputLines)),
+				})
+			}
+			break
+		}
+	}
+	return suggestions
+}
+
+// tailLines keeps the last n lines of text
+func tailLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = append([]string{"..."}, lines[len(lines)-n:]...)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,149 @@
+// Package review asks claude for code review findings in a structured form
+// and converts them to the SARIF and JUnit XML formats read by code review
+// and CI tools
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Severities of findings, as SARIF levels
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// Finding is one problem reported by a review
+type Finding struct {
+	Rule     string `json:"rule"`     // short identifier, e.g. "sql-injection"
+	Severity string `json:"severity"` // error, warning or note
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	EndLine  int    `json:"end_line,omitempty"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+}
+
+// Prompt returns the review instructions sent by /review, with an optional
+// focus such as "error handling in internal/claude"
+func Prompt(focus string) string {
+	var b strings.Builder
+	b.WriteString("Review the uncommitted changes in this repository")
+	if focus != "" {
+		b.WriteString(", focusing on " + focus)
+	}
+	b.WriteString(". Explain the problems you find, then end your answer with all of them ")
+	b.WriteString("in a single ```json fenced block holding an array of objects with these fields:\n")
+	b.WriteString(`- "rule": a short kebab-case identifier for the kind of problem` + "\n")
+	b.WriteString(`- "severity": "error", "warning" or "note"` + "\n")
+	b.WriteString(`- "file": the path relative to the repository root` + "\n")
+	b.WriteString(`- "line" and "end_line": the lines concerned, when known` + "\n")
+	b.WriteString(`- "title": a one-line summary` + "\n")
+	b.WriteString(`- "message": the explanation and suggested fix` + "\n")
+	b.WriteString("Use an empty array when there is nothing to report.")
+	return b.String()
+}
+
+// jsonBlockPattern matches fenced JSON code blocks
+var jsonBlockPattern = regexp.MustCompile("(?s)```json\\s*\\n(.*?)```")
+
+// Parse extracts the findings from a review answer: the last fenced JSON
+// block holding an array of findings, or an object with a "findings" array.
+// It reports whether such a block was found, as an empty array is a valid
+// review.
+func Parse(text string) ([]Finding, bool, error) {
+	blocks := jsonBlockPattern.FindAllStringSubmatch(text, -1)
+	var lastErr error
+	for i := len(blocks) - 1; i >= 0; i-- {
+		findings, err := parseBlock(blocks[i][1])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return normalize(findings), true, nil
+	}
+	if lastErr != nil {
+		return nil, false, fmt.Errorf("failed to parse findings: %w", lastErr)
+	}
+	return nil, false, nil
+}
+
+// parseBlock decodes an array of findings or a {"findings": [...]} object
+func parseBlock(block string) ([]Finding, error) {
+	block = strings.TrimSpace(block)
+	var findings []Finding
+	if strings.HasPrefix(block, "[") {
+		err := json.Unmarshal([]byte(block), &findings)
+		return findings, err
+	}
+	var wrapped struct {
+		Findings *[]Finding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(block), &wrapped); err != nil {
+		return nil, err
+	}
+	if wrapped.Findings == nil {
+		return nil, fmt.Errorf("no findings array")
+	}
+	return *wrapped.Findings, nil
+}
+
+// normalize fills in defaults so every finding can be exported
+func normalize(findings []Finding) []Finding {
+	for i := range findings {
+		f := &findings[i]
+		switch strings.ToLower(f.Severity) {
+		case SeverityError, "critical", "high":
+			f.Severity = SeverityError
+		case SeverityNote, "info", "low":
+			f.Severity = SeverityNote
+		default:
+			f.Severity = SeverityWarning
+		}
+		if f.Rule == "" {
+			f.Rule = "review"
+		}
+		if f.Title == "" {
+			f.Title, _, _ = strings.Cut(f.Message, "\n")
+		}
+		f.File = filepath.ToSlash(f.File)
+		if f.EndLine < f.Line {
+			f.EndLine = f.Line
+		}
+	}
+	return findings
+}
+
+// Export writes findings to path, as SARIF for .sarif and .sarif.json files
+// and as JUnit XML for .xml files
+func Export(path string, findings []Finding) error {
+	var data []byte
+	var err error
+	switch lower := strings.ToLower(path); {
+	case strings.HasSuffix(lower, ".sarif"), strings.HasSuffix(lower, ".sarif.json"):
+		data, err = SARIF(findings)
+	case strings.HasSuffix(lower, ".xml"):
+		data, err = JUnit(findings)
+	default:
+		return fmt.Errorf("unknown findings format for %s: use .sarif, .sarif.json or .xml", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create export directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write findings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package review
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// JUnit XML report, reduced to the parts findings need
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      string        `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure"`
+	Skipped   *junitSkipped `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnit converts findings to a JUnit XML report with a test case per
+// finding. Errors and warnings are failures; notes are skipped cases, so
+// they show up without failing the build. A review without findings is a
+// single passing case.
+func JUnit(findings []Finding) ([]byte, error) {
+	suite := junitSuite{Name: ToolName}
+	for _, f := range findings {
+		c := junitCase{
+			Name:      f.Rule + ": " + f.Title,
+			Classname: f.File,
+			File:      f.File,
+		}
+		location := f.File
+		if f.Line > 0 {
+			c.Line = strconv.Itoa(f.Line)
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if c.Classname == "" {
+			c.Classname = ToolName
+		}
+
+		if f.Severity == SeverityNote {
+			c.Skipped = &junitSkipped{Message: f.Title}
+			suite.Skipped++
+		} else {
+			text := findingText(f)
+			if location != "" {
+				text = location + "\n\n" + text
+			}
+			c.Failure = &junitFailure{Message: f.Title, Type: f.Severity, Text: text}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	if len(suite.Cases) == 0 {
+		suite.Cases = []junitCase{{Name: "no findings", Classname: ToolName}}
+	}
+	suite.Tests = len(suite.Cases)
+
+	data, err := xml.MarshalIndent(junitSuites{
+		Name:     ToolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JUnit XML: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
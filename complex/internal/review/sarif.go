@@ -0,0 +1,123 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToolName identifies the exporter in SARIF and JUnit reports
+const ToolName = "customclaude-review"
+
+// SARIF 2.1.0 log, reduced to the parts findings need
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// SARIF converts findings to a SARIF 2.1.0 log with one run
+func SARIF(findings []Finding) ([]byte, error) {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: ToolName, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+	for _, f := range findings {
+		index, ok := ruleIndex[f.Rule]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[f.Rule] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               f.Rule,
+				ShortDescription: sarifMessage{Text: f.Title},
+			})
+		}
+
+		result := sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: index,
+			Level:     f.Severity,
+			Message:   sarifMessage{Text: findingText(f)},
+		}
+		if f.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: f.File},
+			}}
+			if f.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line, EndLine: f.EndLine}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// findingText is the full message of a finding: its title and explanation
+func findingText(f Finding) string {
+	switch {
+	case f.Message == "":
+		return f.Title
+	case f.Title == "" || f.Title == f.Message:
+		return f.Message
+	}
+	return f.Title + "\n\n" + f.Message
+}
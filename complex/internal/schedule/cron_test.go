@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+// bits returns the set of the given values
+func bits(values ...int) uint64 {
+	var set uint64
+	for _, v := range values {
+		set |= 1 << v
+	}
+	return set
+}
+
+// span returns the set of the values from lo to hi, every step
+func span(lo, hi, step int) uint64 {
+	var set uint64
+	for v := lo; v <= hi; v += step {
+		set |= 1 << v
+	}
+	return set
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Cron
+	}{
+		{
+			expr: "* * * * *",
+			want: Cron{minute: span(0, 59, 1), hour: span(0, 23, 1), dom: span(1, 31, 1), month: span(1, 12, 1), dow: span(0, 7, 1), domAny: true, dowAny: true},
+		},
+		{
+			expr: "30 2 * * 1-5",
+			want: Cron{minute: bits(30), hour: bits(2), dom: span(1, 31, 1), month: span(1, 12, 1), dow: bits(1, 2, 3, 4, 5), domAny: true},
+		},
+		{
+			expr: "*/15 0-12/4 1,15 1-3,6,9-12/3 *",
+			want: Cron{minute: bits(0, 15, 30, 45), hour: bits(0, 4, 8, 12), dom: bits(1, 15), month: bits(1, 2, 3, 6, 9, 12), dow: span(0, 7, 1), dowAny: true},
+		},
+		{
+			// A value with a step runs to the end of the field
+			expr: "5/20 22/1 */10 */5 */2",
+			want: Cron{minute: bits(5, 25, 45), hour: bits(22, 23), dom: bits(1, 11, 21, 31), month: bits(1, 6, 11), dow: bits(0, 2, 4, 6)},
+		},
+		{
+			// 7 is Sunday as well as 0
+			expr: "0 0 * * 7",
+			want: Cron{minute: bits(0), hour: bits(0), dom: span(1, 31, 1), month: span(1, 12, 1), dow: bits(0, 7), domAny: true},
+		},
+		{
+			expr: "  @daily ",
+			want: Cron{minute: bits(0), hour: bits(0), dom: span(1, 31, 1), month: span(1, 12, 1), dow: span(0, 7, 1), domAny: true, dowAny: true},
+		},
+		{
+			expr: "@weekly",
+			want: Cron{minute: bits(0), hour: bits(0), dom: span(1, 31, 1), month: span(1, 12, 1), dow: bits(0), domAny: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseCron(%q) = %+v, want %+v", tt.expr, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@yearly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"1,,2 * * * *",
+		"-1 * * * *",
+	} {
+		if c, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) = %+v, want an error", expr, *c)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"next minute on the step", "*/15 * * * *", at(2024, 3, 8, 10, 7).Add(30 * time.Second), at(2024, 3, 8, 10, 15)},
+		{"strictly after a match", "0 12 * * *", at(2024, 3, 8, 12, 0), at(2024, 3, 9, 12, 0)},
+		{"hour rolls into the next day", "30 2 * * *", at(2024, 3, 8, 23, 59), at(2024, 3, 9, 2, 30)},
+		{"across the end of a month", "30 2 * * *", at(2024, 1, 31, 3, 0), at(2024, 2, 1, 2, 30)},
+		{"across the end of a year", "0 0 1 * *", at(2024, 12, 15, 10, 0), at(2025, 1, 1, 0, 0)},
+		{"last minute of the year", "59 23 31 12 *", at(2024, 12, 31, 23, 59), at(2025, 12, 31, 23, 59)},
+		{"months without the day are skipped", "0 0 31 * *", at(2024, 4, 1, 0, 0), at(2024, 5, 31, 0, 0)},
+		{"leap day", "0 0 29 2 *", at(2025, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"restricted month", "0 6 1 */6 *", at(2024, 7, 1, 6, 0), at(2025, 1, 1, 6, 0)},
+		{"weekdays from a Friday", "0 9 * * 1-5", at(2024, 3, 8, 10, 0), at(2024, 3, 11, 9, 0)},
+		{"Sunday as 0", "0 0 * * 0", at(2024, 9, 11, 0, 0), at(2024, 9, 15, 0, 0)},
+		{"Sunday as 7", "0 0 * * 7", at(2024, 9, 11, 0, 0), at(2024, 9, 15, 0, 0)},
+		{"day of month only", "0 0 10 * *", at(2024, 9, 11, 0, 0), at(2024, 10, 10, 0, 0)},
+		// With both day fields restricted either one matches
+		{"day of month before day of week", "0 0 10 * 5", at(2024, 9, 7, 0, 0), at(2024, 9, 10, 0, 0)},
+		{"day of week before day of month", "0 0 10 * 5", at(2024, 9, 11, 0, 0), at(2024, 9, 13, 0, 0)},
+		{"day of week across a month", "0 0 1 * 1", at(2024, 9, 30, 0, 0), at(2024, 10, 1, 0, 0)},
+		{"never", "0 0 30 2 *", at(2024, 1, 1, 0, 0), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) of %q = %s, want %s", tt.from, tt.expr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"complex/internal/logging"
+)
+
+// Job is a prompt run on a schedule, configured as a [[schedule]] entry
+type Job struct {
+	Name   string `toml:"name"`
+	Cron   string `toml:"cron"` // e.g. "0 2 * * *" or "@nightly"
+	Prompt string `toml:"prompt"`
+	Model  string `toml:"model"` // empty for the configured model
+	// ReadOnly runs in plan mode without write tools; nobody is around to
+	// approve tool use in daemon mode
+	ReadOnly bool `toml:"read_only"`
+}
+
+// RunFunc runs one job
+type RunFunc func(ctx context.Context, job Job) error
+
+// Scheduler runs jobs when their schedules come due. Jobs run one at a time;
+// a run that overlaps a later due time delays it rather than stacking runs.
+type Scheduler struct {
+	jobs  []Job
+	crons []*Cron
+	run   RunFunc
+	log   *slog.Logger
+}
+
+// New checks the jobs and their schedules
+func New(jobs []Job, run RunFunc) (*Scheduler, error) {
+	s := &Scheduler{jobs: jobs, run: run, log: logging.For("schedule")}
+	names := make(map[string]bool)
+	for i, job := range jobs {
+		if job.Name == "" {
+			return nil, fmt.Errorf("schedule %d has no name", i+1)
+		}
+		if names[job.Name] {
+			return nil, fmt.Errorf("schedule %q is defined twice", job.Name)
+		}
+		names[job.Name] = true
+		if job.Prompt == "" {
+			return nil, fmt.Errorf("schedule %q has no prompt", job.Name)
+		}
+		cron, err := ParseCron(job.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", job.Name, err)
+		}
+		s.crons = append(s.crons, cron)
+	}
+	return s, nil
+}
+
+// NextRuns returns the next due time of every job after t
+func (s *Scheduler) NextRuns(t time.Time) map[string]time.Time {
+	next := make(map[string]time.Time, len(s.jobs))
+	for i, job := range s.jobs {
+		next[job.Name] = s.crons[i].Next(t)
+	}
+	return next
+}
+
+// Run runs due jobs until ctx is cancelled. Failed runs are logged and do
+// not stop the scheduler.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.jobs) == 0 {
+		return fmt.Errorf("no schedules configured")
+	}
+
+	due := make([]time.Time, len(s.jobs))
+	now := time.Now()
+	for i, cron := range s.crons {
+		due[i] = cron.Next(now)
+		s.log.Info("scheduled", "job", s.jobs[i].Name, "next", due[i])
+	}
+
+	for {
+		// Wait for the earliest due job
+		next := -1
+		for i, t := range due {
+			if !t.IsZero() && (next < 0 || t.Before(due[next])) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return fmt.Errorf("no schedule will ever run again")
+		}
+
+		timer := time.NewTimer(time.Until(due[next]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		job := s.jobs[next]
+		s.log.Info("running scheduled prompt", "job", job.Name)
+		started := time.Now()
+		if err := s.run(ctx, job); err != nil {
+			s.log.Error("scheduled run failed", "job", job.Name, "err", err)
+		} else {
+			s.log.Info("scheduled run finished", "job", job.Name, "elapsed", time.Since(started))
+		}
+		due[next] = s.crons[next].Next(time.Now())
+	}
+}
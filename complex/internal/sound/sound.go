@@ -0,0 +1,95 @@
+package sound
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cue identifies an event that can be announced with a sound
+type Cue string
+
+const (
+	CueTurnComplete Cue = "turn_complete"
+	CueError        Cue = "error"
+	CueApproval     Cue = "approval"
+)
+
+// bellCounts makes the terminal bell fallback distinguishable per cue
+var bellCounts = map[Cue]int{
+	CueTurnComplete: 1,
+	CueError:        2,
+	CueApproval:     3,
+}
+
+// minInterval suppresses bursts of the same cue (e.g. a flood of errors)
+const minInterval = time.Second
+
+// Config controls which cues play and how
+type Config struct {
+	Enabled bool           `json:"enabled" toml:"enabled"`
+	Player  string         `json:"player,omitempty" toml:"player"` // external command, e.g. "paplay" or "afplay"
+	Sounds  map[Cue]string `json:"sounds,omitempty" toml:"sounds"` // sound file per cue, passed to Player
+}
+
+// Player plays cues according to its Config
+type Player struct {
+	cfg      Config
+	out      io.Writer
+	mutex    sync.Mutex
+	lastPlay map[Cue]time.Time
+}
+
+// NewPlayer creates a player. The terminal bell is written to stderr so it
+// does not interleave with the TUI renderer on stdout.
+func NewPlayer(cfg Config) *Player {
+	return &Player{
+		cfg:      cfg,
+		out:      os.Stderr,
+		lastPlay: make(map[Cue]time.Time),
+	}
+}
+
+// SetConfig replaces the player configuration
+func (p *Player) SetConfig(cfg Config) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cfg = cfg
+}
+
+// Play announces a cue. It never blocks on the external player.
+func (p *Player) Play(cue Cue) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.cfg.Enabled {
+		return
+	}
+	if last, ok := p.lastPlay[cue]; ok && time.Since(last) < minInterval {
+		return
+	}
+	p.lastPlay[cue] = time.Now()
+
+	if file := p.cfg.Sounds[cue]; p.cfg.Player != "" && file != "" {
+		fields := strings.Fields(p.cfg.Player)
+		cmd := exec.Command(fields[0], append(fields[1:], file)...)
+		if err := cmd.Start(); err == nil {
+			go cmd.Wait()
+			return
+		}
+	}
+
+	// Space the bells out so terminals don't merge them into one
+	out, count := p.out, bellCounts[cue]
+	go func() {
+		for i := 0; i < count; i++ {
+			if i > 0 {
+				time.Sleep(150 * time.Millisecond)
+			}
+			out.Write([]byte("\a"))
+		}
+	}()
+}
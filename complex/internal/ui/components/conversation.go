@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
 
 	"complex/internal/claude"
@@ -11,11 +12,78 @@ import (
 
 // ConversationComponent handles the display of conversation messages
 type ConversationComponent struct {
-	messages  []claude.ConversationMessage
-	width     int
-	height    int
-	scrollPos int
-	styles    *ConversationStyles
+	FollowMode
+
+	messages []claude.ConversationMessage
+	width    int
+	height   int
+	styles   *ConversationStyles
+
+	// viewport scrolls the rendered message lines; stale is set when the
+	// messages or size changed since they were rendered
+	viewport viewport.Model
+	stale    bool
+}
+
+// FollowMode decides whether a conversation view stays pinned to its live
+// tail as messages stream in. The zero value follows. While paused the
+// viewport is frozen for reading and arriving messages are counted.
+type FollowMode struct {
+	paused bool
+	unseen int
+}
+
+// Following reports whether the view is pinned to the tail
+func (f *FollowMode) Following() bool {
+	return !f.paused
+}
+
+// ToggleFollow switches between following and paused, returning whether
+// the view now follows
+func (f *FollowMode) ToggleFollow() bool {
+	if f.paused {
+		f.FollowTail()
+	} else {
+		f.PauseFollow()
+	}
+	return !f.paused
+}
+
+// FollowTail pins the view to the tail again; the caller scrolls there
+func (f *FollowMode) FollowTail() {
+	f.paused = false
+	f.unseen = 0
+}
+
+// PauseFollow freezes the view where it is
+func (f *FollowMode) PauseFollow() {
+	f.paused = true
+}
+
+// ContentArrived records content added to the conversation and reports
+// whether the view should scroll to the tail. newMessage is false for text
+// streamed into a message already shown.
+func (f *FollowMode) ContentArrived(newMessage bool) bool {
+	if !f.paused {
+		return true
+	}
+	if newMessage {
+		f.unseen++
+	}
+	return false
+}
+
+// Unseen is the number of messages that arrived while paused
+func (f *FollowMode) Unseen() int {
+	return f.unseen
+}
+
+// FollowLabel names the active mode for an indicator
+func (f *FollowMode) FollowLabel() string {
+	if f.paused {
+		return "⏸ Paused"
+	}
+	return "▶ Following"
 }
 
 // ConversationStyles contains styling for conversation display
@@ -80,24 +148,29 @@ func NewConversationComponent() *ConversationComponent {
 func (cc *ConversationComponent) SetDimensions(width, height int) {
 	cc.width = width
 	cc.height = height
+	cc.stale = true
 }
 
 // AddMessage adds a new message to the conversation
 func (cc *ConversationComponent) AddMessage(message claude.ConversationMessage) {
 	cc.messages = append(cc.messages, message)
 
-	// Auto-scroll to bottom when new message is added
-	cc.ScrollToBottom()
-
 	// Limit message history to prevent memory issues
 	if len(cc.messages) > 1000 {
 		cc.messages = cc.messages[len(cc.messages)-1000:]
 	}
+	cc.stale = true
+
+	// Auto-scroll to bottom when following the tail
+	if cc.ContentArrived(true) {
+		cc.ScrollToBottom()
+	}
 }
 
 // SetMessages sets the entire message list
 func (cc *ConversationComponent) SetMessages(messages []claude.ConversationMessage) {
 	cc.messages = append([]claude.ConversationMessage(nil), messages...)
+	cc.stale = true
 	cc.ScrollToBottom()
 }
 
@@ -108,94 +181,79 @@ func (cc *ConversationComponent) GetMessages() []claude.ConversationMessage {
 
 // ScrollUp scrolls up by one line
 func (cc *ConversationComponent) ScrollUp() {
-	if cc.scrollPos > 0 {
-		cc.scrollPos--
+	cc.refresh()
+	if !cc.viewport.AtTop() {
+		cc.viewport.ScrollUp(1)
+		cc.PauseFollow()
 	}
 }
 
 // ScrollDown scrolls down by one line
 func (cc *ConversationComponent) ScrollDown() {
-	maxScroll := cc.getMaxScrollPosition()
-	if cc.scrollPos < maxScroll {
-		cc.scrollPos++
-	}
+	cc.refresh()
+	cc.viewport.ScrollDown(1)
+	cc.followAtBottom()
 }
 
 // ScrollPageUp scrolls up by one page
 func (cc *ConversationComponent) ScrollPageUp() {
-	pageSize := cc.height - 2
-	cc.scrollPos = max(0, cc.scrollPos-pageSize)
+	cc.refresh()
+	if !cc.viewport.AtTop() {
+		cc.viewport.PageUp()
+		cc.PauseFollow()
+	}
 }
 
 // ScrollPageDown scrolls down by one page
 func (cc *ConversationComponent) ScrollPageDown() {
-	pageSize := cc.height - 2
-	maxScroll := cc.getMaxScrollPosition()
-	cc.scrollPos = min(maxScroll, cc.scrollPos+pageSize)
+	cc.refresh()
+	cc.viewport.PageDown()
+	cc.followAtBottom()
 }
 
 // ScrollToTop scrolls to the top of the conversation
 func (cc *ConversationComponent) ScrollToTop() {
-	cc.scrollPos = 0
-}
-
-// ScrollToBottom scrolls to the bottom of the conversation
-func (cc *ConversationComponent) ScrollToBottom() {
-	cc.scrollPos = cc.getMaxScrollPosition()
-}
-
-// getMaxScrollPosition calculates the maximum scroll position
-func (cc *ConversationComponent) getMaxScrollPosition() int {
-	if cc.height == 0 {
-		return 0
+	cc.refresh()
+	if !cc.viewport.AtTop() {
+		cc.viewport.GotoTop()
+		cc.PauseFollow()
 	}
-
-	totalLines := cc.getTotalLines()
-	visibleLines := cc.height - 2 // Account for padding
-
-	maxScroll := totalLines - visibleLines
-	if maxScroll < 0 {
-		maxScroll = 0
-	}
-
-	return maxScroll
 }
 
-// getTotalLines calculates total lines needed for all messages
-func (cc *ConversationComponent) getTotalLines() int {
-	if cc.width == 0 {
-		return 0
-	}
-
-	totalLines := 0
-	contentWidth := cc.width - 4 // Account for padding and borders
-
-	for _, msg := range cc.messages {
-		lines := cc.calculateMessageLines(msg, contentWidth)
-		totalLines += lines + 1 // +1 for spacing between messages
+// followAtBottom follows the tail again once scrolled back to it
+func (cc *ConversationComponent) followAtBottom() {
+	if cc.viewport.AtBottom() {
+		cc.FollowTail()
 	}
+}
 
-	return totalLines
+// ScrollToBottom scrolls to the bottom of the conversation
+func (cc *ConversationComponent) ScrollToBottom() {
+	cc.refresh()
+	cc.viewport.GotoBottom()
 }
 
-// calculateMessageLines calculates how many lines a message will take
-func (cc *ConversationComponent) calculateMessageLines(
-	msg claude.ConversationMessage,
-	width int,
-) int {
-	if width <= 0 {
-		return 1
+// refresh renders the messages into the viewport if they or the size
+// changed. The container's padding takes two columns on each side and a
+// row above and below.
+func (cc *ConversationComponent) refresh() {
+	if !cc.stale {
+		return
 	}
-
-	// Header line (user/assistant indicator + timestamp)
-	lines := 1
-
-	// Content lines
-	content := msg.Content
-	contentLines := strings.Split(wordWrap(content, width-2), "\n") // -2 for message prefix
-	lines += len(contentLines)
-
-	return lines
+	cc.stale = false
+
+	contentWidth := max(1, cc.width-4)
+	var lines []string
+	for i, msg := range cc.messages {
+		lines = append(lines, strings.Split(cc.renderMessage(msg, contentWidth), "\n")...)
+		if i < len(cc.messages)-1 {
+			lines = append(lines, "") // spacing between messages
+		}
+	}
+	cc.viewport.Width = contentWidth
+	cc.viewport.Height = max(1, cc.height-2)
+	cc.viewport.SetContent(strings.Join(lines, "\n"))
+	cc.viewport.SetYOffset(cc.viewport.YOffset)
 }
 
 // Render renders the conversation component
@@ -214,84 +272,13 @@ func (cc *ConversationComponent) Render() string {
 			Render(emptyMsg)
 	}
 
-	content := cc.renderVisibleMessages()
-
+	cc.refresh()
 	return cc.styles.Container.
 		Width(cc.width).
 		Height(cc.height).
-		Render(content)
+		Render(cc.viewport.View())
 }
 
-func (cc *ConversationComponent) renderVisibleMessages() string {
-	if len(cc.messages) == 0 {
-		return ""
-	}
-
-	contentWidth := cc.width - 4  // Account for padding
-	visibleLines := cc.height - 2 // Number of lines to show
-
-	var allLines []string
-
-	for _, msg := range cc.messages {
-		msgLines := cc.renderMessage(msg, contentWidth)
-		lines := strings.Split(msgLines, "\n")
-		allLines = append(allLines, lines...)
-		allLines = append(allLines, "") // spacing between messages
-	}
-
-	// Clamp scroll position
-	if cc.scrollPos > len(allLines)-visibleLines {
-		cc.scrollPos = max(0, len(allLines)-visibleLines)
-	}
-
-	end := cc.scrollPos + visibleLines
-	if end > len(allLines) {
-		end = len(allLines)
-	}
-
-	visible := allLines[cc.scrollPos:end]
-	return strings.Join(visible, "\n")
-}
-
-// renderVisibleMessages renders only the messages that should be visible
-// func (cc *ConversationComponent) renderVisibleMessages() string {
-// 	if len(cc.messages) == 0 {
-// 		return ""
-// 	}
-//
-// 	contentWidth := cc.width - 4 // Account for padding
-// 	visibleLines := cc.height - 2 // Account for container padding
-//
-// 	var renderedLines []string
-// 	currentLine := 0
-//
-// 	// Calculate which messages to show based on scroll position
-// 	for _, msg := range cc.messages {
-// 		msgLines := cc.renderMessage(msg, contentWidth)
-// 		msgLinesList := strings.Split(msgLines, "\n")
-//
-// 		for _, line := range msgLinesList {
-// 			if currentLine >= cc.scrollPos && len(renderedLines) < visibleLines {
-// 				renderedLines = append(renderedLines, line)
-// 			}
-// 			currentLine++
-// 		}
-//
-// 		// Add spacing between messages
-// 		if currentLine >= cc.scrollPos && len(renderedLines) < visibleLines {
-// 			renderedLines = append(renderedLines, "")
-// 		}
-// 		currentLine++
-//
-// 		// Break if we have enough lines
-// 		if len(renderedLines) >= visibleLines {
-// 			break
-// 		}
-// 	}
-//
-// 	return strings.Join(renderedLines, "\n")
-// }
-
 // renderMessage renders a single message
 func (cc *ConversationComponent) renderMessage(msg claude.ConversationMessage, width int) string {
 	var style lipgloss.Style
@@ -354,18 +341,20 @@ func (cc *ConversationComponent) renderMessage(msg claude.ConversationMessage, w
 }
 
 // GetScrollInfo returns current scroll information for display
-func (cc *ConversationComponent) GetScrollInfo() (current, max int) {
-	return cc.scrollPos, cc.getMaxScrollPosition()
+func (cc *ConversationComponent) GetScrollInfo() (current, maxScroll int) {
+	cc.refresh()
+	return cc.viewport.YOffset, max(0, cc.viewport.TotalLineCount()-cc.viewport.Height)
 }
 
 // IsAtBottom returns true if scrolled to bottom
 func (cc *ConversationComponent) IsAtBottom() bool {
-	return cc.scrollPos >= cc.getMaxScrollPosition()
+	cc.refresh()
+	return cc.viewport.AtBottom()
 }
 
 // IsAtTop returns true if scrolled to top
 func (cc *ConversationComponent) IsAtTop() bool {
-	return cc.scrollPos <= 0
+	return cc.viewport.AtTop()
 }
 
 // Helper functions
@@ -383,44 +372,7 @@ func max(a, b int) int {
 	return b
 }
 
-// wordWrap wraps text to fit within the specified width
+// wordWrap wraps text to fit within the specified display width
 func wordWrap(text string, width int) string {
-	if width <= 0 {
-		return text
-	}
-
-	if len(text) <= width {
-		return text
-	}
-
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return text
-	}
-
-	var result []string
-	var currentLine strings.Builder
-
-	for _, word := range words {
-		// If adding this word would exceed width, start new line
-		if currentLine.Len()+len(word)+1 > width {
-			if currentLine.Len() > 0 {
-				result = append(result, currentLine.String())
-				currentLine.Reset()
-			}
-		}
-
-		// Add word to current line
-		if currentLine.Len() > 0 {
-			currentLine.WriteString(" ")
-		}
-		currentLine.WriteString(word)
-	}
-
-	// Add final line if not empty
-	if currentLine.Len() > 0 {
-		result = append(result, currentLine.String())
-	}
-
-	return strings.Join(result, "\n")
+	return WrapText(text, width)
 }
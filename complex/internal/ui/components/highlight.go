@@ -0,0 +1,143 @@
+package components
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// highlightCacheSize bounds the highlighted snippets kept between renders
+const highlightCacheSize = 256
+
+// codeStyles maps markdown themes to the chroma style used for code, so code
+// outside markdown matches code blocks inside it. Themes without colors map
+// to "".
+var codeStyles = map[string]string{
+	"dark":        "monokai",
+	"light":       "github",
+	"dracula":     "dracula",
+	"tokyo-night": "tokyonight-night",
+	"pink":        "monokai",
+	"ascii":       "",
+	"notty":       "",
+}
+
+// Highlighter colors source code for the terminal with chroma. The view is
+// rendered on every update, so results are cached.
+type Highlighter struct {
+	style *chroma.Style // nil disables highlighting
+	cache map[string][]string
+}
+
+// NewHighlighter creates a highlighter for a markdown theme name; custom
+// style files get the dark theme's colors
+func NewHighlighter(theme string) *Highlighter {
+	name, ok := codeStyles[theme]
+	if !ok {
+		name = codeStyles["dark"]
+	}
+	h := &Highlighter{cache: make(map[string][]string)}
+	if name != "" {
+		h.style = styles.Get(name)
+	}
+	return h
+}
+
+// Highlight returns code as colored lines. language is a language name,
+// alias, file extension or file name; code in an unknown language, or with
+// highlighting disabled, is returned as is.
+func (h *Highlighter) Highlight(code, language string) []string {
+	if h == nil || h.style == nil || language == "" {
+		return strings.Split(code, "\n")
+	}
+
+	key := language + "\x00" + code
+	if lines, ok := h.cache[key]; ok {
+		return lines
+	}
+	lines := h.highlight(code, language)
+	if len(h.cache) >= highlightCacheSize {
+		clear(h.cache)
+	}
+	h.cache[key] = lines
+	return lines
+}
+
+// highlight lexes and formats code, falling back to the plain lines
+func (h *Highlighter) highlight(code, language string) []string {
+	plain := strings.Split(code, "\n")
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Match(filepath.Base(language))
+	}
+	if lexer == nil {
+		return plain
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return plain
+	}
+
+	// Format line by line so no color runs across a line break, where panels
+	// and wrapping would cut it
+	var lines []string
+	for _, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		if last := len(tokens) - 1; last >= 0 {
+			tokens[last].Value = strings.TrimSuffix(tokens[last].Value, "\n")
+		}
+		var buf bytes.Buffer
+		if err := formatters.TTY256.Format(&buf, h.style, chroma.Literator(tokens...)); err != nil {
+			return plain
+		}
+		lines = append(lines, buf.String())
+	}
+	for len(lines) < len(plain) {
+		lines = append(lines, "")
+	}
+	return lines[:len(plain)]
+}
+
+// Segment is a run of text that is either prose or a fenced code block
+type Segment struct {
+	Text     string // the code without its fences, or the prose
+	Language string // from the opening fence
+	Code     bool
+}
+
+// SplitFences splits text into prose and ``` or ~~~ fenced code blocks. An
+// unclosed block runs to the end of the text, as it does while streaming.
+func SplitFences(text string) []Segment {
+	var segments []Segment
+	var current []string
+	fence := ""
+	language := ""
+
+	flush := func(code bool) {
+		if len(current) > 0 || code {
+			segments = append(segments, Segment{Text: strings.Join(current, "\n"), Language: language, Code: code})
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			flush(false)
+			fence = trimmed[:3]
+			language, _, _ = strings.Cut(strings.TrimSpace(trimmed[3:]), " ")
+		case fence != "" && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "":
+			flush(true)
+			fence, language = "", ""
+		default:
+			current = append(current, line)
+		}
+	}
+	flush(fence != "")
+	return segments
+}
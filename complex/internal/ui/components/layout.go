@@ -1,79 +1,96 @@
 package components
 
+// DefaultSidebarWidth is the side panel's width unless configured
+const DefaultSidebarWidth = 30
+
 // LayoutManager centralizes layout calculations and constraints
 type LayoutManager struct {
-    width               int
-    height              int
-    headerFooterMargin  int // combined header, footer, margins (existing code uses 4)
-    panelPaddingMargin  int // extra padding/margins inside panels (existing code used -4)
-    sidebarWidthTotal   int // total sidebar reservation (style width + margins)
-    scrollIndicatorLines int // reserved lines for scroll indicator
+	width                int
+	height               int
+	headerFooterMargin   int // combined header, footer, margins (existing code uses 4)
+	panelPaddingMargin   int // extra padding/margins inside panels (existing code used -4)
+	sidebarWidthTotal    int // total sidebar reservation (style width + margins), 0 when collapsed
+	scrollIndicatorLines int // reserved lines for scroll indicator
 }
 
 // NewLayoutManager creates a new layout manager with defaults matching current UI
 func NewLayoutManager(width, height int) *LayoutManager {
-    return &LayoutManager{
-        width:                width,
-        height:               height,
-        headerFooterMargin:   4,  // from renderMainView: contentHeight := a.height - 4
-        panelPaddingMargin:   4,  // renderConversationPanel called with height-4
-        sidebarWidthTotal:    35, // leftWidth := a.width - 35
-        scrollIndicatorLines: 2,  // reserved for scroll status
-    }
+	return &LayoutManager{
+		width:                width,
+		height:               height,
+		headerFooterMargin:   4,  // from renderMainView: contentHeight := a.height - 4
+		panelPaddingMargin:   4,  // renderConversationPanel called with height-4
+		sidebarWidthTotal:    35, // leftWidth := a.width - 35
+		scrollIndicatorLines: 2,  // reserved for scroll status
+	}
+}
+
+// SetSidebarWidth sets the side panel's style width; 0 collapses it and the
+// conversation takes its place
+func (lm *LayoutManager) SetSidebarWidth(width int) {
+	if width <= 0 {
+		lm.sidebarWidthTotal = 0
+		return
+	}
+	lm.sidebarWidthTotal = width + 5
 }
 
 // CalculatePanelDimensions returns the sizes to use for panels
 func (lm *LayoutManager) CalculatePanelDimensions() PanelDimensions {
-    // Available height for the main content area
-    contentHeight := lm.height - lm.headerFooterMargin
-    // Panel heights - let caller subtract padding as needed (app.go does height-4)
-    panelHeight := contentHeight
+	// Available height for the main content area
+	contentHeight := lm.height - lm.headerFooterMargin
+	// Panel heights - let caller subtract padding as needed (app.go does height-4)
+	panelHeight := contentHeight
 
-    // Widths: conversation takes remaining width after sidebar reservation
-    convWidth := lm.width - lm.sidebarWidthTotal
-    if convWidth < 1 {
-        convWidth = 1
-    }
+	// Widths: conversation takes remaining width after sidebar reservation.
+	// Collapsed, it spans the screen less its own border and margin.
+	convWidth := lm.width - lm.sidebarWidthTotal
+	if lm.sidebarWidthTotal == 0 {
+		convWidth = lm.width - 4
+	}
+	if convWidth < 1 {
+		convWidth = 1
+	}
 
-    // Sidebar style sets Width(30); we reserve 35 in total to include spacing
-    sidebarWidth := lm.sidebarWidthTotal
+	// Sidebar style width plus 5 columns of spacing (30 reserves 35)
+	sidebarWidth := lm.sidebarWidthTotal
 
-    if panelHeight < 1 {
-        panelHeight = 1
-    }
+	if panelHeight < 1 {
+		panelHeight = 1
+	}
 
-    return PanelDimensions{
-        ConversationWidth:  convWidth,
-        ConversationHeight: panelHeight,
-        SidebarWidth:       sidebarWidth,
-        SidebarHeight:      panelHeight,
-    }
+	return PanelDimensions{
+		ConversationWidth:  convWidth,
+		ConversationHeight: panelHeight,
+		SidebarWidth:       sidebarWidth,
+		SidebarHeight:      panelHeight,
+	}
 }
 
 // GetConversationConstraints computes rendering constraints for the conversation area
 func (lm *LayoutManager) GetConversationConstraints() ConversationConstraints {
-    dims := lm.CalculatePanelDimensions()
-    // Inner content height for conversation (match renderConversationPanel: height-4)
-    inner := dims.ConversationHeight - lm.panelPaddingMargin
-    if inner < 1 {
-        inner = 1
-    }
-    viewport := inner - lm.scrollIndicatorLines
-    if viewport < 1 {
-        viewport = 1
-    }
-    return ConversationConstraints{
-        MaxHeight:          inner,
-        ViewportHeight:     viewport,
-        ScrollSpaceHeight:  lm.scrollIndicatorLines,
-        ConversationWidth:  dims.ConversationWidth,
-        ConversationHeight: dims.ConversationHeight,
-    }
+	dims := lm.CalculatePanelDimensions()
+	// Inner content height for conversation (match renderConversationPanel: height-4)
+	inner := dims.ConversationHeight - lm.panelPaddingMargin
+	if inner < 1 {
+		inner = 1
+	}
+	viewport := inner - lm.scrollIndicatorLines
+	if viewport < 1 {
+		viewport = 1
+	}
+	return ConversationConstraints{
+		MaxHeight:          inner,
+		ViewportHeight:     viewport,
+		ScrollSpaceHeight:  lm.scrollIndicatorLines,
+		ConversationWidth:  dims.ConversationWidth,
+		ConversationHeight: dims.ConversationHeight,
+	}
 }
 
 // ValidatePanelHeights ensures panels do not exceed allocated heights
 func (lm *LayoutManager) ValidatePanelHeights(panels []PanelContent) error {
-    // Minimal validation placeholder; can be expanded to detailed checks
-    // Currently no-op to avoid introducing new error paths before full integration
-    return nil
+	// Minimal validation placeholder; can be expanded to detailed checks
+	// Currently no-op to avoid introducing new error paths before full integration
+	return nil
 }
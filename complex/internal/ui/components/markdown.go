@@ -1,20 +1,30 @@
 package components
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // MarkdownRenderer wraps glamour for consistent markdown rendering
 type MarkdownRenderer struct {
 	renderer *glamour.TermRenderer
 	width    int
+	style    string
 }
 
 // NewMarkdownRenderer creates a new markdown renderer with custom styling
 func NewMarkdownRenderer(width int) (*MarkdownRenderer, error) {
 	// Use the dark style as base and customize it
+	return NewMarkdownRendererWithStyle(width, "dark")
+}
+
+// NewMarkdownRendererWithStyle creates a markdown renderer using a glamour
+// style name ("dark", "light", "notty", ...) or style file path
+func NewMarkdownRendererWithStyle(width int, style string) (*MarkdownRenderer, error) {
 	renderer, err := glamour.NewTermRenderer(
-		glamour.WithStylePath("dark"),
+		glamour.WithStylePath(style),
 		glamour.WithWordWrap(width),
 		glamour.WithEmoji(),
 	)
@@ -25,12 +35,38 @@ func NewMarkdownRenderer(width int) (*MarkdownRenderer, error) {
 	return &MarkdownRenderer{
 		renderer: renderer,
 		width:    width,
+		style:    style,
 	}, nil
 }
 
-// Render renders markdown content to styled terminal output
+// Render renders markdown content to styled terminal output. Glamour only
+// breaks lines at spaces, so lines it leaves too wide (unbroken CJK runs,
+// long URLs) are hard-wrapped, and RTL content is right-aligned.
 func (mr *MarkdownRenderer) Render(content string) (string, error) {
-	return mr.renderer.Render(content)
+	rendered, err := mr.renderer.Render(content)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		if ansi.StringWidth(line) > mr.width {
+			lines[i] = ansi.Hardwrap(line, mr.width, true)
+		}
+	}
+	rendered = strings.Join(lines, "\n")
+
+	return AlignText(rendered, mr.width, DetectScript(content)), nil
+}
+
+// Width returns the column width output is wrapped at
+func (mr *MarkdownRenderer) Width() int {
+	return mr.width
+}
+
+// Style returns the glamour style name or path the renderer uses
+func (mr *MarkdownRenderer) Style() string {
+	return mr.style
 }
 
 // UpdateWidth updates the renderer width for responsive display
@@ -40,7 +76,7 @@ func (mr *MarkdownRenderer) UpdateWidth(width int) error {
 	}
 
 	// Recreate renderer with new width
-	newRenderer, err := NewMarkdownRenderer(width)
+	newRenderer, err := NewMarkdownRendererWithStyle(width, mr.style)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,27 @@
+package components
+
+import "strings"
+
+// sparkBlocks are the bar heights of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of bars scaled to the largest one. Zero
+// and negative values get the lowest bar.
+func Sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if peak > 0 && v > 0 {
+			level = min(len(sparkBlocks)-1, int(v/peak*float64(len(sparkBlocks)-1)+0.5))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
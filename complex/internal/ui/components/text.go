@@ -0,0 +1,207 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Script is the dominant writing system of a message, used to pick wrapping
+// and alignment rules
+type Script int
+
+const (
+	ScriptLatin Script = iota
+	ScriptCJK
+	ScriptRTL
+)
+
+// DetectScript classifies text by counting letters per writing system. Text is
+// RTL when right-to-left letters outnumber the others, and CJK when ideographs
+// or kana make up a meaningful share of it.
+func DetectScript(text string) Script {
+	var cjk, rtl, other int
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			cjk++
+		case isRTL(r):
+			rtl++
+		case unicode.IsLetter(r):
+			other++
+		}
+	}
+
+	switch {
+	case rtl > 0 && rtl >= other && rtl >= cjk:
+		return ScriptRTL
+	case cjk > 0 && cjk*4 >= other:
+		// A single ideograph carries roughly a word, so weigh them up
+		return ScriptCJK
+	}
+	return ScriptLatin
+}
+
+// isCJK reports whether r is a character that may be broken on either side
+// when wrapping
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJK punctuation
+		(r >= 0xFF00 && r <= 0xFFEF) // Fullwidth forms
+}
+
+// isRTL reports whether r belongs to a right-to-left script
+func isRTL(r rune) bool {
+	return unicode.In(r, unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko)
+}
+
+// WrapText wraps text to the given display width. Lines are wrapped one by
+// one, so line breaks in the text are kept along with each line's
+// indentation. Widths are measured in terminal cells with ANSI escapes
+// taking none, so wide CJK characters and emoji count double and styled
+// text wraps where it is seen; runs of CJK text are broken between
+// characters since they contain no spaces, and words longer than the width
+// are split without losing their styling.
+func WrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapLine wraps a single line of text to width cells
+func wrapLine(text string, width int) string {
+	if ansi.StringWidth(text) <= width {
+		return text
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return text
+	}
+
+	var result []string
+	var line strings.Builder
+	lineWidth := 0
+
+	// Keep the indentation of the line, as long as it leaves room for text
+	if indent := text[:len(text)-len(strings.TrimLeft(text, " \t"))]; ansi.StringWidth(indent) < width/2 {
+		line.WriteString(indent)
+		lineWidth = ansi.StringWidth(indent)
+	}
+	indented := lineWidth > 0
+
+	flush := func() {
+		result = append(result, line.String())
+		line.Reset()
+		lineWidth = 0
+		indented = false
+	}
+
+	for _, word := range words {
+		for i, seg := range wrapSegments(word) {
+			segWidth := ansi.StringWidth(seg)
+			afterIndent := indented
+			indented = false
+			space := i == 0 && lineWidth > 0 && !afterIndent
+
+			need := segWidth
+			if space {
+				need++
+			}
+			if lineWidth > 0 && lineWidth+need > width {
+				if afterIndent {
+					// Drop the indentation rather than leave it on a line
+					// of its own
+					line.Reset()
+					lineWidth = 0
+				} else {
+					flush()
+				}
+				space = false
+			}
+			if space {
+				line.WriteByte(' ')
+				lineWidth++
+			}
+
+			// Split segments that cannot fit on a line of their own
+			if lineWidth == 0 && segWidth > width {
+				pieces := strings.Split(ansi.Hardwrap(seg, width, true), "\n")
+				result = append(result, pieces[:len(pieces)-1]...)
+				seg = pieces[len(pieces)-1]
+				segWidth = ansi.StringWidth(seg)
+			}
+
+			line.WriteString(seg)
+			lineWidth += segWidth
+		}
+	}
+
+	if lineWidth > 0 {
+		flush()
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// wrapSegments splits a word into the pieces a line may break between: each
+// CJK character on its own, everything else kept together
+func wrapSegments(word string) []string {
+	var segs []string
+	start := 0
+	for i, r := range word {
+		if !isCJK(r) {
+			continue
+		}
+		if i > start {
+			segs = append(segs, word[start:i])
+		}
+		end := i + len(string(r))
+		segs = append(segs, word[i:end])
+		start = end
+	}
+	if start < len(word) {
+		segs = append(segs, word[start:])
+	}
+	return segs
+}
+
+// AlignText right-aligns each line of RTL text within width and leaves other
+// scripts untouched. Lines may contain ANSI styling.
+func AlignText(text string, width int, script Script) string {
+	if script != ScriptRTL || width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		visible := strings.TrimRight(ansi.Strip(line), " ")
+		if visible == "" {
+			continue
+		}
+		lineWidth := ansi.StringWidth(visible)
+		if lineWidth >= width {
+			continue
+		}
+		lines[i] = strings.Repeat(" ", width-lineWidth) + ansi.Truncate(line, lineWidth, "") + ansi.ResetStyle
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Truncate shortens s to at most maxWidth cells, ending it with "..." when cut.
+// It never splits a multi-byte character.
+func Truncate(s string, maxWidth int) string {
+	if ansi.StringWidth(s) <= maxWidth {
+		return s
+	}
+	if maxWidth <= 3 {
+		return ansi.Truncate(s, maxWidth, "")
+	}
+	return ansi.Truncate(s, maxWidth, "...")
+}
@@ -0,0 +1,283 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"complex/internal/logging"
+)
+
+// Version is the release the running build was made from, stamped with
+//
+//	go build -ldflags "-X complex/internal/update.Version=v1.2.0" ./cmd
+var Version = ""
+
+// DefaultRepo is the GitHub repository publishing the releases
+const DefaultRepo = "TheLazyLemur/cc-custom-integration"
+
+// DefaultCommand upgrades a source checkout; {version} is replaced by the
+// release tag
+const DefaultCommand = "git fetch --tags && git checkout {version} && (cd complex && go build -o cc-custom ./cmd)"
+
+// releasesURL lists a repository's releases, newest first
+const releasesURL = "https://api.github.com/repos/%s/releases?per_page=30"
+
+// Config controls the update check. It is off unless enabled, as it calls
+// out to GitHub.
+type Config struct {
+	Check    bool          `toml:"check"`
+	Repo     string        `toml:"repo"`     // owner/name on GitHub
+	Interval time.Duration `toml:"interval"` // between checks, shared by all instances
+	Command  string        `toml:"command"`  // shown as the way to upgrade
+}
+
+// DefaultConfig returns the update check settings used when none are
+// configured
+func DefaultConfig() Config {
+	return Config{Repo: DefaultRepo, Interval: 24 * time.Hour, Command: DefaultCommand}
+}
+
+// UpgradeCommand returns the command that upgrades to the release tag
+func (c Config) UpgradeCommand(tag string) string {
+	command := c.Command
+	if command == "" {
+		command = DefaultCommand
+	}
+	return strings.ReplaceAll(command, "{version}", tag)
+}
+
+// Current returns the running version: the stamped release, the module
+// version of a go install, or "dev"
+func Current() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// Release is a published release and its notes
+type Release struct {
+	Tag        string    `json:"tag_name"`
+	Name       string    `json:"name"`
+	Notes      string    `json:"body"`
+	URL        string    `json:"html_url"`
+	Published  time.Time `json:"published_at"`
+	Draft      bool      `json:"draft"`
+	Prerelease bool      `json:"prerelease"`
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Current  string
+	Releases []Release // newest first
+	Checked  time.Time
+}
+
+// Latest returns the newest release
+func (r Result) Latest() (Release, bool) {
+	if len(r.Releases) == 0 {
+		return Release{}, false
+	}
+	return r.Releases[0], true
+}
+
+// Newer returns the releases newer than the running version. A dev build
+// cannot be placed among the releases, so it has none.
+func (r Result) Newer() []Release {
+	if _, ok := parseVersion(r.Current); !ok {
+		return nil
+	}
+	var newer []Release
+	for _, release := range r.Releases {
+		if compareVersions(release.Tag, r.Current) > 0 {
+			newer = append(newer, release)
+		}
+	}
+	return newer
+}
+
+// Available reports whether a newer release exists
+func (r Result) Available() bool {
+	return len(r.Newer()) > 0
+}
+
+// cacheFile is the last check, kept so restarts and several instances do
+// not each ask GitHub
+type cacheFile struct {
+	Repo     string    `json:"repo"`
+	Checked  time.Time `json:"checked"`
+	Releases []Release `json:"releases"`
+}
+
+// Checker looks up releases on GitHub
+type Checker struct {
+	cfg       Config
+	cachePath string
+	client    *http.Client
+	log       *slog.Logger
+}
+
+// NewChecker creates a checker caching results at cachePath ("" for none)
+func NewChecker(cfg Config, cachePath string) *Checker {
+	if cfg.Repo == "" {
+		cfg.Repo = DefaultRepo
+	}
+	return &Checker{
+		cfg:       cfg,
+		cachePath: cachePath,
+		client:    &http.Client{Timeout: 15 * time.Second},
+		log:       logging.For("update"),
+	}
+}
+
+// Config returns the settings the checker was created with
+func (c *Checker) Config() Config {
+	return c.cfg
+}
+
+// Check returns the published releases, from the cache while it is younger
+// than the interval
+func (c *Checker) Check(ctx context.Context) (Result, error) {
+	if cache, ok := c.readCache(); ok && time.Since(cache.Checked) < c.cfg.Interval {
+		return Result{Current: Current(), Releases: cache.Releases, Checked: cache.Checked}, nil
+	}
+	return c.Refresh(ctx)
+}
+
+// Refresh asks GitHub for the releases, ignoring the cache
+func (c *Checker) Refresh(ctx context.Context) (Result, error) {
+	releases, err := c.fetch(ctx)
+	if err != nil {
+		return Result{Current: Current()}, err
+	}
+	result := Result{Current: Current(), Releases: releases, Checked: time.Now()}
+	c.writeCache(cacheFile{Repo: c.cfg.Repo, Checked: result.Checked, Releases: releases})
+	c.log.Info("checked for updates", "current", result.Current, "releases", len(releases), "available", result.Available())
+	return result, nil
+}
+
+// fetch lists the repository's published releases, newest first
+func (c *Checker) fetch(ctx context.Context) ([]Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(releasesURL, c.cfg.Repo), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch releases: %s", resp.Status)
+	}
+
+	var all []Release
+	if err := json.NewDecoder(resp.Body).Decode(&all); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+	var releases []Release
+	for _, release := range all {
+		if !release.Draft && !release.Prerelease {
+			releases = append(releases, release)
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return compareVersions(releases[i].Tag, releases[j].Tag) > 0
+	})
+	return releases, nil
+}
+
+// readCache returns the cached check for the configured repository
+func (c *Checker) readCache() (cacheFile, bool) {
+	var cache cacheFile
+	if c.cachePath == "" {
+		return cache, false
+	}
+	data, err := os.ReadFile(c.cachePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.log.Warn("failed to read update cache", "err", err)
+		}
+		return cache, false
+	}
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Repo != c.cfg.Repo {
+		return cache, false
+	}
+	return cache, true
+}
+
+// writeCache stores a check; failing to is only logged
+func (c *Checker) writeCache(cache cacheFile) {
+	if c.cachePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err == nil {
+		err = os.WriteFile(c.cachePath, data, 0o644)
+	}
+	if err != nil {
+		c.log.Warn("failed to write update cache", "err", err)
+	}
+}
+
+// parseVersion reads the numeric parts of a tag such as v1.2.3 or 1.2,
+// ignoring pre-release and build suffixes
+func parseVersion(tag string) ([3]int, bool) {
+	var parts [3]int
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "v")
+	if i := strings.IndexAny(tag, "-+"); i >= 0 {
+		tag = tag[:i]
+	}
+	fields := strings.Split(tag, ".")
+	if tag == "" || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// compareVersions orders two tags; tags that are not versions sort first
+func compareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		switch {
+		case okA == okB:
+			return strings.Compare(a, b)
+		case okA:
+			return 1
+		default:
+			return -1
+		}
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] > vb[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+// Command mcp-approval is the MCP permission server for claude's
+// --permission-prompt-tool. Claude starts it over stdio; it forwards every
+// approval prompt to the TUI listening on $CC_CUSTOM_APPROVAL_SOCKET, and
+// denies with an explanation when no TUI is there to ask.
+//
+// An MCP config using it:
+//
+//	{"mcpServers": {"permission": {"command": "mcp-approval"}}}
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"customclaude/pkg/mcpapproval"
+)
+
+func main() {
+	socket := flag.String("socket", os.Getenv(mcpapproval.SocketEnv), "unix socket of the TUI's approval broker")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := &mcpapproval.Server{Socket: *socket, Log: os.Stderr}
+	if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
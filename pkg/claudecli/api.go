@@ -0,0 +1,421 @@
+package claudecli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the API backend
+const (
+	DefaultAPIBaseURL   = "https://api.anthropic.com"
+	DefaultAPIMaxTokens = 8192
+	anthropicVersion    = "2023-06-01"
+)
+
+// APIRunner answers prompts through the Anthropic Messages API instead of the
+// claude CLI. It writes the stream-json lines the CLI would, API streaming
+// events included, so the output parses the same. Claude gets no tools
+// here; it can only answer in text.
+//
+// Conversations are kept in memory by session ID, so a session can only be
+// resumed by the runner that started it. An unknown session starts over with
+// a warning on stderr.
+type APIRunner struct {
+	APIKey    string
+	BaseURL   string // empty for DefaultAPIBaseURL
+	MaxTokens int    // 0 for DefaultAPIMaxTokens
+	// Price returns the cost in USD of a response; nil reports no cost
+	Price func(model string, usage Usage) float64
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	sessions map[string][]apiMessage
+}
+
+// apiMessage is a turn of a Messages API conversation
+type apiMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// apiRequest is the body of a Messages API request
+type apiRequest struct {
+	Model     string       `json:"model"`
+	MaxTokens int          `json:"max_tokens"`
+	System    string       `json:"system,omitempty"`
+	Messages  []apiMessage `json:"messages"`
+	Stream    bool         `json:"stream"`
+}
+
+// apiEvent is the part of a streamed Messages API event the runner reads
+type apiEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage Usage  `json:"usage"`
+	} `json:"message"`
+	ContentBlock struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content_block"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Start implements Runner
+func (r *APIRunner) Start(ctx context.Context, opts Options, prompt, sessionID string) (Process, error) {
+	if r.APIKey == "" {
+		return nil, errors.New("the API backend needs an API key")
+	}
+	model := opts.Model
+	if model == "" {
+		return nil, errors.New("the API backend needs a model")
+	}
+
+	history, known := r.history(sessionID)
+	unknown := sessionID != "" && !known
+	if sessionID == "" {
+		sessionID = newSessionID()
+	}
+	body, err := json.Marshal(apiRequest{
+		Model:     model,
+		MaxTokens: r.maxTokens(),
+		System:    opts.SystemPrompt,
+		Messages:  append(history, apiMessage{Role: "user", Content: prompt}),
+		Stream:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stdout, stdoutW := io.Pipe()
+	stderr := newStderrBuffer()
+	p := &apiProcess{stdout: stdout, stderr: stderr, cancel: cancel, done: make(chan struct{})}
+	turn := &apiTurn{
+		runner:    r,
+		out:       json.NewEncoder(stdoutW),
+		sessionID: sessionID,
+		model:     model,
+		prompt:    prompt,
+		started:   time.Now(),
+	}
+	go func() {
+		defer close(p.done)
+		if unknown {
+			fmt.Fprintf(stderr, "session %s is not known to the API backend; its earlier turns are not sent\n", sessionID)
+		}
+		err := turn.run(ctx, body, opts.Dir)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintln(stderr, err)
+		}
+		if ctx.Err() != nil {
+			err = fmt.Errorf("API request cancelled: %w", ctx.Err())
+		}
+		p.err = err
+		stdoutW.Close()
+		stderr.Close()
+	}()
+	return p, nil
+}
+
+// history returns a copy of a session's conversation and whether the runner
+// knows it
+func (r *APIRunner) history(sessionID string) ([]apiMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	messages, ok := r.sessions[sessionID]
+	return append([]apiMessage(nil), messages...), ok
+}
+
+// remember appends a completed exchange to a session
+func (r *APIRunner) remember(sessionID, prompt, answer string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sessions == nil {
+		r.sessions = make(map[string][]apiMessage)
+	}
+	r.sessions[sessionID] = append(r.sessions[sessionID],
+		apiMessage{Role: "user", Content: prompt},
+		apiMessage{Role: "assistant", Content: answer})
+}
+
+func (r *APIRunner) maxTokens() int {
+	if r.MaxTokens > 0 {
+		return r.MaxTokens
+	}
+	return DefaultAPIMaxTokens
+}
+
+// apiTurn streams one Messages API response as stream-json lines
+type apiTurn struct {
+	runner    *APIRunner
+	out       *json.Encoder
+	sessionID string
+	model     string
+	prompt    string
+	started   time.Time
+}
+
+// run sends the request and translates the response
+func (t *apiTurn) run(ctx context.Context, body []byte, dir string) error {
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	t.write(map[string]any{
+		"type": "system", "subtype": "init", "cwd": dir, "session_id": t.sessionID,
+		"tools": []string{}, "mcp_servers": []MCPServerStatus{}, "model": t.model,
+	})
+
+	baseURL := strings.TrimSuffix(t.runner.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultAPIBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create API request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("x-api-key", t.runner.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	client := t.runner.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp)
+	}
+	return t.stream(resp.Body)
+}
+
+// stream reads the server-sent events of a response
+func (t *apiTurn) stream(body io.Reader) error {
+	var (
+		messageID  string
+		usage      Usage
+		stopReason string
+		blocks     = make(map[int]*strings.Builder)
+		order      []int
+	)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), DefaultMaxLineBytes)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // event names, comments and blank separators
+		}
+		data = strings.TrimSpace(data)
+		var event apiEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to parse API event: %w", err)
+		}
+
+		switch event.Type {
+		case "ping":
+			continue
+		case "error":
+			if event.Error != nil {
+				return fmt.Errorf("API error: %s: %s", event.Error.Type, event.Error.Message)
+			}
+			return errors.New("API error")
+		case "message_start":
+			messageID = event.Message.ID
+			usage = event.Message.Usage
+			if event.Message.Model != "" {
+				t.model = event.Message.Model
+			}
+		case "content_block_start":
+			if event.ContentBlock.Type == "text" {
+				blocks[event.Index] = &strings.Builder{}
+				blocks[event.Index].WriteString(event.ContentBlock.Text)
+				order = append(order, event.Index)
+			}
+		case "content_block_delta":
+			if block, ok := blocks[event.Index]; ok && event.Delta.Type == "text_delta" {
+				block.WriteString(event.Delta.Text)
+			}
+		case "message_delta":
+			stopReason = event.Delta.StopReason
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+		}
+		t.write(map[string]any{"type": "stream_event", "event": json.RawMessage(data), "session_id": t.sessionID})
+
+		if event.Type == "message_stop" {
+			t.finish(messageID, blocks, order, stopReason, usage)
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read API stream: %w", err)
+	}
+	return errors.New("API stream ended before the message was complete")
+}
+
+// finish writes the assistant message and the result, and keeps the
+// exchange for the session's next turn
+func (t *apiTurn) finish(messageID string, blocks map[int]*strings.Builder, order []int, stopReason string, usage Usage) {
+	content := make([]ContentBlock, 0, len(order))
+	var texts []string
+	for _, index := range order {
+		text := blocks[index].String()
+		content = append(content, ContentBlock{Type: "text", Text: text})
+		texts = append(texts, text)
+	}
+	answer := strings.Join(texts, "\n\n")
+
+	t.write(map[string]any{
+		"type": "assistant",
+		"message": map[string]any{
+			"id": messageID, "type": "message", "role": "assistant", "model": t.model,
+			"content": content, "stop_reason": stopReason, "usage": usage,
+		},
+		"session_id": t.sessionID,
+	})
+	cost := 0.0
+	if t.runner.Price != nil {
+		cost = t.runner.Price(t.model, usage)
+	}
+	t.write(Message{
+		Type:         "result",
+		Subtype:      "success",
+		SessionID:    t.sessionID,
+		Result:       answer,
+		DurationMs:   int(time.Since(t.started).Milliseconds()),
+		NumTurns:     1,
+		TotalCostUSD: cost,
+		Usage:        &usage,
+	})
+	t.runner.remember(t.sessionID, t.prompt, answer)
+}
+
+// write sends a stream-json line; a reader that went away is not an error
+func (t *apiTurn) write(line any) {
+	t.out.Encode(line)
+}
+
+// apiError describes a failed API response
+func apiError(resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		return fmt.Errorf("API error (%d): %s: %s", resp.StatusCode, body.Error.Type, body.Error.Message)
+	}
+	return fmt.Errorf("API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
+
+// newSessionID returns a random UUID-formatted session ID, like the CLI's
+func newSessionID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// stderrBuffer holds what the runner writes to stderr until it is read.
+// Unlike an io.Pipe, writing never waits for a reader, so a caller can read
+// stdout to the end before stderr, as it can with a process's few stderr
+// lines.
+type stderrBuffer struct {
+	mu     sync.Mutex
+	ready  *sync.Cond
+	buf    bytes.Buffer
+	closed bool
+}
+
+func newStderrBuffer() *stderrBuffer {
+	b := &stderrBuffer{}
+	b.ready = sync.NewCond(&b.mu)
+	return b
+}
+
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.ready.Broadcast()
+	return b.buf.Write(p)
+}
+
+// Read waits for output, returning io.EOF once the buffer is closed and
+// drained
+func (b *stderrBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.buf.Len() == 0 && !b.closed {
+		b.ready.Wait()
+	}
+	if b.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return b.buf.Read(p)
+}
+
+// Close ends the output once what was written has been read
+func (b *stderrBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.ready.Broadcast()
+	return nil
+}
+
+// apiProcess is a running API request
+type apiProcess struct {
+	stdout *io.PipeReader
+	stderr *stderrBuffer
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+func (p *apiProcess) Stdout() io.Reader { return p.stdout }
+func (p *apiProcess) Stderr() io.Reader { return p.stderr }
+func (p *apiProcess) PID() int          { return 0 }
+
+func (p *apiProcess) Wait() error {
+	<-p.done
+	p.cancel()
+	return p.err
+}
+
+func (p *apiProcess) Kill() error {
+	p.cancel()
+	return nil
+}
@@ -0,0 +1,268 @@
+package claudecli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// apiServer replays an SSE fixture as the response to every Messages API
+// request and records the requests
+type apiServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []apiRequest
+}
+
+func newAPIServer(t *testing.T, fixture string) *apiServer {
+	stream, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &apiServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			http.Error(w, `{"type":"error","error":{"type":"authentication_error","message":"bad request"}}`, http.StatusUnauthorized)
+			return
+		}
+		var req apiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		s.mu.Unlock()
+		w.Header().Set("content-type", "text/event-stream")
+		w.Write(stream)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *apiServer) lastRequest(t *testing.T) apiRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		t.Fatal("no API request")
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+// apiRun is what one APIRunner turn wrote
+type apiRun struct {
+	sequence  string
+	init      *InitEvent
+	assistant *AssistantEvent
+	result    *ResultEvent
+	stderr    string
+	err       error
+}
+
+// runAPI runs a turn, reading all of stdout before stderr as a sequential
+// caller would
+func runAPI(t *testing.T, r *APIRunner, sessionID string) apiRun {
+	t.Helper()
+	p, err := r.Start(context.Background(), Options{Model: "claude-sonnet-4-20250514", Dir: t.TempDir()}, "What is io.Reader?", sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var run apiRun
+	var stdout, stderr []byte
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		if stdout, err = io.ReadAll(p.Stdout()); err == nil {
+			stderr, err = io.ReadAll(p.Stderr())
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		p.Kill()
+		t.Fatal("reading stdout, then stderr, did not finish")
+	}
+	run.err = p.Wait()
+	run.stderr = string(stderr)
+
+	var sequence []byte
+	parser := NewParser(strings.NewReader(string(stdout)), 0)
+	for {
+		_, event, err := parser.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("event %d: %v", len(sequence), err)
+		}
+		sequence = append(sequence, eventCodes[event.Type()])
+		switch e := event.(type) {
+		case *InitEvent:
+			run.init = e
+		case *AssistantEvent:
+			run.assistant = e
+		case *ResultEvent:
+			run.result = e
+		}
+	}
+	run.sequence = string(sequence)
+	return run
+}
+
+func TestAPIRunnerStream(t *testing.T) {
+	tests := []struct {
+		fixture string
+		// sequence is the event types in order, abbreviated by eventCodes
+		sequence   string
+		text       string // of the assistant message and the result
+		stopReason string
+		usage      Usage
+		err        string // of Wait and on stderr
+	}{
+		{
+			fixture:    "api-text.sse",
+			sequence:   "spppppppar",
+			text:       "Go's `io.Reader` has a single method, `Read`.",
+			stopReason: "end_turn",
+			usage:      Usage{InputTokens: 25, OutputTokens: 15},
+		},
+		{
+			// The tool call is streamed but not part of the answer: the
+			// runner gives Claude no tools to run
+			fixture:    "api-tool-use.sse",
+			sequence:   "sppppppppppppar",
+			text:       "Let me check the weather in San Francisco.",
+			stopReason: "tool_use",
+			usage:      Usage{InputTokens: 472, OutputTokens: 89},
+		},
+		{
+			fixture:  "api-error.sse",
+			sequence: "sppp",
+			err:      "API error: overloaded_error: Overloaded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			server := newAPIServer(t, tt.fixture)
+			r := &APIRunner{
+				APIKey:  "test-key",
+				BaseURL: server.URL + "/",
+				Price:   func(_ string, usage Usage) float64 { return float64(usage.OutputTokens) / 1000 },
+			}
+			run := runAPI(t, r, "")
+
+			if run.sequence != tt.sequence {
+				t.Errorf("sequence = %s, want %s", run.sequence, tt.sequence)
+			}
+			if run.init == nil || run.init.Init.SessionID == "" {
+				t.Fatalf("init = %+v, want a session", run.init)
+			}
+			req := server.lastRequest(t)
+			if !req.Stream || req.MaxTokens != DefaultAPIMaxTokens || len(req.Messages) != 1 {
+				t.Errorf("request = %+v", req)
+			}
+
+			if tt.err != "" {
+				if run.err == nil || !strings.Contains(run.err.Error(), tt.err) {
+					t.Errorf("Wait() = %v, want %q", run.err, tt.err)
+				}
+				if !strings.Contains(run.stderr, tt.err) {
+					t.Errorf("stderr = %q, want %q", run.stderr, tt.err)
+				}
+				if _, known := r.history(run.init.Init.SessionID); known {
+					t.Error("failed turn was kept in the session")
+				}
+				return
+			}
+
+			if run.err != nil || run.stderr != "" {
+				t.Errorf("Wait() = %v, stderr %q", run.err, run.stderr)
+			}
+			if run.assistant == nil {
+				t.Fatal("no assistant event")
+			}
+			msg := run.assistant.Message
+			if len(run.assistant.Blocks) != 1 || run.assistant.Blocks[0].Type != "text" || run.assistant.Blocks[0].Text != tt.text {
+				t.Errorf("assistant blocks = %+v, want the text %q", run.assistant.Blocks, tt.text)
+			}
+			if msg.StopReason != tt.stopReason || msg.Usage == nil || *msg.Usage != tt.usage {
+				t.Errorf("assistant stop %q usage %+v, want %q %+v", msg.StopReason, msg.Usage, tt.stopReason, tt.usage)
+			}
+			if run.result == nil {
+				t.Fatal("no result event")
+			}
+			got := run.result.Result
+			if got.Subtype != "success" || got.IsError || got.Result != tt.text || got.SessionID != run.init.Init.SessionID {
+				t.Errorf("result = %s/%v/%q/%s", got.Subtype, got.IsError, got.Result, got.SessionID)
+			}
+			if want := float64(tt.usage.OutputTokens) / 1000; got.TotalCostUSD != want || got.Usage == nil || *got.Usage != tt.usage {
+				t.Errorf("result cost %v usage %+v, want %v %+v", got.TotalCostUSD, got.Usage, want, tt.usage)
+			}
+		})
+	}
+}
+
+func TestAPIRunnerResume(t *testing.T) {
+	server := newAPIServer(t, "api-text.sse")
+	r := &APIRunner{APIKey: "test-key", BaseURL: server.URL}
+
+	first := runAPI(t, r, "")
+	if first.err != nil || first.init == nil {
+		t.Fatalf("first turn: %v, init %+v", first.err, first.init)
+	}
+	sessionID := first.init.Init.SessionID
+
+	// A known session sends its earlier turns
+	second := runAPI(t, r, sessionID)
+	if second.err != nil || second.stderr != "" {
+		t.Errorf("resumed turn: %v, stderr %q", second.err, second.stderr)
+	}
+	if second.init == nil || second.init.Init.SessionID != sessionID {
+		t.Errorf("resumed init = %+v, want session %s", second.init, sessionID)
+	}
+	if roles := messageRoles(server.lastRequest(t)); roles != "user assistant user" {
+		t.Errorf("resumed request roles = %s", roles)
+	}
+
+	// An unknown session keeps its ID but starts over, with a warning
+	const unknown = "6f1e2d3c-4b5a-4978-8a69-5b4c3d2e1f00"
+	third := runAPI(t, r, unknown)
+	if third.err != nil || third.sequence != "spppppppar" {
+		t.Errorf("unknown session: %v, sequence %s", third.err, third.sequence)
+	}
+	if third.init == nil || third.init.Init.SessionID != unknown || third.result == nil || third.result.Result.SessionID != unknown {
+		t.Errorf("unknown session init %+v, result %+v", third.init, third.result)
+	}
+	if !strings.Contains(third.stderr, "session "+unknown+" is not known") {
+		t.Errorf("stderr = %q, want the unknown session warning", third.stderr)
+	}
+	if roles := messageRoles(server.lastRequest(t)); roles != "user" {
+		t.Errorf("unknown session request roles = %s", roles)
+	}
+	if history, _ := r.history(unknown); len(history) != 2 {
+		t.Errorf("unknown session kept %d messages, want 2", len(history))
+	}
+}
+
+func messageRoles(req apiRequest) string {
+	roles := make([]string, len(req.Messages))
+	for i, msg := range req.Messages {
+		roles[i] = msg.Role
+	}
+	return strings.Join(roles, " ")
+}
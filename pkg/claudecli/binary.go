@@ -0,0 +1,42 @@
+package claudecli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// ErrBinaryNotFound is returned by FindBinary when claude is not installed
+// anywhere it looks
+var ErrBinaryNotFound = errors.New("claude CLI not found in PATH; install it with `npm install -g @anthropic-ai/claude-code`")
+
+// FindBinary locates the claude executable: on the PATH (claude.exe or
+// claude.cmd on Windows), then the local install under ~/.claude/local, then
+// on Windows npm's global directory, which shells started before the
+// install do not have on their PATH
+func FindBinary() (string, error) {
+	if path, err := exec.LookPath("claude"); err == nil {
+		return path, nil
+	}
+
+	var candidates []string
+	if home, err := os.UserHomeDir(); err == nil {
+		local := filepath.Join(home, ".claude", "local", "claude")
+		if runtime.GOOS == "windows" {
+			candidates = append(candidates, local+".exe", local+".cmd")
+		} else {
+			candidates = append(candidates, local)
+		}
+	}
+	if appData := os.Getenv("APPDATA"); runtime.GOOS == "windows" && appData != "" {
+		candidates = append(candidates, filepath.Join(appData, "npm", "claude.cmd"))
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", ErrBinaryNotFound
+}
@@ -0,0 +1,174 @@
+package claudecli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// ReadOnlyDisallowedTools are the tools that can modify the workspace
+var ReadOnlyDisallowedTools = []string{"Edit", "MultiEdit", "Write", "NotebookEdit"}
+
+// Options configures claude invocations
+type Options struct {
+	// Binary is the claude executable; empty for the one FindBinary finds
+	Binary string
+
+	Model          string
+	PermissionTool string
+	MCPConfig      string
+	SystemPrompt   string // appended to claude's own
+
+	// ReadOnly runs in plan permission mode without the write tools
+	ReadOnly bool
+
+	// AllowedTools and DisallowedTools are permission rules, such as "Read"
+	// or "Bash(git diff:*)"; PermissionMode is claude's --permission-mode.
+	// ReadOnly takes precedence over the mode.
+	AllowedTools    []string
+	DisallowedTools []string
+	PermissionMode  string
+
+	// PartialMessages streams API events as PartialEvents
+	PartialMessages bool
+
+	// ExtraArgs are passed before the prompt
+	ExtraArgs []string
+
+	// Env holds NAME=value pairs added to the inherited environment, which
+	// claude's tools see too
+	Env []string
+
+	// Dir is the directory claude runs in; empty for the current one
+	Dir string
+}
+
+// Args returns the CLI arguments to run prompt, resuming sessionID when it
+// is not empty
+func (o Options) Args(prompt, sessionID string) []string {
+	var args []string
+	mode, disallowed := o.PermissionMode, o.DisallowedTools
+	if o.ReadOnly {
+		mode = "plan"
+		disallowed = append([]string(nil), ReadOnlyDisallowedTools...)
+		for _, tool := range o.DisallowedTools {
+			if !slices.Contains(disallowed, tool) {
+				disallowed = append(disallowed, tool)
+			}
+		}
+	}
+	// Kept first so the variadic tool lists cannot swallow the prompt
+	if mode != "" {
+		args = append(args, "--permission-mode", mode)
+	}
+	if len(o.AllowedTools) > 0 {
+		args = append(args, "--allowedTools", strings.Join(o.AllowedTools, ","))
+	}
+	if len(disallowed) > 0 {
+		args = append(args, "--disallowedTools", strings.Join(disallowed, ","))
+	}
+	args = append(args, "--output-format", "stream-json")
+	if o.PartialMessages {
+		args = append(args, "--include-partial-messages")
+	}
+	args = append(args, "--verbose", "-p")
+	if o.PermissionTool != "" {
+		args = append(args, "--permission-prompt-tool", o.PermissionTool)
+	}
+	if o.MCPConfig != "" {
+		args = append(args, "--mcp-config", o.MCPConfig)
+	}
+	if o.Model != "" {
+		args = append(args, "--model", o.Model)
+	}
+	if o.SystemPrompt != "" {
+		args = append(args, "--append-system-prompt", o.SystemPrompt)
+	}
+	args = append(args, o.ExtraArgs...)
+	if sessionID != "" {
+		args = append(args, "--resume", sessionID)
+	}
+	return append(args, prompt)
+}
+
+// Client starts claude invocations
+type Client struct {
+	Options Options
+
+	// Stderr, when set, receives claude's stderr instead of Run.Stderr
+	Stderr io.Writer
+}
+
+// NewClient creates a client for the given options
+func NewClient(opts Options) *Client {
+	return &Client{Options: opts}
+}
+
+// Run is a started claude process; it implements Process
+type Run struct {
+	Args   []string
+	stdout io.ReadCloser
+	stderr io.ReadCloser
+	cmd    *exec.Cmd
+}
+
+// Start runs prompt, resuming sessionID when it is not empty. Cancelling ctx
+// kills the process.
+func (c *Client) Start(ctx context.Context, prompt, sessionID string) (*Run, error) {
+	binary := c.Options.Binary
+	if binary == "" {
+		var err error
+		if binary, err = FindBinary(); err != nil {
+			return nil, err
+		}
+	}
+	run := &Run{Args: c.Options.Args(prompt, sessionID)}
+	run.cmd = exec.CommandContext(ctx, binary, run.Args...)
+	if len(c.Options.Env) > 0 {
+		run.cmd.Env = append(os.Environ(), c.Options.Env...)
+	}
+	run.cmd.Dir = c.Options.Dir
+
+	var err error
+	if run.stdout, err = run.cmd.StdoutPipe(); err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if c.Stderr != nil {
+		run.cmd.Stderr = c.Stderr
+	} else if run.stderr, err = run.cmd.StderrPipe(); err != nil {
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+	if err := run.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	return run, nil
+}
+
+// Stdout returns claude's stream-json output
+func (r *Run) Stdout() io.Reader {
+	return r.stdout
+}
+
+// Stderr returns claude's stderr; nil when Client.Stderr receives it
+func (r *Run) Stderr() io.Reader {
+	return r.stderr
+}
+
+// PID returns the process ID of the claude process
+func (r *Run) PID() int {
+	return r.cmd.Process.Pid
+}
+
+// Wait waits for claude to exit
+func (r *Run) Wait() error {
+	return r.cmd.Wait()
+}
+
+// Kill stops claude at once
+func (r *Run) Kill() error {
+	return r.cmd.Process.Kill()
+}
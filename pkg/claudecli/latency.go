@@ -0,0 +1,106 @@
+package claudecli
+
+import (
+	"fmt"
+	"time"
+)
+
+// TurnLatency breaks down where the wall time of a turn went
+type TurnLatency struct {
+	TimeToFirstToken time.Duration `json:"time_to_first_token"`
+	ToolTime         time.Duration `json:"tool_time"`
+	ModelTime        time.Duration `json:"model_time"`
+	WallTime         time.Duration `json:"wall_time"`
+}
+
+// String formats the breakdown for display after a turn
+func (l TurnLatency) String() string {
+	return fmt.Sprintf("first token %s · model %s · tools %s · total %s",
+		roundLatency(l.TimeToFirstToken),
+		roundLatency(l.ModelTime),
+		roundLatency(l.ToolTime),
+		roundLatency(l.WallTime))
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Millisecond)
+}
+
+// LatencyTracker measures a single turn. Tool time is the union of the
+// intervals during which at least one tool was running, so parallel tools
+// are not double counted.
+type LatencyTracker struct {
+	start        time.Time
+	firstToken   time.Time
+	activeTools  map[string]bool
+	toolsStarted time.Time
+	toolTime     time.Duration
+}
+
+// Begin resets the tracker at the start of a turn
+func (lt *LatencyTracker) Begin(now time.Time) {
+	*lt = LatencyTracker{
+		start:       now,
+		activeTools: make(map[string]bool),
+	}
+}
+
+// MarkOutput records the first assistant output of the turn
+func (lt *LatencyTracker) MarkOutput(now time.Time) {
+	if lt.firstToken.IsZero() {
+		lt.firstToken = now
+	}
+}
+
+// ToolStarted records a tool_use block
+func (lt *LatencyTracker) ToolStarted(id string, now time.Time) {
+	if lt.activeTools == nil {
+		lt.activeTools = make(map[string]bool)
+	}
+	if lt.activeTools[id] {
+		return
+	}
+	if len(lt.activeTools) == 0 {
+		lt.toolsStarted = now
+	}
+	lt.activeTools[id] = true
+}
+
+// ToolFinished records the tool_result for a tool_use block
+func (lt *LatencyTracker) ToolFinished(id string, now time.Time) {
+	if !lt.activeTools[id] {
+		return
+	}
+	delete(lt.activeTools, id)
+	if len(lt.activeTools) == 0 {
+		lt.toolTime += now.Sub(lt.toolsStarted)
+	}
+}
+
+// Finish returns the breakdown for the turn ending at now
+func (lt *LatencyTracker) Finish(now time.Time) TurnLatency {
+	if lt.start.IsZero() {
+		return TurnLatency{}
+	}
+
+	toolTime := lt.toolTime
+	if len(lt.activeTools) > 0 {
+		toolTime += now.Sub(lt.toolsStarted)
+	}
+
+	latency := TurnLatency{
+		ToolTime: toolTime,
+		WallTime: now.Sub(lt.start),
+	}
+	if !lt.firstToken.IsZero() {
+		latency.TimeToFirstToken = lt.firstToken.Sub(lt.start)
+	}
+	latency.ModelTime = latency.WallTime - latency.ToolTime
+	if latency.ModelTime < 0 {
+		latency.ModelTime = 0
+	}
+	return latency
+}
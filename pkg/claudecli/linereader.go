@@ -0,0 +1,79 @@
+package claudecli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxLineBytes is the longest stream line parsed when no limit is
+// configured. Tool results with whole files inline easily pass the 64KB of a
+// default bufio.Scanner.
+const DefaultMaxLineBytes = 64 << 20
+
+// LineTooLongError reports a stream line that was skipped for exceeding the
+// line limit
+type LineTooLongError struct {
+	Size  int
+	Limit int
+}
+
+func (e *LineTooLongError) Error() string {
+	return fmt.Sprintf("stream line of %d bytes exceeds the %d byte limit and was skipped (raise max_stream_line_bytes)", e.Size, e.Limit)
+}
+
+// LineReader reads newline-terminated lines of any length up to a limit.
+// A longer line is consumed up to its newline and reported, so one oversized
+// message does not end the stream.
+type LineReader struct {
+	r     *bufio.Reader
+	limit int
+}
+
+// NewLineReader reads lines from r; a limit of 0 or less uses the default
+func NewLineReader(r io.Reader, limit int) *LineReader {
+	if limit <= 0 {
+		limit = DefaultMaxLineBytes
+	}
+	return &LineReader{r: bufio.NewReaderSize(r, 64*1024), limit: limit}
+}
+
+// Next returns the next line without its line ending. An oversized line is
+// returned as a *LineTooLongError; io.EOF ends the stream.
+func (lr *LineReader) Next() (string, error) {
+	var line []byte
+	size := 0
+	tooLong := false
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		size += len(chunk)
+		if !tooLong {
+			if len(line)+len(chunk) > lr.limit+1 {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		if errors.Is(err, io.EOF) && size == 0 {
+			return "", io.EOF
+		}
+		if tooLong {
+			return "", &LineTooLongError{Size: size, Limit: lr.limit}
+		}
+		if n := len(line); n > 0 && line[n-1] == '\n' {
+			line = line[:n-1]
+			if n := len(line); n > 0 && line[n-1] == '\r' {
+				line = line[:n-1]
+			}
+		}
+		return string(line), nil
+	}
+}
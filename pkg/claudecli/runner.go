@@ -0,0 +1,130 @@
+package claudecli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Process is a started claude invocation. Read Stdout and Stderr to the end,
+// with ProcessStream for Stdout, before calling Wait.
+type Process interface {
+	Stdout() io.Reader
+	// Stderr is nil when the runner sends stderr elsewhere
+	Stderr() io.Reader
+	Wait() error
+	Kill() error
+	// PID is the OS process, 0 when there is none
+	PID() int
+}
+
+// Runner starts claude invocations. ExecRunner runs the binary; other
+// implementations stand in for it in tests, replays or remote transports.
+type Runner interface {
+	// Start runs prompt with opts, resuming sessionID when it is not
+	// empty. Cancelling ctx stops the invocation.
+	Start(ctx context.Context, opts Options, prompt, sessionID string) (Process, error)
+}
+
+// ExecRunner runs the claude binary through a Client
+type ExecRunner struct {
+	// Stderr, when set, receives claude's stderr instead of Process.Stderr
+	Stderr io.Writer
+}
+
+// Start implements Runner
+func (r ExecRunner) Start(ctx context.Context, opts Options, prompt, sessionID string) (Process, error) {
+	client := NewClient(opts)
+	client.Stderr = r.Stderr
+	run, err := client.Start(ctx, prompt, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ReplayRunner answers every invocation with a recorded stream-json file,
+// such as those in testdata, whatever the prompt and options
+type ReplayRunner struct {
+	Path  string
+	Delay time.Duration // pause before each line
+}
+
+// Start implements Runner
+func (r ReplayRunner) Start(ctx context.Context, _ Options, _, _ string) (Process, error) {
+	file, err := os.Open(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stdout, writer := io.Pipe()
+	p := &replayProcess{stdout: stdout, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		defer file.Close()
+		err := paceLines(ctx, file, writer, r.Delay)
+		// Like a killed process, a cancelled replay just ends its output
+		if ctx.Err() == nil {
+			p.err = err
+			writer.CloseWithError(err)
+		} else {
+			p.err = fmt.Errorf("replay stopped: %w", ctx.Err())
+			writer.Close()
+		}
+	}()
+	return p, nil
+}
+
+// replayProcess is a running replay
+type replayProcess struct {
+	stdout *io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+func (p *replayProcess) Stdout() io.Reader { return p.stdout }
+func (p *replayProcess) Stderr() io.Reader { return strings.NewReader("") }
+func (p *replayProcess) PID() int          { return 0 }
+
+func (p *replayProcess) Wait() error {
+	<-p.done
+	p.cancel()
+	return p.err
+}
+
+func (p *replayProcess) Kill() error {
+	p.cancel()
+	return nil
+}
+
+// paceLines copies src to dst a line at a time, waiting delay before each,
+// until src ends or ctx is done
+func paceLines(ctx context.Context, src io.Reader, dst io.Writer, delay time.Duration) error {
+	lines := bufio.NewReader(src)
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(line) > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if _, err := dst.Write(line); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read replay: %w", err)
+		}
+	}
+}
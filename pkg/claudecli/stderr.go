@@ -0,0 +1,76 @@
+package claudecli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity ranks a line claude wrote to stderr
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the severity's name
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "info"
+}
+
+// MarshalText encodes the severity by name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity reads a severity filter: "all" or "info", "warn" or
+// "warning", and "error"
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "all", "info":
+		return SeverityInfo, nil
+	case "warn", "warning", "warnings":
+		return SeverityWarning, nil
+	case "error", "errors":
+		return SeverityError, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q; use all, warn or error", name)
+}
+
+// StderrLine is a line claude wrote to stderr
+type StderrLine struct {
+	Text     string    `json:"text"`
+	Severity Severity  `json:"severity"`
+	Received time.Time `json:"received"`
+}
+
+// NewStderrLine classifies a line received now
+func NewStderrLine(text string) StderrLine {
+	return StderrLine{Text: text, Severity: ClassifyStderr(text), Received: time.Now()}
+}
+
+// ClassifyStderr guesses the severity of a stderr line from its wording.
+// Claude and the tools it runs have no common format, so this only looks for
+// the words they use.
+func ClassifyStderr(text string) Severity {
+	lower := strings.ToLower(text)
+	for _, word := range []string{"error", "fatal", "panic", "exception", "failed", "✗"} {
+		if strings.Contains(lower, word) {
+			return SeverityError
+		}
+	}
+	for _, word := range []string{"warn", "deprecat"} {
+		if strings.Contains(lower, word) {
+			return SeverityWarning
+		}
+	}
+	return SeverityInfo
+}
@@ -0,0 +1,176 @@
+package claudecli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Event is a decoded stream-json line. It is one of *InitEvent,
+// *AssistantEvent, *UserEvent, *PartialEvent, *ResultEvent or *OtherEvent.
+type Event interface {
+	// Type is the "type" field of the line
+	Type() string
+}
+
+// InitEvent starts an invocation with the session and its tools
+type InitEvent struct {
+	Init SystemInit
+}
+
+// AssistantEvent is a complete assistant message
+type AssistantEvent struct {
+	Message AssistantMessage
+	// Blocks is the decoded Message.Content; empty when it is not a list
+	Blocks []ContentBlock
+}
+
+// UserEvent is a user message, which carries tool results
+type UserEvent struct {
+	Blocks []ContentBlock
+}
+
+// PartialEvent is an API streaming event, sent with --include-partial-messages
+type PartialEvent struct {
+	Event json.RawMessage
+}
+
+// ResultEvent ends an invocation with its cost and usage
+type ResultEvent struct {
+	Result Message
+}
+
+// OtherEvent is a line of a type this package does not decode
+type OtherEvent struct {
+	Kind    string
+	Subtype string
+}
+
+func (*InitEvent) Type() string      { return "system" }
+func (*AssistantEvent) Type() string { return "assistant" }
+func (*UserEvent) Type() string      { return "user" }
+func (*PartialEvent) Type() string   { return "stream_event" }
+func (*ResultEvent) Type() string    { return "result" }
+func (e *OtherEvent) Type() string   { return e.Kind }
+
+// ParseLine decodes one stream-json line
+func ParseLine(line string) (Event, error) {
+	var head struct {
+		Type    string `json:"type"`
+		Subtype string `json:"subtype,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(line), &head); err != nil {
+		return nil, fmt.Errorf("failed to parse stream line: %w", err)
+	}
+
+	switch {
+	case head.Type == "system" && head.Subtype == "init":
+		var init SystemInit
+		if err := json.Unmarshal([]byte(line), &init); err != nil {
+			return nil, fmt.Errorf("failed to parse init message: %w", err)
+		}
+		return &InitEvent{Init: init}, nil
+
+	case head.Type == "assistant":
+		var data struct {
+			Message AssistantMessage `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			return nil, fmt.Errorf("failed to parse assistant message: %w", err)
+		}
+		event := &AssistantEvent{Message: data.Message}
+		json.Unmarshal(data.Message.Content, &event.Blocks)
+		return event, nil
+
+	case head.Type == "user":
+		var data struct {
+			Message struct {
+				Content json.RawMessage `json:"content"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			return nil, fmt.Errorf("failed to parse user message: %w", err)
+		}
+		event := &UserEvent{}
+		json.Unmarshal(data.Message.Content, &event.Blocks)
+		return event, nil
+
+	case head.Type == "stream_event":
+		var data struct {
+			Event json.RawMessage `json:"event"`
+		}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			return nil, fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		return &PartialEvent{Event: data.Event}, nil
+
+	case head.Type == "result":
+		var result Message
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			return nil, fmt.Errorf("failed to parse result: %w", err)
+		}
+		return &ResultEvent{Result: result}, nil
+	}
+	return &OtherEvent{Kind: head.Type, Subtype: head.Subtype}, nil
+}
+
+// Parser decodes a stream-json stream one event at a time, for callers that
+// pull events rather than take callbacks, such as replays and tests
+type Parser struct {
+	lines *LineReader
+}
+
+// NewParser reads stream-json lines from r. Lines longer than maxLineBytes
+// (0 for the default) are skipped and reported.
+func NewParser(r io.Reader, maxLineBytes int) *Parser {
+	return &Parser{lines: NewLineReader(r, maxLineBytes)}
+}
+
+// Next returns the next non-empty line and its event. A *LineTooLongError,
+// or a parse error returned with its line, only affects that line and the
+// stream can be read on; io.EOF ends it, and any other error is a failed
+// read.
+func (p *Parser) Next() (string, Event, error) {
+	for {
+		line, err := p.lines.Next()
+		var tooLong *LineTooLongError
+		switch {
+		case errors.Is(err, io.EOF):
+			return "", nil, io.EOF
+		case errors.As(err, &tooLong):
+			return "", nil, tooLong
+		case err != nil:
+			return "", nil, fmt.Errorf("failed to read stream: %w", err)
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		event, err := ParseLine(line)
+		return line, event, err
+	}
+}
+
+// StreamHandler receives each non-empty stream line. event is nil when err
+// is set: a *LineTooLongError for a skipped line, or a parse error.
+type StreamHandler func(line string, event Event, err error)
+
+// ProcessStream reads stream-json lines from r until EOF, decoding each and
+// handing it to handle. Lines longer than maxLineBytes (0 for the default)
+// are skipped and reported. Only a failed read ends the stream early.
+func ProcessStream(r io.Reader, maxLineBytes int, handle StreamHandler) error {
+	parser := NewParser(r, maxLineBytes)
+	for {
+		line, event, err := parser.Next()
+		var tooLong *LineTooLongError
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil && line == "" && !errors.As(err, &tooLong):
+			return err
+		}
+		handle(line, event, err)
+	}
+}
@@ -0,0 +1,200 @@
+package claudecli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// eventCodes abbreviates event types in the expected sequences
+var eventCodes = map[string]byte{
+	"system":       's',
+	"assistant":    'a',
+	"user":         'u',
+	"stream_event": 'p',
+	"result":       'r',
+}
+
+func TestParserFixtures(t *testing.T) {
+	tests := []struct {
+		file string
+		// sequence is the event types in order, abbreviated by eventCodes
+		sequence   string
+		toolUses   int
+		toolErrors int
+		subtype    string
+		isError    bool
+		numTurns   int
+		sessionID  string
+		result     string // prefix of the result text
+	}{
+		{
+			file:      "text-reply.jsonl",
+			sequence:  "sppppppppppappr",
+			subtype:   "success",
+			numTurns:  1,
+			sessionID: "5d2c7a3e-0f4b-4c1e-9a57-2f1d8b6e4c10",
+			result:    "Go's `io.Reader` has a single method",
+		},
+		{
+			file:       "tool-session.jsonl",
+			sequence:   "saauauauauauauauar",
+			toolUses:   7,
+			toolErrors: 1,
+			subtype:    "success",
+			numTurns:   16,
+			sessionID:  "f2c0fab9-66f2-49ae-8b20-b2566f10de6a",
+			result:     "Basic HTTP server created",
+		},
+		{
+			file:       "tool-errors.jsonl",
+			sequence:   "sauauauauauauauauauar",
+			toolUses:   9,
+			toolErrors: 4,
+			subtype:    "success",
+			numTurns:   36,
+			sessionID:  "f1c32ff4-7994-418e-9f81-216b4b88bb74",
+			result:     "Added `-port` flag",
+		},
+		{
+			file:       "error-result.jsonl",
+			sequence:   "saur",
+			toolUses:   1,
+			toolErrors: 1,
+			subtype:    "error_during_execution",
+			isError:    true,
+			numTurns:   2,
+			sessionID:  "9e41b0c2-7d3a-4f85-b6e9-0a2c5d8f1b37",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var sequence []byte
+			var toolUses, toolErrors int
+			var init *InitEvent
+			var result *ResultEvent
+			parser := NewParser(f, 0)
+			for {
+				_, event, err := parser.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("event %d: %v", len(sequence), err)
+				}
+				sequence = append(sequence, eventCodes[event.Type()])
+
+				switch e := event.(type) {
+				case *InitEvent:
+					init = e
+				case *AssistantEvent:
+					for _, block := range e.Blocks {
+						if block.Type == "tool_use" {
+							toolUses++
+						}
+					}
+				case *UserEvent:
+					for _, block := range e.Blocks {
+						if block.IsError {
+							toolErrors++
+						}
+					}
+				case *ResultEvent:
+					result = e
+				}
+			}
+
+			if string(sequence) != tt.sequence {
+				t.Errorf("sequence = %s, want %s", sequence, tt.sequence)
+			}
+			if toolUses != tt.toolUses || toolErrors != tt.toolErrors {
+				t.Errorf("tool uses/errors = %d/%d, want %d/%d", toolUses, toolErrors, tt.toolUses, tt.toolErrors)
+			}
+			if init == nil || init.Init.SessionID != tt.sessionID {
+				t.Errorf("init = %+v, want session %s", init, tt.sessionID)
+			}
+			if result == nil {
+				t.Fatal("no result event")
+			}
+			got := result.Result
+			if got.Subtype != tt.subtype || got.IsError != tt.isError || got.NumTurns != tt.numTurns || got.SessionID != tt.sessionID {
+				t.Errorf("result = %s/%v/%d turns/%s, want %s/%v/%d turns/%s",
+					got.Subtype, got.IsError, got.NumTurns, got.SessionID,
+					tt.subtype, tt.isError, tt.numTurns, tt.sessionID)
+			}
+			if !strings.HasPrefix(got.Result, tt.result) || (tt.result == "" && got.Result != "") {
+				t.Errorf("result text = %q, want it to start with %q", got.Result, tt.result)
+			}
+			if got.TotalCostUSD <= 0 || got.Usage == nil {
+				t.Errorf("result cost %v and usage %v not decoded", got.TotalCostUSD, got.Usage)
+			}
+		})
+	}
+}
+
+func TestProcessStreamOversizedLine(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "error-result.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(fixture)), "\n")
+	limit := 0
+	for _, line := range lines {
+		limit = max(limit, len(line))
+	}
+	// An oversized assistant line between the init and the rest
+	huge := `{"type":"assistant","message":{"content":[{"type":"text","text":"` + strings.Repeat("x", limit) + `"}]}}`
+	stream := strings.Join(append([]string{lines[0], huge}, lines[1:]...), "\n") + "\n"
+
+	var sequence []byte
+	var tooLong *LineTooLongError
+	err = ProcessStream(strings.NewReader(stream), limit, func(line string, event Event, err error) {
+		if err != nil {
+			if !errors.As(err, &tooLong) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if event != nil {
+				t.Errorf("event %v reported with error", event)
+			}
+			sequence = append(sequence, '!')
+			return
+		}
+		sequence = append(sequence, eventCodes[event.Type()])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "s!aur"; string(sequence) != want {
+		t.Errorf("sequence = %s, want %s", sequence, want)
+	}
+	if tooLong == nil {
+		t.Fatal("oversized line not reported")
+	}
+	if tooLong.Limit != limit || tooLong.Size != len(huge)+1 {
+		t.Errorf("error = %+v, want size %d and limit %d", tooLong, len(huge)+1, limit)
+	}
+}
+
+func TestParseLineInvalid(t *testing.T) {
+	if _, err := ParseLine(`{"type":"assistant"`); err == nil {
+		t.Error("truncated line parsed without error")
+	}
+	event, err := ParseLine(`{"type":"system","subtype":"compact_boundary"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, ok := event.(*OtherEvent); !ok || other.Kind != "system" || other.Subtype != "compact_boundary" {
+		t.Errorf("event = %#v, want an OtherEvent", event)
+	}
+}
@@ -0,0 +1,51 @@
+package claudecli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SystemPromptFiles are the per-project system prompt files looked for in the
+// working directory, in order of precedence
+var SystemPromptFiles = []string{
+	filepath.Join(".cc-custom", "system.md"),
+	"CLAUDE.md",
+}
+
+// SystemPrompt is the text passed to claude with --append-system-prompt
+type SystemPrompt struct {
+	Text string
+	// Source is the file the prompt was read from; empty when it was set
+	// during the session
+	Source string
+}
+
+// DetectSystemPrompt reads the first system prompt file found in dir. A
+// missing file is not an error; the returned prompt is empty.
+func DetectSystemPrompt(dir string) (SystemPrompt, error) {
+	for _, name := range SystemPromptFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return SystemPrompt{}, fmt.Errorf("failed to read system prompt %s: %w", path, err)
+		}
+		return SystemPrompt{Text: strings.TrimSpace(string(data)), Source: path}, nil
+	}
+	return SystemPrompt{}, nil
+}
+
+// SystemPromptPath returns the file to edit the system prompt of dir in: the
+// file it is read from, or .cc-custom/system.md when there is none
+func SystemPromptPath(dir string) string {
+	if prompt, err := DetectSystemPrompt(dir); err == nil && prompt.Source != "" {
+		return prompt.Source
+	}
+	return filepath.Join(dir, SystemPromptFiles[0])
+}
@@ -0,0 +1,35 @@
+package claudecli
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StreamTimestamp returns the generation time carried in a stream line's
+// "timestamp" field (RFC 3339 or Unix seconds/milliseconds), or fallback when
+// the line has none
+func StreamTimestamp(line string, fallback time.Time) time.Time {
+	var stamped struct {
+		Timestamp json.RawMessage `json:"timestamp"`
+	}
+	if err := json.Unmarshal([]byte(line), &stamped); err != nil || len(stamped.Timestamp) == 0 {
+		return fallback
+	}
+
+	var text string
+	if err := json.Unmarshal(stamped.Timestamp, &text); err == nil {
+		if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+			return t
+		}
+		return fallback
+	}
+
+	var number float64
+	if err := json.Unmarshal(stamped.Timestamp, &number); err == nil && number > 0 {
+		if number > 1e12 {
+			return time.UnixMilli(int64(number))
+		}
+		return time.Unix(0, int64(number*float64(time.Second)))
+	}
+	return fallback
+}
@@ -0,0 +1,99 @@
+// Package claudecli runs the Claude CLI in stream-json mode and decodes its
+// output. It holds the wire types and parsing shared by the simple CLI and
+// the TUI.
+package claudecli
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Message is a stream-json line from the Claude CLI; result lines fill the
+// summary fields
+type Message struct {
+	Type         string          `json:"type"`
+	Subtype      string          `json:"subtype,omitempty"`
+	Message      json.RawMessage `json:"message,omitempty"`
+	SessionID    string          `json:"session_id,omitempty"`
+	IsError      bool            `json:"is_error,omitempty"`
+	Result       string          `json:"result,omitempty"`
+	DurationMs   int             `json:"duration_ms,omitempty"`
+	NumTurns     int             `json:"num_turns,omitempty"`
+	TotalCostUSD float64         `json:"total_cost_usd,omitempty"`
+	Usage        *Usage          `json:"usage,omitempty"`
+}
+
+// Usage represents token usage statistics
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+}
+
+// Add accumulates other into u
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+	u.OutputTokens += other.OutputTokens
+}
+
+// AssistantMessage represents an assistant response message
+type AssistantMessage struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Role       string          `json:"role"`
+	Model      string          `json:"model"`
+	Content    json.RawMessage `json:"content"`
+	StopReason string          `json:"stop_reason"`
+	Usage      *Usage          `json:"usage,omitempty"`
+}
+
+// SystemInit represents system initialization message
+type SystemInit struct {
+	CWD        string            `json:"cwd"`
+	SessionID  string            `json:"session_id"`
+	Tools      []string          `json:"tools"`
+	Model      string            `json:"model"`
+	MCPServers []MCPServerStatus `json:"mcp_servers"`
+}
+
+// MCPServerStatus is the connection state of an MCP server reported at init
+type MCPServerStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ContentBlock is one block of message content: text, a tool_use in an
+// assistant message, or a tool_result in a user message
+type ContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+// ResultText returns the text of a tool_result block, whose content is either
+// a string or a list of text blocks
+func (b ContentBlock) ResultText() string {
+	var text string
+	if err := json.Unmarshal(b.Content, &text); err == nil {
+		return text
+	}
+	var blocks []ContentBlock
+	if err := json.Unmarshal(b.Content, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, block := range blocks {
+		if block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
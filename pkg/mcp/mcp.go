@@ -0,0 +1,308 @@
+// Package mcp merges the MCP server definitions of several Claude config
+// files into the single file passed to claude's --mcp-config, with servers
+// switched on and off and health checks. The simple CLI and the TUI share it.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// healthTimeout bounds a single server health check
+const healthTimeout = 3 * time.Second
+
+// Config selects the MCP config files to merge and the servers to leave out
+type Config struct {
+	// Files are Claude MCP config files ({"mcpServers": {...}}), merged in
+	// order; a later definition of a server replaces an earlier one
+	Files []string `toml:"files" json:"files"`
+	// Disabled servers are left out of the merged config
+	Disabled []string `toml:"disabled" json:"disabled"`
+}
+
+// Server is an MCP server defined in one of the config files
+type Server struct {
+	Name    string
+	Source  string // config file defining the server
+	Type    string // "stdio", "sse" or "http"
+	Command string
+	URL     string
+	Enabled bool
+
+	definition json.RawMessage
+}
+
+// Target returns the command or URL the server is reached at
+func (s Server) Target() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return s.Command
+}
+
+// Health is the result of checking that a server can be reached
+type Health struct {
+	Server  string
+	OK      bool
+	Detail  string
+	Checked time.Time
+}
+
+// Manager loads MCP servers from several config files and writes the merged
+// config of the enabled ones for claude's --mcp-config
+type Manager struct {
+	mu         sync.Mutex
+	files      []string
+	mergedPath string
+	servers    []Server
+	disabled   map[string]bool
+	builtins   map[string]json.RawMessage
+}
+
+// BuiltinSource is the source of servers added by the application rather
+// than a config file
+const BuiltinSource = "built-in"
+
+// NewManager loads the configured files and writes the merged config to
+// mergedPath
+func NewManager(cfg Config, mergedPath string) (*Manager, error) {
+	m := &Manager{
+		files:      cfg.Files,
+		mergedPath: mergedPath,
+		disabled:   make(map[string]bool),
+	}
+	for _, name := range cfg.Disabled {
+		m.disabled[name] = true
+	}
+	if err := m.Load(); err != nil {
+		return nil, err
+	}
+	if err := m.writeMerged(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MergedPath returns the generated config file to pass to claude
+func (m *Manager) MergedPath() string {
+	return m.mergedPath
+}
+
+// SetBuiltin adds a server used unless a config file defines one with the
+// same name, and rewrites the merged config
+func (m *Manager) SetBuiltin(name string, definition any) error {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return fmt.Errorf("failed to encode MCP server %q: %w", name, err)
+	}
+	m.mu.Lock()
+	if m.builtins == nil {
+		m.builtins = make(map[string]json.RawMessage)
+	}
+	m.builtins[name] = data
+	m.mu.Unlock()
+	if err := m.Load(); err != nil {
+		return err
+	}
+	return m.writeMerged()
+}
+
+// Load (re)reads the server definitions from the config files
+func (m *Manager) Load() error {
+	byName := make(map[string]Server)
+	m.mu.Lock()
+	for name, definition := range m.builtins {
+		server, err := parseServer(name, definition)
+		if err != nil {
+			m.mu.Unlock()
+			return fmt.Errorf("invalid MCP server %q: %w", name, err)
+		}
+		server.Source = BuiltinSource
+		byName[name] = server
+	}
+	m.mu.Unlock()
+	for _, path := range m.files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read MCP config %s: %w", path, err)
+		}
+		var file struct {
+			MCPServers map[string]json.RawMessage `json:"mcpServers"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse MCP config %s: %w", path, err)
+		}
+		for name, definition := range file.MCPServers {
+			server, err := parseServer(name, definition)
+			if err != nil {
+				return fmt.Errorf("invalid MCP server %q in %s: %w", name, path, err)
+			}
+			server.Source = path
+			byName[name] = server
+		}
+	}
+
+	servers := make([]Server, 0, len(byName))
+	for _, server := range byName {
+		servers = append(servers, server)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers = servers
+	return nil
+}
+
+// parseServer reads the fields of a server definition needed for display and
+// health checks; the definition itself is passed through unchanged
+func parseServer(name string, definition json.RawMessage) (Server, error) {
+	var fields struct {
+		Type    string `json:"type"`
+		Command string `json:"command"`
+		URL     string `json:"url"`
+	}
+	if err := json.Unmarshal(definition, &fields); err != nil {
+		return Server{}, err
+	}
+	if fields.Type == "" {
+		fields.Type = "stdio"
+	}
+	return Server{
+		Name:       name,
+		Type:       fields.Type,
+		Command:    fields.Command,
+		URL:        fields.URL,
+		definition: definition,
+	}, nil
+}
+
+// Servers returns all known servers sorted by name
+func (m *Manager) Servers() []Server {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	servers := make([]Server, len(m.servers))
+	for i, server := range m.servers {
+		server.Enabled = !m.disabled[server.Name]
+		servers[i] = server
+	}
+	return servers
+}
+
+// SetEnabled enables or disables a server and rewrites the merged config. It
+// takes effect on the next claude invocation.
+func (m *Manager) SetEnabled(name string, enabled bool) error {
+	if _, ok := m.server(name); !ok {
+		return fmt.Errorf("unknown MCP server %q", name)
+	}
+	m.mu.Lock()
+	if enabled {
+		delete(m.disabled, name)
+	} else {
+		m.disabled[name] = true
+	}
+	m.mu.Unlock()
+	return m.writeMerged()
+}
+
+// server looks up a server by name
+func (m *Manager) server(name string) (Server, bool) {
+	for _, server := range m.Servers() {
+		if server.Name == name {
+			return server, true
+		}
+	}
+	return Server{}, false
+}
+
+// writeMerged writes the enabled servers to the merged config file
+func (m *Manager) writeMerged() error {
+	merged := make(map[string]json.RawMessage)
+	for _, server := range m.Servers() {
+		if server.Enabled {
+			merged[server.Name] = server.definition
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{"mcpServers": merged}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode MCP config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.mergedPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create MCP config directory: %w", err)
+	}
+	if err := os.WriteFile(m.mergedPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write merged MCP config: %w", err)
+	}
+	return nil
+}
+
+// Check verifies that a server can be reached: the URL of sse/http servers
+// must answer and the command of stdio servers must be installed
+func (m *Manager) Check(ctx context.Context, name string) Health {
+	health := Health{Server: name, Checked: time.Now()}
+	server, ok := m.server(name)
+	if !ok {
+		health.Detail = "unknown server"
+		return health
+	}
+
+	switch {
+	case server.URL != "":
+		health.OK, health.Detail = checkURL(ctx, server.URL)
+	case server.Command != "":
+		if path, err := exec.LookPath(server.Command); err != nil {
+			health.Detail = fmt.Sprintf("command not found: %s", server.Command)
+		} else {
+			health.OK, health.Detail = true, path
+		}
+	default:
+		health.Detail = "no command or url"
+	}
+	return health
+}
+
+// CheckAll checks every server concurrently
+func (m *Manager) CheckAll(ctx context.Context) []Health {
+	servers := m.Servers()
+	results := make([]Health, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = m.Check(ctx, name)
+		}(i, server.Name)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkURL reports whether url answers without a server error. Only the
+// response headers are awaited, so streaming SSE endpoints don't block.
+func checkURL(ctx context.Context, url string) (bool, string) {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err.Error()
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, fmt.Sprintf("unreachable: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return false, resp.Status
+	}
+	return true, resp.Status
+}
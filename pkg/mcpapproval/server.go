@@ -0,0 +1,258 @@
+// Package mcpapproval is the MCP permission server behind claude's
+// --permission-prompt-tool. Claude starts it over stdio and calls its
+// approval_prompt tool before running a tool; each call is forwarded to the
+// TUI's approval broker over a unix socket so the user decides in the UI.
+package mcpapproval
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Names claude knows the server and its tool by
+const (
+	ServerName     = "permission"
+	ToolName       = "approval_prompt"
+	PermissionTool = "mcp__" + ServerName + "__" + ToolName
+)
+
+// SocketEnv names the environment variable holding the broker's socket
+const SocketEnv = "CC_CUSTOM_APPROVAL_SOCKET"
+
+// protocolVersion is answered to clients that do not ask for one
+const protocolVersion = "2024-11-05"
+
+// maxMessageBytes bounds a JSON-RPC message read from claude
+const maxMessageBytes = 16 * 1024 * 1024
+
+// DefaultSocket returns the socket a TUI process listens on, unique to the
+// process so several TUIs can run at once
+func DefaultSocket() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("cc-custom-approval-%d.sock", os.Getpid()))
+}
+
+// ServerDefinition returns the MCP config entry that starts the server:
+// command and args run it, and the socket is passed in the environment
+func ServerDefinition(command string, args []string, socket string) map[string]any {
+	return map[string]any{
+		"type":    "stdio",
+		"command": command,
+		"args":    append([]string{}, args...),
+		"env":     map[string]string{SocketEnv: socket},
+	}
+}
+
+// rpcMessage is a JSON-RPC 2.0 request or notification from claude
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// toolCall are the parameters of tools/call
+type toolCall struct {
+	Name      string `json:"name"`
+	Arguments struct {
+		ToolName  string          `json:"tool_name"`
+		Input     json.RawMessage `json:"input"`
+		ToolUseID string          `json:"tool_use_id,omitempty"`
+	} `json:"arguments"`
+}
+
+// Server answers claude's MCP requests
+type Server struct {
+	// Socket is where the approval broker listens; empty denies every
+	// request, as there is no one to ask
+	Socket string
+	// Log receives diagnostics; claude shows the server's stderr in its
+	// MCP logs
+	Log io.Writer
+
+	mu  sync.Mutex
+	out *json.Encoder
+}
+
+// Serve reads newline-delimited JSON-RPC messages from in and writes the
+// responses to out until in ends or ctx is cancelled. Approval prompts are
+// answered concurrently, as claude may ask about several tools at once.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			s.respondError(nil, -32700, "parse error")
+			continue
+		}
+		if msg.Method == "tools/call" {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handleCall(ctx, msg)
+			}()
+			continue
+		}
+		s.handle(msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read MCP messages: %w", err)
+	}
+	return nil
+}
+
+// handle answers the protocol's bookkeeping requests
+func (s *Server) handle(msg rpcMessage) {
+	if len(msg.ID) == 0 {
+		return // notifications need no answer
+	}
+	switch msg.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		version := params.ProtocolVersion
+		if version == "" {
+			version = protocolVersion
+		}
+		s.respond(msg.ID, map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "cc-custom-approval", "version": "1"},
+		})
+	case "ping":
+		s.respond(msg.ID, map[string]any{})
+	case "tools/list":
+		s.respond(msg.ID, map[string]any{"tools": []map[string]any{{
+			"name":        ToolName,
+			"description": "Asks the user to approve a tool call",
+			"inputSchema": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tool_name":   map[string]string{"type": "string"},
+					"input":       map[string]string{"type": "object"},
+					"tool_use_id": map[string]string{"type": "string"},
+				},
+				"required": []string{"tool_name", "input"},
+			},
+		}}})
+	default:
+		s.respondError(msg.ID, -32601, "method not found: "+msg.Method)
+	}
+}
+
+// handleCall answers an approval prompt with the user's decision
+func (s *Server) handleCall(ctx context.Context, msg rpcMessage) {
+	var call toolCall
+	if err := json.Unmarshal(msg.Params, &call); err != nil {
+		s.respondError(msg.ID, -32602, "invalid params")
+		return
+	}
+	if call.Name != ToolName {
+		s.respondError(msg.ID, -32602, "unknown tool: "+call.Name)
+		return
+	}
+
+	decision, err := s.ask(ctx, call)
+	if err != nil {
+		s.logf("approval of %s failed: %v", call.Arguments.ToolName, err)
+		decision, _ = json.Marshal(map[string]string{
+			"behavior": "deny",
+			"message":  fmt.Sprintf("Permission for %s could not be asked: %v", call.Arguments.ToolName, err),
+		})
+	}
+	s.respond(msg.ID, map[string]any{
+		"content": []map[string]string{{"type": "text", "text": string(decision)}},
+	})
+}
+
+// ask forwards a prompt to the broker and returns its decision
+func (s *Server) ask(ctx context.Context, call toolCall) ([]byte, error) {
+	if s.Socket == "" {
+		return nil, fmt.Errorf("no approval UI is running (%s is not set)", SocketEnv)
+	}
+	body, err := json.Marshal(map[string]any{
+		"tool_name":   call.Arguments.ToolName,
+		"input":       call.Arguments.Input,
+		"tool_use_id": call.Arguments.ToolUseID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", s.Socket)
+		},
+	}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://cc-custom/approval", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("the approval UI is not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+	decision, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decision: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("approval UI answered %d: %s", resp.StatusCode, strings.TrimSpace(string(decision)))
+	}
+	return bytes.TrimSpace(decision), nil
+}
+
+func (s *Server) respond(id json.RawMessage, result any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func (s *Server) respondError(id json.RawMessage, code int, message string) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.write(map[string]any{"jsonrpc": "2.0", "id": id, "error": rpcError{Code: code, Message: message}})
+}
+
+// write sends a message; responses of concurrent calls must not interleave
+func (s *Server) write(message any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.out.Encode(message); err != nil {
+		s.logf("failed to write response: %v", err)
+	}
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Log != nil {
+		fmt.Fprintf(s.Log, format+"\n", args...)
+	}
+}
@@ -0,0 +1,82 @@
+// Package platform covers the operating system differences met by both the
+// simple CLI and the TUI: where files live, how processes are inspected and
+// stopped, and which helper programs to run.
+package platform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// ConfigHome returns the base directory for user settings: %AppData% on
+// Windows and ~/.config elsewhere, macOS included, where cc-custom has
+// always kept them
+func ConfigHome() (string, error) {
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the config directory: %w", err)
+		}
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".config"), nil
+}
+
+// ConfigDir returns the directory shared by both binaries for settings and
+// local data: $CC_CUSTOM_CONFIG_DIR if set, otherwise cc-custom under
+// ConfigHome
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("CC_CUSTOM_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	base, err := ConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "cc-custom"), nil
+}
+
+// StateHome returns the base directory for logs and other state:
+// %LocalAppData% on Windows, otherwise $XDG_STATE_HOME or ~/.local/state
+func StateHome() (string, error) {
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the local app data directory: %w", err)
+		}
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// DefaultEditor is the editor run when neither $VISUAL nor $EDITOR is set
+func DefaultEditor() string {
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// ShellCommand runs command through the system shell: sh -c, or cmd /C on
+// Windows
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
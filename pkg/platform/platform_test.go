@@ -0,0 +1,88 @@
+package platform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestConfigHome(t *testing.T) {
+	dir, err := ConfigHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filepath.IsAbs(dir) {
+		t.Errorf("ConfigHome() = %q, want an absolute path", dir)
+	}
+	if runtime.GOOS != "windows" {
+		home, _ := os.UserHomeDir()
+		if want := filepath.Join(home, ".config"); dir != want {
+			t.Errorf("ConfigHome() = %q, want %q", dir, want)
+		}
+	}
+}
+
+func TestStateHome(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		dir, err := StateHome()
+		if err != nil || !filepath.IsAbs(dir) {
+			t.Errorf("StateHome() = %q, %v, want an absolute path", dir, err)
+		}
+		return
+	}
+
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	if dir, err := StateHome(); err != nil || dir != state {
+		t.Errorf("StateHome() = %q, %v, want $XDG_STATE_HOME %q", dir, err, state)
+	}
+	t.Setenv("XDG_STATE_HOME", "")
+	home, _ := os.UserHomeDir()
+	if dir, err := StateHome(); err != nil || dir != filepath.Join(home, ".local", "state") {
+		t.Errorf("StateHome() = %q, %v, want ~/.local/state", dir, err)
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	out, err := ShellCommand(context.Background(), "echo hello").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "hello" {
+		t.Errorf("output = %q, want hello", got)
+	}
+}
+
+func TestProcessAlive(t *testing.T) {
+	if !ProcessAlive(os.Getpid()) {
+		t.Error("this process is reported dead")
+	}
+
+	cmd := ShellCommand(context.Background(), "exit 0")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if ProcessAlive(cmd.Process.Pid) {
+		t.Errorf("exited process %d is reported alive", cmd.Process.Pid)
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	t.Setenv("CC_CUSTOM_CONFIG_DIR", "")
+	home, err := ConfigHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir, err := ConfigDir(); err != nil || dir != filepath.Join(home, "cc-custom") {
+		t.Errorf("ConfigDir() = %q, %v, want cc-custom under %q", dir, err, home)
+	}
+
+	override := t.TempDir()
+	t.Setenv("CC_CUSTOM_CONFIG_DIR", override)
+	if dir, err := ConfigDir(); err != nil || dir != override {
+		t.Errorf("ConfigDir() = %q, %v, want $CC_CUSTOM_CONFIG_DIR %q", dir, err, override)
+	}
+}
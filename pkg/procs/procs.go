@@ -0,0 +1,133 @@
+// Package procs tracks the claude processes started by either binary, one
+// state file each under platform.ConfigDir, so processes left behind by a
+// crashed run can be found and stopped on a later start.
+package procs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"customclaude/pkg/platform"
+)
+
+// Record is the state file written for each running claude process
+type Record struct {
+	PID       int       `json:"pid"`
+	ParentPID int       `json:"parent_pid"`
+	Host      string    `json:"host"`
+	SessionID string    `json:"session_id,omitempty"`
+	Started   time.Time `json:"started"`
+}
+
+// Orphan is a claude process still running after the instance that spawned
+// it exited
+type Orphan struct {
+	Record
+	Command string
+}
+
+// dir returns the directory holding one state file per claude process
+func dir() (string, error) {
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "procs"), nil
+}
+
+// Track writes the state file of a started claude process. Failures only
+// cost orphan detection, so callers log or ignore them.
+func Track(pid int, sessionID string) error {
+	dir, err := dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create process state directory: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	data, err := json.Marshal(Record{
+		PID:       pid,
+		ParentPID: os.Getpid(),
+		Host:      host,
+		SessionID: sessionID,
+		Started:   time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode process state: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, strconv.Itoa(pid)+".json"), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write process state: %w", err)
+	}
+	return nil
+}
+
+// Untrack removes the state file of an exited claude process
+func Untrack(pid int) {
+	if dir, err := dir(); err == nil {
+		os.Remove(filepath.Join(dir, strconv.Itoa(pid)+".json"))
+	}
+}
+
+// FindOrphans returns the tracked claude processes on this host whose
+// spawning instance is gone. State files of processes that already exited,
+// or whose PID now belongs to something other than claude, are removed.
+func FindOrphans() ([]Orphan, error) {
+	dir, err := dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process state: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	var orphans []Orphan
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			os.Remove(path)
+			continue
+		}
+		if record.Host != host || platform.ProcessAlive(record.ParentPID) {
+			continue
+		}
+
+		command := ""
+		if platform.ProcessAlive(record.PID) {
+			command = platform.ProcessCommand(record.PID)
+		}
+		if !strings.Contains(command, "claude") {
+			os.Remove(path)
+			continue
+		}
+		orphans = append(orphans, Orphan{Record: record, Command: command})
+	}
+	return orphans, nil
+}
+
+// Kill terminates an orphaned claude process and forgets it
+func Kill(orphan Orphan) error {
+	err := platform.Terminate(orphan.PID)
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to kill process %d: %w", orphan.PID, err)
+	}
+	Untrack(orphan.PID)
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package sessionlock keeps advisory lock files so the simple CLI, the TUI
+// and its tabs never resume the same Claude session concurrently. Locks live
+// in the locks directory under platform.ConfigDir, one file per session ID.
+package sessionlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"customclaude/pkg/platform"
+)
+
+// Lock is the content of a lock file held by the process that currently owns
+// a Claude session
+type Lock struct {
+	PID        int       `json:"pid"`
+	Host       string    `json:"host"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// LockedError is returned when another live owner holds the session
+type LockedError struct {
+	SessionID string
+	Holder    Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("session %s is in use by PID %d on %s since %s (use /takeover to take it over)",
+		e.SessionID, e.Holder.PID, e.Holder.Host, e.Holder.AcquiredAt.Local().Format("15:04:05"))
+}
+
+// alive reports whether the lock holder may still be running. Holders on
+// other hosts cannot be checked and are assumed alive.
+func (l Lock) alive() bool {
+	if host, _ := os.Hostname(); host != l.Host {
+		return true
+	}
+	return platform.ProcessAlive(l.PID)
+}
+
+// Path returns the lock file for a session ID
+func Path(sessionID string) (string, error) {
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "locks", sessionID+".lock"), nil
+}
+
+// read reads a lock file
+func read(path string) (Lock, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Lock{}, false
+	}
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return Lock{}, false
+	}
+	return lock, true
+}
+
+// Locker holds at most one session lock at a time. Each Locker is a distinct
+// owner, so two in the same process (tabs of the TUI) do not share a session
+// either. The zero value is ready to use.
+type Locker struct {
+	owner string
+	held  string
+}
+
+// ownerID identifies this locker in lock files
+func (l *Locker) ownerID() string {
+	if l.owner == "" {
+		l.owner = fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	}
+	return l.owner
+}
+
+// Held returns the session whose lock this locker holds, or ""
+func (l *Locker) Held() string {
+	return l.held
+}
+
+// Acquire takes the lock for sessionID, releasing the previously held one.
+// Locks of dead processes are taken over silently; live ones only when force
+// is set, otherwise a *LockedError is returned.
+func (l *Locker) Acquire(sessionID string, force bool) error {
+	if sessionID == "" {
+		return nil
+	}
+	path, err := Path(sessionID)
+	if err != nil {
+		return err
+	}
+	if sessionID == l.held {
+		// Still ours unless another instance took it over
+		if holder, ok := read(path); ok && holder.Owner == l.ownerID() {
+			return nil
+		}
+		l.held = ""
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	data, err := json.Marshal(Lock{
+		PID:        os.Getpid(),
+		Host:       host,
+		Owner:      l.ownerID(),
+		AcquiredAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode session lock: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, err = file.Write(data)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("failed to write session lock: %w", err)
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrExist) || attempt > 0 {
+			return fmt.Errorf("failed to create session lock: %w", err)
+		}
+
+		holder, ok := read(path)
+		if ok && holder.Owner != l.ownerID() && holder.alive() && !force {
+			return &LockedError{SessionID: sessionID, Holder: holder}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove stale session lock: %w", err)
+		}
+	}
+
+	l.Release()
+	l.held = sessionID
+	return nil
+}
+
+// Release removes the lock held by this locker, if any
+func (l *Locker) Release() {
+	if l.held == "" {
+		return
+	}
+	if path, err := Path(l.held); err == nil {
+		if holder, ok := read(path); ok && holder.Owner == l.ownerID() {
+			os.Remove(path)
+		}
+	}
+	l.held = ""
+}
+
+// Holder returns the live owner of sessionID when another locker holds it
+func (l *Locker) Holder(sessionID string) (Lock, bool) {
+	if sessionID == "" || sessionID == l.held {
+		return Lock{}, false
+	}
+	path, err := Path(sessionID)
+	if err != nil {
+		return Lock{}, false
+	}
+	holder, ok := read(path)
+	if !ok || holder.Owner == l.ownerID() || !holder.alive() {
+		return Lock{}, false
+	}
+	return holder, true
+}
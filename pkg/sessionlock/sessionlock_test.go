@@ -0,0 +1,87 @@
+package sessionlock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"customclaude/pkg/platform"
+)
+
+func TestLocker(t *testing.T) {
+	t.Setenv("CC_CUSTOM_CONFIG_DIR", t.TempDir())
+	var first, second Locker
+	t.Cleanup(first.Release)
+	t.Cleanup(second.Release)
+
+	if err := first.Acquire("session-1", false); err != nil {
+		t.Fatal(err)
+	}
+	// Acquiring again keeps the lock
+	if err := first.Acquire("session-1", false); err != nil {
+		t.Fatal(err)
+	}
+
+	var locked *LockedError
+	if err := second.Acquire("session-1", false); !errors.As(err, &locked) {
+		t.Fatalf("err = %v, want LockedError", err)
+	}
+	if locked.Holder.PID != os.Getpid() {
+		t.Errorf("holder PID %d, want %d", locked.Holder.PID, os.Getpid())
+	}
+	if _, ok := second.Holder("session-1"); !ok {
+		t.Error("Holder does not report the other owner")
+	}
+
+	// Taking over moves the lock; the old owner no longer holds it
+	if err := second.Acquire("session-1", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Acquire("session-1", false); !errors.As(err, &locked) {
+		t.Errorf("err = %v after take over, want LockedError", err)
+	}
+
+	// Releasing removes the lock file
+	second.Release()
+	path, err := Path("session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file left after release: %v", err)
+	}
+}
+
+func TestLockerOfDeadProcess(t *testing.T) {
+	t.Setenv("CC_CUSTOM_CONFIG_DIR", t.TempDir())
+
+	cmd := platform.ShellCommand(context.Background(), "exit 0")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	stale, _ := json.Marshal(Lock{PID: cmd.Process.Pid, Host: host, Owner: "gone", AcquiredAt: time.Now()})
+	path, err := Path("session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, stale, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var locker Locker
+	t.Cleanup(locker.Release)
+	if err := locker.Acquire("session-1", false); err != nil {
+		t.Fatalf("stale lock not taken over: %v", err)
+	}
+	if holder, ok := read(path); !ok || holder.PID != os.Getpid() {
+		t.Errorf("lock holder %+v, want this process", holder)
+	}
+}
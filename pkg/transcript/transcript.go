@@ -0,0 +1,88 @@
+// Package transcript holds what the simple CLI and the TUI share when
+// exporting a conversation: the path template, the Markdown rendering of its
+// steps and the file writing.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultPathTemplate is used when no export path template is configured
+const DefaultPathTemplate = "transcripts/{date}-{session_id}.md"
+
+// Entry is one step of the conversation: a user prompt, assistant text, a
+// tool invocation or a notice
+type Entry struct {
+	Type      string          `json:"type"`
+	Content   string          `json:"content"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// ExpandPath fills the placeholders of an export path template: {date},
+// {time}, {session_id} and {conversation_id}. An empty template is
+// DefaultPathTemplate.
+func ExpandPath(template, sessionID, conversationID string) string {
+	if template == "" {
+		template = DefaultPathTemplate
+	}
+	if sessionID == "" {
+		sessionID = "no-session"
+	}
+	now := time.Now()
+	return strings.NewReplacer(
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("150405"),
+		"{session_id}", sessionID,
+		"{conversation_id}", conversationID,
+	).Replace(template)
+}
+
+// IsJSONL reports whether an export path asks for JSON lines rather than
+// Markdown
+func IsJSONL(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".json":
+		return true
+	}
+	return false
+}
+
+// Markdown renders the entries as Markdown sections
+func Markdown(entries []Entry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		stamp := entry.Timestamp.Format("15:04:05")
+		switch entry.Type {
+		case "user":
+			fmt.Fprintf(&b, "## 👤 User (%s)\n\n%s\n\n", stamp, entry.Content)
+		case "assistant":
+			fmt.Fprintf(&b, "## 🤖 Claude (%s)\n\n%s\n\n", stamp, entry.Content)
+		case "tool_use":
+			fmt.Fprintf(&b, "> 🔧 %s (%s)\n", entry.Content, stamp)
+			if len(entry.ToolInput) > 0 && string(entry.ToolInput) != "null" {
+				fmt.Fprintf(&b, ">\n> ```json\n> %s\n> ```\n", entry.ToolInput)
+			}
+			b.WriteString("\n")
+		default:
+			fmt.Fprintf(&b, "_%s_ (%s)\n\n", entry.Content, stamp)
+		}
+	}
+	return b.String()
+}
+
+// Write writes an export to path, creating its directory
+func Write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package transcript
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandPath(t *testing.T) {
+	date := time.Now().Format("2006-01-02")
+	tests := []struct {
+		template, sessionID, conversationID string
+		want                                string
+	}{
+		{"", "abc", "", "transcripts/" + date + "-abc.md"},
+		{"out/{conversation_id}-{session_id}.jsonl", "", "conv-1", "out/conv-1-no-session.jsonl"},
+	}
+	for _, tt := range tests {
+		if got := ExpandPath(tt.template, tt.sessionID, tt.conversationID); got != tt.want {
+			t.Errorf("ExpandPath(%q, %q, %q) = %q, want %q", tt.template, tt.sessionID, tt.conversationID, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	got := Markdown([]Entry{
+		{Type: "user", Content: "list files", Timestamp: at},
+		{Type: "tool_use", Content: "Bash", ToolInput: json.RawMessage(`{"command":"ls"}`), Timestamp: at},
+		{Type: "tool_use", Content: "Read", ToolInput: json.RawMessage("null"), Timestamp: at},
+		{Type: "assistant", Content: "Two files.", Timestamp: at},
+		{Type: "system", Content: "Compacted", Timestamp: at},
+	})
+	want := strings.Join([]string{
+		"## 👤 User (15:04:05)\n\nlist files\n\n",
+		"> 🔧 Bash (15:04:05)\n>\n> ```json\n> {\"command\":\"ls\"}\n> ```\n\n",
+		"> 🔧 Read (15:04:05)\n\n",
+		"## 🤖 Claude (15:04:05)\n\nTwo files.\n\n",
+		"_Compacted_ (15:04:05)\n\n",
+	}, "")
+	if got != want {
+		t.Errorf("Markdown =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+
+	"customclaude/pkg/claudecli"
+	"customclaude/pkg/mcp"
+	"customclaude/pkg/platform"
+	"customclaude/pkg/transcript"
+)
+
+// Config holds user settings shared with the complex TUI. Keybindings are
+// only used by the TUI but are accepted here so both read the same file.
+type Config struct {
+	Model          string            `toml:"model"`
+	MCPConfig      string            `toml:"mcp_config"`
+	PermissionTool string            `toml:"permission_tool"`
+	Theme          string            `toml:"theme"`
+	WordWrap       int               `toml:"word_wrap"`
+	Keybindings    map[string]string `toml:"keybindings"`
+	ExportPath     string            `toml:"export_path"`
+	MaxLineBytes   int               `toml:"max_stream_line_bytes"`
+	Retries        RetryPolicy       `toml:"retries"`
+	MCP            mcp.Config        `toml:"mcp"`
+}
+
+// defaultConfig returns the settings used when no config file exists
+func defaultConfig() Config {
+	return Config{
+		MCPConfig:      "config.json",
+		PermissionTool: "mcp__permission__approval_prompt",
+		WordWrap:       80,
+		ExportPath:     transcript.DefaultPathTemplate,
+		MaxLineBytes:   claudecli.DefaultMaxLineBytes,
+		Retries:        defaultRetryPolicy(),
+	}
+}
+
+// configPath returns the location of config.toml
+func configPath() (string, error) {
+	if path := os.Getenv("CC_CUSTOM_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// loadConfig reads config.toml (if present) and applies CC_CUSTOM_*
+// environment variable overrides
+func loadConfig() (Config, error) {
+	cfg := defaultConfig()
+
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	if _, err := toml.DecodeFile(path, &cfg); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	for env, field := range map[string]*string{
+		"CC_CUSTOM_MODEL":           &cfg.Model,
+		"CC_CUSTOM_MCP_CONFIG":      &cfg.MCPConfig,
+		"CC_CUSTOM_PERMISSION_TOOL": &cfg.PermissionTool,
+		"CC_CUSTOM_THEME":           &cfg.Theme,
+		"CC_CUSTOM_EXPORT_PATH":     &cfg.ExportPath,
+	} {
+		if value, ok := os.LookupEnv(env); ok {
+			*field = value
+		}
+	}
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_WORD_WRAP")); err == nil {
+		cfg.WordWrap = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_MAX_ATTEMPTS")); err == nil {
+		cfg.Retries.MaxAttempts = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CC_CUSTOM_MAX_STREAM_LINE_BYTES")); err == nil {
+		cfg.MaxLineBytes = value
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"customclaude/pkg/platform"
+)
+
+// runEditor opens path in $VISUAL or $EDITOR, or the platform default, and waits for it to exit
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = platform.DefaultEditor()
+	}
+	// The editor may carry flags, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+	return nil
+}
+
+// editPrompt writes a prompt in the editor, starting from initial, and
+// returns the saved text. An empty prompt means there is nothing to send.
+func editPrompt(initial string) (string, error) {
+	file, err := os.CreateTemp("", "cc-custom-prompt-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create prompt file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	_, err = file.WriteString(initial)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+
+	if err := runEditor(path); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"customclaude/pkg/transcript"
+)
+
+// record appends an entry to the conversation transcript
+func (sm *SessionManager) record(entryType, content string, toolInput json.RawMessage) {
+	sm.transcript = append(sm.transcript, transcript.Entry{
+		Type:      entryType,
+		Content:   content,
+		ToolInput: toolInput,
+		Timestamp: sm.eventTime(),
+	})
+}
+
+// exportPath fills the placeholders of the configured template. A non-empty
+// format ("md" or "jsonl") replaces the template's extension.
+func (sm *SessionManager) exportPath(format string) string {
+	template := sm.config.ExportPath
+	if template == "" {
+		template = transcript.DefaultPathTemplate
+	}
+	if format != "" {
+		template = strings.TrimSuffix(template, filepath.Ext(template)) + "." + format
+	}
+	return transcript.ExpandPath(template, sm.CurrentSessionID, "")
+}
+
+// ExportTranscript writes the full conversation to a Markdown or JSONL file
+// and returns its path
+func (sm *SessionManager) ExportTranscript(format string) (string, error) {
+	switch format {
+	case "", "md", "jsonl":
+	default:
+		return "", fmt.Errorf("unknown export format %q (use md or jsonl)", format)
+	}
+
+	path := sm.exportPath(format)
+	var data []byte
+	if transcript.IsJSONL(path) {
+		var err error
+		if data, err = sm.transcriptJSONL(); err != nil {
+			return "", err
+		}
+	} else {
+		data = []byte(sm.transcriptMarkdown())
+	}
+
+	if err := transcript.Write(path, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// transcriptJSONL encodes each transcript entry and a final stats record as
+// JSON lines
+func (sm *SessionManager) transcriptJSONL() ([]byte, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, entry := range sm.transcript {
+		if err := enc.Encode(entry); err != nil {
+			return nil, fmt.Errorf("failed to encode transcript: %w", err)
+		}
+	}
+	stats := map[string]interface{}{
+		"type":          "stats",
+		"session_id":    sm.CurrentSessionID,
+		"session_chain": sm.SessionChain,
+		"model":         sm.Model,
+		"duration_ms":   sm.CumulativeDuration,
+		"num_turns":     sm.CumulativeTurns,
+		"cost_usd":      sm.CumulativeCost,
+		"usage":         sm.CumulativeUsage,
+	}
+	if err := enc.Encode(stats); err != nil {
+		return nil, fmt.Errorf("failed to encode transcript: %w", err)
+	}
+	return []byte(b.String()), nil
+}
+
+// transcriptMarkdown renders the transcript followed by the conversation
+// summary
+func (sm *SessionManager) transcriptMarkdown() string {
+	var b strings.Builder
+	b.WriteString("# Conversation Transcript\n\n")
+	fmt.Fprintf(&b, "- **Session:** %s\n", sm.CurrentSessionID)
+	if sm.Model != "" {
+		fmt.Fprintf(&b, "- **Model:** %s\n", sm.Model)
+	}
+	fmt.Fprintf(&b, "- **Started:** %s\n\n", sm.ConversationStart.Format(time.RFC3339))
+
+	b.WriteString(transcript.Markdown(sm.transcript))
+	b.WriteString(sm.SummaryMarkdown())
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"customclaude/pkg/claudecli"
+)
+
+// clipboardCommands lists the clipboard helpers we know how to drive, in the
+// order they are tried.
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard pipes text into the first available clipboard helper
+func copyToClipboard(text string) error {
+	for _, candidate := range clipboardCommands {
+		path, err := exec.LookPath(candidate[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", candidate[0], err)
+		}
+		return nil
+	}
+	return errors.New("no clipboard utility found")
+}
+
+// handoffArgs returns the arguments needed to resume the current session in
+// the official interactive CLI
+func (sm *SessionManager) handoffArgs() []string {
+	args := []string{"--resume", sm.CurrentSessionID}
+	if sm.Model != "" {
+		args = append(args, "--model", sm.Model)
+	}
+	return args
+}
+
+// handoffCommand returns the shell command for resuming the current session
+func (sm *SessionManager) handoffCommand() string {
+	return "claude " + strings.Join(sm.handoffArgs(), " ")
+}
+
+// Handoff prints the resume command for the current session and copies it to
+// the clipboard. When launch is true the official CLI is started in
+// interactive mode and takes over the terminal until it exits.
+func (sm *SessionManager) Handoff(launch bool) error {
+	if sm.CurrentSessionID == "" {
+		return errors.New("no active session to hand off")
+	}
+
+	command := sm.handoffCommand()
+	fmt.Printf("%s %s\n",
+		metricStyle.Render("Resume with:"),
+		valueStyle.Render(command))
+
+	if err := copyToClipboard(command); err != nil {
+		fmt.Print(subtitleStyle.Render(fmt.Sprintf("Could not copy to clipboard: %v", err)))
+		fmt.Print("\n")
+	} else {
+		fmt.Print(subtitleStyle.Render("Copied to clipboard"))
+		fmt.Print("\n")
+	}
+
+	if !launch {
+		return nil
+	}
+
+	fmt.Printf("\n%s %s\n\n",
+		systemStyle.Render("🚪 [System]"),
+		subtitleStyle.Render("Handing off to interactive Claude Code..."))
+
+	binary, err := claudecli.FindBinary()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(binary, sm.handoffArgs()...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("interactive session failed: %w", err)
+	}
+
+	fmt.Printf("\n%s %s\n",
+		systemStyle.Render("🔙 [System]"),
+		subtitleStyle.Render("Returned from interactive session"))
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"customclaude/pkg/claudecli"
+	"customclaude/pkg/procs"
+)
+
+// headlessPrompt returns the prompt for non-interactive mode: the -p value,
+// stdin when it is piped, or both (stdin appended as context). ok is false
+// when neither is present and the interactive loop should run.
+func headlessPrompt(flagPrompt string) (prompt string, ok bool, err error) {
+	var piped string
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		piped = strings.TrimSpace(string(data))
+	}
+
+	switch {
+	case flagPrompt != "" && piped != "":
+		return flagPrompt + "\n\n" + piped, true, nil
+	case flagPrompt != "":
+		return flagPrompt, true, nil
+	case piped != "":
+		return piped, true, nil
+	}
+	return "", false, nil
+}
+
+// Exit codes of the headless modes, so scripts can tell what happened
+const (
+	exitOK             = 0
+	exitModelError     = 1   // claude failed or sent no result
+	exitUsage          = 2   // bad command line
+	exitBudgetExceeded = 3   // --max-budget or claude's turn limit was hit
+	exitToolFailure    = 4   // the turn failed after a tool call failed
+	exitCancelled      = 130 // interrupted by SIGINT or SIGTERM
+)
+
+// headlessOptions are the flags of the headless modes
+type headlessOptions struct {
+	// JSON prints the raw result message instead of its text
+	JSON bool
+	// MaxBudget fails runs costing more, in USD; 0 for no limit
+	MaxBudget float64
+	// ResultJSON is a file the run's headlessOutcome is written to
+	ResultJSON string
+}
+
+// headlessOutcome summarizes a headless run for --result-json
+type headlessOutcome struct {
+	Status      string   `json:"status"`
+	ExitCode    int      `json:"exit_code"`
+	SessionID   string   `json:"session_id,omitempty"`
+	Subtype     string   `json:"subtype,omitempty"`
+	Result      string   `json:"result,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	CostUSD     float64  `json:"cost_usd"`
+	DurationMs  int      `json:"duration_ms"`
+	NumTurns    int      `json:"num_turns"`
+	FailedTools []string `json:"failed_tools,omitempty"`
+}
+
+// newHeadlessOutcome classifies a finished run. result is nil when claude
+// sent none; runErr is the failure to run or read claude, if any.
+func newHeadlessOutcome(ctx context.Context, result *claudecli.Message, failedTools []string, runErr error, maxBudget float64) headlessOutcome {
+	outcome := headlessOutcome{FailedTools: failedTools}
+	if runErr != nil {
+		outcome.Error = runErr.Error()
+	}
+	if result != nil {
+		outcome.SessionID = result.SessionID
+		outcome.Subtype = result.Subtype
+		outcome.Result = result.Result
+		outcome.CostUSD = result.TotalCostUSD
+		outcome.DurationMs = result.DurationMs
+		outcome.NumTurns = result.NumTurns
+	}
+
+	failed := result == nil || result.IsError || result.Subtype != "success" || runErr != nil
+	switch {
+	case ctx.Err() != nil:
+		outcome.Status, outcome.ExitCode = "cancelled", exitCancelled
+	case result != nil && (result.Subtype == "error_max_turns" || result.Subtype == "error_max_budget_usd"):
+		outcome.Status, outcome.ExitCode = "budget_exceeded", exitBudgetExceeded
+	case maxBudget > 0 && outcome.CostUSD > maxBudget:
+		outcome.Status, outcome.ExitCode = "budget_exceeded", exitBudgetExceeded
+		if outcome.Error == "" {
+			outcome.Error = fmt.Sprintf("cost $%.4f exceeds the budget of $%.4f", outcome.CostUSD, maxBudget)
+		}
+	case failed && len(failedTools) > 0:
+		outcome.Status, outcome.ExitCode = "tool_failure", exitToolFailure
+	case failed:
+		outcome.Status, outcome.ExitCode = "model_error", exitModelError
+	default:
+		outcome.Status, outcome.ExitCode = "ok", exitOK
+	}
+	return outcome
+}
+
+// finish writes the outcome to path, when set, and returns its exit code
+func (o headlessOutcome) finish(path string) int {
+	if path == "" {
+		return o.ExitCode
+	}
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write result summary: %v\n", err)
+	}
+	return o.ExitCode
+}
+
+// headlessContext is cancelled by SIGINT or SIGTERM, which stops claude
+func headlessContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// runHeadless runs a single prompt without any interactive output and
+// returns the process exit code, one of the exit* constants. With
+// opts.JSON the raw result message is printed instead of its text.
+func (sm *SessionManager) runHeadless(prompt string, opts headlessOptions) int {
+	ctx, stop := headlessContext()
+	defer stop()
+
+	client := claudecli.NewClient(sm.claudeOptions())
+	client.Stderr = os.Stderr
+	run, err := client.Start(ctx, prompt, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return newHeadlessOutcome(ctx, nil, nil, err, opts.MaxBudget).finish(opts.ResultJSON)
+	}
+	procs.Track(run.PID(), sm.CurrentSessionID) // failing only costs orphan detection
+	defer procs.Untrack(run.PID())
+
+	var result *claudecli.Message
+	var resultLine string
+	toolNames := make(map[string]string)
+	var failedTools []string
+	err = claudecli.ProcessStream(run.Stdout(), sm.config.MaxLineBytes, func(line string, event claudecli.Event, err error) {
+		var tooLong *claudecli.LineTooLongError
+		if errors.As(err, &tooLong) {
+			fmt.Fprintln(os.Stderr, tooLong)
+		}
+		switch e := event.(type) {
+		case *claudecli.AssistantEvent:
+			for _, block := range e.Blocks {
+				if block.Type == "tool_use" {
+					toolNames[block.ID] = block.Name
+				}
+			}
+		case *claudecli.UserEvent:
+			for _, block := range e.Blocks {
+				if block.ToolUseID != "" && block.IsError {
+					failedTools = append(failedTools, toolNames[block.ToolUseID])
+				}
+			}
+		case *claudecli.ResultEvent:
+			result = &e.Result
+			resultLine = line
+		}
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to read output: %w", err)
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if waitErr := run.Wait(); waitErr != nil && err == nil {
+		err = fmt.Errorf("command failed: %w", waitErr)
+	}
+	if result == nil {
+		if err == nil {
+			err = errors.New("no result received from claude")
+		}
+		fmt.Fprintln(os.Stderr, err)
+	} else if opts.JSON {
+		fmt.Println(resultLine)
+	} else if result.IsError {
+		fmt.Fprintln(os.Stderr, result.Result)
+	} else {
+		fmt.Println(result.Result)
+	}
+
+	return newHeadlessOutcome(ctx, result, failedTools, err, opts.MaxBudget).finish(opts.ResultJSON)
+}
+
+// runStreamed runs a single prompt like runHeadless, but shows the whole
+// stream through the --output sink instead of only the result
+func (sm *SessionManager) runStreamed(prompt string, opts headlessOptions) int {
+	ctx, stop := headlessContext()
+	defer stop()
+
+	err := sm.runCommand(ctx, prompt, false)
+	if err != nil {
+		sm.output.Error(err)
+	}
+	return newHeadlessOutcome(ctx, sm.lastResult, sm.failedTools, err, opts.MaxBudget).finish(opts.ResultJSON)
+}
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
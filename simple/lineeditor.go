@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+
+	"customclaude/pkg/platform"
+)
+
+// maxHistory is the number of prompts kept in the history file
+const maxHistory = 1000
+
+// Control keys handled by the line editor
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyBackspace = 8
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// lineEditor reads prompts from the terminal with history recall (up/down)
+// and reverse search (Ctrl+R). When stdin is not a terminal it falls back to
+// plain line reads.
+type lineEditor struct {
+	reader      *bufio.Reader
+	fd          int
+	history     []string
+	historyPath string
+}
+
+// newLineEditor creates a line editor and loads history from historyPath.
+// An empty path keeps history in memory only.
+func newLineEditor(historyPath string) *lineEditor {
+	le := &lineEditor{
+		reader:      bufio.NewReader(os.Stdin),
+		fd:          int(os.Stdin.Fd()),
+		historyPath: historyPath,
+	}
+	if historyPath != "" {
+		if data, err := os.ReadFile(historyPath); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if line != "" {
+					le.history = append(le.history, line)
+				}
+			}
+		}
+		if len(le.history) > maxHistory {
+			le.history = le.history[len(le.history)-maxHistory:]
+		}
+	}
+	return le
+}
+
+// historyPath returns the default history file in the config directory
+func historyPath() string {
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "history")
+}
+
+// AddHistory records a submitted prompt, skipping immediate repeats
+func (le *lineEditor) AddHistory(line string) {
+	if line == "" || strings.Contains(line, "\n") {
+		return
+	}
+	if n := len(le.history); n > 0 && le.history[n-1] == line {
+		return
+	}
+	le.history = append(le.history, line)
+	if len(le.history) > maxHistory {
+		le.history = le.history[len(le.history)-maxHistory:]
+	}
+
+	if le.historyPath == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(le.historyPath), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(le.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// ReadLine prints the prompt and reads one line of input. Ctrl+C and Ctrl+D
+// on an empty line return io.EOF.
+func (le *lineEditor) ReadLine(prompt string) (string, error) {
+	if !term.IsTerminal(le.fd) {
+		fmt.Print(prompt)
+		line, err := le.reader.ReadString('\n')
+		if err != nil && !(err == io.EOF && line != "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	state, err := term.MakeRaw(le.fd)
+	if err != nil {
+		return "", fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer term.Restore(le.fd, state)
+
+	ed := &editState{prompt: prompt, historyIndex: len(le.history)}
+	ed.render()
+
+	for {
+		r, _, err := le.reader.ReadRune()
+		if err != nil {
+			fmt.Print("\r\n")
+			return "", err
+		}
+
+		if ed.searching {
+			if done := le.handleSearchKey(ed, r); done {
+				fmt.Print("\r\n")
+				return string(ed.line), nil
+			}
+			continue
+		}
+
+		switch r {
+		case keyEnter, '\n':
+			fmt.Print("\r\n")
+			return string(ed.line), nil
+		case keyCtrlC:
+			fmt.Print("\r\n")
+			return "", io.EOF
+		case keyCtrlD:
+			if len(ed.line) == 0 {
+				fmt.Print("\r\n")
+				return "", io.EOF
+			}
+			ed.deleteForward()
+		case keyCtrlA:
+			ed.pos = 0
+		case keyCtrlE:
+			ed.pos = len(ed.line)
+		case keyCtrlB:
+			ed.moveLeft()
+		case keyCtrlF:
+			ed.moveRight()
+		case keyCtrlP:
+			le.recall(ed, -1)
+		case keyCtrlN:
+			le.recall(ed, 1)
+		case keyCtrlK:
+			ed.line = ed.line[:ed.pos]
+		case keyCtrlU:
+			ed.line = append([]rune(nil), ed.line[ed.pos:]...)
+			ed.pos = 0
+		case keyCtrlW:
+			ed.deleteWord()
+		case keyCtrlL:
+			fmt.Print("\x1b[H\x1b[2J")
+		case keyCtrlR:
+			ed.startSearch()
+		case keyBackspace, keyDelete:
+			ed.backspace()
+		case keyEscape:
+			le.handleEscape(ed)
+		default:
+			if r >= ' ' {
+				ed.insert(r)
+			}
+		}
+		ed.render()
+	}
+}
+
+// handleEscape handles arrow keys and other escape sequences
+func (le *lineEditor) handleEscape(ed *editState) {
+	next, _, err := le.reader.ReadRune()
+	if err != nil || (next != '[' && next != 'O') {
+		return
+	}
+	code, _, err := le.reader.ReadRune()
+	if err != nil {
+		return
+	}
+	switch code {
+	case 'A':
+		le.recall(ed, -1)
+	case 'B':
+		le.recall(ed, 1)
+	case 'C':
+		ed.moveRight()
+	case 'D':
+		ed.moveLeft()
+	case 'H':
+		ed.pos = 0
+	case 'F':
+		ed.pos = len(ed.line)
+	case '1', '3', '4', '7', '8':
+		// Extended sequences such as ESC [ 3 ~ (delete) end with '~'
+		if tail, _, err := le.reader.ReadRune(); err == nil && tail == '~' {
+			switch code {
+			case '3':
+				ed.deleteForward()
+			case '1', '7':
+				ed.pos = 0
+			case '4', '8':
+				ed.pos = len(ed.line)
+			}
+		}
+	}
+}
+
+// recall moves through history; dir is -1 for older and 1 for newer entries.
+// The line being edited is kept so stepping past the newest entry restores it.
+func (le *lineEditor) recall(ed *editState, dir int) {
+	index := ed.historyIndex + dir
+	if index < 0 || index > len(le.history) {
+		return
+	}
+	if ed.historyIndex == len(le.history) {
+		ed.draft = append([]rune(nil), ed.line...)
+	}
+	ed.historyIndex = index
+	if index == len(le.history) {
+		ed.setLine(ed.draft)
+	} else {
+		ed.setLine([]rune(le.history[index]))
+	}
+}
+
+// handleSearchKey handles a key during reverse search and reports whether the
+// line should be submitted
+func (le *lineEditor) handleSearchKey(ed *editState, r rune) bool {
+	switch r {
+	case keyEnter, '\n':
+		ed.acceptSearch()
+		return true
+	case keyCtrlR:
+		le.search(ed, ed.searchIndex-1)
+	case keyBackspace, keyDelete:
+		if len(ed.query) > 0 {
+			ed.query = ed.query[:len(ed.query)-1]
+			le.search(ed, len(le.history)-1)
+		}
+	case keyCtrlG, keyCtrlC:
+		ed.cancelSearch()
+	case keyEscape:
+		ed.acceptSearch()
+		le.handleEscape(ed)
+	default:
+		if r >= ' ' {
+			ed.query = append(ed.query, r)
+			le.search(ed, ed.searchIndex)
+		} else {
+			ed.acceptSearch()
+		}
+	}
+	ed.render()
+	return false
+}
+
+// search finds the newest history entry at or before from that contains the
+// query
+func (le *lineEditor) search(ed *editState, from int) {
+	query := string(ed.query)
+	if from >= len(le.history) {
+		from = len(le.history) - 1
+	}
+	for i := from; i >= 0; i-- {
+		if strings.Contains(le.history[i], query) {
+			ed.searchIndex = i
+			ed.match = le.history[i]
+			ed.searchFailed = false
+			return
+		}
+	}
+	ed.searchFailed = true
+}
+
+// editState is the line being edited by a single ReadLine call
+type editState struct {
+	prompt       string
+	line         []rune
+	pos          int
+	draft        []rune
+	historyIndex int
+
+	searching    bool
+	query        []rune
+	match        string
+	searchIndex  int
+	searchFailed bool
+	savedLine    []rune
+}
+
+func (ed *editState) setLine(line []rune) {
+	ed.line = append([]rune(nil), line...)
+	ed.pos = len(ed.line)
+}
+
+func (ed *editState) insert(r rune) {
+	ed.line = append(ed.line[:ed.pos], append([]rune{r}, ed.line[ed.pos:]...)...)
+	ed.pos++
+}
+
+func (ed *editState) backspace() {
+	if ed.pos == 0 {
+		return
+	}
+	ed.line = append(ed.line[:ed.pos-1], ed.line[ed.pos:]...)
+	ed.pos--
+}
+
+func (ed *editState) deleteForward() {
+	if ed.pos < len(ed.line) {
+		ed.line = append(ed.line[:ed.pos], ed.line[ed.pos+1:]...)
+	}
+}
+
+func (ed *editState) deleteWord() {
+	start := ed.pos
+	for start > 0 && ed.line[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && ed.line[start-1] != ' ' {
+		start--
+	}
+	ed.line = append(ed.line[:start], ed.line[ed.pos:]...)
+	ed.pos = start
+}
+
+func (ed *editState) moveLeft() {
+	if ed.pos > 0 {
+		ed.pos--
+	}
+}
+
+func (ed *editState) moveRight() {
+	if ed.pos < len(ed.line) {
+		ed.pos++
+	}
+}
+
+func (ed *editState) startSearch() {
+	ed.searching = true
+	ed.query = nil
+	ed.match = ""
+	ed.searchIndex = ed.historyIndex
+	ed.searchFailed = false
+	ed.savedLine = append([]rune(nil), ed.line...)
+}
+
+// acceptSearch leaves search mode with the matched entry as the line
+func (ed *editState) acceptSearch() {
+	ed.searching = false
+	if ed.match != "" {
+		ed.setLine([]rune(ed.match))
+	}
+}
+
+// cancelSearch leaves search mode and restores the line being edited
+func (ed *editState) cancelSearch() {
+	ed.searching = false
+	ed.setLine(ed.savedLine)
+}
+
+// render redraws the prompt and line, leaving the cursor at the edit position
+func (ed *editState) render() {
+	if ed.searching {
+		label := "reverse-i-search"
+		if ed.searchFailed {
+			label = "failing reverse-i-search"
+		}
+		fmt.Printf("\r(%s)`%s': %s\x1b[K", label, string(ed.query), ed.match)
+		return
+	}
+
+	fmt.Printf("\r%s%s\x1b[K", ed.prompt, string(ed.line))
+	if tail := lipgloss.Width(string(ed.line[ed.pos:])); tail > 0 {
+		fmt.Printf("\x1b[%dD", tail)
+	}
+}
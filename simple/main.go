@@ -1,77 +1,60 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
-)
-
-type Message struct {
-	Type        string          `json:"type"`
-	Subtype     string          `json:"subtype,omitempty"`
-	Message     json.RawMessage `json:"message,omitempty"`
-	SessionID   string          `json:"session_id,omitempty"`
-	IsError     bool            `json:"is_error,omitempty"`
-	Result      string          `json:"result,omitempty"`
-	DurationMs  int             `json:"duration_ms,omitempty"`
-	NumTurns    int             `json:"num_turns,omitempty"`
-	TotalCostUSD float64        `json:"total_cost_usd,omitempty"`
-	Usage       *Usage          `json:"usage,omitempty"`
-}
-
-type Usage struct {
-	InputTokens              int `json:"input_tokens"`
-	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
-	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
-	OutputTokens             int `json:"output_tokens"`
-}
 
-type AssistantMessage struct {
-	ID          string          `json:"id"`
-	Type        string          `json:"type"`
-	Role        string          `json:"role"`
-	Model       string          `json:"model"`
-	Content     json.RawMessage `json:"content"`
-	StopReason  string          `json:"stop_reason"`
-}
-
-type SystemInit struct {
-	CWD       string   `json:"cwd"`
-	SessionID string   `json:"session_id"`
-	Tools     []string `json:"tools"`
-	Model     string   `json:"model"`
-}
+	"customclaude/pkg/claudecli"
+	"customclaude/pkg/mcp"
+	"customclaude/pkg/procs"
+	"customclaude/pkg/sessionlock"
+	"customclaude/pkg/transcript"
+)
 
 type ToolExecution struct {
 	ID          string
 	Name        string
 	StartTime   time.Time
 	EndTime     *time.Time
-	Status      string // "starting", "running", "completed", "failed"
+	Status      string // "running", "completed", "failed"
 	Description string
 }
 
 type SessionManager struct {
-	CurrentSessionID     string
-	Model               string
-	SessionChain        []string
-	CumulativeDuration  int
-	CumulativeTurns     int
-	CumulativeCost      float64
-	CumulativeUsage     Usage
-	ConversationStart   time.Time
-	markdownRenderer    *glamour.TermRenderer
-	systemInitShown     bool
-	activeTools         map[string]*ToolExecution
-	toolCounter         int
+	CurrentSessionID   string
+	Model              string
+	SessionChain       []string
+	CumulativeDuration int
+	CumulativeTurns    int
+	CumulativeCost     float64
+	CumulativeUsage    claudecli.Usage
+	ConversationStart  time.Time
+	output             OutputSink
+	lastResult         *claudecli.Message
+	failedTools        []string // names of the tools that failed, for headless summaries
+	systemInitShown    bool
+	activeTools        map[string]*ToolExecution
+	toolCounter        int
+	latency            claudecli.LatencyTracker
+	config             Config
+	readOnly           bool
+	transcript         []transcript.Entry
+	locks              sessionlock.Locker
+	lineTime           time.Time
+	mcp                *mcp.Manager
+	lastInit           claudecli.SystemInit
+	systemOverride     *string
+	stderr             stderrLog
 }
 
 var (
@@ -85,196 +68,162 @@ var (
 
 	// Styles
 	titleStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(primaryColor).
-		PaddingTop(1).
-		PaddingBottom(1)
+			Bold(true).
+			Foreground(primaryColor).
+			PaddingTop(1).
+			PaddingBottom(1)
 
 	subtitleStyle = lipgloss.NewStyle().
-		Foreground(mutedColor).
-		Italic(true)
+			Foreground(mutedColor).
+			Italic(true)
 
 	commandStyle = lipgloss.NewStyle().
-		Foreground(primaryColor).
-		Bold(true)
+			Foreground(primaryColor).
+			Bold(true)
 
 	helpStyle = lipgloss.NewStyle().
-		Foreground(mutedColor).
-		PaddingLeft(2)
+			Foreground(mutedColor).
+			PaddingLeft(2)
 
 	systemStyle = lipgloss.NewStyle().
-		Foreground(successColor).
-		Bold(true)
+			Foreground(successColor).
+			Bold(true)
 
 	errorStyle = lipgloss.NewStyle().
-		Foreground(errorColor).
-		Bold(true)
+			Foreground(errorColor).
+			Bold(true)
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(warningColor).
+			Bold(true)
 
 	toolStyle = lipgloss.NewStyle().
-		Foreground(warningColor).
-		Bold(true)
+			Foreground(warningColor).
+			Bold(true)
 
 	promptStyle = lipgloss.NewStyle().
-		Foreground(primaryColor).
-		Bold(true)
+			Foreground(primaryColor).
+			Bold(true)
 
 	summaryHeaderStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(primaryColor).
-		Background(backgroundFade).
-		Padding(1, 2).
-		MarginTop(1).
-		MarginBottom(1)
+				Bold(true).
+				Foreground(primaryColor).
+				Background(backgroundFade).
+				Padding(1, 2).
+				MarginTop(1).
+				MarginBottom(1)
 
 	summaryStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(primaryColor).
-		Padding(1, 2)
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(primaryColor).
+			Padding(1, 2)
 
 	metricStyle = lipgloss.NewStyle().
-		Foreground(mutedColor)
+			Foreground(mutedColor)
 
 	valueStyle = lipgloss.NewStyle().
-		Foreground(primaryColor).
-		Bold(true)
+			Foreground(primaryColor).
+			Bold(true)
 
 	// Additional subtle styles
 	headerDivider = lipgloss.NewStyle().
-		Foreground(mutedColor).
-		Faint(true)
+			Foreground(mutedColor).
+			Faint(true)
 
 	successIndicator = lipgloss.NewStyle().
-		Foreground(successColor).
-		Bold(true)
+				Foreground(successColor).
+				Bold(true)
 
 	progressDot = lipgloss.NewStyle().
-		Foreground(primaryColor)
+			Foreground(primaryColor)
 
 	// Tool execution progress styles
 	toolStartStyle = lipgloss.NewStyle().
-		Foreground(warningColor).
-		Bold(true)
+			Foreground(warningColor).
+			Bold(true)
 
 	toolRunningStyle = lipgloss.NewStyle().
-		Foreground(primaryColor).
-		Bold(true)
+				Foreground(primaryColor).
+				Bold(true)
 
 	toolCompletedStyle = lipgloss.NewStyle().
-		Foreground(successColor).
-		Bold(true)
+				Foreground(successColor).
+				Bold(true)
 
 	toolFailedStyle = lipgloss.NewStyle().
-		Foreground(errorColor).
-		Bold(true)
+			Foreground(errorColor).
+			Bold(true)
 
 	toolProgressBox = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(mutedColor).
-		Padding(0, 1).
-		MarginLeft(2)
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(mutedColor).
+			Padding(0, 1).
+			MarginLeft(2)
 
 	toolTimeStyle = lipgloss.NewStyle().
-		Foreground(mutedColor).
-		Italic(true)
+			Foreground(mutedColor).
+			Italic(true)
 )
 
-func newMarkdownRenderer() *glamour.TermRenderer {
+func newMarkdownRenderer(theme string, wordWrap int) *glamour.TermRenderer {
+	style := glamour.WithAutoStyle()
+	if theme != "" {
+		style = glamour.WithStylePath(theme)
+	}
 	r, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(80),
+		style,
+		glamour.WithWordWrap(wordWrap),
 	)
 	if err != nil {
 		// Fallback to basic renderer if auto-style fails
 		r, _ = glamour.NewTermRenderer(
 			glamour.WithStandardStyle("dark"),
-			glamour.WithWordWrap(80),
+			glamour.WithWordWrap(wordWrap),
 		)
 	}
 	return r
 }
 
-func (sm *SessionManager) renderMarkdown(content string) string {
-	if sm.markdownRenderer == nil {
-		return content // Fallback to plain text
-	}
-	
-	rendered, err := sm.markdownRenderer.Render(content)
-	if err != nil {
-		return content // Fallback to plain text on error
-	}
-	return strings.TrimSuffix(rendered, "\n") // Remove trailing newline
-}
-
 func (sm *SessionManager) generateToolID() string {
 	sm.toolCounter++
 	return fmt.Sprintf("tool_%d", sm.toolCounter)
 }
 
-func (sm *SessionManager) startTool(name, description string) string {
+// startTool tracks a tool_use block under its tool_use id so the matching
+// tool_result can complete it, even when several tools run in parallel
+func (sm *SessionManager) startTool(toolID, name, description string) string {
 	if sm.activeTools == nil {
 		sm.activeTools = make(map[string]*ToolExecution)
 	}
-	
-	toolID := sm.generateToolID()
+
+	if toolID == "" {
+		toolID = sm.generateToolID()
+	}
 	tool := &ToolExecution{
 		ID:          toolID,
 		Name:        name,
-		StartTime:   time.Now(),
-		Status:      "starting",
+		StartTime:   sm.eventTime(),
+		Status:      "running",
 		Description: description,
 	}
-	
+
 	sm.activeTools[toolID] = tool
-	
-	// Display tool start with animated spinner
-	icon := "⏳"
-	fmt.Printf("\n%s %s\n", 
-		toolStartStyle.Render(fmt.Sprintf("%s [Tool: %s]", icon, name)), 
-		toolTimeStyle.Render(description))
-	
+	sm.output.ToolStarted(tool)
+
 	return toolID
 }
 
-func (sm *SessionManager) updateToolStatus(toolID, status string) {
+// finishTool completes a tool with status "completed" or "failed"
+func (sm *SessionManager) finishTool(toolID, status string) {
 	if tool, exists := sm.activeTools[toolID]; exists {
 		tool.Status = status
-		
-		var icon, statusText string
-		var style lipgloss.Style
-		
-		switch status {
-		case "running":
-			icon = "🔄"
-			statusText = "Running"
-			style = toolRunningStyle
-		case "completed":
-			icon = "✅"
-			statusText = "Completed"
-			style = toolCompletedStyle
-			now := time.Now()
-			tool.EndTime = &now
-		case "failed":
-			icon = "❌"
-			statusText = "Failed"
-			style = toolFailedStyle
-			now := time.Now()
-			tool.EndTime = &now
-		}
-		
-		duration := ""
-		if tool.EndTime != nil {
-			elapsed := tool.EndTime.Sub(tool.StartTime)
-			duration = fmt.Sprintf(" (%s)", elapsed.Round(time.Millisecond))
-		}
-		
-		fmt.Printf("%s %s%s\n", 
-			style.Render(fmt.Sprintf("%s [Tool: %s]", icon, tool.Name)), 
-			toolTimeStyle.Render(statusText),
-			toolTimeStyle.Render(duration))
-		
-		if status == "completed" || status == "failed" {
-			delete(sm.activeTools, toolID)
+		now := sm.eventTime()
+		tool.EndTime = &now
+		if status == "failed" {
+			sm.failedTools = append(sm.failedTools, tool.Name)
 		}
+		sm.output.ToolFinished(tool)
+		delete(sm.activeTools, toolID)
 	}
 }
 
@@ -282,70 +231,69 @@ func (sm *SessionManager) showActiveTools() {
 	if len(sm.activeTools) == 0 {
 		return
 	}
-	
+
 	fmt.Print("\n")
 	fmt.Print(commandStyle.Render("Active Tools:"))
 	fmt.Print("\n")
-	
+
 	for _, tool := range sm.activeTools {
 		elapsed := time.Since(tool.StartTime)
-		status := fmt.Sprintf("%s - %s (%s)", 
-			tool.Name, 
-			tool.Status, 
+		status := fmt.Sprintf("%s - %s (%s)",
+			tool.Name,
+			tool.Status,
 			elapsed.Round(time.Second))
-		
+
 		fmt.Print(toolProgressBox.Render(status))
 		fmt.Print("\n")
 	}
 }
 
-func (sm *SessionManager) ExecuteCommand(prompt string, resume bool) error {
-	args := []string{
-		"--output-format", "stream-json",
-		"--verbose",
-		"-p",
-		"--permission-prompt-tool", "mcp__permission__approval_prompt",
-		"--mcp-config", "config.json",
+// claudeOptions returns the settings claude is invoked with
+func (sm *SessionManager) claudeOptions() claudecli.Options {
+	systemPrompt, _ := sm.systemPrompt()
+	return claudecli.Options{
+		Model:          sm.Model,
+		PermissionTool: sm.config.PermissionTool,
+		MCPConfig:      sm.mcpConfigPath(),
+		SystemPrompt:   systemPrompt,
+		ReadOnly:       sm.readOnly,
 	}
+}
 
-	if sm.Model != "" {
-		args = append(args, "--model", sm.Model)
-	}
-
-	if resume && sm.CurrentSessionID != "" {
-		args = append(args, "--resume", sm.CurrentSessionID)
-	}
-
-	args = append(args, prompt)
-
-	cmd := exec.Command("claude", args...)
-	
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+// resumeID returns the session a prompt continues, empty for a new one
+func (sm *SessionManager) resumeID(resume bool) string {
+	if !resume {
+		return ""
 	}
+	return sm.CurrentSessionID
+}
 
-	stderr, err := cmd.StderrPipe()
+// runCommand runs a single claude invocation and renders its stream
+func (sm *SessionManager) runCommand(ctx context.Context, prompt string, resume bool) error {
+	sm.latency.Begin(time.Now())
+	run, err := claudecli.NewClient(sm.claudeOptions()).Start(ctx, prompt, sm.resumeID(resume))
 	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start command: %w", err)
+		return err
 	}
+	procs.Track(run.PID(), sm.CurrentSessionID) // failing only costs orphan detection
+	defer procs.Untrack(run.PID())
 
+	// Stderr is summed up after the turn rather than interleaved with it
+	stderrDone := make(chan []claudecli.StderrLine, 1)
 	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			fmt.Fprintf(os.Stderr, "[stderr] %s\n", scanner.Text())
-		}
+		stderrDone <- sm.stderr.collect(run.Stderr())
 	}()
 
-	if err := sm.ProcessStream(stdout); err != nil {
+	if err := sm.ProcessStream(run.Stdout()); err != nil {
+		run.Kill()
+		run.Wait()
+		sm.output.Stderr(<-stderrDone)
 		return fmt.Errorf("failed to process stream: %w", err)
 	}
 
-	if err := cmd.Wait(); err != nil {
+	err = run.Wait()
+	sm.output.Stderr(<-stderrDone)
+	if err != nil {
 		return fmt.Errorf("command failed: %w", err)
 	}
 
@@ -353,140 +301,121 @@ func (sm *SessionManager) ExecuteCommand(prompt string, resume bool) error {
 }
 
 func (sm *SessionManager) ProcessStream(reader io.Reader) error {
-	scanner := bufio.NewScanner(reader)
-	
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
-			continue
+	defer func() { sm.lineTime = time.Time{} }()
+
+	return claudecli.ProcessStream(reader, sm.config.MaxLineBytes, func(line string, event claudecli.Event, err error) {
+		var tooLong *claudecli.LineTooLongError
+		switch {
+		case errors.As(err, &tooLong):
+			sm.output.Error(tooLong)
+			return
+		case err != nil:
+			sm.output.Error(fmt.Errorf("unparsable stream line: %s", line))
+			return
 		}
+		// Stamp output with the line's generation time, or its receipt time
+		sm.lineTime = claudecli.StreamTimestamp(line, time.Now())
+		sm.handleEvent(event)
+	})
+}
 
-		var msg Message
-		if err := json.Unmarshal([]byte(line), &msg); err != nil {
-			fmt.Printf("[parse error] %s\n", line)
-			continue
+// handleEvent tracks one decoded stream line and reports it to the output
+// sink
+func (sm *SessionManager) handleEvent(event claudecli.Event) {
+	switch e := event.(type) {
+	case *claudecli.InitEvent:
+		init := e.Init
+		sm.CurrentSessionID = init.SessionID
+		sm.Model = init.Model
+		sm.lastInit = init
+		if !sm.systemInitShown {
+			sm.output.Init(init)
+			sm.systemInitShown = true
 		}
 
-		switch msg.Type {
-		case "system":
-			if msg.Subtype == "init" {
-				var init SystemInit
-				if err := json.Unmarshal([]byte(line), &init); err == nil {
-					sm.CurrentSessionID = init.SessionID
-					sm.Model = init.Model
-					if !sm.systemInitShown {
-						fmt.Printf("\n%s Session initialized: %s\n", 
-							systemStyle.Render("⚡ [System]"), 
-							valueStyle.Render(init.SessionID))
-						fmt.Printf("%s Model: %s\n", 
-							systemStyle.Render("🤖 [System]"), 
-							valueStyle.Render(init.Model))
-						fmt.Printf("%s Working directory: %s\n", 
-							systemStyle.Render("📁 [System]"), 
-							valueStyle.Render(init.CWD))
-						fmt.Printf("%s Available tools: %s\n\n", 
-							systemStyle.Render("🛠️ [System]"), 
-							valueStyle.Render(fmt.Sprintf("%d", len(init.Tools))))
-						sm.systemInitShown = true
-					}
+	case *claudecli.AssistantEvent:
+		if e.Blocks != nil {
+			sm.latency.MarkOutput(sm.eventTime())
+		}
+		for _, block := range e.Blocks {
+			switch block.Type {
+			case "text":
+				sm.record("assistant", block.Text, nil)
+				sm.output.Text(block.Text)
+			case "tool_use":
+				sm.latency.ToolStarted(block.ID, sm.eventTime())
+				if block.Name == "" {
+					continue
 				}
+				sm.record("tool_use", block.Name, block.Input)
+				sm.startTool(block.ID, block.Name, toolDescription(block.Input))
 			}
+		}
+
+		if e.Message.StopReason == "end_turn" {
+			sm.output.EndTurn()
+		}
 
-		case "assistant":
-			var assistantData struct {
-				Message AssistantMessage `json:"message"`
+	case *claudecli.UserEvent:
+		// Tool results - complete the tool each result belongs to
+		for _, block := range e.Blocks {
+			if block.ToolUseID == "" {
+				continue
 			}
-			if err := json.Unmarshal([]byte(line), &assistantData); err == nil {
-				var content []map[string]interface{}
-				if err := json.Unmarshal(assistantData.Message.Content, &content); err == nil {
-					for _, item := range content {
-						if item["type"] == "text" {
-							if text, ok := item["text"].(string); ok {
-								rendered := sm.renderMarkdown(text)
-								fmt.Print(rendered)
-							}
-						} else if item["type"] == "tool_use" {
-							if toolName, ok := item["name"].(string); ok {
-								description := ""
-								if input, ok := item["input"].(map[string]interface{}); ok {
-									if desc, ok := input["description"].(string); ok {
-										description = desc
-									} else if cmd, ok := input["command"].(string); ok {
-										description = fmt.Sprintf("Executing: %s", cmd)
-									} else if path, ok := input["file_path"].(string); ok {
-										description = fmt.Sprintf("Processing: %s", path)
-									} else if pattern, ok := input["pattern"].(string); ok {
-										description = fmt.Sprintf("Searching: %s", pattern)
-									}
-								}
-								sm.startTool(toolName, description)
-							}
-						}
-					}
-				}
-				
-				if assistantData.Message.StopReason == "end_turn" {
-					fmt.Println()
-				}
+			sm.latency.ToolFinished(block.ToolUseID, sm.eventTime())
+			if block.IsError {
+				sm.finishTool(block.ToolUseID, "failed")
+			} else {
+				sm.finishTool(block.ToolUseID, "completed")
 			}
+		}
 
-		case "user":
-			// Tool results - show completion for the most recent tool
-			if len(sm.activeTools) > 0 {
-				// Find the most recently started active tool
-				var latestTool *ToolExecution
-				var latestToolID string
-				latestTime := time.Time{}
-				
-				for id, tool := range sm.activeTools {
-					if tool.StartTime.After(latestTime) {
-						latestTime = tool.StartTime
-						latestTool = tool
-						latestToolID = id
-					}
-				}
-				
-				if latestTool != nil {
-					if latestTool.Status == "starting" {
-						sm.updateToolStatus(latestToolID, "running")
-					} else if latestTool.Status == "running" {
-						sm.updateToolStatus(latestToolID, "completed")
-					}
-				}
+	case *claudecli.ResultEvent:
+		msg := e.Result
+		sm.lastResult = &msg
+		if msg.Subtype == "success" {
+			sm.CurrentSessionID = msg.SessionID
+			sm.SessionChain = append(sm.SessionChain, msg.SessionID)
+			if err := sm.locks.Acquire(msg.SessionID, false); err != nil {
+				sm.output.Error(err)
 			}
 
-		case "result":
-			if msg.Subtype == "success" {
-				sm.CurrentSessionID = msg.SessionID
-				sm.SessionChain = append(sm.SessionChain, msg.SessionID)
-				
-				// Accumulate session data
-				sm.CumulativeDuration += msg.DurationMs
-				sm.CumulativeTurns += msg.NumTurns
-				sm.CumulativeCost += msg.TotalCostUSD
-				
-				if msg.Usage != nil {
-					sm.CumulativeUsage.InputTokens += msg.Usage.InputTokens
-					sm.CumulativeUsage.CacheCreationInputTokens += msg.Usage.CacheCreationInputTokens
-					sm.CumulativeUsage.CacheReadInputTokens += msg.Usage.CacheReadInputTokens
-					sm.CumulativeUsage.OutputTokens += msg.Usage.OutputTokens
-				}
-				
-				// Just show a completion indicator, not full session info
-				fmt.Print(" ")
-				fmt.Print(successIndicator.Render(""))
-				fmt.Print("\n")
-			} else if msg.IsError {
-				fmt.Printf("\n%s %s\n", errorStyle.Render("❌ [Error]"), msg.Result)
+			// Accumulate session data
+			sm.CumulativeDuration += msg.DurationMs
+			sm.CumulativeTurns += msg.NumTurns
+			sm.CumulativeCost += msg.TotalCostUSD
+
+			if msg.Usage != nil {
+				sm.CumulativeUsage.Add(*msg.Usage)
+			}
+
+			sm.output.Result(msg, sm.latency.Finish(sm.eventTime()))
+			playCue(cueTurnComplete)
+		} else {
+			sm.output.Result(msg, sm.latency.Finish(sm.eventTime()))
+			if msg.IsError {
+				playCue(cueError)
 			}
 		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
+// toolDescription summarizes a tool call from its input for the activity line
+func toolDescription(raw json.RawMessage) string {
+	var input map[string]interface{}
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return ""
 	}
-
-	return nil
+	if desc, ok := input["description"].(string); ok {
+		return desc
+	} else if cmd, ok := input["command"].(string); ok {
+		return fmt.Sprintf("Executing: %s", cmd)
+	} else if path, ok := input["file_path"].(string); ok {
+		return fmt.Sprintf("Processing: %s", path)
+	} else if pattern, ok := input["pattern"].(string); ok {
+		return fmt.Sprintf("Searching: %s", pattern)
+	}
+	return ""
 }
 
 func (sm *SessionManager) ShowConversationSummary() {
@@ -495,61 +424,61 @@ func (sm *SessionManager) ShowConversationSummary() {
 	}
 
 	duration := time.Since(sm.ConversationStart)
-	
+
 	// Header
 	fmt.Print("\n")
 	fmt.Print(summaryHeaderStyle.Render("CONVERSATION SUMMARY"))
 	fmt.Print("\n")
-	
+
 	// Main stats
 	var summaryContent strings.Builder
-	summaryContent.WriteString(fmt.Sprintf("%s %s\n", 
-		metricStyle.Render("Duration:"), 
+	summaryContent.WriteString(fmt.Sprintf("%s %s\n",
+		metricStyle.Render("Duration:"),
 		valueStyle.Render(duration.Round(time.Second).String())))
-	summaryContent.WriteString(fmt.Sprintf("%s %s\n", 
-		metricStyle.Render("Sessions:"), 
+	summaryContent.WriteString(fmt.Sprintf("%s %s\n",
+		metricStyle.Render("Sessions:"),
 		valueStyle.Render(fmt.Sprintf("%d", len(sm.SessionChain)))))
-	summaryContent.WriteString(fmt.Sprintf("%s %s\n", 
-		metricStyle.Render("Total Turns:"), 
+	summaryContent.WriteString(fmt.Sprintf("%s %s\n",
+		metricStyle.Render("Total Turns:"),
 		valueStyle.Render(fmt.Sprintf("%d", sm.CumulativeTurns))))
-	summaryContent.WriteString(fmt.Sprintf("%s %s\n\n", 
-		metricStyle.Render("Total Cost:"), 
+	summaryContent.WriteString(fmt.Sprintf("%s %s\n\n",
+		metricStyle.Render("Total Cost:"),
 		valueStyle.Render(fmt.Sprintf("$%.6f", sm.CumulativeCost))))
-	
+
 	// Token usage
-	summaryContent.WriteString(fmt.Sprintf("%s\n", 
+	summaryContent.WriteString(fmt.Sprintf("%s\n",
 		commandStyle.Render("Token Usage:")))
-	summaryContent.WriteString(fmt.Sprintf("  %s %s\n", 
-		metricStyle.Render("Input Tokens:"), 
+	summaryContent.WriteString(fmt.Sprintf("  %s %s\n",
+		metricStyle.Render("Input Tokens:"),
 		valueStyle.Render(fmt.Sprintf("%d", sm.CumulativeUsage.InputTokens))))
-	summaryContent.WriteString(fmt.Sprintf("  %s %s\n", 
-		metricStyle.Render("Cache Creation:"), 
+	summaryContent.WriteString(fmt.Sprintf("  %s %s\n",
+		metricStyle.Render("Cache Creation:"),
 		valueStyle.Render(fmt.Sprintf("%d", sm.CumulativeUsage.CacheCreationInputTokens))))
-	summaryContent.WriteString(fmt.Sprintf("  %s %s\n", 
-		metricStyle.Render("Cache Read:"), 
+	summaryContent.WriteString(fmt.Sprintf("  %s %s\n",
+		metricStyle.Render("Cache Read:"),
 		valueStyle.Render(fmt.Sprintf("%d", sm.CumulativeUsage.CacheReadInputTokens))))
-	summaryContent.WriteString(fmt.Sprintf("  %s %s\n", 
-		metricStyle.Render("Output Tokens:"), 
+	summaryContent.WriteString(fmt.Sprintf("  %s %s\n",
+		metricStyle.Render("Output Tokens:"),
 		valueStyle.Render(fmt.Sprintf("%d", sm.CumulativeUsage.OutputTokens))))
-	
+
 	totalTokens := sm.CumulativeUsage.InputTokens +
 		sm.CumulativeUsage.CacheCreationInputTokens +
 		sm.CumulativeUsage.CacheReadInputTokens +
 		sm.CumulativeUsage.OutputTokens
-	summaryContent.WriteString(fmt.Sprintf("  %s %s", 
-		metricStyle.Render("Total Tokens:"), 
+	summaryContent.WriteString(fmt.Sprintf("  %s %s",
+		metricStyle.Render("Total Tokens:"),
 		valueStyle.Render(fmt.Sprintf("%d", totalTokens))))
-	
+
 	if len(sm.SessionChain) > 1 {
-		summaryContent.WriteString(fmt.Sprintf("\n\n%s\n", 
+		summaryContent.WriteString(fmt.Sprintf("\n\n%s\n",
 			commandStyle.Render("Session Chain:")))
 		for i, sessionID := range sm.SessionChain {
-			summaryContent.WriteString(fmt.Sprintf("  %s %s\n", 
-				metricStyle.Render(fmt.Sprintf("%d.", i+1)), 
+			summaryContent.WriteString(fmt.Sprintf("  %s %s\n",
+				metricStyle.Render(fmt.Sprintf("%d.", i+1)),
 				valueStyle.Render(sessionID)))
 		}
 	}
-	
+
 	fmt.Print(summaryStyle.Render(summaryContent.String()))
 	fmt.Print("\n")
 }
@@ -558,19 +487,20 @@ func (sm *SessionManager) StartNewConversation() {
 	if len(sm.SessionChain) > 0 {
 		sm.ShowConversationSummary()
 	}
-	
+
 	// Reset for new conversation
 	sm.CurrentSessionID = ""
 	sm.SessionChain = nil
 	sm.CumulativeDuration = 0
 	sm.CumulativeTurns = 0
 	sm.CumulativeCost = 0
-	sm.CumulativeUsage = Usage{}
+	sm.CumulativeUsage = claudecli.Usage{}
 	sm.ConversationStart = time.Now()
 	sm.systemInitShown = false
 	sm.activeTools = make(map[string]*ToolExecution)
 	sm.toolCounter = 0
-	
+	sm.transcript = nil
+
 	fmt.Print("\n")
 	fmt.Print(systemStyle.Render("🆕 [System]"))
 	fmt.Print(" ")
@@ -579,12 +509,64 @@ func (sm *SessionManager) StartNewConversation() {
 }
 
 func main() {
+	readOnly := flag.Bool("read-only", false, "disallow write tools and run Claude in plan mode")
+	prompt := flag.String("p", "", "run a single prompt non-interactively and print the result")
+	jsonOutput := flag.Bool("json", false, "with -p or piped stdin, print the raw result message as JSON")
+	outputFormat := flag.String("output", outputStyled, "how claude's stream is shown: styled, plain, json or quiet")
+	maxBudget := flag.Float64("max-budget", 0, "with -p or piped stdin, fail with exit code 3 when the run costs more USD than this")
+	resultJSON := flag.String("result-json", "", "with -p or piped stdin, write a JSON summary of the run to this file")
+	flag.Parse()
+	defer setupTerminal()()
+
+	if flag.Arg(0) == "config" {
+		if err := runConfigCommand(flag.Args()[1:]); err != nil {
+			fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+		os.Exit(1)
+	}
+
 	sm := &SessionManager{
-		ConversationStart:   time.Now(),
-		markdownRenderer:    newMarkdownRenderer(),
-		activeTools:         make(map[string]*ToolExecution),
+		Model:             cfg.Model,
+		ConversationStart: time.Now(),
+		activeTools:       make(map[string]*ToolExecution),
+		config:            cfg,
+		readOnly:          *readOnly,
+	}
+	if sm.output, err = newOutputSink(*outputFormat, os.Stdout, os.Stderr, newMarkdownRenderer(cfg.Theme, cfg.WordWrap)); err != nil {
+		fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+		os.Exit(exitUsage)
 	}
-	reader := bufio.NewReader(os.Stdin)
+
+	// Merge the configured MCP config files into the one passed to claude
+	if manager, err := newMCPManager(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: MCP server management disabled: %v\n", err)
+	} else {
+		sm.mcp = manager
+	}
+
+	// Headless mode: a single prompt from -p and/or piped stdin, no banner
+	if input, ok, err := headlessPrompt(*prompt); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	} else if ok {
+		opts := headlessOptions{JSON: *jsonOutput, MaxBudget: *maxBudget, ResultJSON: *resultJSON}
+		if flagSet("output") {
+			os.Exit(sm.runStreamed(input, opts))
+		}
+		os.Exit(sm.runHeadless(input, opts))
+	}
+
+	warnOrphans()
+
+	editor := newLineEditor(historyPath())
+	defer sm.locks.Release()
 
 	fmt.Print(titleStyle.Render("Claude CLI Integration"))
 	fmt.Print("\n")
@@ -592,27 +574,53 @@ func main() {
 	fmt.Print("\n")
 	fmt.Print(headerDivider.Render("────────────────────────────────────────"))
 	fmt.Print("\n\n")
-	
+
+	if sm.readOnly {
+		fmt.Print(errorStyle.Render("🔒 READ-ONLY MODE: plan permission mode, write tools disabled"))
+		fmt.Print("\n\n")
+	}
+
 	fmt.Print(commandStyle.Render("Commands:"))
 	fmt.Print("\n")
 	fmt.Print(helpStyle.Render("  /new     - Start a new conversation"))
 	fmt.Print("\n")
 	fmt.Print(helpStyle.Render("  /model   - Set model (e.g., claude-sonnet-4-20250514)"))
 	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /ask <model> <prompt> - Run one prompt on another model"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /t [name] - List prompt templates, or fill in and send one"))
+	fmt.Print("\n")
 	fmt.Print(helpStyle.Render("  /session - Show current session ID"))
 	fmt.Print("\n")
 	fmt.Print(helpStyle.Render("  /tools   - Show active tools"))
 	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /handoff - Print resume command (/handoff run to launch it)"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /copysummary - Copy conversation summary as Markdown"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /export [md|jsonl] - Write the full transcript to a file"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /takeover - Take over a session locked by another instance"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /mcp [list|check|enable|disable] - Manage MCP servers"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /system [edit|set|clear|reset] - Show or change the system prompt"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /stderr [all|warn|error] - Show what claude wrote to stderr"))
+	fmt.Print("\n")
+	fmt.Print(helpStyle.Render("  /edit [text] - Write the prompt in $EDITOR and send it on save"))
+	fmt.Print("\n")
 	fmt.Print(helpStyle.Render("  /exit    - Exit the program"))
 	fmt.Print("\n\n")
 	fmt.Print(headerDivider.Render("────────────────────────────────────────"))
 	fmt.Print("\n")
 	fmt.Print(subtitleStyle.Render("Type your prompt and press Enter to send to Claude."))
+	fmt.Print("\n")
+	fmt.Print(subtitleStyle.Render("↑/↓ recall previous prompts, Ctrl+R searches history."))
 	fmt.Print("\n\n")
 
 	for {
-		fmt.Print(promptStyle.Render("> "))
-		input, err := reader.ReadString('\n')
+		input, err := editor.ReadLine(promptStyle.Render("> "))
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -625,6 +633,7 @@ func main() {
 		if input == "" {
 			continue
 		}
+		editor.AddHistory(input)
 
 		switch {
 		case input == "/exit":
@@ -634,16 +643,29 @@ func main() {
 			return
 
 		case input == "/new":
+			sm.locks.Release()
 			sm.StartNewConversation()
 			continue
 
+		case input == "/takeover":
+			if sm.CurrentSessionID == "" {
+				fmt.Print(subtitleStyle.Render("No active session"))
+				fmt.Print("\n")
+			} else if err := sm.locks.Acquire(sm.CurrentSessionID, true); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			} else {
+				fmt.Print(subtitleStyle.Render("Took over session lock"))
+				fmt.Print("\n")
+			}
+			continue
+
 		case input == "/session":
 			if sm.CurrentSessionID == "" {
 				fmt.Print(subtitleStyle.Render("No active session"))
 				fmt.Print("\n")
 			} else {
-				fmt.Printf("%s %s\n", 
-					metricStyle.Render("Current session:"), 
+				fmt.Printf("%s %s\n",
+					metricStyle.Render("Current session:"),
 					valueStyle.Render(sm.CurrentSessionID))
 			}
 			continue
@@ -652,17 +674,111 @@ func main() {
 			sm.showActiveTools()
 			continue
 
+		case input == "/copysummary":
+			if err := copyToClipboard(sm.SummaryMarkdown()); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			} else {
+				fmt.Print(subtitleStyle.Render("Conversation summary copied to clipboard"))
+				fmt.Print("\n")
+			}
+			continue
+
+		case input == "/export" || strings.HasPrefix(input, "/export "):
+			path, err := sm.ExportTranscript(strings.TrimSpace(strings.TrimPrefix(input, "/export")))
+			if err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			} else {
+				fmt.Printf("%s %s\n",
+					metricStyle.Render("Transcript written to"),
+					valueStyle.Render(path))
+			}
+			continue
+
+		case input == "/handoff" || input == "/handoff run":
+			if err := sm.Handoff(input == "/handoff run"); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			}
+			continue
+
+		case input == "/stderr" || strings.HasPrefix(input, "/stderr "):
+			if err := sm.handleStderrCommand(strings.Fields(input)[1:]); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			}
+			continue
+
+		case input == "/mcp" || strings.HasPrefix(input, "/mcp "):
+			if err := sm.handleMCPCommand(strings.Fields(input)[1:]); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			}
+			continue
+
+		case input == "/system" || strings.HasPrefix(input, "/system "):
+			if err := sm.handleSystemCommand(strings.TrimSpace(strings.TrimPrefix(input, "/system"))); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+			}
+			continue
+
 		case strings.HasPrefix(input, "/model "):
 			model := strings.TrimPrefix(input, "/model ")
 			sm.Model = model
-			fmt.Printf("%s %s\n", 
-				metricStyle.Render("Model set to:"), 
+			fmt.Printf("%s %s\n",
+				metricStyle.Render("Model set to:"),
 				valueStyle.Render(model))
 			continue
 
+		case input == "/t" || strings.HasPrefix(input, "/t "):
+			prompt, err := fillTemplate(editor, strings.TrimSpace(strings.TrimPrefix(input, "/t")))
+			if err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+				continue
+			}
+			if prompt == "" {
+				continue
+			}
+			if err := sm.ExecuteCommand(prompt, sm.CurrentSessionID != ""); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+				playCue(cueError)
+			}
+			continue
+
+		case input == "/edit" || strings.HasPrefix(input, "/edit "):
+			prompt, err := editPrompt(strings.TrimSpace(strings.TrimPrefix(input, "/edit")))
+			if err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+				continue
+			}
+			if prompt == "" {
+				fmt.Print(subtitleStyle.Render("Empty prompt; nothing sent"))
+				fmt.Print("\n")
+				continue
+			}
+			if err := sm.ExecuteCommand(prompt, sm.CurrentSessionID != ""); err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+				playCue(cueError)
+			}
+			continue
+
+		case strings.HasPrefix(input, "/ask "):
+			model, prompt, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(input, "/ask ")), " ")
+			prompt = strings.TrimSpace(prompt)
+			if prompt == "" {
+				fmt.Printf("%s Usage: /ask <model> <prompt>\n", errorStyle.Render("❌ [Error]"))
+				continue
+			}
+			// The session keeps its model; init reports the one-off model
+			sessionModel := sm.Model
+			sm.Model = model
+			err := sm.ExecuteCommand(prompt, sm.CurrentSessionID != "")
+			sm.Model = sessionModel
+			if err != nil {
+				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+				playCue(cueError)
+			}
+			continue
+
 		case strings.HasPrefix(input, "/"):
-			fmt.Printf("%s Unknown command: %s\n", 
-				errorStyle.Render("❌ [Error]"), 
+			fmt.Printf("%s Unknown command: %s\n",
+				errorStyle.Render("❌ [Error]"),
 				input)
 			continue
 
@@ -670,7 +786,8 @@ func main() {
 			resume := sm.CurrentSessionID != ""
 			if err := sm.ExecuteCommand(input, resume); err != nil {
 				fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+				playCue(cueError)
 			}
 		}
 	}
-}
\ No newline at end of file
+}
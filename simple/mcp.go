@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"customclaude/pkg/mcp"
+	"customclaude/pkg/platform"
+)
+
+// newMCPManager loads the configured files, falling back to the single
+// mcp_config file, and writes the merged config
+func newMCPManager(cfg Config) (*mcp.Manager, error) {
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	settings := cfg.MCP
+	if len(settings.Files) == 0 {
+		settings.Files = []string{cfg.MCPConfig}
+	}
+	return mcp.NewManager(settings, filepath.Join(dir, "mcp-merged.json"))
+}
+
+// mcpConfigPath returns the config file passed to claude's --mcp-config
+func (sm *SessionManager) mcpConfigPath() string {
+	if sm.mcp != nil {
+		return sm.mcp.MergedPath()
+	}
+	return sm.config.MCPConfig
+}
+
+// handleMCPCommand runs "/mcp [list|check|enable <server>|disable <server>]"
+func (sm *SessionManager) handleMCPCommand(args []string) error {
+	if sm.mcp == nil {
+		return fmt.Errorf("MCP server management is not available")
+	}
+
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "list", "check":
+		if err := sm.mcp.Load(); err != nil {
+			return err
+		}
+		sm.showMCPServers(action == "check")
+		return nil
+	case "enable", "disable":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: /mcp %s <server>", action)
+		}
+		if err := sm.mcp.SetEnabled(args[1], action == "enable"); err != nil {
+			return err
+		}
+		fmt.Printf("%s %s (applies to the next prompt)\n",
+			metricStyle.Render("MCP server "+action+"d:"),
+			valueStyle.Render(args[1]))
+		return nil
+	}
+	return fmt.Errorf("usage: /mcp [list|check|enable <server>|disable <server>]")
+}
+
+// showMCPServers prints the configured servers with what the last init
+// reported about them, optionally health-checking each one
+func (sm *SessionManager) showMCPServers(check bool) {
+	servers := sm.mcp.Servers()
+	if len(servers) == 0 {
+		fmt.Print(subtitleStyle.Render("No MCP servers defined in the configured files"))
+		fmt.Print("\n")
+		return
+	}
+
+	connection := make(map[string]string)
+	for _, status := range sm.lastInit.MCPServers {
+		connection[status.Name] = status.Status
+	}
+
+	fmt.Print(commandStyle.Render("MCP servers:"))
+	fmt.Print("\n")
+	for _, server := range servers {
+		state := "enabled"
+		if !server.Enabled {
+			state = "disabled"
+		}
+		fmt.Printf("  %s %s %s\n",
+			valueStyle.Render(server.Name),
+			helpStyle.Render(fmt.Sprintf("[%s, %s]", server.Type, state)),
+			server.Target())
+
+		details := []string{"source " + server.Source}
+		if status, ok := connection[server.Name]; ok {
+			details = append(details, "claude: "+status)
+		}
+		if check {
+			if health := sm.mcp.Check(context.Background(), server.Name); health.OK {
+				details = append(details, "healthy: "+health.Detail)
+			} else {
+				details = append(details, errorStyle.Render("unhealthy: "+health.Detail))
+			}
+		}
+		fmt.Printf("    %s\n", helpStyle.Render(strings.Join(details, " | ")))
+
+		prefix := "mcp__" + server.Name + "__"
+		var tools []string
+		for _, tool := range sm.lastInit.Tools {
+			if strings.HasPrefix(tool, prefix) {
+				tools = append(tools, strings.TrimPrefix(tool, prefix))
+			}
+		}
+		if len(tools) > 0 {
+			fmt.Printf("    %s %s\n", helpStyle.Render("tools:"), strings.Join(tools, ", "))
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+
+	"customclaude/pkg/claudecli"
+)
+
+// Output formats accepted by --output
+const (
+	outputStyled = "styled"
+	outputPlain  = "plain"
+	outputJSON   = "json"
+	outputQuiet  = "quiet"
+)
+
+// OutputSink renders what happens in a claude stream. handleEvent keeps the
+// session state and reports to the sink, so a sink only decides how things
+// look.
+type OutputSink interface {
+	// Init reports a new session, once per conversation
+	Init(init claudecli.SystemInit)
+	// Text is a block of assistant text
+	Text(text string)
+	// EndTurn follows the last assistant message of a turn
+	EndTurn()
+	ToolStarted(tool *ToolExecution)
+	// ToolFinished reports a tool whose Status is "completed" or "failed"
+	ToolFinished(tool *ToolExecution)
+	// Result ends a turn, successful or not
+	Result(msg claudecli.Message, latency claudecli.TurnLatency)
+	Error(err error)
+	// Stderr follows a turn with the lines claude wrote to stderr during it
+	Stderr(lines []claudecli.StderrLine)
+}
+
+// newOutputSink creates the sink for an --output format writing to out, with
+// errors of the quiet sink going to errOut
+func newOutputSink(format string, out, errOut io.Writer, markdown *glamour.TermRenderer) (OutputSink, error) {
+	switch format {
+	case "", outputStyled:
+		return &styledSink{out: out, markdown: markdown}, nil
+	case outputPlain:
+		return &plainSink{out: out}, nil
+	case outputJSON:
+		return &jsonSink{enc: json.NewEncoder(out)}, nil
+	case outputQuiet:
+		return &quietSink{out: out, errOut: errOut}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q; use %s, %s, %s or %s",
+		format, outputStyled, outputPlain, outputJSON, outputQuiet)
+}
+
+// toolDuration is how long a finished tool ran
+func toolDuration(tool *ToolExecution) time.Duration {
+	if tool.EndTime == nil {
+		return 0
+	}
+	return tool.EndTime.Sub(tool.StartTime)
+}
+
+// styledSink renders Markdown and colors for a terminal
+type styledSink struct {
+	out      io.Writer
+	markdown *glamour.TermRenderer
+}
+
+func (s *styledSink) Init(init claudecli.SystemInit) {
+	fmt.Fprintf(s.out, "\n%s Session initialized: %s\n",
+		systemStyle.Render("⚡ [System]"),
+		valueStyle.Render(init.SessionID))
+	fmt.Fprintf(s.out, "%s Model: %s\n",
+		systemStyle.Render("🤖 [System]"),
+		valueStyle.Render(init.Model))
+	fmt.Fprintf(s.out, "%s Working directory: %s\n",
+		systemStyle.Render("📁 [System]"),
+		valueStyle.Render(init.CWD))
+	fmt.Fprintf(s.out, "%s Available tools: %s\n\n",
+		systemStyle.Render("🛠️ [System]"),
+		valueStyle.Render(fmt.Sprintf("%d", len(init.Tools))))
+}
+
+func (s *styledSink) Text(text string) {
+	if s.markdown != nil {
+		if rendered, err := s.markdown.Render(text); err == nil {
+			text = strings.TrimSuffix(rendered, "\n")
+		}
+	}
+	fmt.Fprint(s.out, text)
+}
+
+func (s *styledSink) EndTurn() {
+	fmt.Fprintln(s.out)
+}
+
+func (s *styledSink) ToolStarted(tool *ToolExecution) {
+	fmt.Fprintf(s.out, "\n%s %s\n",
+		toolStartStyle.Render(fmt.Sprintf("⏳ [Tool: %s]", tool.Name)),
+		toolTimeStyle.Render(tool.Description))
+}
+
+func (s *styledSink) ToolFinished(tool *ToolExecution) {
+	icon, statusText, style := "✅", "Completed", toolCompletedStyle
+	if tool.Status == "failed" {
+		icon, statusText, style = "❌", "Failed", toolFailedStyle
+	}
+	fmt.Fprintf(s.out, "%s %s%s\n",
+		style.Render(fmt.Sprintf("%s [Tool: %s]", icon, tool.Name)),
+		toolTimeStyle.Render(statusText),
+		toolTimeStyle.Render(fmt.Sprintf(" (%s)", toolDuration(tool).Round(time.Millisecond))))
+}
+
+func (s *styledSink) Result(msg claudecli.Message, latency claudecli.TurnLatency) {
+	if msg.Subtype != "success" {
+		if msg.IsError {
+			s.Error(fmt.Errorf("%s", msg.Result))
+		}
+		return
+	}
+	// Just show a completion indicator, not full session info
+	fmt.Fprint(s.out, " "+successIndicator.Render("")+"\n")
+	fmt.Fprint(s.out, toolTimeStyle.Render("⏱  "+latency.String())+"\n")
+}
+
+func (s *styledSink) Error(err error) {
+	fmt.Fprintf(s.out, "\n%s %v\n", errorStyle.Render("❌ [Error]"), err)
+}
+
+func (s *styledSink) Stderr(lines []claudecli.StderrLine) {
+	if summary := stderrSummary(lines); summary != "" {
+		fmt.Fprintf(s.out, "%s %s\n", warningStyle.Render("⚠ [stderr]"), toolTimeStyle.Render(summary+" from claude; /stderr shows them"))
+	}
+}
+
+// plainSink writes text without colors or Markdown rendering, for logs and
+// terminals that do not render them
+type plainSink struct {
+	out io.Writer
+}
+
+func (s *plainSink) Init(init claudecli.SystemInit) {
+	fmt.Fprintf(s.out, "[session] %s, model %s, %d tools, in %s\n", init.SessionID, init.Model, len(init.Tools), init.CWD)
+}
+
+func (s *plainSink) Text(text string) {
+	fmt.Fprintln(s.out, strings.TrimRight(text, "\n"))
+}
+
+func (s *plainSink) EndTurn() {}
+
+func (s *plainSink) ToolStarted(tool *ToolExecution) {
+	line := "[tool] " + tool.Name
+	if tool.Description != "" {
+		line += ": " + tool.Description
+	}
+	fmt.Fprintln(s.out, line)
+}
+
+func (s *plainSink) ToolFinished(tool *ToolExecution) {
+	fmt.Fprintf(s.out, "[tool] %s %s (%s)\n", tool.Name, tool.Status, toolDuration(tool).Round(time.Millisecond))
+}
+
+func (s *plainSink) Result(msg claudecli.Message, latency claudecli.TurnLatency) {
+	if msg.Subtype != "success" {
+		if msg.IsError {
+			s.Error(fmt.Errorf("%s", msg.Result))
+		}
+		return
+	}
+	fmt.Fprintf(s.out, "[done] $%.4f, %s\n", msg.TotalCostUSD, latency)
+}
+
+func (s *plainSink) Error(err error) {
+	fmt.Fprintf(s.out, "[error] %v\n", err)
+}
+
+func (s *plainSink) Stderr(lines []claudecli.StderrLine) {
+	if summary := stderrSummary(lines); summary != "" {
+		fmt.Fprintf(s.out, "[stderr] %s from claude; /stderr shows them\n", summary)
+	}
+}
+
+// jsonSink writes one JSON object per line, for other programs to read
+type jsonSink struct {
+	enc *json.Encoder
+}
+
+// jsonRecord is a line written by the JSON sink; type says which fields
+// are set
+type jsonRecord struct {
+	Type        string                 `json:"type"`
+	SessionID   string                 `json:"session_id,omitempty"`
+	Model       string                 `json:"model,omitempty"`
+	CWD         string                 `json:"cwd,omitempty"`
+	Tools       int                    `json:"tools,omitempty"`
+	Text        string                 `json:"text,omitempty"`
+	ToolID      string                 `json:"tool_id,omitempty"`
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Status      string                 `json:"status,omitempty"`
+	DurationMs  int64                  `json:"duration_ms,omitempty"`
+	Subtype     string                 `json:"subtype,omitempty"`
+	IsError     bool                   `json:"is_error,omitempty"`
+	CostUSD     float64                `json:"cost_usd,omitempty"`
+	Usage       *claudecli.Usage       `json:"usage,omitempty"`
+	Latency     *jsonLatency           `json:"latency,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	Stderr      []claudecli.StderrLine `json:"stderr,omitempty"`
+}
+
+// jsonLatency is a claudecli.TurnLatency in milliseconds
+type jsonLatency struct {
+	FirstTokenMs int64 `json:"first_token_ms"`
+	ModelMs      int64 `json:"model_ms"`
+	ToolsMs      int64 `json:"tools_ms"`
+	TotalMs      int64 `json:"total_ms"`
+}
+
+func (s *jsonSink) Init(init claudecli.SystemInit) {
+	s.enc.Encode(jsonRecord{Type: "init", SessionID: init.SessionID, Model: init.Model, CWD: init.CWD, Tools: len(init.Tools)})
+}
+
+func (s *jsonSink) Text(text string) {
+	s.enc.Encode(jsonRecord{Type: "text", Text: text})
+}
+
+func (s *jsonSink) EndTurn() {}
+
+func (s *jsonSink) ToolStarted(tool *ToolExecution) {
+	s.enc.Encode(jsonRecord{Type: "tool_start", ToolID: tool.ID, Name: tool.Name, Description: tool.Description})
+}
+
+func (s *jsonSink) ToolFinished(tool *ToolExecution) {
+	s.enc.Encode(jsonRecord{Type: "tool_end", ToolID: tool.ID, Name: tool.Name, Status: tool.Status,
+		DurationMs: toolDuration(tool).Milliseconds()})
+}
+
+func (s *jsonSink) Result(msg claudecli.Message, latency claudecli.TurnLatency) {
+	s.enc.Encode(jsonRecord{
+		Type:      "result",
+		SessionID: msg.SessionID,
+		Subtype:   msg.Subtype,
+		IsError:   msg.IsError,
+		Text:      msg.Result,
+		CostUSD:   msg.TotalCostUSD,
+		Usage:     msg.Usage,
+		Latency: &jsonLatency{
+			FirstTokenMs: latency.TimeToFirstToken.Milliseconds(),
+			ModelMs:      latency.ModelTime.Milliseconds(),
+			ToolsMs:      latency.ToolTime.Milliseconds(),
+			TotalMs:      latency.WallTime.Milliseconds(),
+		},
+	})
+}
+
+func (s *jsonSink) Error(err error) {
+	s.enc.Encode(jsonRecord{Type: "error", Error: err.Error()})
+}
+
+func (s *jsonSink) Stderr(lines []claudecli.StderrLine) {
+	if len(lines) > 0 {
+		s.enc.Encode(jsonRecord{Type: "stderr", Stderr: lines})
+	}
+}
+
+// quietSink prints only the final result, and errors to errOut
+type quietSink struct {
+	out    io.Writer
+	errOut io.Writer
+}
+
+func (s *quietSink) Init(claudecli.SystemInit)   {}
+func (s *quietSink) Text(string)                 {}
+func (s *quietSink) EndTurn()                    {}
+func (s *quietSink) ToolStarted(*ToolExecution)  {}
+func (s *quietSink) ToolFinished(*ToolExecution) {}
+
+func (s *quietSink) Result(msg claudecli.Message, _ claudecli.TurnLatency) {
+	if msg.IsError {
+		fmt.Fprintln(s.errOut, msg.Result)
+		return
+	}
+	if msg.Subtype == "success" {
+		fmt.Fprintln(s.out, msg.Result)
+	}
+}
+
+func (s *quietSink) Error(err error) {
+	fmt.Fprintln(s.errOut, err)
+}
+
+// Stderr passes warnings and errors on to errOut, where scripts expect them
+func (s *quietSink) Stderr(lines []claudecli.StderrLine) {
+	for _, line := range lines {
+		if line.Severity >= claudecli.SeverityWarning {
+			fmt.Fprintln(s.errOut, line.Text)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"customclaude/pkg/claudecli"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/output")
+
+// playTurn reports a turn with a failed and a completed tool, stderr
+// warnings and an error result to a sink, at fixed times
+func playTurn(sink OutputSink) {
+	start := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	at := func(ms int) *time.Time {
+		t := start.Add(time.Duration(ms) * time.Millisecond)
+		return &t
+	}
+
+	sink.Init(claudecli.SystemInit{
+		CWD:       "/tmp/project",
+		SessionID: "5d2c7a3e-0f4b-4c1e-9a57-2f1d8b6e4c10",
+		Tools:     []string{"Bash", "Read", "Edit"},
+		Model:     "claude-sonnet-4-20250514",
+	})
+	sink.Text("I'll check the tests first.")
+
+	failed := &ToolExecution{ID: "toolu_1", Name: "Bash", StartTime: start, Description: "Executing: go test ./..."}
+	sink.ToolStarted(failed)
+	failed.EndTime, failed.Status = at(1250), "failed"
+	sink.ToolFinished(failed)
+
+	read := &ToolExecution{ID: "toolu_2", Name: "Read", StartTime: *at(1300), Description: "Processing: main.go"}
+	sink.ToolStarted(read)
+	read.EndTime, read.Status = at(1342), "completed"
+	sink.ToolFinished(read)
+
+	sink.Text("The failing test expects `-port` to default to 8080.\n")
+	sink.EndTurn()
+	sink.Result(claudecli.Message{
+		Type:         "result",
+		Subtype:      "success",
+		SessionID:    "5d2c7a3e-0f4b-4c1e-9a57-2f1d8b6e4c10",
+		Result:       "The failing test expects `-port` to default to 8080.",
+		DurationMs:   4120,
+		NumTurns:     3,
+		TotalCostUSD: 0.0121,
+		Usage:        &claudecli.Usage{InputTokens: 12, CacheReadInputTokens: 3400, OutputTokens: 215},
+	}, claudecli.TurnLatency{
+		TimeToFirstToken: 820 * time.Millisecond,
+		ToolTime:         1292 * time.Millisecond,
+		ModelTime:        2828 * time.Millisecond,
+		WallTime:         4120 * time.Millisecond,
+	})
+	sink.Stderr([]claudecli.StderrLine{
+		{Text: "Warning: config.json has no permission server", Severity: claudecli.SeverityWarning, Received: start},
+		{Text: "loading tools", Severity: claudecli.SeverityInfo, Received: start},
+	})
+
+	sink.Error(errors.New("stream line of 1048577 bytes exceeds the 1048576 byte limit and was skipped"))
+	sink.Result(claudecli.Message{
+		Type:      "result",
+		Subtype:   "error_during_execution",
+		SessionID: "5d2c7a3e-0f4b-4c1e-9a57-2f1d8b6e4c10",
+		IsError:   true,
+		Result:    "Claude requested permissions to use Bash, but you haven't granted it yet.",
+	}, claudecli.TurnLatency{WallTime: 350 * time.Millisecond})
+}
+
+func TestOutputSinksGolden(t *testing.T) {
+	// The styled golden file has no colors, whatever the terminal
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	for _, format := range []string{outputStyled, outputPlain, outputJSON, outputQuiet} {
+		t.Run(format, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			sink, err := newOutputSink(format, &out, &errOut, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			playTurn(sink)
+			if errOut.Len() > 0 {
+				out.WriteString("--- stderr ---\n")
+				out.Write(errOut.Bytes())
+			}
+
+			golden := filepath.Join("testdata", "output", format+".golden")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(out.Bytes(), want) {
+				t.Errorf("%s output differs from %s:\n%s", format, golden, out.String())
+			}
+		})
+	}
+}
+
+func TestNewOutputSinkUnknownFormat(t *testing.T) {
+	if _, err := newOutputSink("yaml", &bytes.Buffer{}, &bytes.Buffer{}, nil); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"customclaude/pkg/procs"
+)
+
+// warnOrphans prints a warning about claude processes left by crashed runs,
+// which may keep spending API credits
+func warnOrphans() {
+	orphans, err := procs.FindOrphans()
+	if err != nil || len(orphans) == 0 {
+		return
+	}
+	list := make([]string, len(orphans))
+	for i, orphan := range orphans {
+		list[i] = strconv.Itoa(orphan.PID)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %d claude process(es) left by a crashed run may still be running (PID %s); stop them with /doctor kill in the TUI\n",
+		len(orphans), strings.Join(list, ", "))
+}
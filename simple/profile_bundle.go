@@ -0,0 +1,340 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/term"
+
+	"customclaude/pkg/platform"
+	"customclaude/templates"
+)
+
+// Bundle entries. Anything else in the config directory stays
+// machine-local.
+const (
+	bundleConfig    = "config.toml"
+	bundlePolicy    = "policy.toml"
+	bundleTemplates = "templates"
+)
+
+// maxBundleFile caps the size of a file extracted from a bundle
+const maxBundleFile = 1 << 20
+
+// bundleFile is a file of the config directory included in a bundle
+type bundleFile struct {
+	name string // entry name in the archive
+	path string // location on this machine
+}
+
+// bundleFiles lists the files of the config directory that exist and go in
+// a bundle: config.toml, policy.toml and the prompt templates
+func bundleFiles() ([]bundleFile, error) {
+	root, err := platform.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	cfgPath, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []bundleFile
+	for _, f := range []bundleFile{
+		{name: bundleConfig, path: cfgPath},
+		{name: bundlePolicy, path: filepath.Join(root, bundlePolicy)},
+	} {
+		if _, err := os.Stat(f.path); err == nil {
+			files = append(files, f)
+		}
+	}
+
+	dir := templates.Dir(root)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, bundleFile{name: bundleTemplates + "/" + filepath.ToSlash(rel), path: path})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return files, nil
+}
+
+// bundleTarget returns where a bundle entry is written on this machine, or
+// false for entries a bundle cannot contain
+func bundleTarget(name string) (string, bool) {
+	root, err := platform.ConfigDir()
+	if err != nil {
+		return "", false
+	}
+	switch name {
+	case bundleConfig:
+		path, err := configPath()
+		return path, err == nil
+	case bundlePolicy:
+		return filepath.Join(root, bundlePolicy), true
+	}
+	rel, ok := strings.CutPrefix(name, bundleTemplates+"/")
+	if !ok {
+		return "", false
+	}
+	rel = filepath.Clean(filepath.FromSlash(rel))
+	if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(templates.Dir(root), rel), true
+}
+
+// exportConfigBundle writes config.toml, policy.toml and the prompt
+// templates into a gzipped tar archive at dest
+func exportConfigBundle(dest string) (int, error) {
+	files, err := bundleFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	for i, f := range files {
+		if err := addFileToTar(tw, f.path, f.name); err != nil {
+			return i, fmt.Errorf("failed to bundle %s: %w", f.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return len(files), fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return len(files), fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	return len(files), nil
+}
+
+// addFileToTar copies a single file into the archive under name
+func addFileToTar(tw *tar.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// importResult counts what an import did
+type importResult struct {
+	written int
+	skipped []string
+}
+
+// importConfigBundle applies a bundle produced by exportConfigBundle. The
+// settings of its config.toml are merged into the local one, which keeps
+// the settings the bundle leaves out. Other files replace local ones that
+// differ only once confirm agrees.
+func importConfigBundle(src string, confirm func(name string) bool) (importResult, error) {
+	var result importResult
+
+	in, err := os.Open(src)
+	if err != nil {
+		return result, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer in.Close()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return result, fmt.Errorf("invalid bundle: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target, ok := bundleTarget(hdr.Name)
+		if !ok {
+			return result, fmt.Errorf("bundle contains unexpected entry: %s", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleFile+1))
+		if err != nil {
+			return result, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if len(data) > maxBundleFile {
+			return result, fmt.Errorf("bundle entry %s is larger than %d bytes", hdr.Name, maxBundleFile)
+		}
+
+		if hdr.Name == bundleConfig {
+			data, err = mergeConfig(target, data)
+			if err != nil {
+				return result, err
+			}
+		}
+		if old, err := os.ReadFile(target); err == nil {
+			if bytes.Equal(old, data) {
+				continue
+			}
+			if hdr.Name != bundleConfig && !confirm(hdr.Name) {
+				result.skipped = append(result.skipped, hdr.Name)
+				continue
+			}
+		}
+
+		if err := writeBundleFile(target, data); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", hdr.Name, err)
+		}
+		result.written++
+	}
+	return result, nil
+}
+
+// mergeConfig returns the local config.toml with the settings of a bundled
+// one applied on top, table by table
+func mergeConfig(path string, bundled []byte) ([]byte, error) {
+	incoming := make(map[string]interface{})
+	if _, err := toml.Decode(string(bundled), &incoming); err != nil {
+		return nil, fmt.Errorf("invalid %s in bundle: %w", bundleConfig, err)
+	}
+	local := make(map[string]interface{})
+	if _, err := toml.DecodeFile(path, &local); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	mergeTables(local, incoming)
+
+	var buf bytes.Buffer
+	buf.WriteString("# cc-custom settings; keys left out use their defaults\n\n")
+	if err := toml.NewEncoder(&buf).Encode(local); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeTables sets every key of src in dst, merging nested tables
+func mergeTables(dst, src map[string]interface{}) {
+	for key, value := range src {
+		table, isTable := value.(map[string]interface{})
+		existing, hasTable := dst[key].(map[string]interface{})
+		if isTable && hasTable {
+			mergeTables(existing, table)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// writeBundleFile writes an imported file, keeping the one it replaces as
+// <name>.bak
+func writeBundleFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if old, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+".bak", old, 0o644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// confirmReplace asks on the terminal whether to replace a local file.
+// Without a terminal nothing is replaced.
+func confirmReplace(name string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	fmt.Printf("%s ", warningStyle.Render(fmt.Sprintf("Replace the local %s? [y/N]", name)))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// runConfigCommand handles the `config export|import [--force] <file>`
+// subcommands
+func runConfigCommand(args []string) error {
+	force := len(args) == 3 && args[0] == "import" && args[1] == "--force"
+	if force {
+		args = []string{args[0], args[2]}
+	}
+	if len(args) != 2 {
+		return errors.New("usage: config export <file> | config import [--force] <file>")
+	}
+
+	switch args[0] {
+	case "export":
+		count, err := exportConfigBundle(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n",
+			metricStyle.Render(fmt.Sprintf("Exported %d files to", count)),
+			valueStyle.Render(args[1]))
+	case "import":
+		confirm := confirmReplace
+		if force {
+			confirm = func(string) bool { return true }
+		}
+		result, err := importConfigBundle(args[1], confirm)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n",
+			metricStyle.Render(fmt.Sprintf("Imported %d files from", result.written)),
+			valueStyle.Render(args[1]))
+		if len(result.skipped) > 0 {
+			fmt.Println(subtitleStyle.Render(fmt.Sprintf("Kept the local %s; import with --force to replace them",
+				strings.Join(result.skipped, ", "))))
+		}
+	default:
+		return fmt.Errorf("unknown config command: %s", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy controls how failed claude invocations are retried. It reads
+// the same [retries] table as the TUI.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts; 1 or less disables retries
+	MaxAttempts    int           `toml:"max_attempts"`
+	InitialBackoff time.Duration `toml:"initial_backoff"`
+	MaxBackoff     time.Duration `toml:"max_backoff"`
+}
+
+// defaultRetryPolicy retries twice, waiting 2s and then 4s
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 2 * time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// backoff returns the delay before the attempt following failed, or false
+// when no attempts are left. The delay doubles per attempt up to MaxBackoff.
+func (p RetryPolicy) backoff(failed int) (time.Duration, bool) {
+	if failed >= p.MaxAttempts {
+		return 0, false
+	}
+	delay := p.InitialBackoff
+	for i := 1; i < failed; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay, true
+}
+
+// ExecuteCommand runs a prompt, retrying failed invocations according to the
+// retry policy. Retries resume the session the command started from.
+func (sm *SessionManager) ExecuteCommand(prompt string, resume bool) error {
+	if resume {
+		if err := sm.locks.Acquire(sm.CurrentSessionID, false); err != nil {
+			return err
+		}
+	}
+	sm.record("user", prompt, nil)
+
+	sessionID := sm.CurrentSessionID
+	for failed := 1; ; failed++ {
+		err := sm.runCommand(context.Background(), prompt, resume)
+		if err == nil {
+			return nil
+		}
+
+		delay, ok := sm.config.Retries.backoff(failed)
+		if !ok {
+			return err
+		}
+		fmt.Printf("\n%s %v; retrying %d/%d in %s…\n",
+			errorStyle.Render("🔁 [Retry]"), err, failed+1, sm.config.Retries.MaxAttempts, delay)
+		time.Sleep(delay)
+		sm.CurrentSessionID = sessionID
+	}
+}
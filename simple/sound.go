@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Sound cues for events, so users working in another window can tell what
+// happened. Enabled by setting CC_CUSTOM_SOUND; an external player and
+// per-cue sound files can be set with CC_CUSTOM_SOUND_PLAYER and
+// CC_CUSTOM_SOUND_TURN / _ERROR / _APPROVAL.
+const (
+	cueTurnComplete = "TURN"
+	cueError        = "ERROR"
+	cueApproval     = "APPROVAL"
+)
+
+// cueBells makes the terminal bell fallback distinguishable per cue
+var cueBells = map[string]int{
+	cueTurnComplete: 1,
+	cueError:        2,
+	cueApproval:     3,
+}
+
+// playCue announces an event with the configured sound or the terminal bell
+func playCue(cue string) {
+	if mode := os.Getenv("CC_CUSTOM_SOUND"); mode == "" || mode == "off" {
+		return
+	}
+
+	player := os.Getenv("CC_CUSTOM_SOUND_PLAYER")
+	file := os.Getenv("CC_CUSTOM_SOUND_" + cue)
+	if player != "" && file != "" {
+		fields := strings.Fields(player)
+		cmd := exec.Command(fields[0], append(fields[1:], file)...)
+		if err := cmd.Start(); err == nil {
+			go cmd.Wait()
+			return
+		}
+	}
+
+	for i := 0; i < cueBells[cue]; i++ {
+		if i > 0 {
+			time.Sleep(150 * time.Millisecond)
+		}
+		os.Stdout.WriteString("\a")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"customclaude/pkg/claudecli"
+)
+
+// stderrLimit bounds the stderr lines kept for /stderr
+const stderrLimit = 500
+
+// stderrLog keeps what claude wrote to stderr, so it can be summed up after
+// each turn instead of interleaving with the stream
+type stderrLog struct {
+	mu    sync.Mutex
+	lines []claudecli.StderrLine
+}
+
+// collect reads r to the end, keeping its lines, and returns the lines read
+func (l *stderrLog) collect(r io.Reader) []claudecli.StderrLine {
+	var turn []claudecli.StderrLine
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := claudecli.NewStderrLine(scanner.Text())
+		turn = append(turn, line)
+		l.mu.Lock()
+		l.lines = append(l.lines, line)
+		if len(l.lines) > stderrLimit {
+			l.lines = l.lines[len(l.lines)-stderrLimit:]
+		}
+		l.mu.Unlock()
+	}
+	return turn
+}
+
+// filtered returns the kept lines at or above a severity
+func (l *stderrLog) filtered(severity claudecli.Severity) []claudecli.StderrLine {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var lines []claudecli.StderrLine
+	for _, line := range l.lines {
+		if line.Severity >= severity {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// stderrSummary describes the warnings and errors among lines, or returns ""
+// when there are none
+func stderrSummary(lines []claudecli.StderrLine) string {
+	var warnings, errors int
+	for _, line := range lines {
+		switch line.Severity {
+		case claudecli.SeverityWarning:
+			warnings++
+		case claudecli.SeverityError:
+			errors++
+		}
+	}
+	var parts []string
+	if errors > 0 {
+		parts = append(parts, plural(errors, "error"))
+	}
+	if warnings > 0 {
+		parts = append(parts, plural(warnings, "warning"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// handleStderrCommand runs "/stderr [all|warn|error]", printing the kept
+// stderr lines at or above a severity
+func (sm *SessionManager) handleStderrCommand(args []string) error {
+	severity := claudecli.SeverityInfo
+	if len(args) > 0 {
+		var err error
+		if severity, err = claudecli.ParseSeverity(args[0]); err != nil {
+			return err
+		}
+	}
+	lines := sm.stderr.filtered(severity)
+	if len(lines) == 0 {
+		fmt.Print(subtitleStyle.Render(fmt.Sprintf("No stderr lines at %s or above", severity)))
+		fmt.Print("\n")
+		return nil
+	}
+	for _, line := range lines {
+		text := fmt.Sprintf("%s %-7s %s", line.Received.Format("15:04:05"), line.Severity, line.Text)
+		switch line.Severity {
+		case claudecli.SeverityError:
+			text = errorStyle.Render(text)
+		case claudecli.SeverityInfo:
+			text = toolTimeStyle.Render(text)
+		}
+		fmt.Println(text)
+	}
+	return nil
+}
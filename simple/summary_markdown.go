@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// gitDiffstat returns the short diffstat of uncommitted changes in the
+// working directory, or an empty string outside a git repository
+func gitDiffstat() string {
+	out, err := exec.Command("git", "diff", "--shortstat", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// SummaryMarkdown formats the conversation summary as Markdown for pasting
+// into standups, PR descriptions or time-tracking notes
+func (sm *SessionManager) SummaryMarkdown() string {
+	totalTokens := sm.CumulativeUsage.InputTokens +
+		sm.CumulativeUsage.CacheCreationInputTokens +
+		sm.CumulativeUsage.CacheReadInputTokens +
+		sm.CumulativeUsage.OutputTokens
+
+	var b strings.Builder
+	b.WriteString("## Conversation Summary\n\n")
+	fmt.Fprintf(&b, "- **Duration:** %s\n", time.Since(sm.ConversationStart).Round(time.Second))
+	fmt.Fprintf(&b, "- **Sessions:** %d\n", len(sm.SessionChain))
+	fmt.Fprintf(&b, "- **Turns:** %d\n", sm.CumulativeTurns)
+	fmt.Fprintf(&b, "- **Cost:** $%.4f\n", sm.CumulativeCost)
+	fmt.Fprintf(&b, "- **Tokens:** %d total (input %d, output %d, cache read %d, cache creation %d)\n",
+		totalTokens,
+		sm.CumulativeUsage.InputTokens,
+		sm.CumulativeUsage.OutputTokens,
+		sm.CumulativeUsage.CacheReadInputTokens,
+		sm.CumulativeUsage.CacheCreationInputTokens)
+	if diffstat := gitDiffstat(); diffstat != "" {
+		fmt.Fprintf(&b, "- **Changes:** %s\n", diffstat)
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"customclaude/pkg/claudecli"
+)
+
+// systemPrompt returns the text passed with --append-system-prompt and where
+// it came from. A prompt set with /system set replaces the project file.
+func (sm *SessionManager) systemPrompt() (string, string) {
+	if sm.systemOverride != nil {
+		return *sm.systemOverride, "this session"
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("%s failed to get working directory: %v\n", errorStyle.Render("❌ [Error]"), err)
+		return "", ""
+	}
+	prompt, err := claudecli.DetectSystemPrompt(cwd)
+	if err != nil {
+		fmt.Printf("%s %v\n", errorStyle.Render("❌ [Error]"), err)
+		return "", ""
+	}
+	return prompt.Text, prompt.Source
+}
+
+// handleSystemCommand runs "/system [show|edit|set <text>|clear|reset]"
+func (sm *SessionManager) handleSystemCommand(args string) error {
+	action, rest, _ := strings.Cut(args, " ")
+
+	switch action {
+	case "", "show":
+	case "edit":
+		if err := editSystemPrompt(); err != nil {
+			return err
+		}
+		sm.systemOverride = nil
+	case "set":
+		text := strings.TrimSpace(rest)
+		if text == "" {
+			return fmt.Errorf("usage: /system set <text>")
+		}
+		sm.systemOverride = &text
+	case "clear":
+		empty := ""
+		sm.systemOverride = &empty
+	case "reset":
+		sm.systemOverride = nil
+	default:
+		return fmt.Errorf("usage: /system [show|edit|set <text>|clear|reset]")
+	}
+
+	text, source := sm.systemPrompt()
+	if text == "" {
+		fmt.Print(subtitleStyle.Render("No system prompt is appended"))
+		fmt.Print("\n")
+		return nil
+	}
+	fmt.Printf("%s %s\n", metricStyle.Render("System prompt from"), valueStyle.Render(source))
+	fmt.Println(text)
+	return nil
+}
+
+// editSystemPrompt opens the project system prompt file in $EDITOR
+func editSystemPrompt() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	path := claudecli.SystemPromptPath(cwd)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create system prompt directory: %w", err)
+	}
+	return runEditor(path)
+}
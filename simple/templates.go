@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"customclaude/pkg/platform"
+	"customclaude/templates"
+)
+
+// fillTemplate lists the prompt templates when name is empty, and otherwise
+// asks for the named template's placeholders and returns the expanded
+// prompt. An empty prompt means there is nothing to send.
+func fillTemplate(editor *lineEditor, name string) (string, error) {
+	dir, err := platform.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = templates.Dir(dir)
+	list, err := templates.Load(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		if len(list) == 0 {
+			fmt.Println(subtitleStyle.Render(fmt.Sprintf("No templates yet; add <name>.md files to %s", dir)))
+			return "", nil
+		}
+		fmt.Println(commandStyle.Render("Templates:"))
+		for _, t := range list {
+			fmt.Println(helpStyle.Render(fmt.Sprintf("  %s - %s", t.Name, t.Summary())))
+		}
+		return "", nil
+	}
+
+	t, ok := templates.Find(list, name)
+	if !ok {
+		return "", fmt.Errorf("no template matches %q", name)
+	}
+	values := make(map[string]string)
+	for _, placeholder := range t.Placeholders() {
+		value, err := editor.ReadLine(promptStyle.Render(placeholder + ": "))
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", placeholder, err)
+		}
+		values[placeholder] = value
+	}
+	return t.Expand(values), nil
+}
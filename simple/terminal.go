@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// setupTerminal prepares stdout for the styles and returns a func undoing
+// it. Windows consoles need virtual terminal processing for ANSI sequences;
+// where it cannot be enabled, as in the legacy console, output degrades to
+// plain text. NO_COLOR and limited terminals are already handled by
+// lipgloss's color detection.
+func setupTerminal() func() {
+	restore, err := termenv.EnableVirtualTerminalProcessing(termenv.DefaultOutput())
+	if err != nil {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		return func() {}
+	}
+	return func() { restore() }
+}
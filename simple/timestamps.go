@@ -0,0 +1,12 @@
+package main
+
+import "time"
+
+// eventTime returns the timestamp of the stream line being processed, falling
+// back to the current time outside of stream processing
+func (sm *SessionManager) eventTime() time.Time {
+	if sm.lineTime.IsZero() {
+		return time.Now()
+	}
+	return sm.lineTime
+}
@@ -0,0 +1,127 @@
+// Package templates loads named prompt templates shared by the simple CLI and
+// the TUI. A template is a file in the templates directory whose name, minus
+// its extension, names the template; {{placeholder}} fields are filled in
+// before it is sent.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// extensions are the file types read as templates
+var extensions = []string{".md", ".txt"}
+
+// placeholderPattern matches {{name}}, allowing spaces inside the braces
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// Template is a named prompt with placeholders
+type Template struct {
+	Name string
+	Path string
+	Body string
+}
+
+// Dir returns the templates directory inside a configuration directory
+func Dir(configDir string) string {
+	return filepath.Join(configDir, "templates")
+}
+
+// Load reads the templates in dir, sorted by name. A missing directory has
+// no templates.
+func Load(dir string) ([]Template, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	var list []Template
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !isTemplateExt(ext) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", entry.Name(), err)
+		}
+		list = append(list, Template{
+			Name: strings.TrimSuffix(entry.Name(), ext),
+			Path: path,
+			Body: strings.TrimRight(string(data), "\n"),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func isTemplateExt(ext string) bool {
+	for _, allowed := range extensions {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the template called name, or the only one whose name starts
+// with it
+func Find(list []Template, name string) (Template, bool) {
+	var match Template
+	matches := 0
+	for _, t := range list {
+		if t.Name == name {
+			return t, true
+		}
+		if strings.HasPrefix(t.Name, name) {
+			match = t
+			matches++
+		}
+	}
+	return match, matches == 1
+}
+
+// Placeholders returns the placeholder names of the template in order of
+// first use
+func (t Template) Placeholders() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(t.Body, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Expand fills in the placeholders. Placeholders without a value are left as
+// written.
+func (t Template) Expand(values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(t.Body, func(field string) string {
+		name := placeholderPattern.FindStringSubmatch(field)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return field
+	})
+}
+
+// Summary returns the first non-empty line of the template, for listings
+func (t Template) Summary() string {
+	for _, line := range strings.Split(t.Body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}